@@ -0,0 +1,100 @@
+// breach-filter builds the bloom filter the server screens passwords with
+// from breach corpora in the Have I Been Pwned format. the input is either
+// a directory of range files or a file of full HASH:COUNT lines, like the
+// downloadable pwned passwords list
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sentinel-auth-backend/internal/breach"
+	"strings"
+)
+
+// eachHash calls visit with the full sha1 of every password in the input
+func eachHash(input string, minCount int, visit func(hash string) error) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return eachLine(input, "", minCount, visit)
+	}
+
+	return filepath.WalkDir(input, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		prefix := strings.TrimSuffix(entry.Name(), ".txt")
+		if len(prefix) != 5 {
+			return nil
+		}
+		return eachLine(path, strings.ToUpper(prefix), minCount, visit)
+	})
+}
+
+func eachLine(path string, prefix string, minCount int, visit func(hash string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, count, ok := breach.ParseRangeLine(scanner.Text())
+		if !ok || count < minCount {
+			continue
+		}
+		if err := visit(prefix + strings.ToUpper(hash)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func main() {
+	input := flag.String("in", "", "range directory or HASH:COUNT file to read")
+	output := flag.String("out", "breached-passwords.bloom", "filter file to write")
+	falsePositiveRate := flag.Float64("fp", 0.001, "share of other passwords the filter wrongly matches")
+	minCount := flag.Int("min-count", 1, "leave out passwords seen in fewer breaches")
+	flag.Parse()
+
+	if *input == "" || *falsePositiveRate <= 0 || *falsePositiveRate >= 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// one pass to count, so the filter can be sized before the second
+	var entries uint64
+	err := eachHash(*input, *minCount, func(string) error {
+		entries++
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filter := breach.NewBloomFilter(entries, *falsePositiveRate)
+	if err := eachHash(*input, *minCount, filter.AddHash); err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := filter.WriteTo(file); err != nil {
+		log.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("wrote %d passwords to %s", entries, *output)
+}
@@ -0,0 +1,96 @@
+// import-users brings users exported from another system, like firebase auth
+// or a django app, into a client. it does the same as the admin import
+// endpoint without its size limit, reading json (a list of users or
+// firebase's auth:export file) or csv with a header row
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/database"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	clientId := flag.String("client", "", "client to import the users into")
+	input := flag.String("in", "", "json or csv file to read")
+	format := flag.String("format", "", "json or csv, guessed from the file extension when empty")
+	batchSize := flag.Int("batch-size", 500, "users written per transaction")
+
+	var hashConfig auth.ForeignHashConfig
+	flag.StringVar(&hashConfig.Algorithm, "hash-algorithm", "", "algorithm of users that don't name their own: firebase_scrypt, pbkdf2_sha256, bcrypt, argon2id, sha1, sha256 or sha512")
+	flag.StringVar(&hashConfig.FirebaseSignerKey, "firebase-signer-key", "", "base64 signer key from the firebase console")
+	flag.StringVar(&hashConfig.FirebaseSaltSeparator, "firebase-salt-separator", "", "base64 salt separator from the firebase console")
+	flag.IntVar(&hashConfig.FirebaseRounds, "firebase-rounds", 8, "rounds from the firebase console")
+	flag.IntVar(&hashConfig.FirebaseMemCost, "firebase-mem-cost", 14, "memory cost from the firebase console")
+	flag.StringVar(&hashConfig.SaltPosition, "salt-position", "suffix", "for salted sha, whether the salt goes before (prefix) or after (suffix) the password")
+	flag.StringVar(&hashConfig.SaltEncoding, "salt-encoding", "raw", "for salted sha, raw, base64 or hex")
+	flag.Parse()
+
+	if *clientId == "" || *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*input)), ".")
+	}
+
+	var reader io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var users []auth.ImportUser
+	var err error
+	switch *format {
+	case "json":
+		users, err = auth.ParseImportUsersJson(reader)
+	case "csv":
+		users, err = auth.ParseImportUsersCsv(reader)
+	default:
+		log.Fatal("format must be json or csv")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	appConfig, err := config.InitConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db := database.SetupDb(appConfig)
+	// every batch insert would be logged in full otherwise
+	db = db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Warn)})
+
+	result, err := auth.ImportUsers(db, *clientId, auth.ImportUsersInput{
+		Users:      users,
+		HashConfig: hashConfig,
+		BatchSize:  *batchSize,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("imported %d users, skipped %d already registered, %d failed", result.Imported, result.Skipped, len(result.Failures))
+	if len(result.Failures) > 0 {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, failure := range result.Failures {
+			encoder.Encode(failure)
+		}
+		os.Exit(1)
+	}
+}
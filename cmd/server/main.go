@@ -1,12 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/breach"
 	"sentinel-auth-backend/internal/config"
 	"sentinel-auth-backend/internal/database"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/middleware"
+	"sentinel-auth-backend/internal/ratelimit"
 	"sentinel-auth-backend/internal/routes"
 	"sentinel-auth-backend/internal/server"
+	"sentinel-auth-backend/internal/sms"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -23,9 +38,77 @@ func main() {
 	db := database.SetupDb(appConfig)
 	database.SeedDb(db, appConfig)
 
-	server := server.Create(db, &appConfig)
+	mailer, err := mail.FromConfig(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hashParams, err := auth.PasswordHashParamsFromConfig(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SetPasswordHashParams(hashParams)
+
+	gracePeriod, err := auth.AccountDeletionGracePeriodFromConfig(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SetAccountDeletionGracePeriod(gracePeriod)
+
+	if appConfig.BREACHED_PASSWORDS != "" {
+		dataset, err := breach.Open(appConfig.BREACHED_PASSWORDS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		breach.SetDataset(dataset)
+	}
+
+	smsSender, err := sms.FromConfig(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SetSmsGatewayConfigured(smsSender != nil)
+	if smsSender == nil {
+		// codes for clients that already use the phone provider would go nowhere
+		phoneEnabled, err := auth.IsPhoneProviderEnabled(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if phoneEnabled {
+			log.Fatal("The phone provider is enabled for a client but SMS_SENDER isn't set")
+		}
+	}
+
+	// account events are logged and kept for users' exports
+	events.SubscribeAll(func(event events.Event) {
+		log.Printf("event %s client=%s user=%s data=%v", event.Type, event.ClientId, event.UserId, event.Data)
+	})
+	events.SubscribeAll(auth.AuditLog(db))
+
+	// picks up exports left over by a restart and drops expired archives
+	auth.StartUserExportWorker(db, time.Minute)
+	// erases deleted accounts once their grace period ended
+	auth.StartAccountPurgeWorker(db, time.Hour)
+
+	server := server.Create(db, &appConfig, mailer, smsSender)
 
 	router := gin.Default()
+	// X-Forwarded-For decides the ip requests are limited and locked by, so
+	// only believe it from our own proxies
+	trustedProxies := []string{}
+	for _, proxy := range strings.Split(appConfig.TRUSTED_PROXIES, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal(err)
+	}
+
+	limitStore, limitRoutes, err := ratelimit.FromConfig(appConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	wrapper := api.ServerInterfaceWrapper{
 		Handler: server,
@@ -35,9 +118,13 @@ func main() {
 	corsConfig.AllowOrigins = []string{"*"}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	corsConfig.ExposeHeaders = []string{"Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Password-Warning"}
 	corsConfig.AllowCredentials = true
 	router.Use(cors.New(corsConfig))
 
+	// limits are configured per route, see ratelimit.DefaultRoutes
+	router.Use(middleware.RateLimit(limitStore, limitRoutes, appConfig.RATE_LIMIT_FAIL_MODE == "closed"))
+
 	// very basic versioning
 	v1 := router.Group("v1")
 
@@ -45,9 +132,35 @@ func main() {
 	routes.RegisterRootRoutes(v1, &wrapper)
 
 	// register nested routes
-	routes.RegisterAdminRoutes(v1.Group("/admin"), &wrapper)
+	routes.RegisterAdminRoutes(v1.Group("/admin", middleware.RequireAdmin(db)), &wrapper)
 	routes.RegisterAuthRoutes(v1.Group("/auth"), &wrapper)
-	routes.RegisterUserRoutes(v1.Group("/user"), &wrapper)
+	routes.RegisterUserRoutes(v1.Group("/user", middleware.RequireUser(db)), &wrapper)
+	routes.RegisterClientRoutes(v1.Group("/clients"), &wrapper)
+
+	// listen and serve on 0.0.0.0:8080
+	httpServer := &http.Server{
+		Addr:              appConfig.API_ADDR,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	// finish running requests first, they may still queue emails
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed shutting down: %v", err)
+	}
 
-	router.Run(appConfig.API_ADDR) // listen and serve on 0.0.0.0:8080
+	// delivers the emails still queued
+	mailer.Close()
 }
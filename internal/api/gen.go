@@ -6,22 +6,152 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ApiResourceRequestSigningAlgorithm.
+const (
+	HS256 ApiResourceRequestSigningAlgorithm = "HS256"
+	RS256 ApiResourceRequestSigningAlgorithm = "RS256"
+)
+
+// Defines values for AuthFlowResponseNextStep.
+const (
+	Consent        AuthFlowResponseNextStep = "consent"
+	Mfa            AuthFlowResponseNextStep = "mfa"
+	Reauthenticate AuthFlowResponseNextStep = "reauthenticate"
+)
+
+// Defines values for ClientInformationResponseGrantTypes.
+const (
+	ClientInformationResponseGrantTypesAuthorizationCode ClientInformationResponseGrantTypes = "authorization_code"
+	ClientInformationResponseGrantTypesRefreshToken      ClientInformationResponseGrantTypes = "refresh_token"
+)
+
+// Defines values for ClientInformationResponseTokenEndpointAuthMethod.
+const (
+	ClientInformationResponseTokenEndpointAuthMethodNone          ClientInformationResponseTokenEndpointAuthMethod = "none"
+	ClientInformationResponseTokenEndpointAuthMethodPrivateKeyJwt ClientInformationResponseTokenEndpointAuthMethod = "private_key_jwt"
+)
+
+// Defines values for ClientMetadataGrantTypes.
+const (
+	ClientMetadataGrantTypesAuthorizationCode ClientMetadataGrantTypes = "authorization_code"
+	ClientMetadataGrantTypesRefreshToken      ClientMetadataGrantTypes = "refresh_token"
+)
+
+// Defines values for ClientMetadataTokenEndpointAuthMethod.
+const (
+	ClientMetadataTokenEndpointAuthMethodNone          ClientMetadataTokenEndpointAuthMethod = "none"
+	ClientMetadataTokenEndpointAuthMethodPrivateKeyJwt ClientMetadataTokenEndpointAuthMethod = "private_key_jwt"
+)
+
 // Defines values for EmailLoginRequestCodeChallengeMethod.
 const (
 	EmailLoginRequestCodeChallengeMethodS256 EmailLoginRequestCodeChallengeMethod = "S256"
 )
 
+// Defines values for EmailOtpRequestCodeChallengeMethod.
+const (
+	EmailOtpRequestCodeChallengeMethodS256 EmailOtpRequestCodeChallengeMethod = "S256"
+)
+
 // Defines values for EmailRegistrationRequestCodeChallengeMethod.
 const (
 	EmailRegistrationRequestCodeChallengeMethodS256 EmailRegistrationRequestCodeChallengeMethod = "S256"
 )
 
+// Defines values for MagicLinkRequestCodeChallengeMethod.
+const (
+	MagicLinkRequestCodeChallengeMethodS256 MagicLinkRequestCodeChallengeMethod = "S256"
+)
+
+// Defines values for MfaVerifyRequestFactorType.
+const (
+	MfaVerifyRequestFactorTypePasskey      MfaVerifyRequestFactorType = "passkey"
+	MfaVerifyRequestFactorTypeRecoveryCode MfaVerifyRequestFactorType = "recovery_code"
+	MfaVerifyRequestFactorTypeTotp         MfaVerifyRequestFactorType = "totp"
+)
+
+// Defines values for PasskeySignInRequestCodeChallengeMethod.
+const (
+	PasskeySignInRequestCodeChallengeMethodS256 PasskeySignInRequestCodeChallengeMethod = "S256"
+)
+
+// Defines values for PhoneCodeRequestCodeChallengeMethod.
+const (
+	PhoneCodeRequestCodeChallengeMethodS256 PhoneCodeRequestCodeChallengeMethod = "S256"
+)
+
+// Defines values for ReauthenticateRequestFactorType.
+const (
+	ReauthenticateRequestFactorTypePasskey  ReauthenticateRequestFactorType = "passkey"
+	ReauthenticateRequestFactorTypePassword ReauthenticateRequestFactorType = "password"
+	ReauthenticateRequestFactorTypeTotp     ReauthenticateRequestFactorType = "totp"
+)
+
+// Defines values for StepUpRequestCodeChallengeMethod.
+const (
+	S256 StepUpRequestCodeChallengeMethod = "S256"
+)
+
+// AccountDeletion defines model for AccountDeletion.
+type AccountDeletion struct {
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// DeletedBy user or admin
+	DeletedBy string `json:"deleted_by"`
+
+	// PurgeAt Until when the account can be restored
+	PurgeAt time.Time `json:"purge_at"`
+	UserId  string    `json:"user_id"`
+}
+
+// AdminUserMfa defines model for AdminUserMfa.
+type AdminUserMfa struct {
+	Factors []MfaFactor `json:"factors"`
+
+	// Passkeys Number of passkeys the user registered
+	Passkeys               int `json:"passkeys"`
+	RecoveryCodesRemaining int `json:"recovery_codes_remaining"`
+}
+
+// ApiResource defines model for ApiResource.
+type ApiResource struct {
+	AccessTokenLifetime int      `json:"access_token_lifetime"`
+	Id                  string   `json:"id"`
+	Identifier          string   `json:"identifier"`
+	Name                string   `json:"name"`
+	Scopes              []string `json:"scopes"`
+	SigningAlgorithm    string   `json:"signing_algorithm"`
+
+	// SigningSecret For HS256 apis, the key their access tokens are signed with. Keep it on the api's servers, clients never get it
+	SigningSecret *string `json:"signing_secret,omitempty"`
+}
+
+// ApiResourceRequest defines model for ApiResourceRequest.
+type ApiResourceRequest struct {
+	// AccessTokenLifetime In seconds, defaults to 3600
+	AccessTokenLifetime *int `json:"access_token_lifetime,omitempty"`
+
+	// Identifier Absolute uri that becomes the aud of access tokens for this api
+	Identifier string  `json:"identifier"`
+	Name       *string `json:"name,omitempty"`
+
+	// Scopes Scopes from the registry this api understands
+	Scopes *[]string `json:"scopes,omitempty"`
+
+	// SigningAlgorithm RS256 tokens can be checked offline against /.well-known/jwks.json, HS256 tokens are signed with the client secret
+	SigningAlgorithm *ApiResourceRequestSigningAlgorithm `json:"signing_algorithm,omitempty"`
+}
+
+// ApiResourceRequestSigningAlgorithm RS256 tokens can be checked offline against /.well-known/jwks.json, HS256 tokens are signed with the client secret
+type ApiResourceRequestSigningAlgorithm string
+
 // AuthCodeResponse defines model for AuthCodeResponse.
 type AuthCodeResponse struct {
 	// Code Authentication code to be exchanged for tokens
@@ -32,15 +162,45 @@ type AuthCodeResponse struct {
 	State     *string `json:"state,omitempty"`
 }
 
+// AuthFlowResponse defines model for AuthFlowResponse.
+type AuthFlowResponse struct {
+	// ExpiresIn Seconds left to finish the sign in
+	ExpiresIn int `json:"expires_in"`
+
+	// FlowToken Opaque token identifying the sign in until it is finished
+	FlowToken string `json:"flow_token"`
+
+	// MfaFactors Second factor types the user can answer an mfa step with, or for reauthenticate the ways (password and second factors) to prove who they are again
+	MfaFactors *[]string `json:"mfa_factors,omitempty"`
+
+	// NextStep What the user has to do next
+	NextStep AuthFlowResponseNextStep `json:"next_step"`
+	State    *string                  `json:"state,omitempty"`
+}
+
+// AuthFlowResponseNextStep What the user has to do next
+type AuthFlowResponseNextStep string
+
 // AuthRefreshRequest defines model for AuthRefreshRequest.
 type AuthRefreshRequest struct {
-	ClientId string `json:"client_id"`
+	// ClientAssertion Signed jwt proving the caller holds one of the client's registered keys (RFC 7523)
+	ClientAssertion *string `json:"client_assertion,omitempty"`
+
+	// ClientAssertionType Must be urn:ietf:params:oauth:client-assertion-type:jwt-bearer when authenticating with private_key_jwt
+	ClientAssertionType *string `json:"client_assertion_type,omitempty"`
+	ClientId            string  `json:"client_id"`
 
 	// CodeVerifier Original code verifier used to generate the code challenge
 	CodeVerifier string `json:"code_verifier"`
 
 	// RefreshToken A refresh token issued for the client_id
 	RefreshToken string `json:"refresh_token"`
+
+	// Resource Identifier of the api (RFC 8707) the access token is for. Must be one requested during authorization
+	Resource *string `json:"resource,omitempty"`
+
+	// Scope Space delimited subset of the originally granted scopes to narrow the access token down to
+	Scope *string `json:"scope,omitempty"`
 }
 
 // AuthRefreshTokensResponse defines model for AuthRefreshTokensResponse.
@@ -48,30 +208,51 @@ type AuthRefreshTokensResponse struct {
 	AccessToken string `json:"access_token"`
 	ExpiresIn   int    `json:"expires_in"`
 	IdToken     string `json:"id_token"`
+
+	// Scope Space delimited scopes granted to the access token
+	Scope *string `json:"scope,omitempty"`
 }
 
 // AuthTokenRequest defines model for AuthTokenRequest.
 type AuthTokenRequest struct {
-	ClientId string `json:"client_id"`
+	// ClientAssertion Signed jwt proving the caller holds one of the client's registered keys (RFC 7523)
+	ClientAssertion *string `json:"client_assertion,omitempty"`
+
+	// ClientAssertionType Must be urn:ietf:params:oauth:client-assertion-type:jwt-bearer when authenticating with private_key_jwt
+	ClientAssertionType *string `json:"client_assertion_type,omitempty"`
+	ClientId            string  `json:"client_id"`
 
 	// Code Auth code returned from sign in and sign up methods for sentinel tokens
 	Code string `json:"code"`
 
 	// CodeVerifier Original code verifier used to generate the code challenge
 	CodeVerifier string `json:"code_verifier"`
+
+	// Resource Identifier of the api (RFC 8707) the access token is for. Must be one requested during authorization
+	Resource *string `json:"resource,omitempty"`
 }
 
 // AuthTokenTokensResponse defines model for AuthTokenTokensResponse.
 type AuthTokenTokensResponse struct {
-	AccessToken  string `json:"access_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	IdToken      string `json:"id_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IdToken     string `json:"id_token"`
+
+	// RefreshToken Left out for clients that didn't register the refresh_token grant type
+	RefreshToken *string `json:"refresh_token,omitempty"`
+
+	// Scope Space delimited scopes granted to the access token
+	Scope *string `json:"scope,omitempty"`
 }
 
 // AuthVerifyRequest defines model for AuthVerifyRequest.
 type AuthVerifyRequest struct {
-	ClientId string `json:"client_id"`
+	// ClientAssertion Signed jwt proving the caller holds one of the client's registered keys (RFC 7523)
+	ClientAssertion *string `json:"client_assertion,omitempty"`
+
+	// ClientAssertionType Must be urn:ietf:params:oauth:client-assertion-type:jwt-bearer when authenticating with private_key_jwt
+	ClientAssertionType *string `json:"client_assertion_type,omitempty"`
+	ClientId            string  `json:"client_id"`
 
 	// Token Id or access token as jwt string
 	Token string `json:"token"`
@@ -84,8 +265,169 @@ type AuthVerifyResponse struct {
 	Valid  bool                   `json:"valid"`
 }
 
+// ChangePasswordRequest defines model for ChangePasswordRequest.
+type ChangePasswordRequest struct {
+	// CurrentPassword Required when the user already has a password
+	CurrentPassword *string `json:"current_password,omitempty"`
+	NewPassword     string  `json:"new_password"`
+
+	// SignOutOtherSessions Revoke every session except the one making this request
+	SignOutOtherSessions *bool `json:"sign_out_other_sessions,omitempty"`
+}
+
+// ClientInformationResponse defines model for ClientInformationResponse.
+type ClientInformationResponse struct {
+	ClientId         string  `json:"client_id"`
+	ClientIdIssuedAt int64   `json:"client_id_issued_at"`
+	ClientName       *string `json:"client_name,omitempty"`
+
+	// GrantTypes Defaults to authorization_code and refresh_token
+	GrantTypes *[]ClientInformationResponseGrantTypes `json:"grant_types,omitempty"`
+
+	// Jwks The client's keys for private_key_jwt, mutually exclusive with jwks_uri
+	Jwks *map[string]interface{} `json:"jwks,omitempty"`
+
+	// JwksUri Where the client publishes its keys for private_key_jwt
+	JwksUri *string `json:"jwks_uri,omitempty"`
+	LogoUri *string `json:"logo_uri,omitempty"`
+
+	// RedirectUris Uris the client may ask users to be sent back to
+	RedirectUris []string `json:"redirect_uris"`
+
+	// RegistrationAccessToken Bearer token for reading, updating and deleting this client. Only returned on registration
+	RegistrationAccessToken *string `json:"registration_access_token,omitempty"`
+	RegistrationClientUri   string  `json:"registration_client_uri"`
+
+	// RequireEmailVerification Whether users have to verify their email before signing in with a password. Defaults to false
+	RequireEmailVerification *bool `json:"require_email_verification,omitempty"`
+
+	// Scope Space delimited scopes from the registry the client may request. Defaults to the standard OpenID Connect scopes
+	Scope *string `json:"scope,omitempty"`
+
+	// TokenEndpointAuthMethod Defaults to none
+	TokenEndpointAuthMethod *ClientInformationResponseTokenEndpointAuthMethod `json:"token_endpoint_auth_method,omitempty"`
+}
+
+// ClientInformationResponseGrantTypes defines model for ClientInformationResponse.GrantTypes.
+type ClientInformationResponseGrantTypes string
+
+// ClientInformationResponseTokenEndpointAuthMethod Defaults to none
+type ClientInformationResponseTokenEndpointAuthMethod string
+
+// ClientMetadata defines model for ClientMetadata.
+type ClientMetadata struct {
+	ClientName *string `json:"client_name,omitempty"`
+
+	// GrantTypes Defaults to authorization_code and refresh_token
+	GrantTypes *[]ClientMetadataGrantTypes `json:"grant_types,omitempty"`
+
+	// Jwks The client's keys for private_key_jwt, mutually exclusive with jwks_uri
+	Jwks *map[string]interface{} `json:"jwks,omitempty"`
+
+	// JwksUri Where the client publishes its keys for private_key_jwt
+	JwksUri *string `json:"jwks_uri,omitempty"`
+	LogoUri *string `json:"logo_uri,omitempty"`
+
+	// RedirectUris Uris the client may ask users to be sent back to
+	RedirectUris []string `json:"redirect_uris"`
+
+	// RequireEmailVerification Whether users have to verify their email before signing in with a password. Defaults to false
+	RequireEmailVerification *bool `json:"require_email_verification,omitempty"`
+
+	// Scope Space delimited scopes from the registry the client may request. Defaults to the standard OpenID Connect scopes
+	Scope *string `json:"scope,omitempty"`
+
+	// TokenEndpointAuthMethod Defaults to none
+	TokenEndpointAuthMethod *ClientMetadataTokenEndpointAuthMethod `json:"token_endpoint_auth_method,omitempty"`
+}
+
+// ClientMetadataGrantTypes defines model for ClientMetadata.GrantTypes.
+type ClientMetadataGrantTypes string
+
+// ClientMetadataTokenEndpointAuthMethod Defaults to none
+type ClientMetadataTokenEndpointAuthMethod string
+
+// ClientProviderRequest defines model for ClientProviderRequest.
+type ClientProviderRequest struct {
+	// Data Provider specific settings
+	Data    *map[string]interface{} `json:"data,omitempty"`
+	Enabled bool                    `json:"enabled"`
+}
+
+// ClientScopes defines model for ClientScopes.
+type ClientScopes struct {
+	ClientId *string  `json:"client_id,omitempty"`
+	Scopes   []string `json:"scopes"`
+}
+
+// ConsentGrant defines model for ConsentGrant.
+type ConsentGrant struct {
+	ClientId   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	GrantedAt  time.Time `json:"granted_at"`
+	LogoUri    *string   `json:"logo_uri,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ConsentRequest defines model for ConsentRequest.
+type ConsentRequest struct {
+	Approve   bool   `json:"approve"`
+	FlowToken string `json:"flow_token"`
+}
+
+// ConsentScope defines model for ConsentScope.
+type ConsentScope struct {
+	Description string `json:"description"`
+
+	// Granted Whether the user already shares this scope with the client
+	Granted bool   `json:"granted"`
+	Id      string `json:"id"`
+}
+
+// ConsentScreenResponse defines model for ConsentScreenResponse.
+type ConsentScreenResponse struct {
+	ClientId   string         `json:"client_id"`
+	ClientName string         `json:"client_name"`
+	LogoUri    *string        `json:"logo_uri,omitempty"`
+	Scopes     []ConsentScope `json:"scopes"`
+}
+
+// CreateScopeRequest defines model for CreateScopeRequest.
+type CreateScopeRequest struct {
+	// Description Human readable explanation shown to users
+	Description *string `json:"description,omitempty"`
+
+	// Name Scope token as it will appear in scope parameters, e.g. invoices:read
+	Name string `json:"name"`
+}
+
+// Credential defines model for Credential.
+type Credential struct {
+	CreatedAt     time.Time `json:"created_at"`
+	EmailVerified bool      `json:"email_verified"`
+	HasPassword   bool      `json:"has_password"`
+	Id            string    `json:"id"`
+
+	// Identifier Email, phone number or subject at the provider
+	Identifier string `json:"identifier"`
+	Provider   string `json:"provider"`
+}
+
+// DeleteAccountRequest defines model for DeleteAccountRequest.
+type DeleteAccountRequest struct {
+	// Locale Language of the email
+	Locale *string `json:"locale,omitempty"`
+
+	// RedirectUri Page of the client app the restore link opens with the token, one of the client's redirect uris. Defaults to the first one
+	RedirectUri *string `json:"redirect_uri,omitempty"`
+}
+
 // EmailLoginRequest defines model for EmailLoginRequest.
 type EmailLoginRequest struct {
+	// AcrValues Space delimited acr values (aal1, aal2) in order of preference. Users without a second factor can't sign in when aal2 is the only one
+	AcrValues *string `json:"acr_values,omitempty"`
+
 	// ClientId Client application ID
 	ClientId            string                               `json:"client_id"`
 	CodeChallenge       string                               `json:"code_challenge"`
@@ -99,12 +441,45 @@ type EmailLoginRequest struct {
 
 	// RedirectUri URI to redirect after authentication
 	RedirectUri *string `json:"redirect_uri,omitempty"`
-	State       *string `json:"state,omitempty"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
 }
 
 // EmailLoginRequestCodeChallengeMethod defines model for EmailLoginRequest.CodeChallengeMethod.
 type EmailLoginRequestCodeChallengeMethod string
 
+// EmailOtpRequest defines model for EmailOtpRequest.
+type EmailOtpRequest struct {
+	// AcrValues Space delimited acr values (aal1, aal2) in order of preference. Users without a second factor can't sign in when aal2 is the only one
+	AcrValues           *string                            `json:"acr_values,omitempty"`
+	ClientId            string                             `json:"client_id"`
+	CodeChallenge       string                             `json:"code_challenge"`
+	CodeChallengeMethod EmailOtpRequestCodeChallengeMethod `json:"code_challenge_method"`
+	Email               openapi_types.Email                `json:"email"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
+}
+
+// EmailOtpRequestCodeChallengeMethod defines model for EmailOtpRequest.CodeChallengeMethod.
+type EmailOtpRequestCodeChallengeMethod string
+
+// EmailOtpVerifyRequest defines model for EmailOtpVerifyRequest.
+type EmailOtpVerifyRequest struct {
+	ClientId string              `json:"client_id"`
+	Code     string              `json:"code"`
+	Email    openapi_types.Email `json:"email"`
+}
+
 // EmailRegistrationRequest defines model for EmailRegistrationRequest.
 type EmailRegistrationRequest struct {
 	// ClientId Client application ID
@@ -123,12 +498,30 @@ type EmailRegistrationRequest struct {
 
 	// RedirectUri URI to redirect after authentication
 	RedirectUri *string `json:"redirect_uri,omitempty"`
-	State       *string `json:"state,omitempty"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
 }
 
 // EmailRegistrationRequestCodeChallengeMethod defines model for EmailRegistrationRequest.CodeChallengeMethod.
 type EmailRegistrationRequestCodeChallengeMethod string
 
+// EmailVerificationPolicy defines model for EmailVerificationPolicy.
+type EmailVerificationPolicy struct {
+	// RequireEmailVerification Whether users have to verify their email before signing in with a password
+	RequireEmailVerification bool `json:"require_email_verification"`
+}
+
+// EmailVerifiedResponse defines model for EmailVerifiedResponse.
+type EmailVerifiedResponse struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	// Error Error code
@@ -138,157 +531,1941 @@ type ErrorResponse struct {
 	ErrorDescription string `json:"error_description"`
 }
 
-// StrippedClientProvider defines model for StrippedClientProvider.
-type StrippedClientProvider struct {
-	ClientId       *string                 `json:"client_id,omitempty"`
-	Data           *map[string]interface{} `json:"data"`
-	Id             *string                 `json:"id,omitempty"`
-	ProviderOption *struct {
-		Description *string `json:"description,omitempty"`
-		Id          *string `json:"id,omitempty"`
-		LogoUrl     *string `json:"logo_url,omitempty"`
-		Name        *string `json:"name,omitempty"`
-	} `json:"provider_option,omitempty"`
-}
+// ExportJob defines model for ExportJob.
+type ExportJob struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
 
-// GetAuthProvidersParams defines parameters for GetAuthProviders.
-type GetAuthProvidersParams struct {
-	ClientId string `form:"client_id" json:"client_id"`
+	// ExpiresAt Until when the archive can be downloaded
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Id        string     `json:"id"`
+
+	// RequestedBy user or admin
+	RequestedBy string `json:"requested_by"`
+
+	// Size Bytes of the archive once done
+	Size *int `json:"size,omitempty"`
+
+	// Status pending, running, done or failed
+	Status string `json:"status"`
 }
 
-// PostAuthProvidersEmailLoginJSONRequestBody defines body for PostAuthProvidersEmailLogin for application/json ContentType.
-type PostAuthProvidersEmailLoginJSONRequestBody = EmailLoginRequest
+// ForgotPasswordRequest defines model for ForgotPasswordRequest.
+type ForgotPasswordRequest struct {
+	ClientId string              `json:"client_id"`
+	Email    openapi_types.Email `json:"email"`
 
-// PostAuthProvidersEmailRegisterJSONRequestBody defines body for PostAuthProvidersEmailRegister for application/json ContentType.
-type PostAuthProvidersEmailRegisterJSONRequestBody = EmailRegistrationRequest
+	// RedirectUri Registered redirect uri of the client's reset page, defaults to the first one. The token is added as a query parameter
+	RedirectUri *string `json:"redirect_uri,omitempty"`
+}
 
-// PostAuthRefreshJSONRequestBody defines body for PostAuthRefresh for application/json ContentType.
-type PostAuthRefreshJSONRequestBody = AuthRefreshRequest
+// ImportHashConfig How the other system hashed passwords
+type ImportHashConfig struct {
+	// Algorithm Algorithm of the users that don't name their own, one of firebase_scrypt, pbkdf2_sha256, bcrypt, argon2id, sha1, sha256 or sha512
+	Algorithm       *string `json:"algorithm,omitempty"`
+	FirebaseMemCost *int    `json:"firebase_mem_cost,omitempty"`
+	FirebaseRounds  *int    `json:"firebase_rounds,omitempty"`
 
-// PostAuthTokenJSONRequestBody defines body for PostAuthToken for application/json ContentType.
-type PostAuthTokenJSONRequestBody = AuthTokenRequest
+	// FirebaseSaltSeparator Base64 salt separator from the firebase console
+	FirebaseSaltSeparator *string `json:"firebase_salt_separator,omitempty"`
 
-// PostAuthVerifyJSONRequestBody defines body for PostAuthVerify for application/json ContentType.
-type PostAuthVerifyJSONRequestBody = AuthVerifyRequest
+	// FirebaseSignerKey Base64 signer key from the firebase console's password hash parameters
+	FirebaseSignerKey *string `json:"firebase_signer_key,omitempty"`
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// Get all available providers that a user can sign in with by client id
-	// (GET /auth/providers)
-	GetAuthProviders(c *gin.Context, params GetAuthProvidersParams)
-	// Logs in a user with email and password
-	// (POST /auth/providers/email/login)
-	PostAuthProvidersEmailLogin(c *gin.Context)
-	// Registers a user if email not taken and password meets security requirements
-	// (POST /auth/providers/email/register)
-	PostAuthProvidersEmailRegister(c *gin.Context)
-	// Get new access and identity tokens through refresh token
-	// (POST /auth/refresh)
-	PostAuthRefresh(c *gin.Context)
-	// Swap auth token from sign in methods for access, identity, and refresh tokens
-	// (POST /auth/token)
-	PostAuthToken(c *gin.Context)
-	// Check if an access or id token is issued by sentinel and returns claims
-	// (POST /auth/verify)
-	PostAuthVerify(c *gin.Context)
+	// SaltEncoding For salted sha, raw (the default), base64 or hex
+	SaltEncoding *string `json:"salt_encoding,omitempty"`
+
+	// SaltPosition For salted sha, prefix or suffix (the default) of the password
+	SaltPosition *string `json:"salt_position,omitempty"`
 }
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandler       func(*gin.Context, error, int)
+// ImportUser defines model for ImportUser.
+type ImportUser struct {
+	// CreatedAt RFC 3339 time or unix milliseconds
+	CreatedAt     *string `json:"created_at,omitempty"`
+	Email         string  `json:"email"`
+	EmailVerified *bool   `json:"email_verified,omitempty"`
+	HashAlgorithm *string `json:"hash_algorithm,omitempty"`
+
+	// PasswordHash Hex or base64 digest for salted sha, base64 for firebase, the full hash string otherwise
+	PasswordHash *string `json:"password_hash,omitempty"`
+	PasswordSalt *string `json:"password_salt,omitempty"`
 }
 
-type MiddlewareFunc func(c *gin.Context)
+// ImportUserFailure defines model for ImportUserFailure.
+type ImportUserFailure struct {
+	Detail *string `json:"detail,omitempty"`
+	Email  string  `json:"email"`
 
-// GetAuthProviders operation middleware
-func (siw *ServerInterfaceWrapper) GetAuthProviders(c *gin.Context) {
+	// Index Position of the user in the import
+	Index int `json:"index"`
 
-	var err error
+	// Reason invalid_email, duplicate_email, invalid_password_hash or batch_failed
+	Reason string `json:"reason"`
+}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetAuthProvidersParams
+// ImportUsersRequest defines model for ImportUsersRequest.
+type ImportUsersRequest struct {
+	// BatchSize Users written per transaction, 500 by default
+	BatchSize *int `json:"batch_size,omitempty"`
 
-	// ------------- Required query parameter "client_id" -------------
+	// HashConfig How the other system hashed passwords
+	HashConfig *ImportHashConfig `json:"hash_config,omitempty"`
+	Users      []ImportUser      `json:"users"`
+}
 
-	if paramValue := c.Query("client_id"); paramValue != "" {
+// ImportUsersResponse defines model for ImportUsersResponse.
+type ImportUsersResponse struct {
+	Failures []ImportUserFailure `json:"failures"`
+	Imported int                 `json:"imported"`
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument client_id is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// Skipped Users whose email was already registered
+	Skipped int `json:"skipped"`
+}
 
-	err = runtime.BindQueryParameter("form", true, true, "client_id", c.Request.URL.Query(), &params.ClientId)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
-		return
-	}
+// JwksResponse defines model for JwksResponse.
+type JwksResponse struct {
+	Keys []map[string]interface{} `json:"keys"`
+}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+// LegacyAuthenticator defines model for LegacyAuthenticator.
+type LegacyAuthenticator struct {
+	Enabled        bool       `json:"enabled"`
+	HasSecret      bool       `json:"has_secret"`
+	LastMigratedAt *time.Time `json:"last_migrated_at,omitempty"`
 
-	siw.Handler.GetAuthProviders(c, params)
+	// MigratedUsers Users created through the endpoint so far
+	MigratedUsers int    `json:"migrated_users"`
+	TimeoutMs     int    `json:"timeout_ms"`
+	Url           string `json:"url"`
 }
 
-// PostAuthProvidersEmailLogin operation middleware
-func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailLogin(c *gin.Context) {
+// LegacyAuthenticatorRequest defines model for LegacyAuthenticatorRequest.
+type LegacyAuthenticatorRequest struct {
+	// Enabled True by default
+	Enabled *bool `json:"enabled,omitempty"`
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// Secret Sent as bearer token so the endpoint can tell the requests come from sentinel
+	Secret *string `json:"secret,omitempty"`
 
-	siw.Handler.PostAuthProvidersEmailLogin(c)
+	// TimeoutMs Between 100 and 30000, 5000 by default
+	TimeoutMs *int `json:"timeout_ms,omitempty"`
+
+	// Url Https url the email, password and client_id are posted to as json. It answers 200, optionally with {"email_verified":true}, for valid credentials and 401, 403 or 404 otherwise
+	Url string `json:"url"`
 }
 
-// PostAuthProvidersEmailRegister operation middleware
-func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailRegister(c *gin.Context) {
+// LockoutPolicy defines model for LockoutPolicy.
+type LockoutPolicy struct {
+	// DurationSeconds Length of the first lockout. Each following one doubles, up to a day
+	DurationSeconds int `json:"duration_seconds"`
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// IpThreshold Failed sign ins from one address before it is locked. 0 turns them off
+	IpThreshold int `json:"ip_threshold"`
 
-	siw.Handler.PostAuthProvidersEmailRegister(c)
+	// Threshold Failed sign ins for one email before it is locked. 0 turns lockouts off
+	Threshold int `json:"threshold"`
 }
 
-// PostAuthRefresh operation middleware
-func (siw *ServerInterfaceWrapper) PostAuthRefresh(c *gin.Context) {
+// MagicLinkRequest defines model for MagicLinkRequest.
+type MagicLinkRequest struct {
+	// AcrValues Space delimited acr values (aal1, aal2) in order of preference. Users without a second factor can't sign in when aal2 is the only one
+	AcrValues           *string                             `json:"acr_values,omitempty"`
+	ClientId            string                              `json:"client_id"`
+	CodeChallenge       string                              `json:"code_challenge"`
+	CodeChallengeMethod MagicLinkRequestCodeChallengeMethod `json:"code_challenge_method"`
+	Email               openapi_types.Email                 `json:"email"`
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// RedirectUri Registered redirect uri the link opens, defaults to the client's first one. The token is added as a query parameter
+	RedirectUri *string `json:"redirect_uri,omitempty"`
 
-	siw.Handler.PostAuthRefresh(c)
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
 }
 
-// PostAuthToken operation middleware
-func (siw *ServerInterfaceWrapper) PostAuthToken(c *gin.Context) {
+// MagicLinkRequestCodeChallengeMethod defines model for MagicLinkRequest.CodeChallengeMethod.
+type MagicLinkRequestCodeChallengeMethod string
 
-	for _, middleware := range siw.HandlerMiddlewares {
+// MagicLinkVerifyRequest defines model for MagicLinkVerifyRequest.
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token"`
+}
+
+// MfaEnrollmentResponse defines model for MfaEnrollmentResponse.
+type MfaEnrollmentResponse struct {
+	// RecoveryCodes Single use codes that stand in for the second factor. Only set when this is the user's first second factor, and shown only this once
+	RecoveryCodes *[]string `json:"recovery_codes,omitempty"`
+}
+
+// MfaFactor defines model for MfaFactor.
+type MfaFactor struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	Id         string     `json:"id"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Type       string     `json:"type"`
+}
+
+// MfaPasskeyStartRequest defines model for MfaPasskeyStartRequest.
+type MfaPasskeyStartRequest struct {
+	FlowToken string `json:"flow_token"`
+}
+
+// MfaVerifyRequest defines model for MfaVerifyRequest.
+type MfaVerifyRequest struct {
+	// Code Code from the authenticator app, or a recovery code
+	Code *string `json:"code,omitempty"`
+
+	// FactorType Kind of second factor answering the step. Defaults to totp
+	FactorType *MfaVerifyRequestFactorType `json:"factor_type,omitempty"`
+	FlowToken  string                      `json:"flow_token"`
+
+	// Passkey Response of navigator.credentials.get, binary values base64url encoded
+	Passkey *PasskeyAssertion `json:"passkey,omitempty"`
+}
+
+// MfaVerifyRequestFactorType Kind of second factor answering the step. Defaults to totp
+type MfaVerifyRequestFactorType string
+
+// Passkey defines model for Passkey.
+type Passkey struct {
+	// BackupEligible Whether the passkey can sync between devices
+	BackupEligible bool       `json:"backup_eligible"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Id             string     `json:"id"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	Name           string     `json:"name"`
+}
+
+// PasskeyAssertion Response of navigator.credentials.get, binary values base64url encoded
+type PasskeyAssertion struct {
+	AuthenticatorData string  `json:"authenticator_data"`
+	ClientDataJson    string  `json:"client_data_json"`
+	Id                string  `json:"id"`
+	Signature         string  `json:"signature"`
+	UserHandle        *string `json:"user_handle,omitempty"`
+}
+
+// PasskeyOptionsResponse defines model for PasskeyOptionsResponse.
+type PasskeyOptionsResponse struct {
+	// PublicKey The publicKey member for navigator.credentials, binary values base64url encoded
+	PublicKey map[string]interface{} `json:"public_key"`
+}
+
+// PasskeyRegistrationRequest Response of navigator.credentials.create, binary values base64url encoded
+type PasskeyRegistrationRequest struct {
+	AttestationObject string `json:"attestation_object"`
+	ClientDataJson    string `json:"client_data_json"`
+
+	// Name What to call the passkey. Defaults to Passkey
+	Name *string `json:"name,omitempty"`
+}
+
+// PasskeyRegistrationResponse defines model for PasskeyRegistrationResponse.
+type PasskeyRegistrationResponse struct {
+	Passkey Passkey `json:"passkey"`
+
+	// RecoveryCodes Single use codes that stand in for the second factor. Only set when this is the user's first second factor, and shown only this once
+	RecoveryCodes *[]string `json:"recovery_codes,omitempty"`
+}
+
+// PasskeyRenameRequest defines model for PasskeyRenameRequest.
+type PasskeyRenameRequest struct {
+	Name string `json:"name"`
+}
+
+// PasskeySignInFinishRequest defines model for PasskeySignInFinishRequest.
+type PasskeySignInFinishRequest struct {
+	ClientId string `json:"client_id"`
+
+	// Credential Response of navigator.credentials.get, binary values base64url encoded
+	Credential PasskeyAssertion `json:"credential"`
+}
+
+// PasskeySignInRequest defines model for PasskeySignInRequest.
+type PasskeySignInRequest struct {
+	ClientId            string                                  `json:"client_id"`
+	CodeChallenge       string                                  `json:"code_challenge"`
+	CodeChallengeMethod PasskeySignInRequestCodeChallengeMethod `json:"code_challenge_method"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
+}
+
+// PasskeySignInRequestCodeChallengeMethod defines model for PasskeySignInRequest.CodeChallengeMethod.
+type PasskeySignInRequestCodeChallengeMethod string
+
+// PasswordPolicy defines model for PasswordPolicy.
+type PasswordPolicy struct {
+	// BannedWords Words passwords may not contain, on top of the user's email and the client's name
+	BannedWords []string `json:"banned_words"`
+
+	// BreachMode What happens to passwords found in the breach dataset the server loaded. block refuses them, warn accepts them with a Password-Warning response header and off skips the check
+	BreachMode string `json:"breach_mode"`
+
+	// HistorySize How many of the user's latest passwords, the current one included, can't be chosen again. At most 24
+	HistorySize int `json:"history_size"`
+
+	// MaxLength At most 256
+	MaxLength int `json:"max_length"`
+	MinLength int `json:"min_length"`
+
+	// MinStrength Lowest accepted score from 0 (too guessable) to 4 (very unguessable) of a zxcvbn style estimate
+	MinStrength      int  `json:"min_strength"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireSymbol    bool `json:"require_symbol"`
+	RequireUppercase bool `json:"require_uppercase"`
+}
+
+// PasswordRuleFailure defines model for PasswordRuleFailure.
+type PasswordRuleFailure struct {
+	// Description What the user has to change, to show next to the password field
+	Description string `json:"description"`
+
+	// Rule One of min_length, max_length, uppercase, lowercase, digit, symbol, strength, banned_word, reused or breached
+	Rule string `json:"rule"`
+}
+
+// PhoneCodeRequest defines model for PhoneCodeRequest.
+type PhoneCodeRequest struct {
+	// AcrValues Space delimited acr values (aal1, aal2) in order of preference. Users without a second factor can't sign in when aal2 is the only one
+	AcrValues           *string                             `json:"acr_values,omitempty"`
+	ClientId            string                              `json:"client_id"`
+	CodeChallenge       string                              `json:"code_challenge"`
+	CodeChallengeMethod PhoneCodeRequestCodeChallengeMethod `json:"code_challenge_method"`
+
+	// Phone Phone number in international format, or local to the client's default country code
+	Phone string `json:"phone"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
+}
+
+// PhoneCodeRequestCodeChallengeMethod defines model for PhoneCodeRequest.CodeChallengeMethod.
+type PhoneCodeRequestCodeChallengeMethod string
+
+// PhoneCodeVerifyRequest defines model for PhoneCodeVerifyRequest.
+type PhoneCodeVerifyRequest struct {
+	ClientId string `json:"client_id"`
+	Code     string `json:"code"`
+	Phone    string `json:"phone"`
+}
+
+// ReauthenticateRequest defines model for ReauthenticateRequest.
+type ReauthenticateRequest struct {
+	// Code Code from the authenticator app
+	Code       *string                         `json:"code,omitempty"`
+	FactorType ReauthenticateRequestFactorType `json:"factor_type"`
+	FlowToken  string                          `json:"flow_token"`
+
+	// Passkey Response of navigator.credentials.get, binary values base64url encoded
+	Passkey  *PasskeyAssertion `json:"passkey,omitempty"`
+	Password *string           `json:"password,omitempty"`
+}
+
+// ReauthenticateRequestFactorType defines model for ReauthenticateRequest.FactorType.
+type ReauthenticateRequestFactorType string
+
+// RecoveryCodesResponse defines model for RecoveryCodesResponse.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RecoveryCodesStatus defines model for RecoveryCodesStatus.
+type RecoveryCodesStatus struct {
+	Remaining int `json:"remaining"`
+}
+
+// ResendEmailVerificationRequest defines model for ResendEmailVerificationRequest.
+type ResendEmailVerificationRequest struct {
+	ClientId string              `json:"client_id"`
+	Email    openapi_types.Email `json:"email"`
+}
+
+// ResetPasswordRequest defines model for ResetPasswordRequest.
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// RestoreAccountRequest defines model for RestoreAccountRequest.
+type RestoreAccountRequest struct {
+	Token string `json:"token"`
+}
+
+// ScopeDescription defines model for ScopeDescription.
+type ScopeDescription struct {
+	Description string `json:"description"`
+	Id          string `json:"id"`
+
+	// Standard Whether this is a standard OpenID Connect scope
+	Standard bool `json:"standard"`
+}
+
+// StepUpRequest defines model for StepUpRequest.
+type StepUpRequest struct {
+	// AcrValues Space delimited acr values (aal1, aal2) in order of preference
+	AcrValues           *string                          `json:"acr_values,omitempty"`
+	CodeChallenge       string                           `json:"code_challenge"`
+	CodeChallengeMethod StepUpRequestCodeChallengeMethod `json:"code_challenge_method"`
+
+	// MaxAge Seconds since the user last authenticated after which they have to sign in again
+	MaxAge *int `json:"max_age,omitempty"`
+
+	// Resource Identifiers of the apis (RFC 8707) access tokens may later be requested for
+	Resource *[]string `json:"resource,omitempty"`
+
+	// Scope Space delimited scopes to request. Defaults to openid profile
+	Scope *string `json:"scope,omitempty"`
+	State *string `json:"state,omitempty"`
+}
+
+// StepUpRequestCodeChallengeMethod defines model for StepUpRequest.CodeChallengeMethod.
+type StepUpRequestCodeChallengeMethod string
+
+// StrippedClientProvider defines model for StrippedClientProvider.
+type StrippedClientProvider struct {
+	ClientId       *string                 `json:"client_id,omitempty"`
+	Data           *map[string]interface{} `json:"data"`
+	Id             *string                 `json:"id,omitempty"`
+	ProviderOption *struct {
+		Description *string `json:"description,omitempty"`
+		Id          *string `json:"id,omitempty"`
+		LogoUrl     *string `json:"logo_url,omitempty"`
+		Name        *string `json:"name,omitempty"`
+	} `json:"provider_option,omitempty"`
+}
+
+// TotpConfirmRequest defines model for TotpConfirmRequest.
+type TotpConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// TotpEnrollmentResponse defines model for TotpEnrollmentResponse.
+type TotpEnrollmentResponse struct {
+	FactorId string `json:"factor_id"`
+
+	// OtpauthUri Uri authenticator apps import, usually shown as a qr code
+	OtpauthUri string `json:"otpauth_uri"`
+
+	// Secret Base32 secret for entering the setup by hand
+	Secret string `json:"secret"`
+}
+
+// UnlockRequest defines model for UnlockRequest.
+type UnlockRequest struct {
+	Token string `json:"token"`
+}
+
+// WeakPasswordResponse defines model for WeakPasswordResponse.
+type WeakPasswordResponse struct {
+	// Error Always weak_password
+	Error            string                `json:"error"`
+	ErrorDescription string                `json:"error_description"`
+	FailedRules      []PasswordRuleFailure `json:"failed_rules"`
+}
+
+// PostAdminClientsClientIdUsersImportParams defines parameters for PostAdminClientsClientIdUsersImport.
+type PostAdminClientsClientIdUsersImportParams struct {
+	// HashAlgorithm For csv imports, algorithm of the users that don't name their own
+	HashAlgorithm         *string `form:"hash_algorithm,omitempty" json:"hash_algorithm,omitempty"`
+	FirebaseSignerKey     *string `form:"firebase_signer_key,omitempty" json:"firebase_signer_key,omitempty"`
+	FirebaseSaltSeparator *string `form:"firebase_salt_separator,omitempty" json:"firebase_salt_separator,omitempty"`
+	FirebaseRounds        *int    `form:"firebase_rounds,omitempty" json:"firebase_rounds,omitempty"`
+	FirebaseMemCost       *int    `form:"firebase_mem_cost,omitempty" json:"firebase_mem_cost,omitempty"`
+	SaltPosition          *string `form:"salt_position,omitempty" json:"salt_position,omitempty"`
+	SaltEncoding          *string `form:"salt_encoding,omitempty" json:"salt_encoding,omitempty"`
+}
+
+// DeleteAdminUsersUserIdParams defines parameters for DeleteAdminUsersUserId.
+type DeleteAdminUsersUserIdParams struct {
+	// Purge Erase the user right away instead of after the grace period
+	Purge *bool `form:"purge,omitempty" json:"purge,omitempty"`
+}
+
+// GetAuthConsentParams defines parameters for GetAuthConsent.
+type GetAuthConsentParams struct {
+	FlowToken string `form:"flow_token" json:"flow_token"`
+}
+
+// GetAuthProvidersParams defines parameters for GetAuthProviders.
+type GetAuthProvidersParams struct {
+	ClientId string `form:"client_id" json:"client_id"`
+}
+
+// GetAuthProvidersEmailVerifyParams defines parameters for GetAuthProvidersEmailVerify.
+type GetAuthProvidersEmailVerifyParams struct {
+	Token string `form:"token" json:"token"`
+}
+
+// PutAdminClientsClientIdEmailVerificationJSONRequestBody defines body for PutAdminClientsClientIdEmailVerification for application/json ContentType.
+type PutAdminClientsClientIdEmailVerificationJSONRequestBody = EmailVerificationPolicy
+
+// PutAdminClientsClientIdLegacyAuthenticatorJSONRequestBody defines body for PutAdminClientsClientIdLegacyAuthenticator for application/json ContentType.
+type PutAdminClientsClientIdLegacyAuthenticatorJSONRequestBody = LegacyAuthenticatorRequest
+
+// PutAdminClientsClientIdLockoutJSONRequestBody defines body for PutAdminClientsClientIdLockout for application/json ContentType.
+type PutAdminClientsClientIdLockoutJSONRequestBody = LockoutPolicy
+
+// PutAdminClientsClientIdPasswordPolicyJSONRequestBody defines body for PutAdminClientsClientIdPasswordPolicy for application/json ContentType.
+type PutAdminClientsClientIdPasswordPolicyJSONRequestBody = PasswordPolicy
+
+// PutAdminClientsClientIdProvidersProviderIdJSONRequestBody defines body for PutAdminClientsClientIdProvidersProviderId for application/json ContentType.
+type PutAdminClientsClientIdProvidersProviderIdJSONRequestBody = ClientProviderRequest
+
+// PutAdminClientsClientIdScopesJSONRequestBody defines body for PutAdminClientsClientIdScopes for application/json ContentType.
+type PutAdminClientsClientIdScopesJSONRequestBody = ClientScopes
+
+// PostAdminClientsClientIdUsersImportJSONRequestBody defines body for PostAdminClientsClientIdUsersImport for application/json ContentType.
+type PostAdminClientsClientIdUsersImportJSONRequestBody = ImportUsersRequest
+
+// PostAdminResourcesJSONRequestBody defines body for PostAdminResources for application/json ContentType.
+type PostAdminResourcesJSONRequestBody = ApiResourceRequest
+
+// PutAdminResourcesResourceIdJSONRequestBody defines body for PutAdminResourcesResourceId for application/json ContentType.
+type PutAdminResourcesResourceIdJSONRequestBody = ApiResourceRequest
+
+// PostAdminScopesJSONRequestBody defines body for PostAdminScopes for application/json ContentType.
+type PostAdminScopesJSONRequestBody = CreateScopeRequest
+
+// PostAuthAccountRestoreJSONRequestBody defines body for PostAuthAccountRestore for application/json ContentType.
+type PostAuthAccountRestoreJSONRequestBody = RestoreAccountRequest
+
+// PostAuthConsentJSONRequestBody defines body for PostAuthConsent for application/json ContentType.
+type PostAuthConsentJSONRequestBody = ConsentRequest
+
+// PostAuthMfaJSONRequestBody defines body for PostAuthMfa for application/json ContentType.
+type PostAuthMfaJSONRequestBody = MfaVerifyRequest
+
+// PostAuthMfaPasskeyStartJSONRequestBody defines body for PostAuthMfaPasskeyStart for application/json ContentType.
+type PostAuthMfaPasskeyStartJSONRequestBody = MfaPasskeyStartRequest
+
+// PostAuthProvidersEmailLoginJSONRequestBody defines body for PostAuthProvidersEmailLogin for application/json ContentType.
+type PostAuthProvidersEmailLoginJSONRequestBody = EmailLoginRequest
+
+// PostAuthProvidersEmailPasswordForgotJSONRequestBody defines body for PostAuthProvidersEmailPasswordForgot for application/json ContentType.
+type PostAuthProvidersEmailPasswordForgotJSONRequestBody = ForgotPasswordRequest
+
+// PostAuthProvidersEmailPasswordResetJSONRequestBody defines body for PostAuthProvidersEmailPasswordReset for application/json ContentType.
+type PostAuthProvidersEmailPasswordResetJSONRequestBody = ResetPasswordRequest
+
+// PostAuthProvidersEmailRegisterJSONRequestBody defines body for PostAuthProvidersEmailRegister for application/json ContentType.
+type PostAuthProvidersEmailRegisterJSONRequestBody = EmailRegistrationRequest
+
+// PostAuthProvidersEmailUnlockJSONRequestBody defines body for PostAuthProvidersEmailUnlock for application/json ContentType.
+type PostAuthProvidersEmailUnlockJSONRequestBody = UnlockRequest
+
+// PostAuthProvidersEmailVerifyResendJSONRequestBody defines body for PostAuthProvidersEmailVerifyResend for application/json ContentType.
+type PostAuthProvidersEmailVerifyResendJSONRequestBody = ResendEmailVerificationRequest
+
+// PostAuthProvidersEmailOtpStartJSONRequestBody defines body for PostAuthProvidersEmailOtpStart for application/json ContentType.
+type PostAuthProvidersEmailOtpStartJSONRequestBody = EmailOtpRequest
+
+// PostAuthProvidersEmailOtpVerifyJSONRequestBody defines body for PostAuthProvidersEmailOtpVerify for application/json ContentType.
+type PostAuthProvidersEmailOtpVerifyJSONRequestBody = EmailOtpVerifyRequest
+
+// PostAuthProvidersMagicLinkStartJSONRequestBody defines body for PostAuthProvidersMagicLinkStart for application/json ContentType.
+type PostAuthProvidersMagicLinkStartJSONRequestBody = MagicLinkRequest
+
+// PostAuthProvidersMagicLinkVerifyJSONRequestBody defines body for PostAuthProvidersMagicLinkVerify for application/json ContentType.
+type PostAuthProvidersMagicLinkVerifyJSONRequestBody = MagicLinkVerifyRequest
+
+// PostAuthProvidersPasskeyFinishJSONRequestBody defines body for PostAuthProvidersPasskeyFinish for application/json ContentType.
+type PostAuthProvidersPasskeyFinishJSONRequestBody = PasskeySignInFinishRequest
+
+// PostAuthProvidersPasskeyStartJSONRequestBody defines body for PostAuthProvidersPasskeyStart for application/json ContentType.
+type PostAuthProvidersPasskeyStartJSONRequestBody = PasskeySignInRequest
+
+// PostAuthProvidersPhoneStartJSONRequestBody defines body for PostAuthProvidersPhoneStart for application/json ContentType.
+type PostAuthProvidersPhoneStartJSONRequestBody = PhoneCodeRequest
+
+// PostAuthProvidersPhoneVerifyJSONRequestBody defines body for PostAuthProvidersPhoneVerify for application/json ContentType.
+type PostAuthProvidersPhoneVerifyJSONRequestBody = PhoneCodeVerifyRequest
+
+// PostAuthReauthenticateJSONRequestBody defines body for PostAuthReauthenticate for application/json ContentType.
+type PostAuthReauthenticateJSONRequestBody = ReauthenticateRequest
+
+// PostAuthRefreshJSONRequestBody defines body for PostAuthRefresh for application/json ContentType.
+type PostAuthRefreshJSONRequestBody = AuthRefreshRequest
+
+// PostAuthTokenJSONRequestBody defines body for PostAuthToken for application/json ContentType.
+type PostAuthTokenJSONRequestBody = AuthTokenRequest
+
+// PostAuthVerifyJSONRequestBody defines body for PostAuthVerify for application/json ContentType.
+type PostAuthVerifyJSONRequestBody = AuthVerifyRequest
+
+// PostClientsJSONRequestBody defines body for PostClients for application/json ContentType.
+type PostClientsJSONRequestBody = ClientMetadata
+
+// PutClientsClientIdJSONRequestBody defines body for PutClientsClientId for application/json ContentType.
+type PutClientsClientIdJSONRequestBody = ClientMetadata
+
+// DeleteUserJSONRequestBody defines body for DeleteUser for application/json ContentType.
+type DeleteUserJSONRequestBody = DeleteAccountRequest
+
+// PostUserMfaTotpConfirmJSONRequestBody defines body for PostUserMfaTotpConfirm for application/json ContentType.
+type PostUserMfaTotpConfirmJSONRequestBody = TotpConfirmRequest
+
+// PostUserPasskeysRegisterFinishJSONRequestBody defines body for PostUserPasskeysRegisterFinish for application/json ContentType.
+type PostUserPasskeysRegisterFinishJSONRequestBody = PasskeyRegistrationRequest
+
+// PatchUserPasskeysPasskeyIdJSONRequestBody defines body for PatchUserPasskeysPasskeyId for application/json ContentType.
+type PatchUserPasskeysPasskeyIdJSONRequestBody = PasskeyRenameRequest
+
+// PutUserPasswordJSONRequestBody defines body for PutUserPassword for application/json ContentType.
+type PutUserPasswordJSONRequestBody = ChangePasswordRequest
+
+// PostUserStepUpJSONRequestBody defines body for PostUserStepUp for application/json ContentType.
+type PostUserStepUpJSONRequestBody = StepUpRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Public keys that RS256 access tokens are signed with
+	// (GET /.well-known/jwks.json)
+	GetWellKnownJwksJson(c *gin.Context)
+	// Sets whether password sign ins wait for the email to be verified
+	// (PUT /admin/clients/{client_id}/email_verification)
+	PutAdminClientsClientIdEmailVerification(c *gin.Context, clientId string)
+	// Stops checking unknown emails with the client's old auth system
+	// (DELETE /admin/clients/{client_id}/legacy_authenticator)
+	DeleteAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string)
+	// Shows the endpoint of the client's old auth system that sign ins of unknown emails are checked with
+	// (GET /admin/clients/{client_id}/legacy_authenticator)
+	GetAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string)
+	// Sends password sign ins of emails without an account to the client's old auth system. Users it accepts are created with that password, so they move over with their next sign in
+	// (PUT /admin/clients/{client_id}/legacy_authenticator)
+	PutAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string)
+	// Sets after how many failed sign ins emails and addresses are locked, and for how long
+	// (PUT /admin/clients/{client_id}/lockout)
+	PutAdminClientsClientIdLockout(c *gin.Context, clientId string)
+	// Shows the rules passwords of the client's users must follow
+	// (GET /admin/clients/{client_id}/password_policy)
+	GetAdminClientsClientIdPasswordPolicy(c *gin.Context, clientId string)
+	// Replaces the rules passwords of the client's users must follow. Existing passwords keep working
+	// (PUT /admin/clients/{client_id}/password_policy)
+	PutAdminClientsClientIdPasswordPolicy(c *gin.Context, clientId string)
+	// Enables or disables a sign in provider for a client
+	// (PUT /admin/clients/{client_id}/providers/{provider_id})
+	PutAdminClientsClientIdProvidersProviderId(c *gin.Context, clientId string, providerId string)
+	// Replaces the scopes a client is allowed to request
+	// (PUT /admin/clients/{client_id}/scopes)
+	PutAdminClientsClientIdScopes(c *gin.Context, clientId string)
+	// Imports users exported from another system, like firebase auth or django, into the client. Passwords keep working with their old hashes and are rehashed at the first sign in. Already registered emails are skipped
+	// (POST /admin/clients/{client_id}/users/import)
+	PostAdminClientsClientIdUsersImport(c *gin.Context, clientId string, params PostAdminClientsClientIdUsersImportParams)
+	// Lists registered api resources
+	// (GET /admin/resources)
+	GetAdminResources(c *gin.Context)
+	// Registers an api resource server that access tokens can be restricted to
+	// (POST /admin/resources)
+	PostAdminResources(c *gin.Context)
+	// Removes an api resource
+	// (DELETE /admin/resources/{resource_id})
+	DeleteAdminResourcesResourceId(c *gin.Context, resourceId string)
+	// Updates an api resource. The identifier can not be changed
+	// (PUT /admin/resources/{resource_id})
+	PutAdminResourcesResourceId(c *gin.Context, resourceId string)
+	// Adds a custom api scope to the registry
+	// (POST /admin/scopes)
+	PostAdminScopes(c *gin.Context)
+	// Removes a custom api scope from the registry and from every client allowed to request it
+	// (DELETE /admin/scopes/{scope})
+	DeleteAdminScopesScope(c *gin.Context, scope string)
+	// Deletes a user. They are signed out everywhere and can be restored until the grace period ends, unless purge is set
+	// (DELETE /admin/users/{user_id})
+	DeleteAdminUsersUserId(c *gin.Context, userId string, params DeleteAdminUsersUserIdParams)
+	// Starts building an archive of everything stored about a user, a zip of json files. While one is being built that one is returned. Deleted users can be exported until they are purged
+	// (POST /admin/users/{user_id}/export)
+	PostAdminUsersUserIdExport(c *gin.Context, userId string)
+	// Shows whether an export of a user is ready
+	// (GET /admin/users/{user_id}/export/{job_id})
+	GetAdminUsersUserIdExportJobId(c *gin.Context, userId string, jobId string)
+	// Downloads the archive of a finished export of a user
+	// (GET /admin/users/{user_id}/export/{job_id}/download)
+	GetAdminUsersUserIdExportJobIdDownload(c *gin.Context, userId string, jobId string)
+	// Lifts sign in lockouts on all of a user's emails
+	// (DELETE /admin/users/{user_id}/lockout)
+	DeleteAdminUsersUserIdLockout(c *gin.Context, userId string)
+	// Shows a user's second factors and how many recovery codes they have left
+	// (GET /admin/users/{user_id}/mfa)
+	GetAdminUsersUserIdMfa(c *gin.Context, userId string)
+	// Restores a deleted user whose grace period hasn't ended. They have to sign in again
+	// (POST /admin/users/{user_id}/restore)
+	PostAdminUsersUserIdRestore(c *gin.Context, userId string)
+	// Restores a deleted account using the token from the account deleted email
+	// (POST /auth/account/restore)
+	PostAuthAccountRestore(c *gin.Context)
+	// Returns what a client is asking the user to share, for rendering a consent screen
+	// (GET /auth/consent)
+	GetAuthConsent(c *gin.Context, params GetAuthConsentParams)
+	// Approves or denies a pending consent request. Approving continues the sign in
+	// (POST /auth/consent)
+	PostAuthConsent(c *gin.Context)
+	// Completes the second factor step of a sign in
+	// (POST /auth/mfa)
+	PostAuthMfa(c *gin.Context)
+	// Returns the options for answering the second factor or reauthenticate step of a sign in with a passkey
+	// (POST /auth/mfa/passkey/start)
+	PostAuthMfaPasskeyStart(c *gin.Context)
+	// Get all available providers that a user can sign in with by client id
+	// (GET /auth/providers)
+	GetAuthProviders(c *gin.Context, params GetAuthProvidersParams)
+	// Logs in a user with email and password
+	// (POST /auth/providers/email/login)
+	PostAuthProvidersEmailLogin(c *gin.Context)
+	// Emails a password reset link. Answers the same whether or not the email is registered
+	// (POST /auth/providers/email/password/forgot)
+	PostAuthProvidersEmailPasswordForgot(c *gin.Context)
+	// Sets a new password using the token from the reset email and signs the user out everywhere
+	// (POST /auth/providers/email/password/reset)
+	PostAuthProvidersEmailPasswordReset(c *gin.Context)
+	// Registers a user if email not taken and password meets security requirements
+	// (POST /auth/providers/email/register)
+	PostAuthProvidersEmailRegister(c *gin.Context)
+	// Lifts a sign in lockout using the token from the lockout email
+	// (POST /auth/providers/email/unlock)
+	PostAuthProvidersEmailUnlock(c *gin.Context)
+	// Marks an email as verified using the token from the verification email
+	// (GET /auth/providers/email/verify)
+	GetAuthProvidersEmailVerify(c *gin.Context, params GetAuthProvidersEmailVerifyParams)
+	// Sends another verification email. Answers the same whether or not the email is registered
+	// (POST /auth/providers/email/verify/resend)
+	PostAuthProvidersEmailVerifyResend(c *gin.Context)
+	// Emails a six digit sign in code. The auth code it results in is bound to this request's code challenge
+	// (POST /auth/providers/email_otp/start)
+	PostAuthProvidersEmailOtpStart(c *gin.Context)
+	// Completes an email code sign in
+	// (POST /auth/providers/email_otp/verify)
+	PostAuthProvidersEmailOtpVerify(c *gin.Context)
+	// Emails a single use sign in link. The code it results in is bound to this request's code challenge
+	// (POST /auth/providers/magic_link/start)
+	PostAuthProvidersMagicLinkStart(c *gin.Context)
+	// Completes a magic link sign in with the token from the link
+	// (POST /auth/providers/magic_link/verify)
+	PostAuthProvidersMagicLinkVerify(c *gin.Context)
+	// Completes a passkey sign in with the authenticator's response
+	// (POST /auth/providers/passkey/finish)
+	PostAuthProvidersPasskeyFinish(c *gin.Context)
+	// Starts a passkey sign in. The options go to navigator.credentials.get and the auth code it results in is bound to this request's code challenge
+	// (POST /auth/providers/passkey/start)
+	PostAuthProvidersPasskeyStart(c *gin.Context)
+	// Texts a six digit sign in code to a phone number. Works for new and existing users. The auth code it results in is bound to this request's code challenge
+	// (POST /auth/providers/phone/start)
+	PostAuthProvidersPhoneStart(c *gin.Context)
+	// Completes a phone sign in, registering the user when the number is new
+	// (POST /auth/providers/phone/verify)
+	PostAuthProvidersPhoneVerify(c *gin.Context)
+	// Answers the reauthenticate step a step up ends in when the session is older than max_age
+	// (POST /auth/reauthenticate)
+	PostAuthReauthenticate(c *gin.Context)
+	// Get new access and identity tokens through refresh token
+	// (POST /auth/refresh)
+	PostAuthRefresh(c *gin.Context)
+	// Swap auth token from sign in methods for access, identity, and refresh tokens
+	// (POST /auth/token)
+	PostAuthToken(c *gin.Context)
+	// Check if an access or id token is issued by sentinel and returns claims
+	// (POST /auth/verify)
+	PostAuthVerify(c *gin.Context)
+	// Registers a new client application (RFC 7591). Requires an initial access token as bearer token
+	// (POST /clients)
+	PostClients(c *gin.Context)
+	// Deletes a dynamically registered client (RFC 7592)
+	// (DELETE /clients/{client_id})
+	DeleteClientsClientId(c *gin.Context, clientId string)
+	// Reads a dynamically registered client (RFC 7592). Requires the registration access token as bearer token
+	// (GET /clients/{client_id})
+	GetClientsClientId(c *gin.Context, clientId string)
+	// Replaces the metadata of a dynamically registered client (RFC 7592)
+	// (PUT /clients/{client_id})
+	PutClientsClientId(c *gin.Context, clientId string)
+	// Lists the scope registry, standard OpenID Connect scopes first
+	// (GET /scopes)
+	GetScopes(c *gin.Context)
+	// Deletes the signed in user's account. They are signed out everywhere and emailed a link to restore it until the grace period ends, then it is erased. Needs a sign in from the last 10 minutes
+	// (DELETE /user)
+	DeleteUser(c *gin.Context)
+	// Lists the clients the signed in user has shared data with
+	// (GET /user/consents)
+	GetUserConsents(c *gin.Context)
+	// Revokes consent for a client along with the refresh tokens it was issued
+	// (DELETE /user/consents/{client_id})
+	DeleteUserConsentsClientId(c *gin.Context, clientId string)
+	// Lists the ways the signed in user can sign in
+	// (GET /user/credentials)
+	GetUserCredentials(c *gin.Context)
+	// Starts building an archive of everything stored about the signed in user, a zip of json files. While one is being built that one is returned
+	// (POST /user/export)
+	PostUserExport(c *gin.Context)
+	// Shows whether an export of the signed in user is ready
+	// (GET /user/export/{job_id})
+	GetUserExportJobId(c *gin.Context, jobId string)
+	// Downloads the archive of a finished export of the signed in user
+	// (GET /user/export/{job_id}/download)
+	GetUserExportJobIdDownload(c *gin.Context, jobId string)
+	// Lists the second factors the signed in user enrolled
+	// (GET /user/mfa)
+	GetUserMfa(c *gin.Context)
+	// Tells how many unused recovery codes the signed in user has
+	// (GET /user/mfa/recovery_codes)
+	GetUserMfaRecoveryCodes(c *gin.Context)
+	// Replaces the recovery codes with a new set. The session has to be signed in with a second factor
+	// (POST /user/mfa/recovery_codes)
+	PostUserMfaRecoveryCodes(c *gin.Context)
+	// Removes the authenticator app. The session has to be signed in with a second factor
+	// (DELETE /user/mfa/totp)
+	DeleteUserMfaTotp(c *gin.Context)
+	// Starts setting up an authenticator app. Sign ins ask for its codes once the setup is confirmed
+	// (POST /user/mfa/totp)
+	PostUserMfaTotp(c *gin.Context)
+	// Confirms the authenticator app setup with the first code it shows
+	// (POST /user/mfa/totp/confirm)
+	PostUserMfaTotpConfirm(c *gin.Context)
+	// Lists the signed in user's passkeys
+	// (GET /user/passkeys)
+	GetUserPasskeys(c *gin.Context)
+	// Stores the passkey the authenticator created. It can then be used to sign in and as a second factor
+	// (POST /user/passkeys/register/finish)
+	PostUserPasskeysRegisterFinish(c *gin.Context)
+	// Returns the options for creating a passkey with navigator.credentials.create
+	// (POST /user/passkeys/register/start)
+	PostUserPasskeysRegisterStart(c *gin.Context)
+	// Removes a passkey. The session has to be signed in with a second factor
+	// (DELETE /user/passkeys/{passkey_id})
+	DeleteUserPasskeysPasskeyId(c *gin.Context, passkeyId string)
+	// Renames a passkey
+	// (PATCH /user/passkeys/{passkey_id})
+	PatchUserPasskeysPasskeyId(c *gin.Context, passkeyId string)
+	// Changes the signed in user's password, or adds one to accounts created through another provider
+	// (PUT /user/password)
+	PutUserPassword(c *gin.Context)
+	// Issues a new code for the signed in session. When the session is weaker than acr_values asks for the user has to pass the second factor step first, and when it is older than max_age the reauthenticate step
+	// (POST /user/step_up)
+	PostUserStepUp(c *gin.Context)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandler       func(*gin.Context, error, int)
+}
+
+type MiddlewareFunc func(c *gin.Context)
+
+// GetWellKnownJwksJson operation middleware
+func (siw *ServerInterfaceWrapper) GetWellKnownJwksJson(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetWellKnownJwksJson(c)
+}
+
+// PutAdminClientsClientIdEmailVerification operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdEmailVerification(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdEmailVerification(c, clientId)
+}
+
+// DeleteAdminClientsClientIdLegacyAuthenticator operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminClientsClientIdLegacyAuthenticator(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminClientsClientIdLegacyAuthenticator(c, clientId)
+}
+
+// GetAdminClientsClientIdLegacyAuthenticator operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminClientsClientIdLegacyAuthenticator(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminClientsClientIdLegacyAuthenticator(c, clientId)
+}
+
+// PutAdminClientsClientIdLegacyAuthenticator operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdLegacyAuthenticator(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdLegacyAuthenticator(c, clientId)
+}
+
+// PutAdminClientsClientIdLockout operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdLockout(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdLockout(c, clientId)
+}
+
+// GetAdminClientsClientIdPasswordPolicy operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminClientsClientIdPasswordPolicy(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminClientsClientIdPasswordPolicy(c, clientId)
+}
+
+// PutAdminClientsClientIdPasswordPolicy operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdPasswordPolicy(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdPasswordPolicy(c, clientId)
+}
+
+// PutAdminClientsClientIdProvidersProviderId operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdProvidersProviderId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "provider_id" -------------
+	var providerId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider_id", c.Param("provider_id"), &providerId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter provider_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdProvidersProviderId(c, clientId, providerId)
+}
+
+// PutAdminClientsClientIdScopes operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminClientsClientIdScopes(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminClientsClientIdScopes(c, clientId)
+}
+
+// PostAdminClientsClientIdUsersImport operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminClientsClientIdUsersImport(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostAdminClientsClientIdUsersImportParams
+
+	// ------------- Optional query parameter "hash_algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "hash_algorithm", c.Request.URL.Query(), &params.HashAlgorithm)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter hash_algorithm: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "firebase_signer_key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "firebase_signer_key", c.Request.URL.Query(), &params.FirebaseSignerKey)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter firebase_signer_key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "firebase_salt_separator" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "firebase_salt_separator", c.Request.URL.Query(), &params.FirebaseSaltSeparator)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter firebase_salt_separator: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "firebase_rounds" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "firebase_rounds", c.Request.URL.Query(), &params.FirebaseRounds)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter firebase_rounds: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "firebase_mem_cost" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "firebase_mem_cost", c.Request.URL.Query(), &params.FirebaseMemCost)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter firebase_mem_cost: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "salt_position" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "salt_position", c.Request.URL.Query(), &params.SaltPosition)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter salt_position: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "salt_encoding" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "salt_encoding", c.Request.URL.Query(), &params.SaltEncoding)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter salt_encoding: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAdminClientsClientIdUsersImport(c, clientId, params)
+}
+
+// GetAdminResources operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminResources(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminResources(c)
+}
+
+// PostAdminResources operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminResources(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAdminResources(c)
+}
+
+// DeleteAdminResourcesResourceId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminResourcesResourceId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "resource_id" -------------
+	var resourceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "resource_id", c.Param("resource_id"), &resourceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminResourcesResourceId(c, resourceId)
+}
+
+// PutAdminResourcesResourceId operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminResourcesResourceId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "resource_id" -------------
+	var resourceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "resource_id", c.Param("resource_id"), &resourceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminResourcesResourceId(c, resourceId)
+}
+
+// PostAdminScopes operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminScopes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAdminScopes(c)
+}
+
+// DeleteAdminScopesScope operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminScopesScope(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scope" -------------
+	var scope string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scope", c.Param("scope"), &scope, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scope: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminScopesScope(c, scope)
+}
+
+// DeleteAdminUsersUserId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminUsersUserId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteAdminUsersUserIdParams
+
+	// ------------- Optional query parameter "purge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "purge", c.Request.URL.Query(), &params.Purge)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter purge: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminUsersUserId(c, userId, params)
+}
+
+// PostAdminUsersUserIdExport operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersUserIdExport(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAdminUsersUserIdExport(c, userId)
+}
+
+// GetAdminUsersUserIdExportJobId operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsersUserIdExportJobId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "job_id" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "job_id", c.Param("job_id"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter job_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminUsersUserIdExportJobId(c, userId, jobId)
+}
+
+// GetAdminUsersUserIdExportJobIdDownload operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsersUserIdExportJobIdDownload(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "job_id" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "job_id", c.Param("job_id"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter job_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminUsersUserIdExportJobIdDownload(c, userId, jobId)
+}
+
+// DeleteAdminUsersUserIdLockout operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminUsersUserIdLockout(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminUsersUserIdLockout(c, userId)
+}
+
+// GetAdminUsersUserIdMfa operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsersUserIdMfa(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminUsersUserIdMfa(c, userId)
+}
+
+// PostAdminUsersUserIdRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersUserIdRestore(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", c.Param("user_id"), &userId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter user_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAdminUsersUserIdRestore(c, userId)
+}
+
+// PostAuthAccountRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthAccountRestore(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthAccountRestore(c)
+}
+
+// GetAuthConsent operation middleware
+func (siw *ServerInterfaceWrapper) GetAuthConsent(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthConsentParams
+
+	// ------------- Required query parameter "flow_token" -------------
+
+	if paramValue := c.Query("flow_token"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument flow_token is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "flow_token", c.Request.URL.Query(), &params.FlowToken)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flow_token: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAuthConsent(c, params)
+}
+
+// PostAuthConsent operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthConsent(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthConsent(c)
+}
+
+// PostAuthMfa operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthMfa(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthMfa(c)
+}
+
+// PostAuthMfaPasskeyStart operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthMfaPasskeyStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthMfaPasskeyStart(c)
+}
+
+// GetAuthProviders operation middleware
+func (siw *ServerInterfaceWrapper) GetAuthProviders(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthProvidersParams
+
+	// ------------- Required query parameter "client_id" -------------
+
+	if paramValue := c.Query("client_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument client_id is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "client_id", c.Request.URL.Query(), &params.ClientId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAuthProviders(c, params)
+}
+
+// PostAuthProvidersEmailLogin operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailLogin(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailLogin(c)
+}
+
+// PostAuthProvidersEmailPasswordForgot operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailPasswordForgot(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailPasswordForgot(c)
+}
+
+// PostAuthProvidersEmailPasswordReset operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailPasswordReset(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailPasswordReset(c)
+}
+
+// PostAuthProvidersEmailRegister operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailRegister(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailRegister(c)
+}
+
+// PostAuthProvidersEmailUnlock operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailUnlock(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailUnlock(c)
+}
+
+// GetAuthProvidersEmailVerify operation middleware
+func (siw *ServerInterfaceWrapper) GetAuthProvidersEmailVerify(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthProvidersEmailVerifyParams
+
+	// ------------- Required query parameter "token" -------------
+
+	if paramValue := c.Query("token"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument token is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "token", c.Request.URL.Query(), &params.Token)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter token: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAuthProvidersEmailVerify(c, params)
+}
+
+// PostAuthProvidersEmailVerifyResend operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailVerifyResend(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailVerifyResend(c)
+}
+
+// PostAuthProvidersEmailOtpStart operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailOtpStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailOtpStart(c)
+}
+
+// PostAuthProvidersEmailOtpVerify operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersEmailOtpVerify(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersEmailOtpVerify(c)
+}
+
+// PostAuthProvidersMagicLinkStart operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersMagicLinkStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersMagicLinkStart(c)
+}
+
+// PostAuthProvidersMagicLinkVerify operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersMagicLinkVerify(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersMagicLinkVerify(c)
+}
+
+// PostAuthProvidersPasskeyFinish operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersPasskeyFinish(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersPasskeyFinish(c)
+}
+
+// PostAuthProvidersPasskeyStart operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersPasskeyStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersPasskeyStart(c)
+}
+
+// PostAuthProvidersPhoneStart operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersPhoneStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersPhoneStart(c)
+}
+
+// PostAuthProvidersPhoneVerify operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthProvidersPhoneVerify(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthProvidersPhoneVerify(c)
+}
+
+// PostAuthReauthenticate operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthReauthenticate(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthReauthenticate(c)
+}
+
+// PostAuthRefresh operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthRefresh(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthRefresh(c)
+}
+
+// PostAuthToken operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthToken(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthToken(c)
+}
+
+// PostAuthVerify operation middleware
+func (siw *ServerInterfaceWrapper) PostAuthVerify(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostAuthVerify(c)
+}
+
+// PostClients operation middleware
+func (siw *ServerInterfaceWrapper) PostClients(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostClients(c)
+}
+
+// DeleteClientsClientId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteClientsClientId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteClientsClientId(c, clientId)
+}
+
+// GetClientsClientId operation middleware
+func (siw *ServerInterfaceWrapper) GetClientsClientId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetClientsClientId(c, clientId)
+}
+
+// PutClientsClientId operation middleware
+func (siw *ServerInterfaceWrapper) PutClientsClientId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
 			return
 		}
 	}
 
-	siw.Handler.PostAuthToken(c)
+	siw.Handler.PutClientsClientId(c, clientId)
 }
 
-// PostAuthVerify operation middleware
-func (siw *ServerInterfaceWrapper) PostAuthVerify(c *gin.Context) {
+// GetScopes operation middleware
+func (siw *ServerInterfaceWrapper) GetScopes(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -297,7 +2474,322 @@ func (siw *ServerInterfaceWrapper) PostAuthVerify(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.PostAuthVerify(c)
+	siw.Handler.GetScopes(c)
+}
+
+// DeleteUser operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUser(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUser(c)
+}
+
+// GetUserConsents operation middleware
+func (siw *ServerInterfaceWrapper) GetUserConsents(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserConsents(c)
+}
+
+// DeleteUserConsentsClientId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUserConsentsClientId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "client_id" -------------
+	var clientId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "client_id", c.Param("client_id"), &clientId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter client_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUserConsentsClientId(c, clientId)
+}
+
+// GetUserCredentials operation middleware
+func (siw *ServerInterfaceWrapper) GetUserCredentials(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserCredentials(c)
+}
+
+// PostUserExport operation middleware
+func (siw *ServerInterfaceWrapper) PostUserExport(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserExport(c)
+}
+
+// GetUserExportJobId operation middleware
+func (siw *ServerInterfaceWrapper) GetUserExportJobId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "job_id" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "job_id", c.Param("job_id"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter job_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserExportJobId(c, jobId)
+}
+
+// GetUserExportJobIdDownload operation middleware
+func (siw *ServerInterfaceWrapper) GetUserExportJobIdDownload(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "job_id" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "job_id", c.Param("job_id"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter job_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserExportJobIdDownload(c, jobId)
+}
+
+// GetUserMfa operation middleware
+func (siw *ServerInterfaceWrapper) GetUserMfa(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserMfa(c)
+}
+
+// GetUserMfaRecoveryCodes operation middleware
+func (siw *ServerInterfaceWrapper) GetUserMfaRecoveryCodes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserMfaRecoveryCodes(c)
+}
+
+// PostUserMfaRecoveryCodes operation middleware
+func (siw *ServerInterfaceWrapper) PostUserMfaRecoveryCodes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserMfaRecoveryCodes(c)
+}
+
+// DeleteUserMfaTotp operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUserMfaTotp(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUserMfaTotp(c)
+}
+
+// PostUserMfaTotp operation middleware
+func (siw *ServerInterfaceWrapper) PostUserMfaTotp(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserMfaTotp(c)
+}
+
+// PostUserMfaTotpConfirm operation middleware
+func (siw *ServerInterfaceWrapper) PostUserMfaTotpConfirm(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserMfaTotpConfirm(c)
+}
+
+// GetUserPasskeys operation middleware
+func (siw *ServerInterfaceWrapper) GetUserPasskeys(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserPasskeys(c)
+}
+
+// PostUserPasskeysRegisterFinish operation middleware
+func (siw *ServerInterfaceWrapper) PostUserPasskeysRegisterFinish(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserPasskeysRegisterFinish(c)
+}
+
+// PostUserPasskeysRegisterStart operation middleware
+func (siw *ServerInterfaceWrapper) PostUserPasskeysRegisterStart(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserPasskeysRegisterStart(c)
+}
+
+// DeleteUserPasskeysPasskeyId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUserPasskeysPasskeyId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "passkey_id" -------------
+	var passkeyId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "passkey_id", c.Param("passkey_id"), &passkeyId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter passkey_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUserPasskeysPasskeyId(c, passkeyId)
+}
+
+// PatchUserPasskeysPasskeyId operation middleware
+func (siw *ServerInterfaceWrapper) PatchUserPasskeysPasskeyId(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "passkey_id" -------------
+	var passkeyId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "passkey_id", c.Param("passkey_id"), &passkeyId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter passkey_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PatchUserPasskeysPasskeyId(c, passkeyId)
+}
+
+// PutUserPassword operation middleware
+func (siw *ServerInterfaceWrapper) PutUserPassword(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutUserPassword(c)
+}
+
+// PostUserStepUp operation middleware
+func (siw *ServerInterfaceWrapper) PostUserStepUp(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostUserStepUp(c)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -327,10 +2819,78 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.GET(options.BaseURL+"/.well-known/jwks.json", wrapper.GetWellKnownJwksJson)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/email_verification", wrapper.PutAdminClientsClientIdEmailVerification)
+	router.DELETE(options.BaseURL+"/admin/clients/:client_id/legacy_authenticator", wrapper.DeleteAdminClientsClientIdLegacyAuthenticator)
+	router.GET(options.BaseURL+"/admin/clients/:client_id/legacy_authenticator", wrapper.GetAdminClientsClientIdLegacyAuthenticator)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/legacy_authenticator", wrapper.PutAdminClientsClientIdLegacyAuthenticator)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/lockout", wrapper.PutAdminClientsClientIdLockout)
+	router.GET(options.BaseURL+"/admin/clients/:client_id/password_policy", wrapper.GetAdminClientsClientIdPasswordPolicy)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/password_policy", wrapper.PutAdminClientsClientIdPasswordPolicy)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/providers/:provider_id", wrapper.PutAdminClientsClientIdProvidersProviderId)
+	router.PUT(options.BaseURL+"/admin/clients/:client_id/scopes", wrapper.PutAdminClientsClientIdScopes)
+	router.POST(options.BaseURL+"/admin/clients/:client_id/users/import", wrapper.PostAdminClientsClientIdUsersImport)
+	router.GET(options.BaseURL+"/admin/resources", wrapper.GetAdminResources)
+	router.POST(options.BaseURL+"/admin/resources", wrapper.PostAdminResources)
+	router.DELETE(options.BaseURL+"/admin/resources/:resource_id", wrapper.DeleteAdminResourcesResourceId)
+	router.PUT(options.BaseURL+"/admin/resources/:resource_id", wrapper.PutAdminResourcesResourceId)
+	router.POST(options.BaseURL+"/admin/scopes", wrapper.PostAdminScopes)
+	router.DELETE(options.BaseURL+"/admin/scopes/:scope", wrapper.DeleteAdminScopesScope)
+	router.DELETE(options.BaseURL+"/admin/users/:user_id", wrapper.DeleteAdminUsersUserId)
+	router.POST(options.BaseURL+"/admin/users/:user_id/export", wrapper.PostAdminUsersUserIdExport)
+	router.GET(options.BaseURL+"/admin/users/:user_id/export/:job_id", wrapper.GetAdminUsersUserIdExportJobId)
+	router.GET(options.BaseURL+"/admin/users/:user_id/export/:job_id/download", wrapper.GetAdminUsersUserIdExportJobIdDownload)
+	router.DELETE(options.BaseURL+"/admin/users/:user_id/lockout", wrapper.DeleteAdminUsersUserIdLockout)
+	router.GET(options.BaseURL+"/admin/users/:user_id/mfa", wrapper.GetAdminUsersUserIdMfa)
+	router.POST(options.BaseURL+"/admin/users/:user_id/restore", wrapper.PostAdminUsersUserIdRestore)
+	router.POST(options.BaseURL+"/auth/account/restore", wrapper.PostAuthAccountRestore)
+	router.GET(options.BaseURL+"/auth/consent", wrapper.GetAuthConsent)
+	router.POST(options.BaseURL+"/auth/consent", wrapper.PostAuthConsent)
+	router.POST(options.BaseURL+"/auth/mfa", wrapper.PostAuthMfa)
+	router.POST(options.BaseURL+"/auth/mfa/passkey/start", wrapper.PostAuthMfaPasskeyStart)
 	router.GET(options.BaseURL+"/auth/providers", wrapper.GetAuthProviders)
 	router.POST(options.BaseURL+"/auth/providers/email/login", wrapper.PostAuthProvidersEmailLogin)
+	router.POST(options.BaseURL+"/auth/providers/email/password/forgot", wrapper.PostAuthProvidersEmailPasswordForgot)
+	router.POST(options.BaseURL+"/auth/providers/email/password/reset", wrapper.PostAuthProvidersEmailPasswordReset)
 	router.POST(options.BaseURL+"/auth/providers/email/register", wrapper.PostAuthProvidersEmailRegister)
+	router.POST(options.BaseURL+"/auth/providers/email/unlock", wrapper.PostAuthProvidersEmailUnlock)
+	router.GET(options.BaseURL+"/auth/providers/email/verify", wrapper.GetAuthProvidersEmailVerify)
+	router.POST(options.BaseURL+"/auth/providers/email/verify/resend", wrapper.PostAuthProvidersEmailVerifyResend)
+	router.POST(options.BaseURL+"/auth/providers/email_otp/start", wrapper.PostAuthProvidersEmailOtpStart)
+	router.POST(options.BaseURL+"/auth/providers/email_otp/verify", wrapper.PostAuthProvidersEmailOtpVerify)
+	router.POST(options.BaseURL+"/auth/providers/magic_link/start", wrapper.PostAuthProvidersMagicLinkStart)
+	router.POST(options.BaseURL+"/auth/providers/magic_link/verify", wrapper.PostAuthProvidersMagicLinkVerify)
+	router.POST(options.BaseURL+"/auth/providers/passkey/finish", wrapper.PostAuthProvidersPasskeyFinish)
+	router.POST(options.BaseURL+"/auth/providers/passkey/start", wrapper.PostAuthProvidersPasskeyStart)
+	router.POST(options.BaseURL+"/auth/providers/phone/start", wrapper.PostAuthProvidersPhoneStart)
+	router.POST(options.BaseURL+"/auth/providers/phone/verify", wrapper.PostAuthProvidersPhoneVerify)
+	router.POST(options.BaseURL+"/auth/reauthenticate", wrapper.PostAuthReauthenticate)
 	router.POST(options.BaseURL+"/auth/refresh", wrapper.PostAuthRefresh)
 	router.POST(options.BaseURL+"/auth/token", wrapper.PostAuthToken)
 	router.POST(options.BaseURL+"/auth/verify", wrapper.PostAuthVerify)
+	router.POST(options.BaseURL+"/clients", wrapper.PostClients)
+	router.DELETE(options.BaseURL+"/clients/:client_id", wrapper.DeleteClientsClientId)
+	router.GET(options.BaseURL+"/clients/:client_id", wrapper.GetClientsClientId)
+	router.PUT(options.BaseURL+"/clients/:client_id", wrapper.PutClientsClientId)
+	router.GET(options.BaseURL+"/scopes", wrapper.GetScopes)
+	router.DELETE(options.BaseURL+"/user", wrapper.DeleteUser)
+	router.GET(options.BaseURL+"/user/consents", wrapper.GetUserConsents)
+	router.DELETE(options.BaseURL+"/user/consents/:client_id", wrapper.DeleteUserConsentsClientId)
+	router.GET(options.BaseURL+"/user/credentials", wrapper.GetUserCredentials)
+	router.POST(options.BaseURL+"/user/export", wrapper.PostUserExport)
+	router.GET(options.BaseURL+"/user/export/:job_id", wrapper.GetUserExportJobId)
+	router.GET(options.BaseURL+"/user/export/:job_id/download", wrapper.GetUserExportJobIdDownload)
+	router.GET(options.BaseURL+"/user/mfa", wrapper.GetUserMfa)
+	router.GET(options.BaseURL+"/user/mfa/recovery_codes", wrapper.GetUserMfaRecoveryCodes)
+	router.POST(options.BaseURL+"/user/mfa/recovery_codes", wrapper.PostUserMfaRecoveryCodes)
+	router.DELETE(options.BaseURL+"/user/mfa/totp", wrapper.DeleteUserMfaTotp)
+	router.POST(options.BaseURL+"/user/mfa/totp", wrapper.PostUserMfaTotp)
+	router.POST(options.BaseURL+"/user/mfa/totp/confirm", wrapper.PostUserMfaTotpConfirm)
+	router.GET(options.BaseURL+"/user/passkeys", wrapper.GetUserPasskeys)
+	router.POST(options.BaseURL+"/user/passkeys/register/finish", wrapper.PostUserPasskeysRegisterFinish)
+	router.POST(options.BaseURL+"/user/passkeys/register/start", wrapper.PostUserPasskeysRegisterStart)
+	router.DELETE(options.BaseURL+"/user/passkeys/:passkey_id", wrapper.DeleteUserPasskeysPasskeyId)
+	router.PATCH(options.BaseURL+"/user/passkeys/:passkey_id", wrapper.PatchUserPasskeysPasskeyId)
+	router.PUT(options.BaseURL+"/user/password", wrapper.PutUserPassword)
+	router.POST(options.BaseURL+"/user/step_up", wrapper.PostUserStepUp)
 }
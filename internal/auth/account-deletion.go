@@ -0,0 +1,380 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccountDeletionError string
+
+const (
+	AccountDeletionErrorUserNotFound       AccountDeletionError = "user not found"
+	AccountDeletionErrorNotDeleted         AccountDeletionError = "user is not deleted or can no longer be restored"
+	AccountDeletionErrorInvalidToken       AccountDeletionError = "invalid or expired restore token"
+	AccountDeletionErrorConflict           AccountDeletionError = "identity was taken by another user"
+	AccountDeletionErrorInvalidRedirectUri AccountDeletionError = "redirect uri is not registered for client"
+	AccountDeletionErrorNoRestoreLink      AccountDeletionError = "client has no redirect uri for the restore link"
+)
+
+var defaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// how long deleted accounts can be restored
+var accountDeletionGracePeriod = defaultAccountDeletionGracePeriod
+
+func SetAccountDeletionGracePeriod(period time.Duration) {
+	accountDeletionGracePeriod = period
+}
+
+// AccountDeletionGracePeriodFromConfig reads ACCOUNT_DELETION_GRACE_DAYS, 30
+// days when it isn't set
+func AccountDeletionGracePeriodFromConfig(appConfig config.Config) (time.Duration, error) {
+	if appConfig.ACCOUNT_DELETION_GRACE_DAYS == "" {
+		return defaultAccountDeletionGracePeriod, nil
+	}
+	days, err := strconv.Atoi(appConfig.ACCOUNT_DELETION_GRACE_DAYS)
+	if err != nil || days < 0 || days > 365 {
+		return 0, fmt.Errorf("Env variable ACCOUNT_DELETION_GRACE_DAYS must be a number between 0 and 365")
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+type DeleteAccountInput struct {
+	DeletedBy string
+	// where the restore link goes, and the language of its email
+	RedirectUri string
+	Locale      string
+}
+
+// DeleteAccount soft deletes the user and their identities, so they can no
+// longer sign in, and signs them out everywhere. the account can be restored
+// until the grace period ends, then it is purged. users deleting their own
+// account are emailed a link to restore it, accounts deleted by an admin
+// can only be restored by an admin
+func DeleteAccount(db *gorm.DB, mailer mail.Mailer, userId string, input DeleteAccountInput) (*models.AccountDeletion, error) {
+	var user models.User
+	result := db.Limit(1).Find(&user, "id = ?", userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(AccountDeletionErrorUserNotFound))
+	}
+
+	var client models.Client
+	if err := db.First(&client, "id = ?", user.ClientId).Error; err != nil {
+		return nil, err
+	}
+	if input.RedirectUri != "" {
+		if _, ok := emailLink(&client, input.RedirectUri, ""); !ok {
+			return nil, errors.New(string(AccountDeletionErrorInvalidRedirectUri))
+		}
+	}
+	// users are only told how to restore their account by the email, so it
+	// isn't deleted when the link has nowhere to go
+	sendsRestoreLink := input.DeletedBy == models.AccountDeletedByUser && user.Email != ""
+	if sendsRestoreLink {
+		if _, ok := emailLink(&client, input.RedirectUri, ""); !ok {
+			return nil, errors.New(string(AccountDeletionErrorNoRestoreLink))
+		}
+	}
+
+	// the database keeps microseconds, restoring compares with it
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	deletion := models.AccountDeletion{
+		UserId:    user.ID,
+		ClientId:  user.ClientId,
+		DeletedBy: input.DeletedBy,
+		DeletedAt: now,
+		PurgeAt:   now.Add(accountDeletionGracePeriod),
+	}
+	restoreToken := ""
+	if sendsRestoreLink {
+		restoreToken = crypto.GenerateSecureSecret()
+		restoreTokenHash := crypto.HashSecret(restoreToken)
+		deletion.RestoreTokenHash = &restoreTokenHash
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&deletion).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, user.ID, ""); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Identity{}).Where("user_id = ?", user.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("deleted_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if restoreToken != "" {
+		if err := sendAccountDeletedEmail(mailer, &client, &user, &deletion, restoreToken, input); err != nil {
+			log.Println("failed to send account deleted email:", err)
+		}
+	}
+
+	events.Publish(events.Event{
+		Type:     events.UserDeleted,
+		ClientId: user.ClientId,
+		UserId:   user.ID,
+		Data:     map[string]interface{}{"deleted_by": input.DeletedBy, "purge_at": deletion.PurgeAt},
+		At:       now,
+	})
+
+	return &deletion, nil
+}
+
+func sendAccountDeletedEmail(mailer mail.Mailer, client *models.Client, user *models.User, deletion *models.AccountDeletion, restoreToken string, input DeleteAccountInput) error {
+	link, ok := emailLink(client, input.RedirectUri, restoreToken)
+	if !ok {
+		return errors.New("client has no redirect uri for the restore link")
+	}
+
+	message, err := mail.Render(client, mail.TemplateAccountDeleted, input.Locale, user.Email, mail.Data{
+		"Link":      link,
+		"PurgeDate": deletion.PurgeAt.Format("2006-01-02"),
+	})
+	if err != nil {
+		return err
+	}
+	return mailer.Send(message)
+}
+
+// RestoreAccount brings back a deleted user that wasn't purged yet. they
+// were signed out when deleted and have to sign in again
+func RestoreAccount(db *gorm.DB, userId string, restoredBy string) error {
+	var deletion models.AccountDeletion
+	result := db.Limit(1).Find(&deletion, "user_id = ? AND purge_at > ?", userId, time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(AccountDeletionErrorNotDeleted))
+	}
+	return restoreAccount(db, &deletion, restoredBy)
+}
+
+// RestoreAccountWithToken restores the account with the link from the
+// account deleted email
+func RestoreAccountWithToken(db *gorm.DB, token string) error {
+	if token == "" {
+		return errors.New(string(AccountDeletionErrorInvalidToken))
+	}
+
+	var deletion models.AccountDeletion
+	result := db.Limit(1).Find(&deletion, "restore_token_hash = ? AND purge_at > ?", crypto.HashSecret(token), time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(AccountDeletionErrorInvalidToken))
+	}
+	return restoreAccount(db, &deletion, models.AccountDeletedByUser)
+}
+
+func restoreAccount(db *gorm.DB, deletion *models.AccountDeletion, restoredBy string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// claiming the deletion keeps the purge from running at the same time
+		result := tx.Where("user_id = ? AND purge_at > ?", deletion.UserId, time.Now()).Delete(&models.AccountDeletion{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(string(AccountDeletionErrorNotDeleted))
+		}
+
+		var identities []models.Identity
+		if err := tx.Unscoped().Where("user_id = ? AND deleted_at = ?", deletion.UserId, deletion.DeletedAt).Find(&identities).Error; err != nil {
+			return err
+		}
+		// someone may have signed up with the same phone number meanwhile
+		for _, identity := range identities {
+			var taken int64
+			err := tx.Model(&models.Identity{}).
+				Where("client_id = ? AND provider_option_id = ? AND provider_sub = ?", identity.ClientId, identity.ProviderOptionId, identity.ProviderSub).
+				Count(&taken).Error
+			if err != nil {
+				return err
+			}
+			if taken > 0 {
+				return errors.New(string(AccountDeletionErrorConflict))
+			}
+		}
+
+		err := tx.Unscoped().Model(&models.Identity{}).
+			Where("user_id = ? AND deleted_at = ?", deletion.UserId, deletion.DeletedAt).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.User{}).Where("id = ?", deletion.UserId).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.Event{
+		Type:     events.UserRestored,
+		ClientId: deletion.ClientId,
+		UserId:   deletion.UserId,
+		Data:     map[string]interface{}{"restored_by": restoredBy},
+	})
+	return nil
+}
+
+// PurgeAccount erases a deleted user right away instead of at the end of the
+// grace period
+func PurgeAccount(db *gorm.DB, userId string) error {
+	var deletion models.AccountDeletion
+	result := db.Limit(1).Find(&deletion, "user_id = ?", userId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(AccountDeletionErrorNotDeleted))
+	}
+	return purgeAccount(db, &deletion)
+}
+
+// RunAccountPurges erases the users whose grace period ended
+func RunAccountPurges(db *gorm.DB) {
+	var deletions []models.AccountDeletion
+	if err := db.Where("purge_at <= ?", time.Now()).Find(&deletions).Error; err != nil {
+		log.Println("failed to find deleted accounts:", err)
+		return
+	}
+	for _, deletion := range deletions {
+		if err := purgeAccount(db, &deletion); err != nil {
+			log.Println("failed to purge account:", err)
+		}
+	}
+}
+
+// StartAccountPurgeWorker calls RunAccountPurges every interval
+func StartAccountPurgeWorker(db *gorm.DB, interval time.Duration) {
+	go func() {
+		for {
+			RunAccountPurges(db)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// purgeAccount removes the user with everything that belongs to them. their
+// audit events are kept without anything that points back at them
+func purgeAccount(db *gorm.DB, deletion *models.AccountDeletion) error {
+	userId := deletion.UserId
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// only one instance purges, and not while the user is being restored
+		result := tx.Where("user_id = ?", userId).Delete(&models.AccountDeletion{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var user models.User
+		if err := tx.Unscoped().First(&user, "id = ?", userId).Error; err != nil {
+			return err
+		}
+
+		var identities []models.Identity
+		if err := tx.Unscoped().Where("user_id = ?", userId).Find(&identities).Error; err != nil {
+			return err
+		}
+		identityIds := []string{}
+		for _, identity := range identities {
+			identityIds = append(identityIds, identity.ID)
+
+			// codes and throttles are keyed by the address they went to
+			if identity.ProviderOptionId == "email" {
+				if err := tx.Where("client_id = ? AND kind = ? AND subject = ?", identity.ClientId, models.SignInThrottleKindIdentifier, identity.ProviderSub).Delete(&models.SignInThrottle{}).Error; err != nil {
+					return err
+				}
+			}
+			recipients := []string{identity.ProviderSub}
+			if identity.Phone != nil {
+				recipients = append(recipients, *identity.Phone)
+			}
+			if err := tx.Where("client_id = ? AND recipient IN ?", identity.ClientId, recipients).Delete(&models.OneTimeCode{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("client_id = ? AND email = ?", identity.ClientId, identity.ProviderSub).Delete(&models.MagicLink{}).Error; err != nil {
+				return err
+			}
+		}
+
+		byIdentity := []interface{}{
+			&models.PasswordHistory{},
+			&models.EmailVerificationToken{},
+			&models.PasswordResetToken{},
+		}
+		if len(identityIds) > 0 {
+			for _, model := range byIdentity {
+				if err := tx.Where("identity_id IN ?", identityIds).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// everything pointing at the identities goes before them
+		byUser := []interface{}{
+			&models.RedeemAuthCode{},
+			&models.RefreshToken{},
+			&models.AuthenticationFlow{},
+			&models.Session{},
+			&models.ConsentGrant{},
+			&models.MfaRecoveryCode{},
+			&models.MfaFactor{},
+			&models.PasskeyCredential{},
+			&models.PasskeyChallenge{},
+			&models.ExportJob{},
+		}
+		for _, model := range byUser {
+			if err := tx.Where("user_id = ?", userId).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&models.Identity{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.AuditEvent{}).Where("user_id = ?", userId).Updates(map[string]interface{}{
+			"user_id": "",
+			"data":    models.JsonDictionary{},
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&user).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// only the id is left, for client apps to drop what they keep about them
+	events.Publish(events.Event{
+		Type:     events.UserPurged,
+		ClientId: deletion.ClientId,
+		UserId:   userId,
+		Data:     map[string]interface{}{},
+	})
+	return nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"log"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog stores every event it is handed, so users can see what happened
+// to their account in their export
+func AuditLog(db *gorm.DB) events.Handler {
+	return func(event events.Event) {
+		err := db.Create(&models.AuditEvent{
+			Type:      string(event.Type),
+			ClientId:  event.ClientId,
+			UserId:    event.UserId,
+			Data:      models.JsonDictionary(event.Data),
+			CreatedAt: event.At,
+		}).Error
+		if err != nil {
+			log.Println("failed to store event:", err)
+		}
+	}
+}
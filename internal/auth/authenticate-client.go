@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthenticateClientError string
+
+const (
+	AuthenticateClientErrorMissingAssertion     AuthenticateClientError = "client assertion required"
+	AuthenticateClientErrorInvalidAssertionType AuthenticateClientError = "unsupported client assertion type"
+	AuthenticateClientErrorInvalidAssertion     AuthenticateClientError = "invalid client assertion"
+	AuthenticateClientErrorReplayedAssertion    AuthenticateClientError = "client assertion already used"
+	AuthenticateClientErrorNoKeys               AuthenticateClientError = "client has no keys registered"
+)
+
+const ClientAssertionTypeJwtBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// assertions are meant to be single use and short lived, anything valid for
+// longer than this is rejected so the jti table stays small
+const maxClientAssertionLifetime = 10 * time.Minute
+
+var clientAssertionSigningMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+func getClientJwks(client *models.Client, skipCache bool) (*crypto.Jwks, error) {
+	if len(client.Jwks) > 0 {
+		data, err := json.Marshal(client.Jwks)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.ParseJwks(data)
+	}
+
+	if client.JwksUri != nil && *client.JwksUri != "" {
+		return crypto.FetchJwks(*client.JwksUri, skipCache)
+	}
+
+	return nil, errors.New(string(AuthenticateClientErrorNoKeys))
+}
+
+func parseClientAssertion(client *models.Client, assertion string, skipCache bool) (*jwt.RegisteredClaims, error) {
+	var claims jwt.RegisteredClaims
+
+	_, err := jwt.ParseWithClaims(assertion, &claims, func(t *jwt.Token) (interface{}, error) {
+		jwks, err := getClientJwks(client, skipCache)
+		if err != nil {
+			return nil, err
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return jwks.FindKey(kid, t.Method.Alg())
+	},
+		jwt.WithValidMethods(clientAssertionSigningMethods),
+		jwt.WithIssuer(client.ID),
+		jwt.WithSubject(client.ID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// rememberJti stores the jti until the assertion expires. the unique index on
+// (client_id, jti) makes a second insert of the same value a no-op, which is
+// how we detect replays
+func rememberJti(db *gorm.DB, clientId string, jti string, expiresAt time.Time) (bool, error) {
+	db.Where("expires_at < ?", time.Now()).Delete(&models.ClientAssertionJti{})
+
+	record := models.ClientAssertionJti{
+		ClientId:  clientId,
+		Jti:       jti,
+		ExpiresAt: expiresAt,
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// AuthenticateClient checks that the caller is allowed to act as client.
+// public clients (token endpoint auth method none) rely on pkce alone, while
+// private_key_jwt clients must present a signed client assertion (RFC 7523)
+// whose audience is one of the given urls
+func AuthenticateClient(db *gorm.DB, client *models.Client, assertionType string, assertion string, audiences []string) error {
+	if client.TokenEndpointAuthMethod != models.TokenEndpointAuthMethodPrivateKeyJwt {
+		return nil
+	}
+
+	if assertion == "" {
+		return errors.New(string(AuthenticateClientErrorMissingAssertion))
+	}
+
+	if assertionType != ClientAssertionTypeJwtBearer {
+		return errors.New(string(AuthenticateClientErrorInvalidAssertionType))
+	}
+
+	claims, err := parseClientAssertion(client, assertion, false)
+	if err != nil && client.JwksUri != nil && len(client.Jwks) == 0 {
+		// the client may have rotated its keys since we cached them
+		claims, err = parseClientAssertion(client, assertion, true)
+	}
+	if err != nil {
+		return errors.New(string(AuthenticateClientErrorInvalidAssertion))
+	}
+
+	audienceMatches := slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return aud != "" && slices.Contains(audiences, aud)
+	})
+	if !audienceMatches || claims.ID == "" {
+		return errors.New(string(AuthenticateClientErrorInvalidAssertion))
+	}
+
+	expiresAt := claims.ExpiresAt.Time
+	if time.Until(expiresAt) > maxClientAssertionLifetime {
+		return errors.New(string(AuthenticateClientErrorInvalidAssertion))
+	}
+
+	fresh, err := rememberJti(db, client.ID, claims.ID, expiresAt)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New(string(AuthenticateClientErrorReplayedAssertion))
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"sentinel-auth-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testTokenEndpoint = "https://auth.example.com/v1/auth/token"
+
+// testAssertionClient is a private_key_jwt client with the public half of
+// the returned key registered
+func testAssertionClient(t *testing.T) (*models.Client, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &models.Client{
+		ID:                      "app",
+		TokenEndpointAuthMethod: models.TokenEndpointAuthMethodPrivateKeyJwt,
+		Jwks: models.JsonDictionary{"keys": []interface{}{map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"kid": "key-1",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}},
+	}
+	return client, key
+}
+
+func signTestAssertion(t *testing.T, key *ecdsa.PrivateKey, claims jwt.RegisteredClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func testAssertionClaims(jti string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    "app",
+		Subject:   "app",
+		Audience:  jwt.ClaimStrings{testTokenEndpoint},
+		ID:        jti,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func TestAuthenticateClient(t *testing.T) {
+	client, key := testAssertionClient(t)
+	_, otherKey := testAssertionClient(t)
+
+	expired := testAssertionClaims("expired")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	wrongAudience := testAssertionClaims("wrong-audience")
+	wrongAudience.Audience = jwt.ClaimStrings{"https://other.example.com/token"}
+	longLived := testAssertionClaims("long-lived")
+	longLived.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	wrongIssuer := testAssertionClaims("wrong-issuer")
+	wrongIssuer.Issuer = "other"
+	noExpiry := testAssertionClaims("no-expiry")
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name          string
+		assertionType string
+		assertion     string
+		want          AuthenticateClientError
+	}{
+		{"missing", ClientAssertionTypeJwtBearer, "", AuthenticateClientErrorMissingAssertion},
+		{"wrong type", "urn:example:other", signTestAssertion(t, key, testAssertionClaims("wrong-type")), AuthenticateClientErrorInvalidAssertionType},
+		{"expired", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, expired), AuthenticateClientErrorInvalidAssertion},
+		{"no expiry", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, noExpiry), AuthenticateClientErrorInvalidAssertion},
+		{"valid too long", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, longLived), AuthenticateClientErrorInvalidAssertion},
+		{"wrong audience", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, wrongAudience), AuthenticateClientErrorInvalidAssertion},
+		{"wrong issuer", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, wrongIssuer), AuthenticateClientErrorInvalidAssertion},
+		{"no jti", ClientAssertionTypeJwtBearer, signTestAssertion(t, key, testAssertionClaims("")), AuthenticateClientErrorInvalidAssertion},
+		{"unregistered key", ClientAssertionTypeJwtBearer, signTestAssertion(t, otherKey, testAssertionClaims("other-key")), AuthenticateClientErrorInvalidAssertion},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := testDb(t)
+			err := AuthenticateClient(db, client, test.assertionType, test.assertion, []string{testTokenEndpoint})
+			if err == nil || err.Error() != string(test.want) {
+				t.Errorf("err = %v, want %s", err, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateClientRefusesReusedJti(t *testing.T) {
+	db := testDb(t)
+	client, key := testAssertionClient(t)
+
+	assertion := signTestAssertion(t, key, testAssertionClaims("jti-1"))
+	if err := AuthenticateClient(db, client, ClientAssertionTypeJwtBearer, assertion, []string{testTokenEndpoint}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := AuthenticateClient(db, client, ClientAssertionTypeJwtBearer, assertion, []string{testTokenEndpoint})
+	if err == nil || err.Error() != string(AuthenticateClientErrorReplayedAssertion) {
+		t.Errorf("replay: err = %v, want %s", err, AuthenticateClientErrorReplayedAssertion)
+	}
+
+	// a new assertion with the same jti is a replay as well
+	again := signTestAssertion(t, key, testAssertionClaims("jti-1"))
+	err = AuthenticateClient(db, client, ClientAssertionTypeJwtBearer, again, []string{testTokenEndpoint})
+	if err == nil || err.Error() != string(AuthenticateClientErrorReplayedAssertion) {
+		t.Errorf("same jti: err = %v, want %s", err, AuthenticateClientErrorReplayedAssertion)
+	}
+
+	// other clients may use the same jti
+	other := *client
+	other.ID = "other"
+	otherClaims := testAssertionClaims("jti-1")
+	otherClaims.Issuer, otherClaims.Subject = "other", "other"
+	if err := AuthenticateClient(db, &other, ClientAssertionTypeJwtBearer, signTestAssertion(t, key, otherClaims), []string{testTokenEndpoint}); err != nil {
+		t.Errorf("jti of another client refused: %v", err)
+	}
+}
+
+func TestAuthenticateClientSkipsPublicClients(t *testing.T) {
+	db := testDb(t)
+	if err := AuthenticateClient(db, &models.Client{ID: "app", TokenEndpointAuthMethod: models.TokenEndpointAuthMethodNone}, "", "", nil); err != nil {
+		t.Errorf("public client: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type AuthenticationFlowError string
+
+const (
+	AuthenticationFlowErrorInvalidFlow  AuthenticationFlowError = "invalid or expired flow"
+	AuthenticationFlowErrorWrongStep    AuthenticationFlowError = "flow is not waiting for this step"
+	AuthenticationFlowErrorAccessDenied AuthenticationFlowError = "user denied access"
+)
+
+// how long a user has to finish the remaining steps of a sign in
+const authenticationFlowDurationSeconds = 600
+
+// AuthorizationParams are the parts of an authorization request that end up
+// on the auth code
+type AuthorizationParams struct {
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Scopes              []string
+	Resources           []string
+	State               *string
+	// acr_values of the request, in order of preference
+	AcrValues []string
+	// when the user proved who they are, now when zero
+	AuthTime time.Time
+	// authenticator assurance level reached so far, 1 when zero
+	Aal int
+	// authentication methods used so far, those of the identity's provider
+	// when empty
+	Amr []string
+	// existing session to step up, a new session is started when empty
+	SessionId string
+}
+
+type PendingAuthentication struct {
+	FlowToken string
+	NextStep  string
+	ExpiresIn int
+	// second factors the user can choose from when NextStep is mfa, or
+	// the ways to prove who they are again when it is reauthenticate
+	MfaFactors []string
+}
+
+// AuthorizationResult is either an auth code or, when the user still has
+// something to do, a flow token to continue with
+type AuthorizationResult struct {
+	Code    *GenerateAuthCodeResponse
+	Pending *PendingAuthentication
+	State   *string
+}
+
+func (params AuthorizationParams) aal() int {
+	if params.Aal < 1 {
+		return 1
+	}
+	return params.Aal
+}
+
+func (params AuthorizationParams) amr(identity *models.Identity) []string {
+	if len(params.Amr) == 0 {
+		return providerAmr(identity.ProviderOptionId)
+	}
+	return params.Amr
+}
+
+func createAuthenticationFlow(db *gorm.DB, identity *models.Identity, params AuthorizationParams, nextStep string) (*PendingAuthentication, error) {
+	flowToken := crypto.GenerateSecureSecret()
+
+	authTime := params.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+
+	pending := PendingAuthentication{
+		FlowToken: flowToken,
+		NextStep:  nextStep,
+		ExpiresIn: authenticationFlowDurationSeconds,
+	}
+	switch nextStep {
+	case models.AuthenticationFlowStepMfa:
+		factors, err := userMfaFactorTypes(db, identity.UserId)
+		if err != nil {
+			return nil, err
+		}
+		pending.MfaFactors = factors
+	case models.AuthenticationFlowStepReauthenticate:
+		factors, err := reauthenticationFactors(db, identity.UserId)
+		if err != nil {
+			return nil, err
+		}
+		pending.MfaFactors = factors
+	}
+
+	flow := models.AuthenticationFlow{
+		ClientId:            identity.ClientId,
+		IdentityId:          identity.ID,
+		UserId:              identity.UserId,
+		TokenHash:           crypto.HashSecret(flowToken),
+		NextStep:            nextStep,
+		CodeChallenge:       params.CodeChallenge,
+		CodeChallengeMethod: params.CodeChallengeMethod,
+		Scopes:              pq.StringArray(params.Scopes),
+		Resources:           pq.StringArray(params.Resources),
+		State:               params.State,
+		ExpiresAt:           time.Now().Add(authenticationFlowDurationSeconds * time.Second),
+		AuthTime:            authTime,
+		Aal:                 params.aal(),
+		Amr:                 pq.StringArray(params.amr(identity)),
+	}
+	if params.SessionId != "" {
+		flow.SessionId = &params.SessionId
+	}
+
+	if err := db.Create(&flow).Error; err != nil {
+		return nil, err
+	}
+
+	return &pending, nil
+}
+
+// nextAuthenticationStep returns the step the user still has to complete
+// before a code can be issued, or "" when there is none
+func nextAuthenticationStep(db *gorm.DB, identity *models.Identity, params AuthorizationParams) (string, error) {
+	if params.aal() < 2 {
+		enrolled, err := hasMfa(db, identity.UserId)
+		if err != nil {
+			return "", err
+		}
+		if enrolled {
+			return models.AuthenticationFlowStepMfa, nil
+		}
+
+		// users with a second factor reach any level, for the others it is
+		// the level they are at
+		if _, minimum := requestedAal(params.AcrValues); minimum > params.aal() {
+			return "", errors.New(string(StepUpErrorUnmet))
+		}
+	}
+
+	var client models.Client
+	if err := db.First(&client, "id = ?", identity.ClientId).Error; err != nil {
+		return "", err
+	}
+
+	if !IsFirstPartyClient(&client) {
+		consented, err := HasConsent(db, identity.UserId, client.ID, params.Scopes)
+		if err != nil {
+			return "", err
+		}
+		if !consented {
+			return models.AuthenticationFlowStepConsent, nil
+		}
+	}
+
+	return "", nil
+}
+
+// CompleteAuthorization is called once a user proved who they are. it issues
+// the auth code, unless another step is needed first in which case the
+// request is parked in an authentication flow
+func CompleteAuthorization(db *gorm.DB, identity *models.Identity, params AuthorizationParams) (*AuthorizationResult, error) {
+	nextStep, err := nextAuthenticationStep(db, identity, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if nextStep != "" {
+		pending, err := createAuthenticationFlow(db, identity, params, nextStep)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthorizationResult{Pending: pending, State: params.State}, nil
+	}
+
+	authTime := params.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+
+	var session *models.Session
+	if params.SessionId != "" {
+		session, err = stepUpSession(db, params.SessionId, authTime, params.aal(), params.amr(identity))
+	} else {
+		session, err = startSession(db, identity, authTime, params.aal(), params.amr(identity))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := GenerateAuthCode(db, identity, session.ID, params.CodeChallenge, params.CodeChallengeMethod, params.Scopes, params.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizationResult{Code: code, State: params.State}, nil
+}
+
+// GetAuthenticationFlow looks up an unexpired flow that is waiting for one
+// of steps
+func GetAuthenticationFlow(db *gorm.DB, flowToken string, steps ...string) (*models.AuthenticationFlow, error) {
+	if flowToken == "" {
+		return nil, errors.New(string(AuthenticationFlowErrorInvalidFlow))
+	}
+
+	var flow models.AuthenticationFlow
+	result := db.Preload("Client").Preload("Identity").Limit(1).Find(&flow, "token_hash = ?", crypto.HashSecret(flowToken))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New(string(AuthenticationFlowErrorInvalidFlow))
+	}
+
+	if flow.ExpiresAt.Before(time.Now()) {
+		db.Delete(&flow)
+		return nil, errors.New(string(AuthenticationFlowErrorInvalidFlow))
+	}
+
+	if !slices.Contains(steps, flow.NextStep) {
+		return nil, errors.New(string(AuthenticationFlowErrorWrongStep))
+	}
+
+	return &flow, nil
+}
+
+func flowAuthorizationParams(flow *models.AuthenticationFlow) AuthorizationParams {
+	params := AuthorizationParams{
+		CodeChallenge:       flow.CodeChallenge,
+		CodeChallengeMethod: flow.CodeChallengeMethod,
+		Scopes:              flow.Scopes,
+		Resources:           flow.Resources,
+		State:               flow.State,
+		AuthTime:            flow.AuthTime,
+		Aal:                 flow.Aal,
+		Amr:                 flow.Amr,
+	}
+	if flow.SessionId != nil {
+		params.SessionId = *flow.SessionId
+	}
+	return params
+}
+
+// continueAuthenticationFlow is called after the flow's current step was
+// completed. the flow is consumed and either a code is issued or a new flow
+// for the following step is started
+func continueAuthenticationFlow(db *gorm.DB, flow *models.AuthenticationFlow) (*AuthorizationResult, error) {
+	result := db.Delete(flow)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	// someone else finished this step with the same token at the same time
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(AuthenticationFlowErrorInvalidFlow))
+	}
+
+	return CompleteAuthorization(db, &flow.Identity, flowAuthorizationParams(flow))
+}
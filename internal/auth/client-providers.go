@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ClientProviderError string
+
+const (
+	ClientProviderErrorClientNotFound   ClientProviderError = "client does not exist"
+	ClientProviderErrorProviderNotFound ClientProviderError = "provider does not exist"
+	ClientProviderErrorNoSmsGateway     ClientProviderError = "no sms gateway is configured"
+)
+
+// SetClientProvider turns a sign in provider on or off for a client. data
+// replaces the provider specific settings when given
+func SetClientProvider(db *gorm.DB, clientId string, providerOptionId string, enabled bool, data *map[string]interface{}) (*models.ClientProvider, error) {
+	if !doesClientExist(db, clientId) {
+		return nil, errors.New(string(ClientProviderErrorClientNotFound))
+	}
+
+	var providerOption models.ProviderOption
+	result := db.Limit(1).Find(&providerOption, "id = ?", providerOptionId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New(string(ClientProviderErrorProviderNotFound))
+	}
+	if providerOptionId == phoneProviderOptionId && enabled && !smsGatewayConfigured {
+		return nil, errors.New(string(ClientProviderErrorNoSmsGateway))
+	}
+
+	var clientProvider models.ClientProvider
+	result = db.Limit(1).Find(&clientProvider, "client_id = ? AND provider_option_id = ?", clientId, providerOptionId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		clientProvider = models.ClientProvider{
+			ClientId:         clientId,
+			ProviderOptionId: providerOptionId,
+			Data:             models.JsonDictionary{},
+		}
+	}
+
+	clientProvider.Enabled = enabled
+	if data != nil {
+		clientProvider.Data = *data
+	}
+
+	if err := db.Save(&clientProvider).Error; err != nil {
+		return nil, err
+	}
+	clientProvider.ProviderOption = providerOption
+
+	return &clientProvider, nil
+}
@@ -3,8 +3,36 @@ package auth
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"regexp"
 )
 
+type CodeChallengeError string
+
+const (
+	CodeChallengeErrorInvalid CodeChallengeError = "code challenge must be an S256 challenge"
+)
+
+// S256 challenges are the unpadded base64url sha256 of the verifier, so
+// always 43 characters
+var s256CodeChallengePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+// checkCodeChallenge fails unless the challenge is one passesCodeChallenge
+// can be satisfied with. the plain method would hand the verifier to whoever
+// sees the request, so only S256 is accepted
+func checkCodeChallenge(codeChallenge string, codeChallengeMethod string) error {
+	if codeChallengeMethod != "S256" || !s256CodeChallengePattern.MatchString(codeChallenge) {
+		return errors.New(string(CodeChallengeErrorInvalid))
+	}
+	return nil
+}
+
+// CheckCodeChallenge is checkCodeChallenge for the handlers that check the
+// challenge before the user is signed in
+func CheckCodeChallenge(codeChallenge string, codeChallengeMethod string) error {
+	return checkCodeChallenge(codeChallenge, codeChallengeMethod)
+}
+
 func passesS256CodeChallenge(codeChallenge string, codeVerifier string) bool {
 	hasher := sha256.New()
 	hasher.Write([]byte(codeVerifier))
@@ -16,9 +44,9 @@ func passesS256CodeChallenge(codeChallenge string, codeVerifier string) bool {
 }
 
 func passesCodeChallenge(codeChallenge string, codeChallengeMethod string, codeVerifier string) bool {
-	if codeChallengeMethod == "S256" {
-		return passesS256CodeChallenge(codeChallenge, codeVerifier)
+	if checkCodeChallenge(codeChallenge, codeChallengeMethod) != nil {
+		return false
 	}
 
-	return true
+	return passesS256CodeChallenge(codeChallenge, codeVerifier)
 }
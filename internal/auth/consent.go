@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type ConsentError string
+
+const (
+	ConsentErrorNotFound ConsentError = "consent not found"
+)
+
+func IsFirstPartyClient(client *models.Client) bool {
+	return client.IsFirstParty || client.IsRootClient
+}
+
+// HasConsent checks whether the user already agreed to share every one of
+// scopes with the client
+func HasConsent(db *gorm.DB, userId string, clientId string, scopes []string) (bool, error) {
+	var grant models.ConsentGrant
+	result := db.Limit(1).Find(&grant, "user_id = ? AND client_id = ?", userId, clientId)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(grant.Scopes, scope) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// GrantConsent adds scopes to what the user already shares with the client
+func GrantConsent(db *gorm.DB, userId string, clientId string, scopes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var grant models.ConsentGrant
+		result := tx.Limit(1).Find(&grant, "user_id = ? AND client_id = ?", userId, clientId)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			grant = models.ConsentGrant{
+				UserId:   userId,
+				ClientId: clientId,
+				Scopes:   pq.StringArray(scopes),
+			}
+			return tx.Create(&grant).Error
+		}
+
+		for _, scope := range scopes {
+			if !slices.Contains(grant.Scopes, scope) {
+				grant.Scopes = append(grant.Scopes, scope)
+			}
+		}
+		return tx.Save(&grant).Error
+	})
+}
+
+// ConsentScreen is what a consent page needs to show for a flow
+type ConsentScreen struct {
+	Client          models.Client
+	RequestedScopes []models.Scope
+	GrantedScopes   []string
+}
+
+func GetConsentScreen(db *gorm.DB, flowToken string) (*ConsentScreen, error) {
+	flow, err := GetAuthenticationFlow(db, flowToken, models.AuthenticationFlowStepConsent)
+	if err != nil {
+		return nil, err
+	}
+
+	var requested []models.Scope
+	if len(flow.Scopes) > 0 {
+		if err := db.Where("id IN ?", []string(flow.Scopes)).Find(&requested).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// keep the order the client asked for them in
+	slices.SortFunc(requested, func(a models.Scope, b models.Scope) int {
+		return slices.Index(flow.Scopes, a.ID) - slices.Index(flow.Scopes, b.ID)
+	})
+
+	var grant models.ConsentGrant
+	db.Limit(1).Find(&grant, "user_id = ? AND client_id = ?", flow.UserId, flow.ClientId)
+
+	return &ConsentScreen{
+		Client:          flow.Client,
+		RequestedScopes: requested,
+		GrantedScopes:   grant.Scopes,
+	}, nil
+}
+
+// AnswerConsent records the user's decision on a consent flow. approving
+// stores the grant and continues the sign in, denying ends it
+func AnswerConsent(db *gorm.DB, flowToken string, approved bool) (*AuthorizationResult, error) {
+	flow, err := GetAuthenticationFlow(db, flowToken, models.AuthenticationFlowStepConsent)
+	if err != nil {
+		return nil, err
+	}
+
+	if !approved {
+		db.Delete(flow)
+		return nil, errors.New(string(AuthenticationFlowErrorAccessDenied))
+	}
+
+	if err := GrantConsent(db, flow.UserId, flow.ClientId, flow.Scopes); err != nil {
+		return nil, err
+	}
+
+	return continueAuthenticationFlow(db, flow)
+}
+
+func ListConsents(db *gorm.DB, userId string) ([]models.ConsentGrant, error) {
+	var grants []models.ConsentGrant
+	result := db.Preload("Client").Where("user_id = ?", userId).Order("updated_at DESC").Find(&grants)
+	return grants, result.Error
+}
+
+// RevokeConsent removes the grant and everything the client was issued on the
+// strength of it: refresh tokens, unredeemed codes and pending flows
+func RevokeConsent(db *gorm.DB, userId string, clientId string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND client_id = ?", userId, clientId).Delete(&models.ConsentGrant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(string(ConsentErrorNotFound))
+		}
+
+		err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND client_id = ? AND revoked = ?", userId, clientId, false).
+			Update("revoked", true).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.RedeemAuthCode{}).
+			Where("user_id = ? AND client_id = ? AND redeemed = ? AND expires_at > ?", userId, clientId, false, time.Now()).
+			Update("revoked", true).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ? AND client_id = ?", userId, clientId).Delete(&models.AuthenticationFlow{}).Error
+	})
+}
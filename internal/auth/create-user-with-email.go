@@ -14,7 +14,6 @@ type CreateUserWithEmailError string
 const (
 	CreateUserWithEmailErrorMissingRequiredFields CreateUserWithEmailError = "Missing required fields"
 	CreateUserWithEmailErrorInvalidEmail          CreateUserWithEmailError = "Invalid email"
-	CreateUserWithEmailErrorWeakPassword          CreateUserWithEmailError = "Weak password"
 	CreateUserWithEmailErrorEmailTaken            CreateUserWithEmailError = "Email already taken"
 	CreateUserWithEmailErrorInvalidClient         CreateUserWithEmailError = "Client does not exist"
 	CreateUserWithEmailErrorInvalidClientProvider CreateUserWithEmailError = "Provider not enabled for client"
@@ -38,38 +37,45 @@ func getClientProvider(db *gorm.DB, clientId string, providerOptionId string) (*
 	return &clientProvider, nil
 }
 
+// deleted accounts keep their email until they are purged, so they can be
+// restored
 func isEmailTaken(db *gorm.DB, clientId string, email string) bool {
 	var existingUser models.Identity
-	result := db.First(&existingUser, "client_id = ? AND provider_sub = ? AND provider_option_id = ?", clientId, email, "email")
+	result := db.Unscoped().First(&existingUser, "client_id = ? AND provider_sub = ? AND provider_option_id = ?", clientId, email, "email")
 	return result.RowsAffected > 0
 }
 
-func CreateUserWithEmail(db *gorm.DB, clientId string, email string, password string, metadata *map[string]interface{}) (*models.User, *models.Identity, error) {
+// CreateUserWithEmail registers a user with an email and password. the
+// password rules the client only warns about are returned with the user
+func CreateUserWithEmail(db *gorm.DB, clientId string, email string, password string, metadata *map[string]interface{}) (*models.User, *models.Identity, []PasswordRuleFailure, error) {
 	email = strings.ToLower(strings.Trim(email, " "))
 
 	if email == "" || password == "" || clientId == "" {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorMissingRequiredFields)
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorMissingRequiredFields)
 	}
 
 	if valid := validators.IsValidEmail(email); !valid {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidEmail)
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidEmail)
 	}
 
-	if !validators.IsPasswordStrong(password) {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorWeakPassword)
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidClient)
 	}
 
-	if !doesClientExist(db, clientId) {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidClient)
+	clientProvider, ok := getEnabledClientProvider(db, clientId, "email")
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidClientProvider)
 	}
 
-	if isEmailTaken(db, clientId, email) {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorEmailTaken)
+	warnings, err := checkPasswordPolicy(db, &client, "", email, password)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	clientProvider, err := getClientProvider(db, clientId, "email")
-	if err != nil && !clientProvider.Enabled {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorInvalidClientProvider)
+	if isEmailTaken(db, clientId, email) {
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorEmailTaken)
 	}
 
 	user := models.User{
@@ -77,16 +83,16 @@ func CreateUserWithEmail(db *gorm.DB, clientId string, email string, password st
 		Email:    email,
 	}
 
-	result := db.Create(&user)
+	result = db.Create(&user)
 
 	if result.Error != nil {
-		return nil, nil, result.Error
+		return nil, nil, nil, result.Error
 	}
 
 	data := make(models.JsonDictionary)
 	hash, err := HashPassword(password)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorGeneric)
+		return nil, nil, nil, fmt.Errorf("%s", CreateUserWithEmailErrorGeneric)
 	}
 	data["password_hash"] = hash
 
@@ -101,8 +107,12 @@ func CreateUserWithEmail(db *gorm.DB, clientId string, email string, password st
 
 	result = db.Create(&identity)
 	if result.Error != nil {
-		return nil, nil, result.Error
+		return nil, nil, nil, result.Error
+	}
+	if err := recordPasswordHistory(db, identity.ID, hash); err != nil {
+		return nil, nil, nil, err
 	}
+	identity.Client = clientProvider.Client
 
-	return &user, &identity, nil
+	return &user, &identity, warnings, nil
 }
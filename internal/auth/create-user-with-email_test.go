@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"sentinel-auth-backend/internal/models"
+	"testing"
+)
+
+func TestCreateUserWithEmailRefusesDisabledProvider(t *testing.T) {
+	db := testDb(t)
+	db.Exec("UPDATE client_providers SET enabled = false WHERE id = 'app-email'")
+
+	_, _, _, err := CreateUserWithEmail(db, "app", "user@example.com", "a long enough password", nil)
+	if err == nil || err.Error() != string(CreateUserWithEmailErrorInvalidClientProvider) {
+		t.Errorf("err = %v, want %s", err, CreateUserWithEmailErrorInvalidClientProvider)
+	}
+
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 0 {
+		t.Errorf("%d users created", users)
+	}
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ChangePasswordError string
+
+const (
+	ChangePasswordErrorUnknownUser             ChangePasswordError = "user does not exist"
+	ChangePasswordErrorCurrentPasswordRequired ChangePasswordError = "current password is required"
+	ChangePasswordErrorWrongPassword           ChangePasswordError = "current password is incorrect"
+	ChangePasswordErrorEmailTaken              ChangePasswordError = "email belongs to another account"
+	ChangePasswordErrorProviderDisabled        ChangePasswordError = "email provider not enabled for client"
+	ChangePasswordErrorNoEmail                 ChangePasswordError = "user has no email to sign in with"
+)
+
+type ChangePasswordInput struct {
+	CurrentPassword string
+	NewPassword     string
+	// keep only the session the request was made from
+	SignOutOtherSessions bool
+	CurrentSessionId     string
+	Locale               string
+}
+
+func findUserEmailIdentity(db *gorm.DB, user *models.User) (*models.Identity, error) {
+	var identity models.Identity
+	result := db.Preload("Client").Limit(1).Find(&identity, "client_id = ? AND user_id = ? AND provider_option_id = ?", user.ClientId, user.ID, "email")
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &identity, nil
+}
+
+// addEmailIdentity lets users who signed up through another provider sign in
+// with their email and a password as well
+func addEmailIdentity(db *gorm.DB, user *models.User) (*models.Identity, error) {
+	if user.Email == "" {
+		return nil, errors.New(string(ChangePasswordErrorNoEmail))
+	}
+	if isEmailTaken(db, user.ClientId, user.Email) {
+		return nil, errors.New(string(ChangePasswordErrorEmailTaken))
+	}
+
+	clientProvider, err := getClientProvider(db, user.ClientId, "email")
+	if err != nil || !clientProvider.Enabled {
+		return nil, errors.New(string(ChangePasswordErrorProviderDisabled))
+	}
+
+	identity := models.Identity{
+		ClientId:         user.ClientId,
+		UserId:           user.ID,
+		ProviderSub:      user.Email,
+		ProviderOptionId: "email",
+		ClientProviderId: clientProvider.ID,
+		Data:             models.JsonDictionary{},
+	}
+	if err := db.Create(&identity).Error; err != nil {
+		return nil, err
+	}
+	identity.Client = clientProvider.Client
+
+	return &identity, nil
+}
+
+// ChangePassword sets a new password for a signed in user. users that already
+// have one must confirm the current password, users that signed up through
+// another provider get an email identity. the user is told by email either way.
+// password rules the client only warns about are returned
+func ChangePassword(db *gorm.DB, mailer mail.Mailer, userId string, input ChangePasswordInput) ([]PasswordRuleFailure, error) {
+	var user models.User
+	result := db.Limit(1).Find(&user, "id = ?", userId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New(string(ChangePasswordErrorUnknownUser))
+	}
+
+	identity, err := findUserEmailIdentity(db, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		if currentHash, ok := identity.Data["password_hash"].(string); ok {
+			if input.CurrentPassword == "" {
+				return nil, errors.New(string(ChangePasswordErrorCurrentPasswordRequired))
+			}
+			matches, _ := CompareHashAndPassword(currentHash, input.CurrentPassword)
+			if !matches {
+				return nil, errors.New(string(ChangePasswordErrorWrongPassword))
+			}
+		}
+	}
+
+	var client models.Client
+	if err := db.First(&client, "id = ?", user.ClientId).Error; err != nil {
+		return nil, err
+	}
+	identityId := ""
+	if identity != nil {
+		identityId = identity.ID
+	}
+	warnings, err := checkPasswordPolicy(db, &client, identityId, user.Email, input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := HashPassword(input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if identity == nil {
+			identity, err = addEmailIdentity(tx, &user)
+			if err != nil {
+				return err
+			}
+		}
+
+		if identity.Data == nil {
+			identity.Data = models.JsonDictionary{}
+		}
+		identity.Data["password_hash"] = hash
+		if err := tx.Model(identity).Update("data", identity.Data).Error; err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(tx, identity.ID, hash); err != nil {
+			return err
+		}
+
+		if input.SignOutOtherSessions {
+			return RevokeOtherUserSessions(tx, user.ID, input.CurrentSessionId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the password is already changed, a missing notification shouldn't undo that
+	message, err := mail.Render(&identity.Client, mail.TemplatePasswordChanged, input.Locale, user.Email, mail.Data{
+		"ChangedAt": time.Now().UTC().Format("2006-01-02 15:04 MST"),
+	})
+	if err == nil {
+		err = mailer.Send(message)
+	}
+	if err != nil {
+		log.Println("failed to send password changed email:", err)
+	}
+
+	return warnings, nil
+}
+
+// Credential is one way a user can sign in
+type Credential struct {
+	IdentityId    string
+	Provider      string
+	Identifier    string
+	HasPassword   bool
+	EmailVerified bool
+	CreatedAt     time.Time
+}
+
+func ListCredentials(db *gorm.DB, userId string) ([]Credential, error) {
+	var identities []models.Identity
+	result := db.Where("user_id = ?", userId).Order("created_at ASC").Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	credentials := []Credential{}
+	for _, identity := range identities {
+		_, hasPassword := identity.Data["password_hash"].(string)
+		credentials = append(credentials, Credential{
+			IdentityId:    identity.ID,
+			Provider:      identity.ProviderOptionId,
+			Identifier:    identity.ProviderSub,
+			HasPassword:   hasPassword,
+			EmailVerified: identity.EmailVerified,
+			CreatedAt:     identity.CreatedAt,
+		})
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// tests run against sqlite. the models default their ids to postgres'
+// gen_random_uuid(), which is registered as a function here
+func init() {
+	sql.Register("sqlite3_auth_test", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("gen_random_uuid", func() string {
+				b := make([]byte, 16)
+				rand.Read(b)
+				return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+			}, false)
+		},
+	})
+}
+
+// the columns of the tables the tests touch, postgres types left out
+const testSchema = `
+CREATE TABLE clients (id text PRIMARY KEY DEFAULT (gen_random_uuid()), name text, secret text, require_email_verification boolean DEFAULT false, lockout_threshold integer DEFAULT 10, ip_lockout_threshold integer DEFAULT 100, lockout_duration_seconds integer DEFAULT 900, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE provider_options (id text PRIMARY KEY, name text, mappings blob, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE client_providers (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, provider_option_id text, data blob, enabled boolean, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE users (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, email text UNIQUE, role text DEFAULT 'user', created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE identities (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, provider_sub text, provider_option_id text, client_provider_id text, user_id text, data blob, email_verified boolean DEFAULT false, phone text, phone_verified boolean DEFAULT false, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE password_policies (client_id text PRIMARY KEY, min_length integer NOT NULL, max_length integer NOT NULL, require_uppercase boolean DEFAULT false, require_lowercase boolean DEFAULT false, require_digit boolean DEFAULT false, require_symbol boolean DEFAULT false, min_strength integer NOT NULL, banned_words text, history_size integer NOT NULL, breach_mode text DEFAULT 'block', created_at datetime, updated_at datetime);
+CREATE TABLE password_histories (id text PRIMARY KEY DEFAULT (gen_random_uuid()), identity_id text NOT NULL, password_hash text NOT NULL, created_at datetime);
+CREATE TABLE sessions (id text PRIMARY KEY DEFAULT (gen_random_uuid()), user_id text NOT NULL, client_id text NOT NULL, identity_id text NOT NULL, auth_time datetime, aal integer DEFAULT 1, amr blob, revoked_at datetime, created_at datetime, updated_at datetime);
+CREATE TABLE refresh_tokens (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, user_id text, session_id text, token text NOT NULL, revoked boolean DEFAULT false, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE redeem_auth_codes (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, user_id text NOT NULL, session_id text, redeemed boolean DEFAULT false, revoked boolean DEFAULT false, expires_at datetime, created_at datetime, updated_at datetime, deleted_at datetime);
+CREATE TABLE sign_in_throttles (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, kind text NOT NULL, subject text NOT NULL, failures integer DEFAULT 0, last_failure_at datetime, locked_until datetime, lockouts integer DEFAULT 0, unlock_token_hash text, created_at datetime, updated_at datetime, UNIQUE (client_id, kind, subject));
+CREATE TABLE legacy_authenticators (client_id text PRIMARY KEY, url text NOT NULL, secret text, timeout_ms integer DEFAULT 5000, enabled boolean NOT NULL, migrated_users integer DEFAULT 0, last_migrated_at datetime, created_at datetime, updated_at datetime);
+CREATE TABLE one_time_codes (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, channel text NOT NULL, recipient text NOT NULL, salt text NOT NULL, code_hash text NOT NULL, attempts integer DEFAULT 0, code_challenge text, code_challenge_method text, scopes text, resources text, acr_values text, state text, expires_at datetime, used_at datetime, created_at datetime);
+CREATE TABLE client_assertion_jtis (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, jti text NOT NULL, expires_at datetime, created_at datetime, UNIQUE (client_id, jti));
+CREATE TABLE authentication_flows (id text PRIMARY KEY DEFAULT (gen_random_uuid()), client_id text NOT NULL, user_id text NOT NULL, created_at datetime, updated_at datetime);
+`
+
+// testDb opens an empty in memory database with the email provider enabled
+// for client "app"
+func testDb(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite3_auth_test", DSN: ":memory:"}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection would get its own in memory database
+	sqlDb, _ := db.DB()
+	sqlDb.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDb.Close() })
+
+	for _, statement := range []string{
+		testSchema,
+		"INSERT INTO clients (id, name, secret) VALUES ('app', 'App', 'secret')",
+		"INSERT INTO provider_options (id, name) VALUES ('email', 'Email')",
+		"INSERT INTO client_providers (id, client_id, provider_option_id, enabled) VALUES ('app-email', 'app', 'email', true)",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type EmailOtpError string
+
+const (
+	EmailOtpErrorInvalidEmail     EmailOtpError = "invalid email"
+	EmailOtpErrorProviderDisabled EmailOtpError = "email codes not enabled for client"
+)
+
+const emailOtpProviderOptionId = "email_otp"
+
+type EmailOtpInput struct {
+	ClientId string
+	Email    string
+	Locale   string
+	// the authorization request the code completes
+	Authorization AuthorizationParams
+}
+
+// SendEmailOtp emails a six digit sign in code. like magic links, the auth
+// code it results in can only be redeemed by the device asking here
+func SendEmailOtp(db *gorm.DB, mailer mail.Mailer, input EmailOtpInput) error {
+	email := strings.ToLower(strings.Trim(input.Email, " "))
+	if !validators.IsValidEmail(email) {
+		return errors.New(string(EmailOtpErrorInvalidEmail))
+	}
+	if err := checkCodeChallenge(input.Authorization.CodeChallenge, input.Authorization.CodeChallengeMethod); err != nil {
+		return err
+	}
+
+	clientProvider, ok := getEnabledClientProvider(db, input.ClientId, emailOtpProviderOptionId)
+	if !ok {
+		return errors.New(string(EmailOtpErrorProviderDisabled))
+	}
+
+	code, err := issueOneTimeCode(db, models.OneTimeCodeChannelEmail, input.ClientId, email, input.Authorization)
+	if err != nil {
+		return err
+	}
+
+	message, err := mail.Render(&clientProvider.Client, mail.TemplateEmailOtp, input.Locale, email, mail.Data{
+		"Code":             code,
+		"ExpiresInMinutes": oneTimeCodeDurationSeconds / 60,
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(message)
+}
+
+// VerifyEmailOtp checks a code against the latest one sent to the email and
+// continues the authorization request it was sent for
+func VerifyEmailOtp(db *gorm.DB, clientId string, email string, code string) (*AuthorizationResult, error) {
+	email = strings.ToLower(strings.Trim(email, " "))
+
+	record, err := redeemOneTimeCode(db, models.OneTimeCodeChannelEmail, clientId, email, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// the client may have turned codes off since the email was sent
+	clientProvider, ok := getEnabledClientProvider(db, clientId, emailOtpProviderOptionId)
+	if !ok {
+		return nil, errors.New(string(EmailOtpErrorProviderDisabled))
+	}
+
+	identity, err := findOrCreateVerifiedEmailIdentity(db, clientProvider, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompleteAuthorization(db, identity, oneTimeCodeAuthorizationParams(record))
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationError string
+
+const (
+	EmailVerificationErrorInvalidToken   EmailVerificationError = "invalid or expired verification token"
+	EmailVerificationErrorThrottled      EmailVerificationError = "too many verification emails"
+	EmailVerificationErrorClientNotFound EmailVerificationError = "client not found"
+)
+
+const emailVerificationDurationSeconds = 60 * 60 * 24
+
+// a new verification email can be sent once a minute and at most five times
+// an hour
+const (
+	emailVerificationResendInterval = time.Minute
+	emailVerificationHourlyLimit    = 5
+)
+
+func isEmailVerificationThrottled(db *gorm.DB, identityId string) (bool, error) {
+	now := time.Now()
+
+	var lastHour []models.EmailVerificationToken
+	result := db.Where("identity_id = ? AND created_at > ?", identityId, now.Add(-time.Hour)).Order("created_at DESC").Find(&lastHour)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if len(lastHour) >= emailVerificationHourlyLimit {
+		return true, nil
+	}
+	if len(lastHour) > 0 && lastHour[0].CreatedAt.After(now.Add(-emailVerificationResendInterval)) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// SendEmailVerification mails a fresh verification link for an email
+// identity. verifyUrl is the page the link points to, the token is added as
+// a query parameter. identity.Client has to be loaded for the branding
+func SendEmailVerification(db *gorm.DB, mailer mail.Mailer, identity *models.Identity, verifyUrl string, locale string) error {
+	throttled, err := isEmailVerificationThrottled(db, identity.ID)
+	if err != nil {
+		return err
+	}
+	if throttled {
+		return errors.New(string(EmailVerificationErrorThrottled))
+	}
+
+	token := crypto.GenerateSecureSecret()
+	record := models.EmailVerificationToken{
+		IdentityId: identity.ID,
+		TokenHash:  crypto.HashSecret(token),
+		ExpiresAt:  time.Now().Add(emailVerificationDurationSeconds * time.Second),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return err
+	}
+
+	link := verifyUrl + "?" + url.Values{"token": {token}}.Encode()
+
+	message, err := mail.Render(&identity.Client, mail.TemplateVerifyEmail, locale, identity.ProviderSub, mail.Data{
+		"Link":           link,
+		"ExpiresInHours": emailVerificationDurationSeconds / 3600,
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(message)
+}
+
+// ResendEmailVerification sends another link to an unverified email
+// identity. unknown and already verified emails are ignored so the endpoint
+// can't be used to find out which emails are registered
+func ResendEmailVerification(db *gorm.DB, mailer mail.Mailer, clientId string, email string, verifyUrl string, locale string) error {
+	email = strings.ToLower(strings.Trim(email, " "))
+
+	identity, err := findIdentity(db, clientId, "email", email)
+	if err != nil || identity.EmailVerified {
+		return nil
+	}
+
+	// a throttled resend answers like an unknown email, otherwise the 429
+	// would only ever show up for registered ones
+	err = SendEmailVerification(db, mailer, identity, verifyUrl, locale)
+	if err != nil && err.Error() == string(EmailVerificationErrorThrottled) {
+		return nil
+	}
+	return err
+}
+
+// VerifyEmail consumes a verification token and marks its identity verified
+func VerifyEmail(db *gorm.DB, token string) (*models.Identity, error) {
+	var identity models.Identity
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// claim the token in one statement so it can only be used once
+		var record models.EmailVerificationToken
+		result := tx.Model(&record).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", crypto.HashSecret(token), now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(string(EmailVerificationErrorInvalidToken))
+		}
+
+		if err := tx.First(&record, "token_hash = ?", crypto.HashSecret(token)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&identity, "id = ?", record.IdentityId).Error; err != nil {
+			return errors.New(string(EmailVerificationErrorInvalidToken))
+		}
+
+		identity.EmailVerified = true
+		if err := tx.Model(&identity).Update("email_verified", true).Error; err != nil {
+			return err
+		}
+
+		// older links for the same identity are no longer needed
+		return tx.Model(&models.EmailVerificationToken{}).
+			Where("identity_id = ? AND used_at IS NULL", identity.ID).
+			Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// SetClientRequireEmailVerification sets whether password sign ins of the
+// client wait for the email to be verified
+func SetClientRequireEmailVerification(db *gorm.DB, clientId string, required bool) (*models.Client, error) {
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(EmailVerificationErrorClientNotFound))
+	}
+
+	// Update instead of Updates so false is written too
+	if err := db.Model(&client).Update("require_email_verification", required).Error; err != nil {
+		return nil, err
+	}
+
+	client.RequireEmailVerification = required
+	return &client, nil
+}
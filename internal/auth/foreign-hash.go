@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// algorithms of password hashes imported from other systems. they are only
+// ever checked, the first sign in replaces them with argon2id
+const (
+	HashAlgorithmArgon2id       = "argon2id"
+	HashAlgorithmBcrypt         = "bcrypt"
+	HashAlgorithmFirebaseScrypt = "firebase_scrypt"
+	HashAlgorithmPbkdf2Sha256   = "pbkdf2_sha256"
+	HashAlgorithmSha1           = "sha1"
+	HashAlgorithmSha256         = "sha256"
+	HashAlgorithmSha512         = "sha512"
+)
+
+// ForeignHashConfig describes how another system hashed its passwords
+type ForeignHashConfig struct {
+	// used for users that don't name their own algorithm
+	Algorithm string
+
+	// the project's hash parameters from the firebase console, base64 like
+	// firebase shows them
+	FirebaseSignerKey     string
+	FirebaseSaltSeparator string
+	FirebaseRounds        int
+	FirebaseMemCost       int
+
+	// for salted sha: whether the salt goes before (prefix) or after
+	// (suffix, the default) the password, and if it is raw text (the
+	// default), base64 or hex
+	SaltPosition string
+	SaltEncoding string
+}
+
+// ForeignHash is a password hash as another system exported it
+type ForeignHash struct {
+	Algorithm string
+	Hash      string
+	// for firebase and salted sha, bcrypt, argon2id and django's pbkdf2
+	// strings carry their salt
+	Salt string
+}
+
+// bounds on the cost of imported hashes, so a bad import can't make sign ins
+// tie up the server
+const (
+	foreignMaxScryptMemCost = 20
+	foreignMaxScryptRounds  = 32
+	foreignMaxPbkdf2Rounds  = 10_000_000
+)
+
+func decodeBase64(text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(text); err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("invalid base64")
+}
+
+// decodeDigest reads a digest of size bytes written as hex or base64
+func decodeDigest(text string, size int) ([]byte, error) {
+	if len(text) == size*2 {
+		if data, err := hex.DecodeString(text); err == nil {
+			return data, nil
+		}
+	}
+	data, err := decodeBase64(text)
+	if err != nil || len(data) != size {
+		return nil, errors.New("digest has the wrong length")
+	}
+	return data, nil
+}
+
+func shaForAlgorithm(algorithm string) (func() hash.Hash, bool) {
+	switch algorithm {
+	case HashAlgorithmSha1:
+		return sha1.New, true
+	case HashAlgorithmSha256:
+		return sha256.New, true
+	case HashAlgorithmSha512:
+		return sha512.New, true
+	}
+	return nil, false
+}
+
+// NormalizeForeignHash turns an exported hash into the string stored as the
+// identity's password hash. besides bcrypt, argon2id and django's pbkdf2
+// strings, which are kept as they are, that is
+// $firebase-scrypt$ln=<mem cost>,r=<rounds>$<salt>$<separator>$<signer key>$<hash>
+// or $salted-<sha1|sha256|sha512>$<prefix|suffix>$<salt>$<digest>, all base64
+func NormalizeForeignHash(foreign ForeignHash, config ForeignHashConfig) (string, error) {
+	algorithm := foreign.Algorithm
+	if algorithm == "" {
+		algorithm = config.Algorithm
+	}
+	value := strings.TrimSpace(foreign.Hash)
+
+	switch algorithm {
+	case HashAlgorithmBcrypt:
+		if !isBcryptHash(value) {
+			return "", errors.New("not a bcrypt hash")
+		}
+		return value, nil
+	case HashAlgorithmArgon2id:
+		if _, err := parseArgon2idHash(value); err != nil {
+			return "", err
+		}
+		return value, nil
+	case HashAlgorithmPbkdf2Sha256:
+		if _, err := parsePbkdf2Hash(value); err != nil {
+			return "", err
+		}
+		return value, nil
+	case HashAlgorithmFirebaseScrypt:
+		signerKey, err := decodeBase64(config.FirebaseSignerKey)
+		if err != nil || len(signerKey) == 0 {
+			return "", errors.New("firebase signer key is missing or invalid")
+		}
+		separator, err := decodeBase64(config.FirebaseSaltSeparator)
+		if err != nil {
+			return "", errors.New("firebase salt separator is invalid")
+		}
+		salt, err := decodeBase64(foreign.Salt)
+		if err != nil {
+			return "", errors.New("firebase salt is invalid")
+		}
+		digest, err := decodeBase64(value)
+		if err != nil || len(digest) == 0 {
+			return "", errors.New("firebase hash is invalid")
+		}
+		normalized := fmt.Sprintf("$firebase-scrypt$ln=%d,r=%d$%s$%s$%s$%s",
+			config.FirebaseMemCost, config.FirebaseRounds,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(separator),
+			base64.RawStdEncoding.EncodeToString(signerKey), base64.RawStdEncoding.EncodeToString(digest),
+		)
+		if _, err := parseFirebaseScryptHash(normalized); err != nil {
+			return "", err
+		}
+		return normalized, nil
+	case HashAlgorithmSha1, HashAlgorithmSha256, HashAlgorithmSha512:
+		newHash, _ := shaForAlgorithm(algorithm)
+
+		salt := []byte(foreign.Salt)
+		position := config.SaltPosition
+		// django's old sha1$salt$digest strings carry their salt, which
+		// django put in front of the password
+		if parts := strings.Split(value, "$"); len(parts) == 3 && parts[0] == algorithm {
+			salt, value, position = []byte(parts[1]), parts[2], "prefix"
+		} else {
+			var err error
+			switch config.SaltEncoding {
+			case "", "raw":
+			case "base64":
+				salt, err = decodeBase64(foreign.Salt)
+			case "hex":
+				salt, err = hex.DecodeString(foreign.Salt)
+			default:
+				err = errors.New("unknown salt encoding " + config.SaltEncoding)
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if position == "" {
+			position = "suffix"
+		}
+		if position != "prefix" && position != "suffix" {
+			return "", errors.New("salt position must be prefix or suffix")
+		}
+
+		digest, err := decodeDigest(value, newHash().Size())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("$salted-%s$%s$%s$%s", algorithm, position,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(digest),
+		), nil
+	case "":
+		return "", errors.New("hash algorithm is missing")
+	default:
+		return "", errors.New("unsupported hash algorithm " + algorithm)
+	}
+}
+
+func isForeignHash(hash string) bool {
+	return strings.HasPrefix(hash, "$firebase-scrypt$") ||
+		strings.HasPrefix(hash, "$salted-") ||
+		strings.HasPrefix(hash, "pbkdf2_sha256$")
+}
+
+func compareForeignHash(hash string, password string) (bool, error) {
+	var expected, actual []byte
+
+	switch {
+	case strings.HasPrefix(hash, "$firebase-scrypt$"):
+		parsed, err := parseFirebaseScryptHash(hash)
+		if err != nil {
+			return false, err
+		}
+		// firebase's scrypt variant encrypts the project's signer key with
+		// a key derived from the password
+		key, err := scrypt.Key([]byte(password), append(parsed.salt, parsed.separator...), 1<<parsed.memCost, parsed.rounds, 1, 32)
+		if err != nil {
+			return false, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return false, err
+		}
+		actual = make([]byte, len(parsed.signerKey))
+		cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(actual, parsed.signerKey)
+		expected = parsed.digest
+	case strings.HasPrefix(hash, "$salted-"):
+		parts := strings.Split(hash, "$")
+		if len(parts) != 5 {
+			return false, errors.New("invalid salted hash")
+		}
+		newHash, ok := shaForAlgorithm(strings.TrimPrefix(parts[1], "salted-"))
+		if !ok {
+			return false, errors.New("invalid salted hash")
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+		if err != nil {
+			return false, err
+		}
+		expected, err = base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return false, err
+		}
+
+		digest := newHash()
+		if parts[2] == "prefix" {
+			digest.Write(salt)
+			digest.Write([]byte(password))
+		} else {
+			digest.Write([]byte(password))
+			digest.Write(salt)
+		}
+		actual = digest.Sum(nil)
+	case strings.HasPrefix(hash, "pbkdf2_sha256$"):
+		parsed, err := parsePbkdf2Hash(hash)
+		if err != nil {
+			return false, err
+		}
+		actual, err = pbkdf2.Key(sha256.New, password, []byte(parsed.salt), parsed.rounds, len(parsed.digest))
+		if err != nil {
+			return false, err
+		}
+		expected = parsed.digest
+	default:
+		return false, errors.New("unknown password hash format")
+	}
+
+	if len(expected) == 0 || subtle.ConstantTimeCompare(expected, actual) != 1 {
+		return false, errors.New("password does not match")
+	}
+	return true, nil
+}
+
+type firebaseScryptHash struct {
+	memCost   int
+	rounds    int
+	salt      []byte
+	separator []byte
+	signerKey []byte
+	digest    []byte
+}
+
+func parseFirebaseScryptHash(hash string) (*firebaseScryptHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 7 || parts[1] != "firebase-scrypt" {
+		return nil, errors.New("invalid firebase scrypt hash")
+	}
+
+	var parsed firebaseScryptHash
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d", &parsed.memCost, &parsed.rounds); err != nil {
+		return nil, errors.New("invalid firebase scrypt parameters")
+	}
+	if parsed.memCost < 1 || parsed.memCost > foreignMaxScryptMemCost || parsed.rounds < 1 || parsed.rounds > foreignMaxScryptRounds {
+		return nil, errors.New("firebase scrypt parameters out of range")
+	}
+
+	fields := []*[]byte{&parsed.salt, &parsed.separator, &parsed.signerKey, &parsed.digest}
+	for i, field := range fields {
+		data, err := base64.RawStdEncoding.DecodeString(parts[3+i])
+		if err != nil {
+			return nil, errors.New("invalid firebase scrypt hash")
+		}
+		*field = data
+	}
+	if len(parsed.signerKey) == 0 || len(parsed.digest) == 0 {
+		return nil, errors.New("invalid firebase scrypt hash")
+	}
+	return &parsed, nil
+}
+
+type pbkdf2Hash struct {
+	rounds int
+	salt   string
+	digest []byte
+}
+
+// parsePbkdf2Hash reads django's pbkdf2_sha256$<rounds>$<salt>$<base64 digest>
+func parsePbkdf2Hash(hash string) (*pbkdf2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2_sha256" {
+		return nil, errors.New("invalid pbkdf2_sha256 hash")
+	}
+
+	rounds, err := strconv.Atoi(parts[1])
+	if err != nil || rounds < 1 || rounds > foreignMaxPbkdf2Rounds {
+		return nil, errors.New("pbkdf2_sha256 rounds out of range")
+	}
+	digest, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(digest) == 0 {
+		return nil, errors.New("invalid pbkdf2_sha256 hash")
+	}
+	return &pbkdf2Hash{rounds: rounds, salt: parts[2], digest: digest}, nil
+}
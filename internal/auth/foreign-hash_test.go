@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// the sample from github.com/firebase/scrypt, firebase's own reference
+var firebaseSampleConfig = ForeignHashConfig{
+	Algorithm:             HashAlgorithmFirebaseScrypt,
+	FirebaseSignerKey:     "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==",
+	FirebaseSaltSeparator: "Bw==",
+	FirebaseRounds:        8,
+	FirebaseMemCost:       14,
+}
+
+var firebaseSampleHash = ForeignHash{
+	Hash: "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ==",
+	Salt: "42xEC+ixf3L2lw==",
+}
+
+func TestFirebaseScryptSample(t *testing.T) {
+	hash, err := NormalizeForeignHash(firebaseSampleHash, firebaseSampleConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$firebase-scrypt$ln=14,r=8$") {
+		t.Errorf("normalized to %s", hash)
+	}
+
+	if matches, err := CompareHashAndPassword(hash, "user1password"); !matches {
+		t.Errorf("sample password refused: %v", err)
+	}
+	if matches, _ := CompareHashAndPassword(hash, "user2password"); matches {
+		t.Error("wrong password accepted")
+	}
+	if !PasswordNeedsRehash(hash) {
+		t.Error("firebase hash isn't upgraded")
+	}
+
+	// the project's parameters are part of the hash
+	other := firebaseSampleConfig
+	other.FirebaseSaltSeparator = "CA=="
+	otherHash, _ := NormalizeForeignHash(firebaseSampleHash, other)
+	if matches, _ := CompareHashAndPassword(otherHash, "user1password"); matches {
+		t.Error("hash with another salt separator accepted the password")
+	}
+}
+
+func TestNormalizeFirebaseScryptRejectsBadParameters(t *testing.T) {
+	tests := map[string]func(*ForeignHashConfig){
+		"no signer key":    func(c *ForeignHashConfig) { c.FirebaseSignerKey = "" },
+		"signer key":       func(c *ForeignHashConfig) { c.FirebaseSignerKey = "not base64!" },
+		"memory cost 0":    func(c *ForeignHashConfig) { c.FirebaseMemCost = 0 },
+		"memory cost over": func(c *ForeignHashConfig) { c.FirebaseMemCost = foreignMaxScryptMemCost + 1 },
+		"rounds over":      func(c *ForeignHashConfig) { c.FirebaseRounds = foreignMaxScryptRounds + 1 },
+		"salt separator":   func(c *ForeignHashConfig) { c.FirebaseSaltSeparator = "!!" },
+	}
+	for name, change := range tests {
+		config := firebaseSampleConfig
+		change(&config)
+		if _, err := NormalizeForeignHash(firebaseSampleHash, config); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+
+	if _, err := NormalizeForeignHash(ForeignHash{Hash: "", Salt: firebaseSampleHash.Salt}, firebaseSampleConfig); err == nil {
+		t.Error("empty hash was accepted")
+	}
+}
+
+func TestDjangoPbkdf2Sha256(t *testing.T) {
+	tests := []struct {
+		hash     string
+		password string
+	}{
+		// django's own test of its hasher, from 1.8 and 1.9
+		{"pbkdf2_sha256$20000$seasalt$oBSd886ysm3AqYun62DOdin8YcfbU1z9cksZSuLP9r0=", "lètmein"},
+		{"pbkdf2_sha256$24000$seasalt$V9DfCAVoweeLwxC/L2mb+7swhzF0XYdyQMqmusZqiTc=", "lètmein"},
+		// PBKDF2-HMAC-SHA256 of "password" and "salt" with 1 and 2 rounds
+		{"pbkdf2_sha256$1$salt$" + base64.StdEncoding.EncodeToString(mustHex("120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b")), "password"},
+		{"pbkdf2_sha256$2$salt$" + base64.StdEncoding.EncodeToString(mustHex("ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43")), "password"},
+	}
+
+	for _, test := range tests {
+		hash, err := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmPbkdf2Sha256, Hash: test.hash}, ForeignHashConfig{})
+		if err != nil {
+			t.Errorf("%s: %v", test.hash, err)
+			continue
+		}
+		if hash != test.hash {
+			t.Errorf("django hash was changed to %s", hash)
+		}
+		if matches, err := CompareHashAndPassword(hash, test.password); !matches {
+			t.Errorf("%s: password refused: %v", test.hash, err)
+		}
+		if matches, _ := CompareHashAndPassword(hash, test.password+"!"); matches {
+			t.Errorf("%s: wrong password accepted", test.hash)
+		}
+	}
+
+	for _, invalid := range []string{
+		"pbkdf2_sha1$20000$seasalt$oBSd886ysm3AqYun62DOdin8YcfbU1z9cksZSuLP9r0=",
+		"pbkdf2_sha256$0$seasalt$oBSd886ysm3AqYun62DOdin8YcfbU1z9cksZSuLP9r0=",
+		"pbkdf2_sha256$10000001$seasalt$oBSd886ysm3AqYun62DOdin8YcfbU1z9cksZSuLP9r0=",
+		"pbkdf2_sha256$20000$seasalt$",
+		"pbkdf2_sha256$20000$seasalt",
+	} {
+		if _, err := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmPbkdf2Sha256, Hash: invalid}, ForeignHashConfig{}); err == nil {
+			t.Errorf("%s was accepted", invalid)
+		}
+	}
+}
+
+func mustHex(text string) []byte {
+	data, err := hex.DecodeString(text)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestDjangoSaltedSha1(t *testing.T) {
+	// django's own test of its sha1 hasher
+	hash, err := NormalizeForeignHash(ForeignHash{
+		Algorithm: HashAlgorithmSha1,
+		Hash:      "sha1$seasalt$cff36ea83f5706ce9aa7454e63e431fc726b2dc8",
+	}, ForeignHashConfig{SaltPosition: "suffix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// django puts the salt first whatever the config says
+	if !strings.HasPrefix(hash, "$salted-sha1$prefix$") {
+		t.Errorf("normalized to %s", hash)
+	}
+	if matches, err := CompareHashAndPassword(hash, "lètmein"); !matches {
+		t.Errorf("password refused: %v", err)
+	}
+	if matches, _ := CompareHashAndPassword(hash, "letmein"); matches {
+		t.Error("wrong password accepted")
+	}
+}
+
+// the "abc" examples of FIPS 180, split into salt and password both ways
+func TestSaltedSha(t *testing.T) {
+	digests := map[string]string{
+		HashAlgorithmSha1:   "a9993e364706816aba3e25717850c26c9cd0d89d",
+		HashAlgorithmSha256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		HashAlgorithmSha512: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+	}
+
+	tests := []struct {
+		name     string
+		config   ForeignHashConfig
+		salt     string
+		password string
+	}{
+		{"prefix", ForeignHashConfig{SaltPosition: "prefix"}, "a", "bc"},
+		{"suffix", ForeignHashConfig{SaltPosition: "suffix"}, "c", "ab"},
+		{"default suffix", ForeignHashConfig{}, "bc", "a"},
+		{"hex salt", ForeignHashConfig{SaltPosition: "prefix", SaltEncoding: "hex"}, "6162", "c"},
+		{"base64 salt", ForeignHashConfig{SaltEncoding: "base64"}, "Yw==", "ab"},
+	}
+
+	for algorithm, digest := range digests {
+		raw := mustHex(digest)
+		for _, encoded := range []string{digest, strings.ToUpper(digest), base64.StdEncoding.EncodeToString(raw), base64.RawURLEncoding.EncodeToString(raw)} {
+			for _, test := range tests {
+				hash, err := NormalizeForeignHash(ForeignHash{Algorithm: algorithm, Hash: encoded, Salt: test.salt}, test.config)
+				if err != nil {
+					t.Errorf("%s %s: %v", algorithm, test.name, err)
+					continue
+				}
+				if matches, err := CompareHashAndPassword(hash, test.password); !matches {
+					t.Errorf("%s %s (%s): password refused: %v", algorithm, test.name, encoded, err)
+				}
+				if matches, _ := CompareHashAndPassword(hash, test.salt+test.password); matches {
+					t.Errorf("%s %s: wrong password accepted", algorithm, test.name)
+				}
+			}
+		}
+	}
+
+	// the salt goes on the wrong side
+	hash, _ := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmSha1, Hash: digests[HashAlgorithmSha1], Salt: "a"}, ForeignHashConfig{SaltPosition: "suffix"})
+	if matches, _ := CompareHashAndPassword(hash, "bc"); matches {
+		t.Error("salt on the wrong side accepted the password")
+	}
+}
+
+func TestNormalizeForeignHashRejectsInvalid(t *testing.T) {
+	tests := map[string]ForeignHash{
+		"no algorithm":       {Hash: "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		"unknown algorithm":  {Algorithm: "md5", Hash: "900150983cd24fb0d6963f7d28e17f72"},
+		"short sha1":         {Algorithm: HashAlgorithmSha1, Hash: "a9993e364706816aba3e25717850c26c9cd0d8"},
+		"sha256 length sha1": {Algorithm: HashAlgorithmSha1, Hash: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		"bcrypt":             {Algorithm: HashAlgorithmBcrypt, Hash: "$2c$10$abc"},
+		"argon2id":           {Algorithm: HashAlgorithmArgon2id, Hash: "$argon2id$v=19$m=1,t=1,p=1$$"},
+	}
+	for name, foreign := range tests {
+		if _, err := NormalizeForeignHash(foreign, ForeignHashConfig{}); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+
+	if _, err := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmSha1, Hash: "a9993e364706816aba3e25717850c26c9cd0d89d"}, ForeignHashConfig{SaltPosition: "middle"}); err == nil {
+		t.Error("unknown salt position was accepted")
+	}
+	if _, err := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmSha1, Hash: "a9993e364706816aba3e25717850c26c9cd0d89d", Salt: "zz"}, ForeignHashConfig{SaltEncoding: "hex"}); err == nil {
+		t.Error("salt that isn't hex was accepted")
+	}
+	if _, err := NormalizeForeignHash(ForeignHash{Algorithm: HashAlgorithmSha1, Hash: "a9993e364706816aba3e25717850c26c9cd0d89d"}, ForeignHashConfig{SaltEncoding: "rot13"}); err == nil {
+		t.Error("unknown salt encoding was accepted")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sentinel-auth-backend/internal/models"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -13,7 +14,7 @@ type GenerateAuthCodeResponse struct {
 	ExpiresIn int
 }
 
-func GenerateAuthCode(db *gorm.DB, identity *models.Identity, codeChallenge string, codeChallengeMethod string) (*GenerateAuthCodeResponse, error) {
+func GenerateAuthCode(db *gorm.DB, identity *models.Identity, sessionId string, codeChallenge string, codeChallengeMethod string, scopes []string, resources []string) (*GenerateAuthCodeResponse, error) {
 	code := crypto.GenerateSecureSecret()
 	expiresIn := 600 // in seconds (10 minutes)
 
@@ -23,12 +24,15 @@ func GenerateAuthCode(db *gorm.DB, identity *models.Identity, codeChallenge stri
 		ClientId:            identity.ClientId,
 		IdentityId:          identity.ID,
 		UserId:              identity.UserId,
+		SessionId:           sessionId,
 		Code:                code,
 		Redeemed:            false,
 		Revoked:             false,
 		ExpiresAt:           expiresAt,
 		CodeChallenge:       codeChallenge,
 		CodeChallengeMethod: codeChallengeMethod,
+		Scopes:              pq.StringArray(scopes),
+		Resources:           pq.StringArray(resources),
 	}
 
 	result := db.Create(&redeemAuthCode)
@@ -1,13 +1,191 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sentinel-auth-backend/internal/config"
+	"strconv"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHashParams tune argon2id. raising them makes every hash slower to
+// crack and to check, hashes made with lower ones are upgraded at sign in
+type PasswordHashParams struct {
+	// memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// the OWASP recommendation for argon2id
+var defaultPasswordHashParams = PasswordHashParams{Memory: 19456, Iterations: 2, Parallelism: 1}
+
+const (
+	passwordSaltLength = 16
+	passwordKeyLength  = 32
+	// longest salt and key accepted from stored hashes
+	maxPasswordSaltLength = 64
+	maxPasswordKeyLength  = 64
+	// hashes from before the memory setting was lowered keep working up to
+	// this much, in KiB
+	maxStoredPasswordHashMemory = 64 * 1024
+)
+
+// params new hashes are made with
+var passwordHashParams = defaultPasswordHashParams
+
+func SetPasswordHashParams(params PasswordHashParams) {
+	passwordHashParams = params
+}
+
+// maxPasswordHashMemory is the most memory a stored hash may ask for, so a
+// planted or corrupted hash can't take more than a sign in normally does
+func maxPasswordHashMemory() uint32 {
+	return max(passwordHashParams.Memory, maxStoredPasswordHashMemory)
+}
+
+// PasswordHashParamsFromConfig reads the argon2id parameters from the
+// environment, keeping the defaults for the ones that aren't set
+func PasswordHashParamsFromConfig(appConfig config.Config) (PasswordHashParams, error) {
+	params := defaultPasswordHashParams
+
+	parse := func(name string, value string, max uint64) (uint64, error) {
+		number, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || number < 1 || number > max {
+			return 0, fmt.Errorf("Env variable %s must be a number between 1 and %d", name, max)
+		}
+		return number, nil
+	}
+
+	if appConfig.PASSWORD_HASH_MEMORY_KIB != "" {
+		memory, err := parse("PASSWORD_HASH_MEMORY_KIB", appConfig.PASSWORD_HASH_MEMORY_KIB, 4*1024*1024)
+		if err != nil {
+			return params, err
+		}
+		params.Memory = uint32(memory)
+	}
+	if appConfig.PASSWORD_HASH_ITERATIONS != "" {
+		iterations, err := parse("PASSWORD_HASH_ITERATIONS", appConfig.PASSWORD_HASH_ITERATIONS, 100)
+		if err != nil {
+			return params, err
+		}
+		params.Iterations = uint32(iterations)
+	}
+	if appConfig.PASSWORD_HASH_PARALLELISM != "" {
+		parallelism, err := parse("PASSWORD_HASH_PARALLELISM", appConfig.PASSWORD_HASH_PARALLELISM, 255)
+		if err != nil {
+			return params, err
+		}
+		params.Parallelism = uint8(parallelism)
+	}
+
+	// argon2 needs at least 8 KiB per lane
+	if params.Memory < 8*uint32(params.Parallelism) {
+		return params, errors.New("Env variable PASSWORD_HASH_MEMORY_KIB must be at least 8 per PASSWORD_HASH_PARALLELISM")
+	}
+	return params, nil
+}
+
+// HashPassword hashes with argon2id into a PHC string like
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
 func HashPassword(password string) (string, error) {
-	data, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(data), err
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	params := passwordHashParams
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, passwordKeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+type argon2idHash struct {
+	params PasswordHashParams
+	salt   []byte
+	key    []byte
+}
+
+func parseArgon2idHash(hash string) (*argon2idHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errors.New("unsupported argon2id version")
+	}
+
+	var parsed argon2idHash
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.params.Memory, &parsed.params.Iterations, &parsed.params.Parallelism)
+	// bounded near the configured ones, so a planted hash can't exhaust memory
+	if err != nil || parsed.params.Iterations < 1 || parsed.params.Iterations > 100 ||
+		parsed.params.Parallelism < 1 || parsed.params.Memory < 8*uint32(parsed.params.Parallelism) ||
+		parsed.params.Memory > maxPasswordHashMemory() {
+		return nil, errors.New("invalid argon2id parameters")
+	}
+
+	parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(parsed.salt) > maxPasswordSaltLength {
+		return nil, errors.New("invalid argon2id hash")
+	}
+	parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(parsed.key) == 0 || len(parsed.key) > maxPasswordKeyLength {
+		return nil, errors.New("invalid argon2id hash")
+	}
+	return &parsed, nil
 }
 
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// CompareHashAndPassword checks password against an argon2id hash, a bcrypt
+// one from before argon2id was used, or one imported from another system
 func CompareHashAndPassword(hash string, password string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil, err
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil, err
+	}
+	if isForeignHash(hash) {
+		return compareForeignHash(hash, password)
+	}
+
+	parsed, err := parseArgon2idHash(hash)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), parsed.salt, parsed.params.Iterations, parsed.params.Memory, parsed.params.Parallelism, uint32(len(parsed.key)))
+	if subtle.ConstantTimeCompare(key, parsed.key) != 1 {
+		return false, errors.New("password does not match")
+	}
+	return true, nil
+}
+
+// PasswordNeedsRehash tells whether a hash is weaker than what new hashes are
+// made with: bcrypt, an imported one, or argon2id with less memory, fewer iterations or a
+// shorter salt or key
+func PasswordNeedsRehash(hash string) bool {
+	parsed, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+
+	current := passwordHashParams
+	return parsed.params.Memory < current.Memory ||
+		parsed.params.Iterations < current.Iterations ||
+		parsed.params.Parallelism != current.Parallelism ||
+		len(parsed.salt) < passwordSaltLength ||
+		len(parsed.key) < passwordKeyLength
 }
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// small parameters keep the tests fast, restored afterwards
+func withPasswordHashParams(t *testing.T, params PasswordHashParams) {
+	previous := passwordHashParams
+	SetPasswordHashParams(params)
+	t.Cleanup(func() { SetPasswordHashParams(previous) })
+}
+
+var testPasswordHashParams = PasswordHashParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestHashPassword(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+
+	hash, err := HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("hash = %s, want a PHC string with the configured parameters", hash)
+	}
+
+	parsed, err := parseArgon2idHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.salt) != passwordSaltLength || len(parsed.key) != passwordKeyLength {
+		t.Errorf("salt %d and key %d bytes", len(parsed.salt), len(parsed.key))
+	}
+
+	other, _ := HashPassword("correct horse battery staple")
+	if other == hash {
+		t.Error("two hashes of the same password share a salt")
+	}
+}
+
+func TestCompareHashAndPassword(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+
+	argon2idHash, _ := HashPassword("hunter2")
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+
+	for name, hash := range map[string]string{"argon2id": argon2idHash, "bcrypt": string(bcryptHash)} {
+		if matches, err := CompareHashAndPassword(hash, "hunter2"); !matches || err != nil {
+			t.Errorf("%s: right password refused: %v", name, err)
+		}
+		if matches, _ := CompareHashAndPassword(hash, "hunter3"); matches {
+			t.Errorf("%s: wrong password accepted", name)
+		}
+		if matches, _ := CompareHashAndPassword(hash, ""); matches {
+			t.Errorf("%s: empty password accepted", name)
+		}
+	}
+
+	if matches, err := CompareHashAndPassword("plain text", "plain text"); matches || err == nil {
+		t.Error("unknown hash format was accepted")
+	}
+}
+
+// hashes made elsewhere, with other parameters, still verify
+func TestCompareHashAndPasswordKnownArgon2idHash(t *testing.T) {
+	withPasswordHashParams(t, defaultPasswordHashParams)
+
+	salt := []byte("somesaltsomesalt")
+	key := argon2.IDKey([]byte("password"), salt, 3, 32, 2, 24)
+	hash := fmt.Sprintf("$argon2id$v=19$m=32,t=3,p=2$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+	if matches, err := CompareHashAndPassword(hash, "password"); !matches {
+		t.Errorf("hash with other parameters refused: %v", err)
+	}
+}
+
+func TestParseArgon2idHashRejectsMalformed(t *testing.T) {
+	withPasswordHashParams(t, defaultPasswordHashParams)
+
+	salt := base64.RawStdEncoding.EncodeToString([]byte("somesaltsomesalt"))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+	long := base64.RawStdEncoding.EncodeToString(make([]byte, 65))
+
+	tests := map[string]string{
+		"argon2i":               "$argon2i$v=19$m=19456,t=2,p=1$" + salt + "$" + key,
+		"old version":           "$argon2id$v=16$m=19456,t=2,p=1$" + salt + "$" + key,
+		"missing part":          "$argon2id$v=19$m=19456,t=2,p=1$" + salt,
+		"extra part":            "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + key + "$",
+		"text before":           "x$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + key,
+		"no iterations":         "$argon2id$v=19$m=19456,t=0,p=1$" + salt + "$" + key,
+		"too many iterations":   "$argon2id$v=19$m=19456,t=101,p=1$" + salt + "$" + key,
+		"no parallelism":        "$argon2id$v=19$m=19456,t=2,p=0$" + salt + "$" + key,
+		"parallelism over 255":  "$argon2id$v=19$m=19456,t=2,p=256$" + salt + "$" + key,
+		"memory under 8 a lane": "$argon2id$v=19$m=15,t=2,p=2$" + salt + "$" + key,
+		"memory over the cap":   "$argon2id$v=19$m=65537,t=2,p=1$" + salt + "$" + key,
+		"4 GiB memory":          "$argon2id$v=19$m=4194304,t=2,p=1$" + salt + "$" + key,
+		"memory over uint32":    "$argon2id$v=19$m=4294967296,t=2,p=1$" + salt + "$" + key,
+		"parameters reordered":  "$argon2id$v=19$t=2,m=19456,p=1$" + salt + "$" + key,
+		"salt not base64":       "$argon2id$v=19$m=19456,t=2,p=1$!!!$" + key,
+		"salt too long":         "$argon2id$v=19$m=19456,t=2,p=1$" + long + "$" + key,
+		"empty key":             "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$",
+		"key too long":          "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + long,
+		"padded key":            "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + key + "=",
+	}
+
+	for name, hash := range tests {
+		if _, err := parseArgon2idHash(hash); err == nil {
+			t.Errorf("%s was parsed", name)
+		}
+	}
+
+	if _, err := parseArgon2idHash("$argon2id$v=19$m=65536,t=2,p=1$" + salt + "$" + key); err != nil {
+		t.Errorf("hash at the cap was refused: %v", err)
+	}
+}
+
+func TestParseArgon2idHashMemoryCapFollowsConfig(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("somesaltsomesalt"))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+	hash := "$argon2id$v=19$m=131072,t=2,p=1$" + salt + "$" + key
+
+	withPasswordHashParams(t, defaultPasswordHashParams)
+	if _, err := parseArgon2idHash(hash); err == nil {
+		t.Error("128 MiB hash was parsed with the default parameters")
+	}
+
+	withPasswordHashParams(t, PasswordHashParams{Memory: 131072, Iterations: 2, Parallelism: 1})
+	if _, err := parseArgon2idHash(hash); err != nil {
+		t.Errorf("hash with the configured memory was refused: %v", err)
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	withPasswordHashParams(t, PasswordHashParams{Memory: 64, Iterations: 2, Parallelism: 2})
+
+	salt := []byte("somesaltsomesalt")
+	phc := func(memory uint32, iterations uint32, parallelism uint8, salt []byte, keyLength int) string {
+		return fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", memory, iterations, parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(make([]byte, keyLength)))
+	}
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"current", phc(64, 2, 2, salt, 32), false},
+		{"stronger", phc(128, 3, 2, salt, 64), false},
+		{"less memory", phc(32, 2, 2, salt, 32), true},
+		{"fewer iterations", phc(64, 1, 2, salt, 32), true},
+		{"other parallelism", phc(64, 2, 1, salt, 32), true},
+		{"short salt", phc(64, 2, 2, salt[:8], 32), true},
+		{"short key", phc(64, 2, 2, salt, 16), true},
+		{"bcrypt", string(bcryptHash), true},
+		{"pbkdf2", "pbkdf2_sha256$600000$salt$aGFzaA==", true},
+		{"malformed", "$argon2id$", true},
+	}
+
+	for _, test := range tests {
+		if got := PasswordNeedsRehash(test.hash); got != test.want {
+			t.Errorf("%s: needs rehash = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	hash, _ := HashPassword("hunter2")
+	if PasswordNeedsRehash(hash) {
+		t.Error("fresh hash needs a rehash")
+	}
+}
+
+// bcrypt hashes from before argon2id verify, are flagged at sign in, and
+// what they are rehashed to verifies without needing another upgrade
+func TestBcryptUpgradesToArgon2id(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if matches, _ := CompareHashAndPassword(string(bcryptHash), "hunter2"); !matches {
+		t.Fatal("bcrypt hash refused the password")
+	}
+	if !PasswordNeedsRehash(string(bcryptHash)) {
+		t.Fatal("bcrypt hash isn't upgraded")
+	}
+
+	upgraded, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(upgraded, "$argon2id$") {
+		t.Errorf("upgraded to %s", upgraded)
+	}
+	if matches, _ := CompareHashAndPassword(upgraded, "hunter2"); !matches {
+		t.Error("upgraded hash refused the password")
+	}
+	if PasswordNeedsRehash(upgraded) {
+		t.Error("upgraded hash needs another upgrade")
+	}
+
+	// raising the parameters upgrades argon2id hashes the same way
+	withPasswordHashParams(t, PasswordHashParams{Memory: 128, Iterations: 1, Parallelism: 1})
+	if !PasswordNeedsRehash(upgraded) {
+		t.Error("hash below the raised parameters isn't upgraded")
+	}
+	if matches, _ := CompareHashAndPassword(upgraded, "hunter2"); !matches {
+		t.Error("hash below the raised parameters refused the password")
+	}
+}
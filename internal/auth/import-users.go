@@ -0,0 +1,393 @@
+package auth
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportUsersError string
+
+const (
+	ImportUsersErrorClientNotFound     ImportUsersError = "client not found"
+	ImportUsersErrorProviderNotEnabled ImportUsersError = "email provider not enabled for client"
+	ImportUsersErrorInvalidFile        ImportUsersError = "invalid import file"
+)
+
+// reasons a user of an import was left out
+const (
+	ImportFailureInvalidEmail   = "invalid_email"
+	ImportFailureDuplicateEmail = "duplicate_email"
+	ImportFailureInvalidHash    = "invalid_password_hash"
+	ImportFailureBatchFailed    = "batch_failed"
+)
+
+const defaultImportBatchSize = 500
+
+// ImportUser is a user exported from another system
+type ImportUser struct {
+	Email         string
+	EmailVerified bool
+	// when the user signed up there, now when empty
+	CreatedAt *time.Time
+	// empty for users without a password, they sign in some other way or
+	// reset it
+	PasswordHash  string
+	PasswordSalt  string
+	HashAlgorithm string
+}
+
+type ImportUsersInput struct {
+	Users      []ImportUser
+	HashConfig ForeignHashConfig
+	BatchSize  int
+}
+
+type ImportUserFailure struct {
+	// position of the user in the import
+	Index  int
+	Email  string
+	Reason string
+	Detail string
+}
+
+type ImportUsersResult struct {
+	Imported int
+	// users whose email is already registered
+	Skipped  int
+	Failures []ImportUserFailure
+}
+
+type importRow struct {
+	index int
+	user  models.User
+	hash  string
+	// verified state of the identity
+	verified bool
+}
+
+// ImportUsers creates a user with an email identity for each imported user.
+// batches are written in their own transaction, so a failing batch doesn't
+// undo the ones before it. emails that are already registered are skipped,
+// which makes it safe to run an import again after it was interrupted
+func ImportUsers(db *gorm.DB, clientId string, input ImportUsersInput) (*ImportUsersResult, error) {
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(ImportUsersErrorClientNotFound))
+	}
+
+	clientProvider, err := getClientProvider(db, clientId, "email")
+	if err != nil || !clientProvider.Enabled {
+		return nil, errors.New(string(ImportUsersErrorProviderNotEnabled))
+	}
+
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	importResult := ImportUsersResult{Failures: []ImportUserFailure{}}
+	seen := map[string]bool{}
+	batch := make([]importRow, 0, batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		imported, skipped, err := importBatch(db, clientProvider, batch)
+		if err != nil {
+			for _, row := range batch {
+				importResult.Failures = append(importResult.Failures, ImportUserFailure{
+					Index:  row.index,
+					Email:  row.user.Email,
+					Reason: ImportFailureBatchFailed,
+					Detail: err.Error(),
+				})
+			}
+		} else {
+			importResult.Imported += imported
+			importResult.Skipped += skipped
+		}
+		batch = batch[:0]
+	}
+
+	for i, imported := range input.Users {
+		email := strings.ToLower(strings.TrimSpace(imported.Email))
+		if !validators.IsValidEmail(email) {
+			importResult.Failures = append(importResult.Failures, ImportUserFailure{Index: i, Email: imported.Email, Reason: ImportFailureInvalidEmail})
+			continue
+		}
+		if seen[email] {
+			importResult.Failures = append(importResult.Failures, ImportUserFailure{Index: i, Email: email, Reason: ImportFailureDuplicateEmail})
+			continue
+		}
+		seen[email] = true
+
+		var hash string
+		if imported.PasswordHash != "" {
+			hash, err = NormalizeForeignHash(ForeignHash{
+				Algorithm: imported.HashAlgorithm,
+				Hash:      imported.PasswordHash,
+				Salt:      imported.PasswordSalt,
+			}, input.HashConfig)
+			if err != nil {
+				importResult.Failures = append(importResult.Failures, ImportUserFailure{Index: i, Email: email, Reason: ImportFailureInvalidHash, Detail: err.Error()})
+				continue
+			}
+		}
+
+		row := importRow{
+			index:    i,
+			user:     models.User{ClientId: clientId, Email: email},
+			hash:     hash,
+			verified: imported.EmailVerified,
+		}
+		if imported.CreatedAt != nil {
+			row.user.CreatedAt = *imported.CreatedAt
+		}
+
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return &importResult, nil
+}
+
+func importBatch(db *gorm.DB, clientProvider *models.ClientProvider, batch []importRow) (int, int, error) {
+	imported, skipped := 0, 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		emails := make([]string, len(batch))
+		for i, row := range batch {
+			emails[i] = row.user.Email
+		}
+
+		// emails are unique across clients, deleted users included
+		var existing []string
+		if err := tx.Unscoped().Model(&models.User{}).Where("email IN ?", emails).Pluck("email", &existing).Error; err != nil {
+			return err
+		}
+		taken := map[string]bool{}
+		for _, email := range existing {
+			taken[email] = true
+		}
+
+		rows := make([]importRow, 0, len(batch))
+		for _, row := range batch {
+			if !taken[row.user.Email] {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			skipped = len(batch)
+			return nil
+		}
+
+		users := make([]models.User, len(rows))
+		for i, row := range rows {
+			users[i] = row.user
+		}
+		if err := tx.Create(&users).Error; err != nil {
+			return err
+		}
+
+		identities := make([]models.Identity, len(rows))
+		for i, row := range rows {
+			data := make(models.JsonDictionary)
+			if row.hash != "" {
+				data["password_hash"] = row.hash
+			}
+			identities[i] = models.Identity{
+				ClientId:         clientProvider.ClientId,
+				UserId:           users[i].ID,
+				ProviderSub:      users[i].Email,
+				ProviderOptionId: "email",
+				ClientProviderId: clientProvider.ID,
+				Data:             data,
+				EmailVerified:    row.verified,
+				CreatedAt:        users[i].CreatedAt,
+			}
+		}
+		if err := tx.Create(&identities).Error; err != nil {
+			return err
+		}
+
+		// the imported hash counts as a used password for the reuse rule
+		var history []models.PasswordHistory
+		for i, row := range rows {
+			if row.hash != "" {
+				history = append(history, models.PasswordHistory{IdentityId: identities[i].ID, PasswordHash: row.hash})
+			}
+		}
+		if len(history) > 0 {
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+		}
+
+		imported = len(rows)
+		skipped = len(batch) - len(rows)
+		return nil
+	})
+
+	return imported, skipped, err
+}
+
+// importUserRecord is a user in a json import. besides its own field names it
+// takes the ones of `firebase auth:export`
+type importUserRecord struct {
+	Email         string          `json:"email"`
+	EmailVerified *bool           `json:"email_verified"`
+	CreatedAt     json.RawMessage `json:"created_at"`
+	PasswordHash  string          `json:"password_hash"`
+	PasswordSalt  string          `json:"password_salt"`
+	HashAlgorithm string          `json:"hash_algorithm"`
+
+	FirebaseEmailVerified bool   `json:"emailVerified"`
+	FirebaseCreatedAt     string `json:"createdAt"`
+	FirebasePasswordHash  string `json:"passwordHash"`
+	FirebaseSalt          string `json:"salt"`
+}
+
+// parseImportTime reads RFC 3339 or unix milliseconds like firebase exports
+func parseImportTime(value string) (*time.Time, error) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if value == "" || value == "null" {
+		return nil, nil
+	}
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		parsed := time.UnixMilli(millis)
+		return &parsed, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (r importUserRecord) toImportUser() (ImportUser, error) {
+	user := ImportUser{
+		Email:         r.Email,
+		EmailVerified: r.FirebaseEmailVerified,
+		PasswordHash:  r.PasswordHash,
+		PasswordSalt:  r.PasswordSalt,
+		HashAlgorithm: r.HashAlgorithm,
+	}
+	if r.EmailVerified != nil {
+		user.EmailVerified = *r.EmailVerified
+	}
+	if user.PasswordHash == "" && r.FirebasePasswordHash != "" {
+		user.PasswordHash = r.FirebasePasswordHash
+		user.PasswordSalt = r.FirebaseSalt
+	}
+
+	createdAt := string(r.CreatedAt)
+	if createdAt == "" {
+		createdAt = r.FirebaseCreatedAt
+	}
+	parsed, err := parseImportTime(createdAt)
+	if err != nil {
+		return user, err
+	}
+	user.CreatedAt = parsed
+	return user, nil
+}
+
+// ParseImportUsersJson reads a list of users, or an object with the list in
+// "users" like firebase exports it
+func ParseImportUsersJson(reader io.Reader) ([]ImportUser, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []importUserRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		var wrapped struct {
+			Users []importUserRecord `json:"users"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, errors.New(string(ImportUsersErrorInvalidFile))
+		}
+		records = wrapped.Users
+	}
+
+	users := make([]ImportUser, len(records))
+	for i, record := range records {
+		if users[i], err = record.toImportUser(); err != nil {
+			return nil, errors.New(string(ImportUsersErrorInvalidFile))
+		}
+	}
+	return users, nil
+}
+
+// ParseImportUsersCsv reads users from csv with a header row naming the
+// columns, of which email is required and email_verified, created_at,
+// password_hash, password_salt and hash_algorithm are optional
+func ParseImportUsersCsv(reader io.Reader) ([]ImportUser, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.New(string(ImportUsersErrorInvalidFile))
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New(string(ImportUsersErrorInvalidFile))
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	users := []ImportUser{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(string(ImportUsersErrorInvalidFile))
+		}
+
+		user := ImportUser{
+			Email:         field(record, "email"),
+			PasswordHash:  field(record, "password_hash"),
+			PasswordSalt:  field(record, "password_salt"),
+			HashAlgorithm: field(record, "hash_algorithm"),
+		}
+		if verified := field(record, "email_verified"); verified != "" {
+			if user.EmailVerified, err = strconv.ParseBool(verified); err != nil {
+				return nil, errors.New(string(ImportUsersErrorInvalidFile))
+			}
+		}
+		if user.CreatedAt, err = parseImportTime(field(record, "created_at")); err != nil {
+			return nil, errors.New(string(ImportUsersErrorInvalidFile))
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"fmt"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportUsersInBatches(t *testing.T) {
+	db := testDb(t)
+	createdAt := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	users := []ImportUser{}
+	for i := 0; i < 5; i++ {
+		users = append(users, ImportUser{
+			Email:         fmt.Sprintf(" User%d@Example.com", i),
+			EmailVerified: i%2 == 0,
+			CreatedAt:     &createdAt,
+		})
+	}
+	users[0].PasswordHash = "sha1$seasalt$cff36ea83f5706ce9aa7454e63e431fc726b2dc8"
+	users[0].HashAlgorithm = HashAlgorithmSha1
+
+	result, err := ImportUsers(db, "app", ImportUsersInput{Users: users, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 5 || result.Skipped != 0 || len(result.Failures) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	var identities []models.Identity
+	db.Order("provider_sub").Find(&identities)
+	if len(identities) != 5 {
+		t.Fatalf("%d identities, want 5", len(identities))
+	}
+	for i, identity := range identities {
+		if want := fmt.Sprintf("user%d@example.com", i); identity.ProviderSub != want {
+			t.Errorf("identity %d is %s, want %s", i, identity.ProviderSub, want)
+		}
+		if identity.ClientProviderId != "app-email" || identity.EmailVerified != (i%2 == 0) || !identity.CreatedAt.Equal(createdAt) {
+			t.Errorf("identity %d = %+v", i, identity)
+		}
+	}
+
+	hash, _ := identities[0].Data["password_hash"].(string)
+	if matches, err := CompareHashAndPassword(hash, "lètmein"); !matches {
+		t.Errorf("imported password refused: %v", err)
+	}
+	if _, ok := identities[1].Data["password_hash"]; ok {
+		t.Error("user without a password got a hash")
+	}
+
+	var history int64
+	db.Model(&models.PasswordHistory{}).Count(&history)
+	if history != 1 {
+		t.Errorf("%d password history rows, want 1", history)
+	}
+}
+
+func TestImportUsersSkipsRegisteredEmails(t *testing.T) {
+	db := testDb(t)
+	db.Exec("INSERT INTO users (client_id, email) VALUES ('other', 'taken@example.com')")
+	// deleted accounts keep their email until they are purged
+	db.Exec("INSERT INTO users (client_id, email, deleted_at) VALUES ('app', 'deleted@example.com', CURRENT_TIMESTAMP)")
+
+	users := []ImportUser{
+		{Email: "new@example.com"},
+		{Email: "Taken@example.com"},
+		{Email: "deleted@example.com"},
+		{Email: "other@example.com"},
+	}
+
+	result, err := ImportUsers(db, "app", ImportUsersInput{Users: users, BatchSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 2 || result.Skipped != 2 || len(result.Failures) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// an interrupted import runs again without duplicates
+	result, err = ImportUsers(db, "app", ImportUsersInput{Users: users, BatchSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 0 || result.Skipped != 4 {
+		t.Errorf("second run = %+v", result)
+	}
+
+	var identities int64
+	db.Model(&models.Identity{}).Count(&identities)
+	if identities != 2 {
+		t.Errorf("%d identities, want 2", identities)
+	}
+}
+
+func TestImportUsersReportsFailures(t *testing.T) {
+	db := testDb(t)
+	// the batch with this email can't be written
+	db.Exec(`CREATE TRIGGER refuse BEFORE INSERT ON users WHEN NEW.email = 'refused@example.com'
+		BEGIN SELECT RAISE(ABORT, 'refused'); END`)
+
+	users := []ImportUser{
+		{Email: "first@example.com"},
+		{Email: "not an email"},
+		{Email: "FIRST@example.com"},
+		{Email: "hash@example.com", PasswordHash: "not a hash", HashAlgorithm: HashAlgorithmBcrypt},
+		{Email: "second@example.com"},
+		{Email: "refused@example.com"},
+		{Email: "third@example.com"},
+	}
+
+	result, err := ImportUsers(db, "app", ImportUsersInput{Users: users, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := map[int]string{}
+	for _, failure := range result.Failures {
+		failures[failure.Index] = failure.Reason
+	}
+	want := map[int]string{
+		1: ImportFailureInvalidEmail,
+		2: ImportFailureDuplicateEmail,
+		3: ImportFailureInvalidHash,
+		5: ImportFailureBatchFailed,
+		6: ImportFailureBatchFailed,
+	}
+	if fmt.Sprint(failures) != fmt.Sprint(want) {
+		t.Errorf("failures = %v, want %v", failures, want)
+	}
+
+	// the batch before the failing one stays
+	if result.Imported != 2 {
+		t.Errorf("imported %d, want 2", result.Imported)
+	}
+	var emails []string
+	db.Model(&models.User{}).Order("email").Pluck("email", &emails)
+	if strings.Join(emails, ",") != "first@example.com,second@example.com" {
+		t.Errorf("users = %v", emails)
+	}
+	var identities int64
+	db.Model(&models.Identity{}).Count(&identities)
+	if identities != 2 {
+		t.Errorf("%d identities, want 2", identities)
+	}
+}
+
+func TestImportUsersNeedsTheEmailProvider(t *testing.T) {
+	db := testDb(t)
+
+	if _, err := ImportUsers(db, "missing", ImportUsersInput{}); err == nil || err.Error() != string(ImportUsersErrorClientNotFound) {
+		t.Errorf("unknown client: %v", err)
+	}
+
+	db.Exec("UPDATE client_providers SET enabled = false")
+	if _, err := ImportUsers(db, "app", ImportUsersInput{}); err == nil || err.Error() != string(ImportUsersErrorProviderNotEnabled) {
+		t.Errorf("disabled provider: %v", err)
+	}
+}
+
+func TestParseImportUsers(t *testing.T) {
+	firebase := `{"users": [{"localId": "1", "email": "a@example.com", "emailVerified": true,
+		"passwordHash": "aGFzaA==", "salt": "c2FsdA==", "createdAt": "1554120000000"}]}`
+	users, err := ParseImportUsersJson(strings.NewReader(firebase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Email != "a@example.com" || !users[0].EmailVerified ||
+		users[0].PasswordHash != "aGFzaA==" || users[0].PasswordSalt != "c2FsdA==" ||
+		users[0].CreatedAt == nil || !users[0].CreatedAt.Equal(time.UnixMilli(1554120000000)) {
+		t.Errorf("firebase export = %+v", users)
+	}
+
+	csv := "email, email_verified, created_at, password_hash, hash_algorithm\n" +
+		"b@example.com, true, 2019-04-01T12:00:00Z, sha1$seasalt$cff36ea83f5706ce9aa7454e63e431fc726b2dc8, sha1\n" +
+		"c@example.com, , , ,\n"
+	users, err = ParseImportUsersCsv(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || !users[0].EmailVerified || users[0].HashAlgorithm != HashAlgorithmSha1 ||
+		users[0].CreatedAt == nil || users[1].CreatedAt != nil || users[1].PasswordHash != "" {
+		t.Errorf("csv = %+v", users)
+	}
+
+	for _, invalid := range []string{"not json", `{"users": [{"email": "a@example.com", "created_at": "yesterday"}]}`} {
+		if _, err := ParseImportUsersJson(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%s was parsed", invalid)
+		}
+	}
+	for _, invalid := range []string{"", "name\nalice\n", "email,email_verified\na@example.com,maybe\n"} {
+		if _, err := ParseImportUsersCsv(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%q was parsed", invalid)
+		}
+	}
+}
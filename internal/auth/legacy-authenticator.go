@@ -0,0 +1,326 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LegacyAuthenticatorError string
+
+const (
+	LegacyAuthenticatorErrorClientNotFound LegacyAuthenticatorError = "client not found"
+	LegacyAuthenticatorErrorNotFound       LegacyAuthenticatorError = "legacy authenticator not found"
+	LegacyAuthenticatorErrorInvalidUrl     LegacyAuthenticatorError = "invalid legacy authenticator url"
+	LegacyAuthenticatorErrorInvalidTimeout LegacyAuthenticatorError = "invalid legacy authenticator timeout"
+)
+
+const (
+	legacyAuthenticatorDefaultTimeout = 5 * time.Second
+	legacyAuthenticatorMaxTimeout     = 30 * time.Second
+	// answers are tiny, anything bigger isn't read
+	legacyAuthenticatorMaxResponse = 64 << 10
+)
+
+// LegacyUser is what the old system tells about a user it accepted
+type LegacyUser struct {
+	EmailVerified bool
+}
+
+// LegacyAuthenticator checks a password with a client's old auth system. it
+// returns nil without an error when the old system rejects the credentials
+type LegacyAuthenticator interface {
+	Authenticate(ctx context.Context, config *models.LegacyAuthenticator, email string, password string) (*LegacyUser, error)
+}
+
+// LegacyAuthenticatorFunc lets a function, like a stub in tests, be used as
+// the legacy authenticator
+type LegacyAuthenticatorFunc func(ctx context.Context, config *models.LegacyAuthenticator, email string, password string) (*LegacyUser, error)
+
+func (f LegacyAuthenticatorFunc) Authenticate(ctx context.Context, config *models.LegacyAuthenticator, email string, password string) (*LegacyUser, error) {
+	return f(ctx, config, email, password)
+}
+
+// HttpLegacyAuthenticator posts {"client_id", "email", "password"} as json
+// to the configured url with the secret as bearer token. 200 accepts the
+// user, optionally answering {"email_verified": true}, and 401, 403 or 404
+// reject them. anything else is an error
+type HttpLegacyAuthenticator struct {
+	client *http.Client
+}
+
+func NewHttpLegacyAuthenticator() *HttpLegacyAuthenticator {
+	return &HttpLegacyAuthenticator{client: &http.Client{
+		// a redirect could take the password somewhere else
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (a *HttpLegacyAuthenticator) Authenticate(ctx context.Context, config *models.LegacyAuthenticator, email string, password string) (*LegacyUser, error) {
+	body, err := json.Marshal(map[string]string{
+		"client_id": config.ClientId,
+		"email":     email,
+		"password":  password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.Url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if config.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+config.Secret)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("legacy authenticator returned status %d", resp.StatusCode)
+	}
+
+	var answer struct {
+		EmailVerified bool `json:"email_verified"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, legacyAuthenticatorMaxResponse))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &answer); err != nil {
+			return nil, fmt.Errorf("legacy authenticator answered with invalid json: %w", err)
+		}
+	}
+	return &LegacyUser{EmailVerified: answer.EmailVerified}, nil
+}
+
+// the authenticator sign ins of unknown emails go through
+var legacyAuthenticator LegacyAuthenticator = NewHttpLegacyAuthenticator()
+
+func SetLegacyAuthenticator(authenticator LegacyAuthenticator) {
+	legacyAuthenticator = authenticator
+}
+
+// the password goes to the url, so it must be https unless it stays on this
+// machine
+func validateLegacyAuthenticatorUrl(rawUrl string) bool {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || (ip != nil && ip.IsLoopback())
+	}
+	return false
+}
+
+func findLegacyAuthenticator(db *gorm.DB, clientId string) (*models.LegacyAuthenticator, error) {
+	var config models.LegacyAuthenticator
+	result := db.Limit(1).Find(&config, "client_id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &config, nil
+}
+
+// GetClientLegacyAuthenticator returns the endpoint of the client's old auth
+// system
+func GetClientLegacyAuthenticator(db *gorm.DB, clientId string) (*models.LegacyAuthenticator, error) {
+	config, err := findLegacyAuthenticator(db, clientId)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New(string(LegacyAuthenticatorErrorNotFound))
+	}
+	return config, nil
+}
+
+type LegacyAuthenticatorInput struct {
+	Url       string
+	Secret    string
+	TimeoutMs int
+	Enabled   bool
+}
+
+// SetClientLegacyAuthenticator points sign ins of unknown emails at the
+// client's old auth system. the count of migrated users is kept
+func SetClientLegacyAuthenticator(db *gorm.DB, clientId string, input LegacyAuthenticatorInput) (*models.LegacyAuthenticator, error) {
+	input.Url = strings.TrimSpace(input.Url)
+	if !validateLegacyAuthenticatorUrl(input.Url) {
+		return nil, errors.New(string(LegacyAuthenticatorErrorInvalidUrl))
+	}
+	if input.TimeoutMs == 0 {
+		input.TimeoutMs = int(legacyAuthenticatorDefaultTimeout / time.Millisecond)
+	}
+	if input.TimeoutMs < 100 || input.TimeoutMs > int(legacyAuthenticatorMaxTimeout/time.Millisecond) {
+		return nil, errors.New(string(LegacyAuthenticatorErrorInvalidTimeout))
+	}
+
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(LegacyAuthenticatorErrorClientNotFound))
+	}
+
+	config := models.LegacyAuthenticator{
+		ClientId:  clientId,
+		Url:       input.Url,
+		Secret:    input.Secret,
+		TimeoutMs: input.TimeoutMs,
+		Enabled:   input.Enabled,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "timeout_ms", "enabled", "updated_at"}),
+	}).Create(&config).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return GetClientLegacyAuthenticator(db, clientId)
+}
+
+// DeleteClientLegacyAuthenticator stops sending unknown emails to the
+// client's old auth system
+func DeleteClientLegacyAuthenticator(db *gorm.DB, clientId string) error {
+	result := db.Where("client_id = ?", clientId).Delete(&models.LegacyAuthenticator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(LegacyAuthenticatorErrorNotFound))
+	}
+	return nil
+}
+
+var (
+	errNoLegacyAuthenticator = errors.New("no legacy authenticator")
+	errLegacyUserRejected    = errors.New("rejected by legacy authenticator")
+)
+
+// migrateLegacyUser asks the client's old auth system about an email that
+// has no identity yet. when it accepts the password the user is created
+// with it, hashed like any other. errNoLegacyAuthenticator is returned when
+// the client has none to ask and errLegacyUserRejected when it says no
+func migrateLegacyUser(db *gorm.DB, client *models.Client, email string, password string) (*models.Identity, error) {
+	config, err := findLegacyAuthenticator(db, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || !config.Enabled {
+		return nil, errNoLegacyAuthenticator
+	}
+
+	timeout := time.Duration(config.TimeoutMs) * time.Millisecond
+	if timeout <= 0 || timeout > legacyAuthenticatorMaxTimeout {
+		timeout = legacyAuthenticatorDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	legacyUser, err := legacyAuthenticator.Authenticate(ctx, config, email, password)
+	if err != nil {
+		return nil, err
+	}
+	if legacyUser == nil {
+		return nil, errLegacyUserRejected
+	}
+
+	clientProvider, err := getClientProvider(db, client.ID, "email")
+	if err != nil || !clientProvider.Enabled {
+		return nil, errors.New(string(CreateUserWithEmailErrorInvalidClientProvider))
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		user := models.User{ClientId: client.ID, Email: email}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		identity := models.Identity{
+			ClientId:         client.ID,
+			UserId:           user.ID,
+			ProviderSub:      email,
+			ProviderOptionId: "email",
+			ClientProviderId: clientProvider.ID,
+			Data:             models.JsonDictionary{"password_hash": hash},
+			EmailVerified:    legacyUser.EmailVerified,
+		}
+		if err := tx.Create(&identity).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PasswordHistory{IdentityId: identity.ID, PasswordHash: hash}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.LegacyAuthenticator{}).Where("client_id = ?", client.ID).Updates(map[string]interface{}{
+			"migrated_users":   gorm.Expr("migrated_users + 1"),
+			"last_migrated_at": now,
+		}).Error
+	})
+	if err != nil {
+		// a parallel sign in of the same user may have created them first
+		if identity, findErr := findIdentity(db, client.ID, "email", email); findErr == nil {
+			return identity, nil
+		}
+		return nil, err
+	}
+
+	identity, err := findIdentity(db, client.ID, "email", email)
+	if err != nil {
+		return nil, err
+	}
+
+	events.Publish(events.Event{
+		Type:     events.UserMigrated,
+		ClientId: client.ID,
+		UserId:   identity.UserId,
+		Data:     map[string]interface{}{"email": email},
+		At:       now,
+	})
+	return identity, nil
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// withLegacyAuthenticator stubs the old auth system of every client, the
+// emails it was asked about are returned
+func withLegacyAuthenticator(t *testing.T, authenticate func(email string, password string) (*LegacyUser, error)) *[]string {
+	asked := []string{}
+	previous := legacyAuthenticator
+	SetLegacyAuthenticator(LegacyAuthenticatorFunc(func(ctx context.Context, config *models.LegacyAuthenticator, email string, password string) (*LegacyUser, error) {
+		asked = append(asked, email)
+		return authenticate(email, password)
+	}))
+	t.Cleanup(func() { SetLegacyAuthenticator(previous) })
+	return &asked
+}
+
+func legacyTestDb(t *testing.T) *gorm.DB {
+	db := testDb(t)
+	db.Exec("INSERT INTO legacy_authenticators (client_id, url, timeout_ms, enabled) VALUES ('app', 'https://legacy.example.com', 1000, true)")
+	return db
+}
+
+func legacySignIn(db *gorm.DB, email string, password string) (*models.Identity, error) {
+	return SignInWithEmail(db, nil, SignInWithEmailInput{ClientId: "app", Email: email, Password: password, Ip: "192.0.2.1"})
+}
+
+func TestSignInWithEmailMigratesLegacyUsers(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+	db := legacyTestDb(t)
+	withLegacyAuthenticator(t, func(email string, password string) (*LegacyUser, error) {
+		return &LegacyUser{EmailVerified: true}, nil
+	})
+
+	identity, err := legacySignIn(db, "Legacy@Example.com ", "old password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.ProviderSub != "legacy@example.com" || !identity.EmailVerified {
+		t.Errorf("migrated %+v", identity)
+	}
+
+	// the password is kept hashed like any other, not in the old format
+	hash, _ := identity.Data["password_hash"].(string)
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("hash = %s, want the current argon2id parameters", hash)
+	}
+	if matches, _ := CompareHashAndPassword(hash, "old password"); !matches {
+		t.Error("migrated hash doesn't match the password")
+	}
+
+	var history int64
+	db.Model(&models.PasswordHistory{}).Where("identity_id = ?", identity.ID).Count(&history)
+	if history != 1 {
+		t.Errorf("%d password history entries, want 1", history)
+	}
+	var config models.LegacyAuthenticator
+	db.First(&config, "client_id = ?", "app")
+	if config.MigratedUsers != 1 || config.LastMigratedAt == nil {
+		t.Errorf("migrated_users %d, last_migrated_at %v", config.MigratedUsers, config.LastMigratedAt)
+	}
+}
+
+func TestSignInWithEmailRejectedByLegacyAuthenticator(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+	db := legacyTestDb(t)
+	withLegacyAuthenticator(t, func(email string, password string) (*LegacyUser, error) {
+		return nil, nil
+	})
+
+	_, err := legacySignIn(db, "legacy@example.com", "wrong password")
+	if err == nil || err.Error() != string(SignInWithEmailErrorUnknownUser) {
+		t.Fatalf("err = %v, want %s", err, SignInWithEmailErrorUnknownUser)
+	}
+
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 0 {
+		t.Errorf("%d users created", users)
+	}
+	throttle, _ := findSignInThrottle(db, "app", models.SignInThrottleKindIdentifier, "legacy@example.com")
+	if throttle == nil || throttle.Failures != 1 {
+		t.Errorf("throttle %+v, want the failure counted", throttle)
+	}
+}
+
+func TestSignInWithEmailLegacyAuthenticatorError(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+	db := legacyTestDb(t)
+	withLegacyAuthenticator(t, func(email string, password string) (*LegacyUser, error) {
+		return nil, errors.New("legacy authenticator answered with status 502")
+	})
+
+	_, err := legacySignIn(db, "legacy@example.com", "old password")
+	if err == nil || err.Error() != string(SignInWithEmailErrorUnknownUser) {
+		t.Fatalf("err = %v, want %s", err, SignInWithEmailErrorUnknownUser)
+	}
+
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 0 {
+		t.Errorf("%d users created", users)
+	}
+}
+
+func TestSignInWithEmailSkipsLegacyAuthenticatorForKnownEmails(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+	db := legacyTestDb(t)
+	asked := withLegacyAuthenticator(t, func(email string, password string) (*LegacyUser, error) {
+		return &LegacyUser{}, nil
+	})
+
+	hash, _ := HashPassword("new password")
+	db.Exec("INSERT INTO users (id, client_id, email) VALUES ('known', 'app', 'known@example.com')")
+	db.Exec(`INSERT INTO identities (client_id, user_id, provider_sub, provider_option_id, client_provider_id, data)
+		VALUES ('app', 'known', 'known@example.com', 'email', 'app-email', CAST(? AS blob))`, `{"password_hash":"`+hash+`"}`)
+
+	if _, err := legacySignIn(db, "known@example.com", "new password"); err != nil {
+		t.Fatal(err)
+	}
+	// the old password the legacy system would still accept doesn't work
+	_, err := legacySignIn(db, "known@example.com", "old password")
+	if err == nil || err.Error() != string(SignInWithEmailErrorPasswordCheckFailed) {
+		t.Errorf("err = %v, want %s", err, SignInWithEmailErrorPasswordCheckFailed)
+	}
+	if len(*asked) != 0 {
+		t.Errorf("legacy authenticator asked about %v", *asked)
+	}
+}
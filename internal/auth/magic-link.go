@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type MagicLinkError string
+
+const (
+	MagicLinkErrorInvalidEmail       MagicLinkError = "invalid email"
+	MagicLinkErrorProviderDisabled   MagicLinkError = "magic link not enabled for client"
+	MagicLinkErrorInvalidRedirectUri MagicLinkError = "redirect uri is not registered for client"
+	MagicLinkErrorInvalidLink        MagicLinkError = "invalid, used or expired sign in link"
+)
+
+const magicLinkProviderOptionId = "magic_link"
+
+const magicLinkDurationSeconds = 60 * 15
+
+// one link a minute per email, extra requests are silently dropped
+const magicLinkResendInterval = time.Minute
+
+type MagicLinkInput struct {
+	ClientId    string
+	Email       string
+	RedirectUri string
+	Locale      string
+	// the authorization request the link completes
+	Authorization AuthorizationParams
+}
+
+func getEnabledClientProvider(db *gorm.DB, clientId string, providerOptionId string) (*models.ClientProvider, bool) {
+	clientProvider, err := getClientProvider(db, clientId, providerOptionId)
+	if err != nil || !clientProvider.Enabled {
+		return nil, false
+	}
+	return clientProvider, true
+}
+
+// SendMagicLink emails a single use sign in link. the code the link results
+// in can only be redeemed with the code verifier of the device asking here.
+// emails without an account get one when the link is opened
+func SendMagicLink(db *gorm.DB, mailer mail.Mailer, input MagicLinkInput) error {
+	email := strings.ToLower(strings.Trim(input.Email, " "))
+	if !validators.IsValidEmail(email) {
+		return errors.New(string(MagicLinkErrorInvalidEmail))
+	}
+	if err := checkCodeChallenge(input.Authorization.CodeChallenge, input.Authorization.CodeChallengeMethod); err != nil {
+		return err
+	}
+
+	clientProvider, ok := getEnabledClientProvider(db, input.ClientId, magicLinkProviderOptionId)
+	if !ok {
+		return errors.New(string(MagicLinkErrorProviderDisabled))
+	}
+
+	if _, ok := emailLink(&clientProvider.Client, input.RedirectUri, ""); !ok {
+		return errors.New(string(MagicLinkErrorInvalidRedirectUri))
+	}
+
+	var recent int64
+	err := db.Model(&models.MagicLink{}).
+		Where("client_id = ? AND email = ? AND created_at > ?", input.ClientId, email, time.Now().Add(-magicLinkResendInterval)).
+		Count(&recent).Error
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token := crypto.GenerateSecureSecret()
+	record := models.MagicLink{
+		ClientId:            input.ClientId,
+		Email:               email,
+		TokenHash:           crypto.HashSecret(token),
+		CodeChallenge:       input.Authorization.CodeChallenge,
+		CodeChallengeMethod: input.Authorization.CodeChallengeMethod,
+		Scopes:              pq.StringArray(input.Authorization.Scopes),
+		Resources:           pq.StringArray(input.Authorization.Resources),
+		AcrValues:           pq.StringArray(input.Authorization.AcrValues),
+		State:               input.Authorization.State,
+		ExpiresAt:           time.Now().Add(magicLinkDurationSeconds * time.Second),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return err
+	}
+
+	link, _ := emailLink(&clientProvider.Client, input.RedirectUri, token)
+
+	message, err := mail.Render(&clientProvider.Client, mail.TemplateMagicLink, input.Locale, email, mail.Data{
+		"Link":             link,
+		"ExpiresInMinutes": magicLinkDurationSeconds / 60,
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(message)
+}
+
+// RedeemMagicLink consumes the token from a magic link and continues the
+// authorization request it was sent for
+func RedeemMagicLink(db *gorm.DB, token string) (*AuthorizationResult, error) {
+	now := time.Now()
+
+	// claim the link in one statement so it can only be used once
+	result := db.Model(&models.MagicLink{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", crypto.HashSecret(token), now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(MagicLinkErrorInvalidLink))
+	}
+
+	var record models.MagicLink
+	if err := db.First(&record, "token_hash = ?", crypto.HashSecret(token)).Error; err != nil {
+		return nil, err
+	}
+
+	// the client may have turned magic links off since the email was sent
+	clientProvider, ok := getEnabledClientProvider(db, record.ClientId, magicLinkProviderOptionId)
+	if !ok {
+		return nil, errors.New(string(MagicLinkErrorProviderDisabled))
+	}
+
+	identity, err := findOrCreateVerifiedEmailIdentity(db, clientProvider, record.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompleteAuthorization(db, identity, AuthorizationParams{
+		CodeChallenge:       record.CodeChallenge,
+		CodeChallengeMethod: record.CodeChallengeMethod,
+		Scopes:              record.Scopes,
+		Resources:           record.Resources,
+		AcrValues:           record.AcrValues,
+		State:               record.State,
+	})
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MfaError string
+
+const (
+	MfaErrorInvalidCode         MfaError = "invalid second factor code"
+	MfaErrorTooManyAttempts     MfaError = "too many wrong second factor codes"
+	MfaErrorUnsupportedType     MfaError = "second factor type not enrolled"
+	MfaErrorStepUpRequired      MfaError = "session has to be signed in with a second factor"
+	MfaErrorNotEnrolled         MfaError = "second factor not enrolled"
+	MfaErrorAlreadyEnrolled     MfaError = "second factor already enrolled"
+	MfaErrorNoPendingEnrollment MfaError = "no second factor waiting for confirmation"
+	MfaErrorUserNotFound        MfaError = "user not found"
+)
+
+// wrong codes allowed per sign in before it has to be started over
+const mfaMaxAttempts = 5
+
+// hasMfa tells whether sign ins of the user need a second factor
+func hasMfa(db *gorm.DB, userId string) (bool, error) {
+	factors, err := userMfaFactorTypes(db, userId)
+	return len(factors) > 0, err
+}
+
+// userMfaFactorTypes lists the kinds of second factors a user can sign in
+// with. recovery codes are listed when the user has any left, but only next
+// to a real factor
+func userMfaFactorTypes(db *gorm.DB, userId string) ([]string, error) {
+	var types []string
+	err := db.Model(&models.MfaFactor{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userId).
+		Distinct().Pluck("type", &types).Error
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := hasUsablePasskeys(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	if passkeys {
+		types = append(types, models.MfaFactorTypePasskey)
+	}
+
+	if len(types) > 0 {
+		remaining, err := CountRecoveryCodes(db, userId)
+		if err != nil {
+			return nil, err
+		}
+		if remaining > 0 {
+			types = append(types, models.MfaFactorTypeRecoveryCode)
+		}
+	}
+	return types, nil
+}
+
+func ListMfaFactors(db *gorm.DB, userId string) ([]models.MfaFactor, error) {
+	var factors []models.MfaFactor
+	result := db.Where("user_id = ? AND confirmed_at IS NOT NULL", userId).Order("created_at ASC").Find(&factors)
+	return factors, result.Error
+}
+
+// countMfaAttempt records a guess against the flow. once the limit is
+// reached the flow is dropped and the user has to sign in again
+func countMfaAttempt(db *gorm.DB, flow *models.AuthenticationFlow) error {
+	result := db.Model(&models.AuthenticationFlow{}).
+		Where("id = ? AND attempts < ?", flow.ID, mfaMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		db.Delete(flow)
+		return errors.New(string(MfaErrorTooManyAttempts))
+	}
+	flow.Attempts++
+	return nil
+}
+
+// wrongMfaCode is the error for a failed guess, dropping the flow when it was
+// the last one allowed
+func wrongMfaCode(db *gorm.DB, flow *models.AuthenticationFlow) error {
+	if flow.Attempts >= mfaMaxAttempts {
+		db.Delete(flow)
+		return errors.New(string(MfaErrorTooManyAttempts))
+	}
+	return errors.New(string(MfaErrorInvalidCode))
+}
+
+// MfaVerification answers the second factor step, either with a code or
+// with a passkey assertion
+type MfaVerification struct {
+	FlowToken  string
+	FactorType string
+	Code       string
+	Passkey    *PasskeyAssertion
+	// language of the alert sent when a recovery code is used
+	Locale string
+}
+
+// VerifyMfa completes the second factor step of a sign in. the session the
+// resulting code belongs to is at assurance level 2
+func VerifyMfa(db *gorm.DB, mailer mail.Mailer, input MfaVerification) (*AuthorizationResult, error) {
+	flow, err := GetAuthenticationFlow(db, input.FlowToken, models.AuthenticationFlowStepMfa)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := countMfaAttempt(db, flow); err != nil {
+		return nil, err
+	}
+
+	switch input.FactorType {
+	case models.MfaFactorTypeTotp:
+		err = verifyTotpFactor(db, flow.UserId, input.Code)
+	case models.MfaFactorTypePasskey:
+		err = verifyPasskeyFactor(db, flow, input.Passkey)
+	case models.MfaFactorTypeRecoveryCode:
+		err = verifyRecoveryCode(db, mailer, flow.UserId, input.Code, input.Locale)
+	default:
+		return nil, errors.New(string(MfaErrorUnsupportedType))
+	}
+	if err != nil {
+		if err.Error() == string(MfaErrorInvalidCode) {
+			return nil, wrongMfaCode(db, flow)
+		}
+		return nil, err
+	}
+
+	// the sign in is only complete now, so that is when the user authenticated
+	flow.Aal = 2
+	flow.AuthTime = time.Now()
+	flow.Amr = appendAmr(flow.Amr, factorAmr(input.FactorType), "mfa")
+	return continueAuthenticationFlow(db, flow)
+}
+
+// MfaSummary is what admins see of a user's second factors
+type MfaSummary struct {
+	Factors                []models.MfaFactor
+	Passkeys               int64
+	RecoveryCodesRemaining int64
+}
+
+func GetMfaSummary(db *gorm.DB, userId string) (*MfaSummary, error) {
+	var users int64
+	if err := db.Model(&models.User{}).Where("id = ?", userId).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users == 0 {
+		return nil, errors.New(string(MfaErrorUserNotFound))
+	}
+
+	factors, err := ListMfaFactors(db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := MfaSummary{Factors: factors}
+	if err := db.Model(&models.PasskeyCredential{}).Where("user_id = ?", userId).Count(&summary.Passkeys).Error; err != nil {
+		return nil, err
+	}
+	if summary.RecoveryCodesRemaining, err = CountRecoveryCodes(db, userId); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// requireAal2 makes sure the request comes from a session that used a
+// second factor, for changes that would weaken the account
+func requireAal2(db *gorm.DB, sessionId string) error {
+	if sessionId == "" || sessionAal(db, sessionId) < 2 {
+		return errors.New(string(MfaErrorStepUpRequired))
+	}
+	return nil
+}
+
+func touchMfaFactor(db *gorm.DB, factor *models.MfaFactor) {
+	db.Model(factor).Update("last_used_at", time.Now())
+}
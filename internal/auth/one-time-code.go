@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type OneTimeCodeError string
+
+const (
+	OneTimeCodeErrorThrottled       OneTimeCodeError = "too many sign in codes"
+	OneTimeCodeErrorInvalidCode     OneTimeCodeError = "invalid or expired sign in code"
+	OneTimeCodeErrorTooManyAttempts OneTimeCodeError = "too many wrong sign in codes"
+)
+
+const (
+	oneTimeCodeDigits          = 6
+	oneTimeCodeDurationSeconds = 60 * 10
+	// wrong guesses allowed before a code stops working
+	oneTimeCodeMaxAttempts = 5
+)
+
+// a new code can be sent once a minute and at most five times an hour
+const (
+	oneTimeCodeResendInterval = time.Minute
+	oneTimeCodeHourlyLimit    = 5
+)
+
+func isOneTimeCodeThrottled(db *gorm.DB, channel string, clientId string, recipient string) (bool, error) {
+	now := time.Now()
+
+	var lastHour []models.OneTimeCode
+	result := db.Where("client_id = ? AND channel = ? AND recipient = ? AND created_at > ?", clientId, channel, recipient, now.Add(-time.Hour)).
+		Order("created_at DESC").Find(&lastHour)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if len(lastHour) >= oneTimeCodeHourlyLimit {
+		return true, nil
+	}
+	if len(lastHour) > 0 && lastHour[0].CreatedAt.After(now.Add(-oneTimeCodeResendInterval)) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// issueOneTimeCode stores a new code for recipient and returns it so it can
+// be sent. only the hash is kept and earlier codes for the recipient stop
+// working
+func issueOneTimeCode(db *gorm.DB, channel string, clientId string, recipient string, params AuthorizationParams) (string, error) {
+	throttled, err := isOneTimeCodeThrottled(db, channel, clientId, recipient)
+	if err != nil {
+		return "", err
+	}
+	if throttled {
+		return "", errors.New(string(OneTimeCodeErrorThrottled))
+	}
+
+	code := crypto.GenerateNumericCode(oneTimeCodeDigits)
+	salt := crypto.GenerateSecureSecret()
+	now := time.Now()
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OneTimeCode{}).
+			Where("client_id = ? AND channel = ? AND recipient = ? AND used_at IS NULL", clientId, channel, recipient).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.OneTimeCode{
+			ClientId:            clientId,
+			Channel:             channel,
+			Recipient:           recipient,
+			Salt:                salt,
+			CodeHash:            crypto.HashCode(salt, code),
+			CodeChallenge:       params.CodeChallenge,
+			CodeChallengeMethod: params.CodeChallengeMethod,
+			Scopes:              pq.StringArray(params.Scopes),
+			Resources:           pq.StringArray(params.Resources),
+			AcrValues:           pq.StringArray(params.AcrValues),
+			State:               params.State,
+			ExpiresAt:           now.Add(oneTimeCodeDurationSeconds * time.Second),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// redeemOneTimeCode checks code against the latest one sent to recipient and
+// consumes it when it matches
+func redeemOneTimeCode(db *gorm.DB, channel string, clientId string, recipient string, code string) (*models.OneTimeCode, error) {
+	now := time.Now()
+
+	var record models.OneTimeCode
+	result := db.Where("client_id = ? AND channel = ? AND recipient = ? AND used_at IS NULL AND expires_at > ?", clientId, channel, recipient, now).
+		Order("created_at DESC").Limit(1).Find(&record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(OneTimeCodeErrorInvalidCode))
+	}
+
+	// count the attempt before comparing so parallel guesses can't go over the limit
+	result = db.Model(&models.OneTimeCode{}).
+		Where("id = ? AND attempts < ?", record.ID, oneTimeCodeMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(OneTimeCodeErrorTooManyAttempts))
+	}
+
+	given := crypto.HashCode(record.Salt, strings.Trim(code, " "))
+	if subtle.ConstantTimeCompare([]byte(given), []byte(record.CodeHash)) != 1 {
+		if record.Attempts+1 >= oneTimeCodeMaxAttempts {
+			return nil, errors.New(string(OneTimeCodeErrorTooManyAttempts))
+		}
+		return nil, errors.New(string(OneTimeCodeErrorInvalidCode))
+	}
+
+	// claim the code in one statement so it can only be used once
+	result = db.Model(&models.OneTimeCode{}).
+		Where("id = ? AND used_at IS NULL", record.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(OneTimeCodeErrorInvalidCode))
+	}
+
+	return &record, nil
+}
+
+func oneTimeCodeAuthorizationParams(record *models.OneTimeCode) AuthorizationParams {
+	return AuthorizationParams{
+		CodeChallenge:       record.CodeChallenge,
+		CodeChallengeMethod: record.CodeChallengeMethod,
+		Scopes:              record.Scopes,
+		Resources:           record.Resources,
+		AcrValues:           record.AcrValues,
+		State:               record.State,
+	}
+}
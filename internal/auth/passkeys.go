@@ -0,0 +1,589 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/webauthn"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type PasskeyError string
+
+const (
+	PasskeyErrorProviderDisabled    PasskeyError = "passkeys not enabled for client"
+	PasskeyErrorRelyingPartyMissing PasskeyError = "passkey relying party not configured for client"
+	PasskeyErrorInvalidChallenge    PasskeyError = "invalid, used or expired passkey challenge"
+	PasskeyErrorUnknownCredential   PasskeyError = "unknown passkey"
+	PasskeyErrorAlreadyRegistered   PasskeyError = "passkey already registered"
+	PasskeyErrorVerificationFailed  PasskeyError = "passkey verification failed"
+	PasskeyErrorCloned              PasskeyError = "passkey signature counter went backwards"
+	PasskeyErrorNotFound            PasskeyError = "passkey not found"
+	PasskeyErrorInvalidName         PasskeyError = "invalid passkey name"
+)
+
+const passkeyProviderOptionId = "passkey"
+
+const passkeyChallengeDurationSeconds = 60 * 5
+
+const passkeyDefaultName = "Passkey"
+
+// PasskeyOptions are handed to navigator.credentials.create or get as the
+// publicKey member
+type PasskeyOptions = map[string]interface{}
+
+// PasskeyAssertion is the response of navigator.credentials.get, every
+// field base64url encoded
+type PasskeyAssertion struct {
+	CredentialId      string
+	ClientDataJSON    string
+	AuthenticatorData string
+	Signature         string
+	// optional, discoverable credentials return the user id they were made for
+	UserHandle string
+}
+
+type relyingParty struct {
+	Id      string
+	Name    string
+	Origins []string
+}
+
+// passkeyRelyingParty reads the webauthn settings of a client. rp_id and
+// origins in the provider data win over the client's allowed origins, whose
+// first entry's host is the default relying party id
+func passkeyRelyingParty(clientProvider *models.ClientProvider) (*relyingParty, error) {
+	rp := relyingParty{Name: clientProvider.Client.Name}
+
+	if origins, ok := clientProvider.Data["origins"].([]interface{}); ok {
+		for _, origin := range origins {
+			if value, ok := origin.(string); ok {
+				rp.Origins = append(rp.Origins, strings.TrimRight(value, "/"))
+			}
+		}
+	} else {
+		for _, origin := range clientProvider.Client.AllowedOrigins {
+			rp.Origins = append(rp.Origins, strings.TrimRight(origin, "/"))
+		}
+	}
+
+	rp.Id, _ = clientProvider.Data["rp_id"].(string)
+	if rp.Id == "" && len(rp.Origins) > 0 {
+		if origin, err := url.Parse(rp.Origins[0]); err == nil {
+			rp.Id = origin.Hostname()
+		}
+	}
+
+	if rp.Id == "" || len(rp.Origins) == 0 {
+		return nil, errors.New(string(PasskeyErrorRelyingPartyMissing))
+	}
+	return &rp, nil
+}
+
+func enabledPasskeyProvider(db *gorm.DB, clientId string) (*models.ClientProvider, *relyingParty, error) {
+	clientProvider, ok := getEnabledClientProvider(db, clientId, passkeyProviderOptionId)
+	if !ok {
+		return nil, nil, errors.New(string(PasskeyErrorProviderDisabled))
+	}
+	rp, err := passkeyRelyingParty(clientProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+	return clientProvider, rp, nil
+}
+
+func createPasskeyChallenge(db *gorm.DB, record models.PasskeyChallenge) (string, error) {
+	challenge := webauthn.NewChallenge()
+	record.ChallengeHash = crypto.HashSecret(challenge)
+	record.ExpiresAt = time.Now().Add(passkeyChallengeDurationSeconds * time.Second)
+
+	if err := db.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// claimPasskeyChallenge consumes the challenge a webauthn response answers.
+// scope narrows down which challenges qualify, like the user or flow
+func claimPasskeyChallenge(db *gorm.DB, clientDataJSON []byte, ceremony string, scope func(*gorm.DB) *gorm.DB) (*models.PasskeyChallenge, string, error) {
+	challenge, err := webauthn.ClientDataChallenge(clientDataJSON)
+	if err != nil || challenge == "" {
+		return nil, "", errors.New(string(PasskeyErrorInvalidChallenge))
+	}
+
+	now := time.Now()
+	query := db.Model(&models.PasskeyChallenge{}).
+		Where("challenge_hash = ? AND ceremony = ? AND used_at IS NULL AND expires_at > ?", crypto.HashSecret(challenge), ceremony, now)
+	result := scope(query).Update("used_at", now)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", errors.New(string(PasskeyErrorInvalidChallenge))
+	}
+
+	var record models.PasskeyChallenge
+	if err := db.First(&record, "challenge_hash = ?", crypto.HashSecret(challenge)).Error; err != nil {
+		return nil, "", err
+	}
+	return &record, challenge, nil
+}
+
+func credentialDescriptors(credentials []models.PasskeyCredential) []map[string]interface{} {
+	descriptors := []map[string]interface{}{}
+	for _, credential := range credentials {
+		descriptors = append(descriptors, map[string]interface{}{
+			"type": "public-key",
+			"id":   credential.CredentialId,
+		})
+	}
+	return descriptors
+}
+
+func userPasskeys(db *gorm.DB, userId string) ([]models.PasskeyCredential, error) {
+	var credentials []models.PasskeyCredential
+	result := db.Where("user_id = ?", userId).Order("created_at ASC").Find(&credentials)
+	return credentials, result.Error
+}
+
+// StartPasskeyRegistration returns the options for creating a passkey for a
+// signed in user. passkeys are made discoverable so they can sign in without
+// typing an email first
+func StartPasskeyRegistration(db *gorm.DB, userId string) (PasskeyOptions, error) {
+	var user models.User
+	if err := db.First(&user, "id = ?", userId).Error; err != nil {
+		return nil, err
+	}
+
+	_, rp, err := enabledPasskeyProvider(db, user.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := userPasskeys(db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := createPasskeyChallenge(db, models.PasskeyChallenge{
+		ClientId: user.ClientId,
+		UserId:   &user.ID,
+		Ceremony: models.PasskeyCeremonyRegistration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := []map[string]interface{}{}
+	for _, algorithm := range webauthn.SupportedAlgorithms {
+		params = append(params, map[string]interface{}{"type": "public-key", "alg": algorithm})
+	}
+
+	account := totpAccountName(db, &user)
+	return PasskeyOptions{
+		"challenge": challenge,
+		"rp":        map[string]interface{}{"id": rp.Id, "name": rp.Name},
+		"user": map[string]interface{}{
+			"id":          webauthn.EncodeBase64Url([]byte(user.ID)),
+			"name":        account,
+			"displayName": account,
+		},
+		"pubKeyCredParams": params,
+		"timeout":          passkeyChallengeDurationSeconds * 1000,
+		"attestation":      "none",
+		"authenticatorSelection": map[string]interface{}{
+			"residentKey":        "required",
+			"requireResidentKey": true,
+			"userVerification":   "preferred",
+		},
+		"excludeCredentials": credentialDescriptors(existing),
+	}, nil
+}
+
+func decodePasskeyFields(values ...string) ([][]byte, error) {
+	decoded := make([][]byte, len(values))
+	for i, value := range values {
+		data, err := webauthn.DecodeBase64Url(value)
+		if err != nil {
+			return nil, errors.New(string(PasskeyErrorVerificationFailed))
+		}
+		decoded[i] = data
+	}
+	return decoded, nil
+}
+
+func validPasskeyName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	return name, name != "" && len(name) <= 64
+}
+
+// ensurePasskeyIdentity gives users with passkeys an identity for the
+// passkey provider, so passkey sign ins go through the same flow as others
+func ensurePasskeyIdentity(db *gorm.DB, clientProvider *models.ClientProvider, userId string) (*models.Identity, error) {
+	identity, err := findIdentity(db, clientProvider.ClientId, passkeyProviderOptionId, userId)
+	if err == nil {
+		return identity, nil
+	}
+
+	created := models.Identity{
+		ClientId:         clientProvider.ClientId,
+		UserId:           userId,
+		ProviderSub:      userId,
+		ProviderOptionId: passkeyProviderOptionId,
+		ClientProviderId: clientProvider.ID,
+		Data:             models.JsonDictionary{},
+	}
+	if err := db.Create(&created).Error; err != nil {
+		return nil, err
+	}
+	return findIdentity(db, clientProvider.ClientId, passkeyProviderOptionId, userId)
+}
+
+// FinishPasskeyRegistration checks the response of navigator.credentials.create
+// and stores the new passkey. users for whom it is the first second factor
+// also get their recovery codes
+func FinishPasskeyRegistration(db *gorm.DB, userId string, clientDataJSON string, attestationObject string, name string) (*models.PasskeyCredential, []string, error) {
+	if name == "" {
+		name = passkeyDefaultName
+	}
+	name, ok := validPasskeyName(name)
+	if !ok {
+		return nil, nil, errors.New(string(PasskeyErrorInvalidName))
+	}
+
+	decoded, err := decodePasskeyFields(clientDataJSON, attestationObject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, challenge, err := claimPasskeyChallenge(db, decoded[0], models.PasskeyCeremonyRegistration, func(query *gorm.DB) *gorm.DB {
+		return query.Where("user_id = ?", userId)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientProvider, rp, err := enabledPasskeyProvider(db, record.ClientId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verified, err := webauthn.VerifyRegistration(decoded[0], decoded[1], webauthn.Expectation{
+		Challenge: challenge,
+		RpId:      rp.Id,
+		Origins:   rp.Origins,
+	})
+	if err != nil {
+		return nil, nil, errors.New(string(PasskeyErrorVerificationFailed))
+	}
+
+	credential := models.PasskeyCredential{
+		UserId:         userId,
+		ClientId:       record.ClientId,
+		CredentialId:   webauthn.EncodeBase64Url(verified.Id),
+		PublicKey:      verified.PublicKey,
+		Algorithm:      verified.Algorithm,
+		SignCount:      int64(verified.SignCount),
+		Name:           name,
+		BackupEligible: verified.BackupEligible,
+	}
+
+	var recoveryCodes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&models.PasskeyCredential{}).Where("credential_id = ?", credential.CredentialId).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return errors.New(string(PasskeyErrorAlreadyRegistered))
+		}
+
+		if err := tx.Create(&credential).Error; err != nil {
+			return err
+		}
+		if _, err := ensurePasskeyIdentity(tx, clientProvider, userId); err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = issueInitialRecoveryCodes(tx, userId)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &credential, recoveryCodes, nil
+}
+
+// checkPasskeyAssertion verifies an assertion against a stored passkey and
+// moves its signature counter forward
+func checkPasskeyAssertion(db *gorm.DB, credential *models.PasskeyCredential, rp *relyingParty, challenge string, decoded [][]byte, requireUserVerification bool) (*webauthn.Assertion, error) {
+	assertion, err := webauthn.VerifyAssertion(decoded[0], decoded[1], decoded[2], credential.PublicKey, credential.Algorithm, webauthn.Expectation{
+		Challenge:               challenge,
+		RpId:                    rp.Id,
+		Origins:                 rp.Origins,
+		RequireUserVerification: requireUserVerification,
+	})
+	if err != nil {
+		return nil, errors.New(string(PasskeyErrorVerificationFailed))
+	}
+
+	if !webauthn.IsSignCountValid(credential.SignCount, assertion.SignCount) {
+		return nil, errors.New(string(PasskeyErrorCloned))
+	}
+	signCount := int64(assertion.SignCount)
+
+	result := db.Model(&models.PasskeyCredential{}).
+		Where("id = ? AND sign_count = ?", credential.ID, credential.SignCount).
+		Updates(map[string]interface{}{"sign_count": signCount, "last_used_at": time.Now()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(PasskeyErrorCloned))
+	}
+
+	return assertion, nil
+}
+
+// StartPasskeySignIn returns the options for a passwordless sign in. no
+// credentials are listed, the authenticator offers the discoverable ones it
+// has for the relying party
+func StartPasskeySignIn(db *gorm.DB, clientId string, params AuthorizationParams) (PasskeyOptions, error) {
+	_, rp, err := enabledPasskeyProvider(db, clientId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCodeChallenge(params.CodeChallenge, params.CodeChallengeMethod); err != nil {
+		return nil, err
+	}
+
+	challenge, err := createPasskeyChallenge(db, models.PasskeyChallenge{
+		ClientId:            clientId,
+		Ceremony:            models.PasskeyCeremonySignIn,
+		CodeChallenge:       params.CodeChallenge,
+		CodeChallengeMethod: params.CodeChallengeMethod,
+		Scopes:              pq.StringArray(params.Scopes),
+		Resources:           pq.StringArray(params.Resources),
+		State:               params.State,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return PasskeyOptions{
+		"challenge":        challenge,
+		"rpId":             rp.Id,
+		"timeout":          passkeyChallengeDurationSeconds * 1000,
+		"userVerification": "required",
+		"allowCredentials": []map[string]interface{}{},
+	}, nil
+}
+
+// FinishPasskeySignIn checks the response of navigator.credentials.get and
+// continues the authorization request the sign in started with. the
+// authenticator verified the user, so the session starts at assurance level 2
+func FinishPasskeySignIn(db *gorm.DB, clientId string, input PasskeyAssertion) (*AuthorizationResult, error) {
+	decoded, err := decodePasskeyFields(input.ClientDataJSON, input.AuthenticatorData, input.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	record, challenge, err := claimPasskeyChallenge(db, decoded[0], models.PasskeyCeremonySignIn, func(query *gorm.DB) *gorm.DB {
+		return query.Where("client_id = ?", clientId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientProvider, rp, err := enabledPasskeyProvider(db, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	var credential models.PasskeyCredential
+	result := db.Limit(1).Find(&credential, "client_id = ? AND credential_id = ?", clientId, strings.TrimRight(input.CredentialId, "="))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(PasskeyErrorUnknownCredential))
+	}
+	if input.UserHandle != "" {
+		userHandle, err := webauthn.DecodeBase64Url(input.UserHandle)
+		if err != nil || string(userHandle) != credential.UserId {
+			return nil, errors.New(string(PasskeyErrorUnknownCredential))
+		}
+	}
+
+	if _, err := checkPasskeyAssertion(db, &credential, rp, challenge, decoded, true); err != nil {
+		return nil, err
+	}
+
+	// passkeys of deleted accounts are kept until the purge in case they
+	// are restored
+	var users int64
+	if err := db.Model(&models.User{}).Where("id = ?", credential.UserId).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users == 0 {
+		return nil, errors.New(string(PasskeyErrorUnknownCredential))
+	}
+
+	identity, err := ensurePasskeyIdentity(db, clientProvider, credential.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompleteAuthorization(db, identity, AuthorizationParams{
+		CodeChallenge:       record.CodeChallenge,
+		CodeChallengeMethod: record.CodeChallengeMethod,
+		Scopes:              record.Scopes,
+		Resources:           record.Resources,
+		State:               record.State,
+		Aal:                 2,
+	})
+}
+
+// hasUsablePasskeys tells whether a user can answer a second factor step
+// with a passkey, which needs passkeys to still be enabled for their client
+func hasUsablePasskeys(db *gorm.DB, userId string) (bool, error) {
+	var credential models.PasskeyCredential
+	result := db.Where("user_id = ?", userId).Limit(1).Find(&credential)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	_, _, err := enabledPasskeyProvider(db, credential.ClientId)
+	return err == nil, nil
+}
+
+// StartPasskeyMfa returns the options for answering the second factor step
+// of a sign in with one of the user's passkeys
+func StartPasskeyMfa(db *gorm.DB, flowToken string) (PasskeyOptions, error) {
+	flow, err := GetAuthenticationFlow(db, flowToken, models.AuthenticationFlowStepMfa, models.AuthenticationFlowStepReauthenticate)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rp, err := enabledPasskeyProvider(db, flow.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := userPasskeys(db, flow.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) == 0 {
+		return nil, errors.New(string(MfaErrorUnsupportedType))
+	}
+
+	challenge, err := createPasskeyChallenge(db, models.PasskeyChallenge{
+		ClientId: flow.ClientId,
+		UserId:   &flow.UserId,
+		FlowId:   &flow.ID,
+		Ceremony: models.PasskeyCeremonyMfa,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return PasskeyOptions{
+		"challenge":        challenge,
+		"rpId":             rp.Id,
+		"timeout":          passkeyChallengeDurationSeconds * 1000,
+		"userVerification": "preferred",
+		"allowCredentials": credentialDescriptors(credentials),
+	}, nil
+}
+
+// verifyPasskeyFactor checks a passkey assertion made for the second factor
+// step of flow
+func verifyPasskeyFactor(db *gorm.DB, flow *models.AuthenticationFlow, input *PasskeyAssertion) error {
+	if input == nil {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	decoded, err := decodePasskeyFields(input.ClientDataJSON, input.AuthenticatorData, input.Signature)
+	if err != nil {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	_, challenge, err := claimPasskeyChallenge(db, decoded[0], models.PasskeyCeremonyMfa, func(query *gorm.DB) *gorm.DB {
+		return query.Where("flow_id = ?", flow.ID)
+	})
+	if err != nil {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	_, rp, err := enabledPasskeyProvider(db, flow.ClientId)
+	if err != nil {
+		return err
+	}
+
+	var credential models.PasskeyCredential
+	result := db.Limit(1).Find(&credential, "user_id = ? AND credential_id = ?", flow.UserId, strings.TrimRight(input.CredentialId, "="))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	if _, err := checkPasskeyAssertion(db, &credential, rp, challenge, decoded, false); err != nil {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+	return nil
+}
+
+func ListPasskeys(db *gorm.DB, userId string) ([]models.PasskeyCredential, error) {
+	return userPasskeys(db, userId)
+}
+
+func RenamePasskey(db *gorm.DB, userId string, passkeyId string, name string) (*models.PasskeyCredential, error) {
+	name, ok := validPasskeyName(name)
+	if !ok {
+		return nil, errors.New(string(PasskeyErrorInvalidName))
+	}
+
+	var credential models.PasskeyCredential
+	result := db.Limit(1).Find(&credential, "id = ? AND user_id = ?", passkeyId, userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(PasskeyErrorNotFound))
+	}
+
+	if err := db.Model(&credential).Update("name", name).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// RemovePasskey deletes one of the user's passkeys. passkeys are second
+// factors, so like authenticator apps only sessions signed in with a second
+// factor may remove them
+func RemovePasskey(db *gorm.DB, userId string, sessionId string, passkeyId string) error {
+	if err := requireAal2(db, sessionId); err != nil {
+		return err
+	}
+
+	result := db.Where("id = ? AND user_id = ?", passkeyId, userId).Delete(&models.PasskeyCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(PasskeyErrorNotFound))
+	}
+	return dropUnneededRecoveryCodes(db, userId)
+}
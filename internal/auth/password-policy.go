@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sentinel-auth-backend/internal/breach"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordPolicyError string
+
+const (
+	PasswordPolicyErrorClientNotFound PasswordPolicyError = "client not found"
+	PasswordPolicyErrorInvalidPolicy  PasswordPolicyError = "invalid password policy"
+)
+
+// PasswordRule names a requirement of a password policy
+type PasswordRule string
+
+const (
+	PasswordRuleMinLength  PasswordRule = "min_length"
+	PasswordRuleMaxLength  PasswordRule = "max_length"
+	PasswordRuleUppercase  PasswordRule = "uppercase"
+	PasswordRuleLowercase  PasswordRule = "lowercase"
+	PasswordRuleDigit      PasswordRule = "digit"
+	PasswordRuleSymbol     PasswordRule = "symbol"
+	PasswordRuleStrength   PasswordRule = "strength"
+	PasswordRuleBannedWord PasswordRule = "banned_word"
+	PasswordRuleReused     PasswordRule = "reused"
+	PasswordRuleBreached   PasswordRule = "breached"
+)
+
+type PasswordRuleFailure struct {
+	Rule        PasswordRule
+	Description string
+}
+
+// WeakPasswordError lists every rule of the client's policy a password broke
+type WeakPasswordError struct {
+	Failures []PasswordRuleFailure
+}
+
+func (e *WeakPasswordError) Error() string {
+	return "weak password"
+}
+
+// longer passwords are refused whatever the policy says, hashing huge ones
+// would be an easy way to tie up the server
+const passwordMaxBytes = 1024
+
+// highest max length a policy can set
+const passwordMaxLengthLimit = 256
+
+// most passwords kept per identity, and so the largest history size
+const passwordHistoryLimit = 24
+
+func defaultPasswordPolicy(clientId string) *models.PasswordPolicy {
+	return &models.PasswordPolicy{
+		ClientId:    clientId,
+		MinLength:   8,
+		MaxLength:   64,
+		MinStrength: 2,
+		BannedWords: []string{},
+		BreachMode:  models.PasswordBreachModeBlock,
+	}
+}
+
+// GetPasswordPolicy returns the client's policy, or the default one when it
+// never set one
+func GetPasswordPolicy(db *gorm.DB, clientId string) (*models.PasswordPolicy, error) {
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(PasswordPolicyErrorClientNotFound))
+	}
+	return findPasswordPolicy(db, clientId)
+}
+
+func findPasswordPolicy(db *gorm.DB, clientId string) (*models.PasswordPolicy, error) {
+	var policy models.PasswordPolicy
+	result := db.Limit(1).Find(&policy, "client_id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return defaultPasswordPolicy(clientId), nil
+	}
+	return &policy, nil
+}
+
+type PasswordPolicyInput struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	MinStrength      int
+	BannedWords      []string
+	HistorySize      int
+	BreachMode       string
+}
+
+// SetPasswordPolicy replaces the client's password policy. passwords already
+// set aren't checked again, the policy applies the next time one is chosen
+func SetPasswordPolicy(db *gorm.DB, clientId string, input PasswordPolicyInput) (*models.PasswordPolicy, error) {
+	if input.MinLength < 1 || input.MaxLength < input.MinLength || input.MaxLength > passwordMaxLengthLimit ||
+		input.MinStrength < 0 || input.MinStrength > 4 ||
+		input.HistorySize < 0 || input.HistorySize > passwordHistoryLimit ||
+		(input.BreachMode != models.PasswordBreachModeBlock && input.BreachMode != models.PasswordBreachModeWarn && input.BreachMode != models.PasswordBreachModeOff) {
+		return nil, errors.New(string(PasswordPolicyErrorInvalidPolicy))
+	}
+
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(PasswordPolicyErrorClientNotFound))
+	}
+
+	bannedWords := []string{}
+	for _, word := range input.BannedWords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			bannedWords = append(bannedWords, word)
+		}
+	}
+
+	policy := models.PasswordPolicy{
+		ClientId:         clientId,
+		MinLength:        input.MinLength,
+		MaxLength:        input.MaxLength,
+		RequireUppercase: input.RequireUppercase,
+		RequireLowercase: input.RequireLowercase,
+		RequireDigit:     input.RequireDigit,
+		RequireSymbol:    input.RequireSymbol,
+		MinStrength:      input.MinStrength,
+		BannedWords:      bannedWords,
+		HistorySize:      input.HistorySize,
+		BreachMode:       input.BreachMode,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"min_length", "max_length", "require_uppercase", "require_lowercase", "require_digit",
+			"require_symbol", "min_strength", "banned_words", "history_size", "breach_mode", "updated_at",
+		}),
+	}).Create(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// words a user's password may not contain besides the policy's own: the
+// email, its local part and the client's name
+func passwordUserWords(client *models.Client, email string) []string {
+	words := []string{}
+	if email != "" {
+		words = append(words, email)
+		local, _, _ := strings.Cut(email, "@")
+		words = append(words, local)
+	}
+	words = append(words, client.Name)
+	for _, word := range strings.FieldsFunc(client.Name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) >= 4 {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// checkPasswordPolicy checks password against the client's policy. identityId
+// is the email identity the password is for, if it exists yet, to look up the
+// passwords it had before. rules the policy only warns about are returned
+// when the password is accepted
+func checkPasswordPolicy(db *gorm.DB, client *models.Client, identityId string, email string, password string) ([]PasswordRuleFailure, error) {
+	policy, err := findPasswordPolicy(db, client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []PasswordRuleFailure{}
+	fail := func(rule PasswordRule, description string) {
+		failures = append(failures, PasswordRuleFailure{Rule: rule, Description: description})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		fail(PasswordRuleMinLength, fmt.Sprintf("Use at least %d characters", policy.MinLength))
+	}
+	if length > policy.MaxLength || len(password) > passwordMaxBytes {
+		fail(PasswordRuleMaxLength, fmt.Sprintf("Use at most %d characters", policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUppercase && !hasUpper {
+		fail(PasswordRuleUppercase, "Use at least one uppercase letter")
+	}
+	if policy.RequireLowercase && !hasLower {
+		fail(PasswordRuleLowercase, "Use at least one lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		fail(PasswordRuleDigit, "Use at least one digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		fail(PasswordRuleSymbol, "Use at least one symbol")
+	}
+
+	userWords := passwordUserWords(client, email)
+	if _, found := validators.ContainsWord(password, append(userWords, policy.BannedWords...)); found {
+		fail(PasswordRuleBannedWord, "Don't use your email, the app's name or other banned words")
+	}
+
+	if validators.PasswordStrength(password, append(userWords, policy.BannedWords...)) < policy.MinStrength {
+		fail(PasswordRuleStrength, "Too easy to guess, add more words or uncommon characters")
+	}
+
+	if identityId != "" && policy.HistorySize > 0 {
+		reused, err := isPasswordReused(db, identityId, password, policy.HistorySize)
+		if err != nil {
+			return nil, err
+		}
+		if reused {
+			fail(PasswordRuleReused, fmt.Sprintf("Don't reuse any of your last %d passwords", policy.HistorySize))
+		}
+	}
+
+	warnings := []PasswordRuleFailure{}
+	if policy.BreachMode != models.PasswordBreachModeOff {
+		// a broken dataset shouldn't stop everyone from choosing passwords
+		count, err := breach.Count(password)
+		if err != nil {
+			log.Println("failed to screen password against breaches:", err)
+		}
+		if count > 0 {
+			breached := PasswordRuleFailure{Rule: PasswordRuleBreached, Description: "This password appeared in a data breach, choose one you haven't used elsewhere"}
+			if policy.BreachMode == models.PasswordBreachModeBlock {
+				failures = append(failures, breached)
+			} else {
+				warnings = append(warnings, breached)
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, &WeakPasswordError{Failures: failures}
+	}
+	return warnings, nil
+}
+
+func isPasswordReused(db *gorm.DB, identityId string, password string, historySize int) (bool, error) {
+	var history []models.PasswordHistory
+	err := db.Where("identity_id = ?", identityId).Order("created_at DESC").Limit(historySize).Find(&history).Error
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range history {
+		if matches, _ := CompareHashAndPassword(entry.PasswordHash, password); matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPasswordHistory remembers the hash of a password an identity was
+// given, keeping as many as the largest history a policy can ask for
+func recordPasswordHistory(db *gorm.DB, identityId string, hash string) error {
+	if err := db.Create(&models.PasswordHistory{IdentityId: identityId, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	return db.Where("identity_id = ? AND id NOT IN (?)", identityId,
+		db.Model(&models.PasswordHistory{}).Select("id").
+			Where("identity_id = ?", identityId).
+			Order("created_at DESC").
+			Limit(passwordHistoryLimit),
+	).Delete(&models.PasswordHistory{}).Error
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"reflect"
+	"sentinel-auth-backend/internal/breach"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+var testPolicyClient = &models.Client{ID: "app", Name: "Sentinel Demo"}
+
+func passwordRules(failures []PasswordRuleFailure) []PasswordRule {
+	rules := []PasswordRule{}
+	for _, failure := range failures {
+		rules = append(rules, failure.Rule)
+	}
+	return rules
+}
+
+// withBreachedPasswords loads a dataset of passwords for the test. the filter
+// is sized for more entries so other passwords don't match by accident
+func withBreachedPasswords(t *testing.T, passwords ...string) {
+	filter := breach.NewBloomFilter(1000, 0.0001)
+	for _, password := range passwords {
+		filter.AddHash(breach.Hash(password))
+	}
+	breach.SetDataset(filter)
+	t.Cleanup(func() { breach.SetDataset(nil) })
+}
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	withBreachedPasswords(t, "Zq8#rT5!wN3@")
+
+	strict := *defaultPasswordPolicy("app")
+	strict.MinLength = 12
+	strict.RequireUppercase = true
+	strict.RequireLowercase = true
+	strict.RequireDigit = true
+	strict.RequireSymbol = true
+	strict.BannedWords = []string{"acme"}
+
+	warnBreached := *defaultPasswordPolicy("app")
+	warnBreached.BreachMode = models.PasswordBreachModeWarn
+	ignoreBreached := *defaultPasswordPolicy("app")
+	ignoreBreached.BreachMode = models.PasswordBreachModeOff
+
+	tests := []struct {
+		name     string
+		policy   *models.PasswordPolicy
+		password string
+		failures []PasswordRule
+		warnings []PasswordRule
+	}{
+		{"strong", nil, "kX9$mQ2!vL7#pR4w", nil, []PasswordRule{}},
+		{"too short", nil, "kX9$m", []PasswordRule{PasswordRuleMinLength, PasswordRuleStrength}, nil},
+		{"too long", nil, strings.Repeat("kX9$mQ2!", 9), []PasswordRule{PasswordRuleMaxLength}, nil},
+		{"common", nil, "password", []PasswordRule{PasswordRuleStrength}, nil},
+		{"client name", nil, "Sentinel-kX9$mQ2!", []PasswordRule{PasswordRuleBannedWord}, nil},
+		{"email", nil, "john.smith-kX9$mQ2!", []PasswordRule{PasswordRuleBannedWord}, nil},
+		{"character classes", &strict, "kx9mq2vl7pr4wzzz", []PasswordRule{PasswordRuleUppercase, PasswordRuleSymbol}, nil},
+		{"policy banned word", &strict, "Acme-kX9$mQ2!vL7#", []PasswordRule{PasswordRuleBannedWord}, nil},
+		{"all classes", &strict, "kX9$mQ2!vL7#pR4w", nil, []PasswordRule{}},
+		{"breached", nil, "Zq8#rT5!wN3@", []PasswordRule{PasswordRuleBreached}, nil},
+		{"breached warn only", &warnBreached, "Zq8#rT5!wN3@", nil, []PasswordRule{PasswordRuleBreached}},
+		{"breached not screened", &ignoreBreached, "Zq8#rT5!wN3@", nil, []PasswordRule{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := testDb(t)
+			if test.policy != nil {
+				if err := db.Omit(clause.Associations).Create(test.policy).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			warnings, err := checkPasswordPolicy(db, testPolicyClient, "", "john.smith@example.com", test.password)
+			if test.failures == nil {
+				if err != nil {
+					t.Fatalf("refused: %v", err)
+				}
+				if !reflect.DeepEqual(passwordRules(warnings), test.warnings) {
+					t.Errorf("warnings %v, want %v", passwordRules(warnings), test.warnings)
+				}
+				return
+			}
+
+			weak, ok := err.(*WeakPasswordError)
+			if !ok {
+				t.Fatalf("err = %v, want a weak password", err)
+			}
+			if !reflect.DeepEqual(passwordRules(weak.Failures), test.failures) {
+				t.Errorf("failed %v, want %v", passwordRules(weak.Failures), test.failures)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordPolicyHistory(t *testing.T) {
+	withPasswordHashParams(t, testPasswordHashParams)
+	db := testDb(t)
+
+	policy := defaultPasswordPolicy("app")
+	policy.HistorySize = 2
+	if err := db.Omit(clause.Associations).Create(policy).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// oldest first
+	start := time.Now().Add(-time.Hour)
+	for i, password := range []string{"first-kX9$mQ2!vL7#", "second-kX9$mQ2!vL7#", "third-kX9$mQ2!vL7#"} {
+		hash, _ := HashPassword(password)
+		entry := models.PasswordHistory{IdentityId: "identity", PasswordHash: hash, CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := db.Create(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := checkPasswordPolicy(db, testPolicyClient, "identity", "", "second-kX9$mQ2!vL7#")
+	if weak, ok := err.(*WeakPasswordError); !ok || !reflect.DeepEqual(passwordRules(weak.Failures), []PasswordRule{PasswordRuleReused}) {
+		t.Errorf("reusing a recent password: err = %v", err)
+	}
+
+	// only the latest HistorySize passwords count
+	if _, err := checkPasswordPolicy(db, testPolicyClient, "identity", "", "first-kX9$mQ2!vL7#"); err != nil {
+		t.Errorf("password older than the history refused: %v", err)
+	}
+	// new identities have no history yet
+	if _, err := checkPasswordPolicy(db, testPolicyClient, "", "", "third-kX9$mQ2!vL7#"); err != nil {
+		t.Errorf("password without an identity refused: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetError string
+
+const (
+	PasswordResetErrorInvalidClient      PasswordResetError = "client does not exist"
+	PasswordResetErrorInvalidRedirectUri PasswordResetError = "redirect uri is not registered for client"
+	PasswordResetErrorInvalidToken       PasswordResetError = "invalid or expired reset token"
+)
+
+const passwordResetDurationSeconds = 60 * 30
+
+// one reset email a minute per identity, extra requests are silently dropped
+const passwordResetResendInterval = time.Minute
+
+// RequestPasswordReset mails a reset link to an email identity. the link goes
+// to redirectUri, which must be registered for the client, or to the client's
+// first redirect uri. it succeeds whether or not the email is registered so
+// the endpoint can't be used to find out which emails are
+func RequestPasswordReset(db *gorm.DB, mailer mail.Mailer, clientId string, email string, redirectUri string, locale string) error {
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errors.New(string(PasswordResetErrorInvalidClient))
+	}
+
+	// checked up front so a bad redirect uri fails for unknown emails as well
+	if _, ok := emailLink(&client, redirectUri, ""); !ok {
+		return errors.New(string(PasswordResetErrorInvalidRedirectUri))
+	}
+
+	email = strings.ToLower(strings.Trim(email, " "))
+	identity, err := findIdentity(db, clientId, "email", email)
+	if err != nil {
+		return nil
+	}
+
+	var recent int64
+	err = db.Model(&models.PasswordResetToken{}).
+		Where("identity_id = ? AND created_at > ?", identity.ID, time.Now().Add(-passwordResetResendInterval)).
+		Count(&recent).Error
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token := crypto.GenerateSecureSecret()
+	record := models.PasswordResetToken{
+		IdentityId: identity.ID,
+		TokenHash:  crypto.HashSecret(token),
+		ExpiresAt:  time.Now().Add(passwordResetDurationSeconds * time.Second),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return err
+	}
+
+	link, _ := emailLink(&client, redirectUri, token)
+
+	message, err := mail.Render(&identity.Client, mail.TemplateResetPassword, locale, identity.ProviderSub, mail.Data{
+		"Link":             link,
+		"ExpiresInMinutes": passwordResetDurationSeconds / 60,
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(message)
+}
+
+// ResetPassword consumes a reset token and replaces the identity's password.
+// the user is signed out everywhere since whoever knew the old password may
+// still hold tokens. password rules the client only warns about are returned
+func ResetPassword(db *gorm.DB, token string, password string) ([]PasswordRuleFailure, error) {
+	var warnings []PasswordRuleFailure
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// claim the token in one statement so it can only be used once
+		var record models.PasswordResetToken
+		result := tx.Model(&record).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", crypto.HashSecret(token), now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(string(PasswordResetErrorInvalidToken))
+		}
+
+		if err := tx.First(&record, "token_hash = ?", crypto.HashSecret(token)).Error; err != nil {
+			return err
+		}
+
+		var identity models.Identity
+		if err := tx.Preload("Client").First(&identity, "id = ?", record.IdentityId).Error; err != nil {
+			return errors.New(string(PasswordResetErrorInvalidToken))
+		}
+
+		// a rejected password rolls back the claim, so the link still works
+		var err error
+		warnings, err = checkPasswordPolicy(tx, &identity.Client, identity.ID, identity.ProviderSub, password)
+		if err != nil {
+			return err
+		}
+
+		hash, err := HashPassword(password)
+		if err != nil {
+			return err
+		}
+
+		if identity.Data == nil {
+			identity.Data = models.JsonDictionary{}
+		}
+		identity.Data["password_hash"] = hash
+
+		// following the link proves the user can read the inbox
+		err = tx.Model(&identity).Updates(map[string]interface{}{
+			"data":           identity.Data,
+			"email_verified": true,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(tx, identity.ID, hash); err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.PasswordResetToken{}).
+			Where("identity_id = ? AND used_at IS NULL", identity.ID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		return RevokeUserSessions(tx, identity.UserId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
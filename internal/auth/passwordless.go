@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+type PasswordlessError string
+
+const (
+	PasswordlessErrorAccountDeleted PasswordlessError = "account with this email was deleted and can be restored"
+)
+
+// emailLink points an emailed link at one of the client's pages. redirectUri
+// must be registered for the client and defaults to its first one. token is
+// added as a query parameter
+func emailLink(client *models.Client, redirectUri string, token string) (string, bool) {
+	if redirectUri == "" && len(client.RedirectUris) > 0 {
+		redirectUri = client.RedirectUris[0]
+	}
+	if redirectUri == "" || !slices.Contains(client.RedirectUris, redirectUri) {
+		return "", false
+	}
+
+	link, err := url.Parse(redirectUri)
+	if err != nil {
+		return "", false
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), true
+}
+
+// findOrCreateVerifiedEmailIdentity returns the identity for a passwordless
+// provider once the user proved they own email. users that already signed up
+// with the same email get the provider linked to their account, dropping a
+// password nobody verified the email for, anyone else gets a new account.
+// emails of deleted accounts can't be signed in with until the account is
+// restored or purged
+func findOrCreateVerifiedEmailIdentity(db *gorm.DB, clientProvider *models.ClientProvider, email string) (*models.Identity, error) {
+	var identity *models.Identity
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findIdentity(tx, clientProvider.ClientId, clientProvider.ProviderOptionId, email)
+		if err == nil {
+			identity = existing
+			if !identity.EmailVerified {
+				identity.EmailVerified = true
+				return tx.Model(identity).Update("email_verified", true).Error
+			}
+			return nil
+		}
+
+		var user models.User
+		result := tx.Unscoped().Limit(1).Find(&user, "client_id = ? AND email = ?", clientProvider.ClientId, email)
+		if result.Error != nil {
+			return result.Error
+		}
+		if user.DeletedAt.Valid {
+			return errors.New(string(PasswordlessErrorAccountDeleted))
+		}
+		if result.RowsAffected > 0 {
+			if err := dropUnverifiedPassword(tx, &user); err != nil {
+				return err
+			}
+		} else {
+			user = models.User{
+				ClientId: clientProvider.ClientId,
+				Email:    email,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		}
+
+		identity = &models.Identity{
+			ClientId:         clientProvider.ClientId,
+			UserId:           user.ID,
+			ProviderSub:      email,
+			ProviderOptionId: clientProvider.ProviderOptionId,
+			ClientProviderId: clientProvider.ID,
+			Data:             models.JsonDictionary{},
+			EmailVerified:    true,
+		}
+		if err := tx.Create(identity).Error; err != nil {
+			return err
+		}
+		identity.User = user
+		identity.Client = clientProvider.Client
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// dropUnverifiedPassword verifies the user's email identity now that the
+// owner of the email proved it. a password set before that may have been
+// chosen by someone who registered an email they don't own, so it is
+// dropped and its sessions revoked. the owner can set one with a reset
+func dropUnverifiedPassword(tx *gorm.DB, user *models.User) error {
+	identity, err := findUserEmailIdentity(tx, user)
+	if err != nil {
+		return err
+	}
+	if identity == nil || identity.EmailVerified {
+		return nil
+	}
+
+	_, hadPassword := identity.Data["password_hash"]
+	delete(identity.Data, "password_hash")
+	err = tx.Model(&models.Identity{}).Where("id = ?", identity.ID).
+		Updates(map[string]interface{}{"data": identity.Data, "email_verified": true}).Error
+	if err != nil {
+		return err
+	}
+	if hadPassword {
+		return revokeSessions(tx, user.ID, "")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"sentinel-auth-backend/internal/models"
+	"testing"
+)
+
+var testMagicLinkProvider = &models.ClientProvider{ID: "app-magic-link", ClientId: "app", ProviderOptionId: magicLinkProviderOptionId, Enabled: true}
+
+func TestFindOrCreateVerifiedEmailIdentity(t *testing.T) {
+	db := testDb(t)
+
+	created, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "new@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UserId == "" || created.ProviderSub != "new@example.com" || !created.EmailVerified {
+		t.Errorf("created %+v", created)
+	}
+
+	again, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "new@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != created.ID {
+		t.Error("second sign in made another identity")
+	}
+
+	// users that signed up with a password get the provider linked
+	db.Exec("INSERT INTO users (id, client_id, email) VALUES ('password-user', 'app', 'password@example.com')")
+	linked, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "password@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.UserId != "password-user" {
+		t.Errorf("identity belongs to %s, want the existing user", linked.UserId)
+	}
+}
+
+func TestFindOrCreateVerifiedEmailIdentityRefusesDeletedAccounts(t *testing.T) {
+	db := testDb(t)
+	db.Exec("INSERT INTO users (id, client_id, email, deleted_at) VALUES ('deleted-user', 'app', 'deleted@example.com', CURRENT_TIMESTAMP)")
+	db.Exec(`INSERT INTO identities (client_id, user_id, provider_sub, provider_option_id, deleted_at)
+		VALUES ('app', 'deleted-user', 'deleted@example.com', 'magic_link', CURRENT_TIMESTAMP)`)
+
+	_, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "deleted@example.com")
+	if err == nil || err.Error() != string(PasswordlessErrorAccountDeleted) {
+		t.Fatalf("err = %v, want %s", err, PasswordlessErrorAccountDeleted)
+	}
+
+	var users int64
+	db.Unscoped().Model(&models.User{}).Count(&users)
+	if users != 1 {
+		t.Errorf("%d users, want only the deleted one", users)
+	}
+}
+
+func TestFindOrCreateVerifiedEmailIdentityDropsUnverifiedPassword(t *testing.T) {
+	db := testDb(t)
+	// someone registered the email with a password without proving they own it
+	db.Exec("INSERT INTO users (id, client_id, email) VALUES ('squatted', 'app', 'owner@example.com')")
+	db.Exec(`INSERT INTO identities (id, client_id, user_id, provider_sub, provider_option_id, client_provider_id, data)
+		VALUES ('squatted-email', 'app', 'squatted', 'owner@example.com', 'email', 'app-email', CAST('{"password_hash":"attacker"}' AS blob))`)
+	db.Exec("INSERT INTO sessions (id, user_id, client_id, identity_id) VALUES ('attacker-session', 'squatted', 'app', 'squatted-email')")
+	db.Exec("INSERT INTO refresh_tokens (client_id, user_id, session_id, token) VALUES ('app', 'squatted', 'attacker-session', 'token')")
+
+	linked, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "owner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.UserId != "squatted" {
+		t.Fatalf("identity belongs to %s, want the existing user", linked.UserId)
+	}
+
+	var password models.Identity
+	db.First(&password, "id = ?", "squatted-email")
+	if _, ok := password.Data["password_hash"]; ok {
+		t.Error("unverified password kept")
+	}
+	if !password.EmailVerified {
+		t.Error("email identity not verified")
+	}
+
+	var session models.Session
+	db.First(&session, "id = ?", "attacker-session")
+	if session.RevokedAt == nil {
+		t.Error("session of the unverified password not revoked")
+	}
+	var token models.RefreshToken
+	db.First(&token, "session_id = ?", "attacker-session")
+	if !token.Revoked {
+		t.Error("refresh token of the unverified password not revoked")
+	}
+}
+
+func TestFindOrCreateVerifiedEmailIdentityKeepsVerifiedPassword(t *testing.T) {
+	db := testDb(t)
+	db.Exec("INSERT INTO users (id, client_id, email) VALUES ('owner', 'app', 'owner@example.com')")
+	db.Exec(`INSERT INTO identities (id, client_id, user_id, provider_sub, provider_option_id, client_provider_id, data, email_verified)
+		VALUES ('owner-email', 'app', 'owner', 'owner@example.com', 'email', 'app-email', CAST('{"password_hash":"owner"}' AS blob), true)`)
+	db.Exec("INSERT INTO sessions (id, user_id, client_id, identity_id) VALUES ('owner-session', 'owner', 'app', 'owner-email')")
+
+	if _, err := findOrCreateVerifiedEmailIdentity(db, testMagicLinkProvider, "owner@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	var password models.Identity
+	db.First(&password, "id = ?", "owner-email")
+	if password.Data["password_hash"] != "owner" {
+		t.Error("verified password dropped")
+	}
+	var session models.Session
+	db.First(&session, "id = ?", "owner-session")
+	if session.RevokedAt != nil {
+		t.Error("session of the verified password revoked")
+	}
+}
+
+func TestFindOrCreatePhoneIdentityRefusesDeletedAccounts(t *testing.T) {
+	db := testDb(t)
+	phoneProvider := &models.ClientProvider{ID: "app-phone", ClientId: "app", ProviderOptionId: phoneProviderOptionId, Enabled: true}
+	db.Exec("INSERT INTO users (id, client_id, email, deleted_at) VALUES ('deleted-user', 'app', 'deleted@example.com', CURRENT_TIMESTAMP)")
+	db.Exec(`INSERT INTO identities (client_id, user_id, provider_sub, provider_option_id, phone, deleted_at)
+		VALUES ('app', 'deleted-user', '+15550100', 'phone', '+15550100', CURRENT_TIMESTAMP)`)
+
+	_, _, err := findOrCreatePhoneIdentity(db, phoneProvider, "+15550100")
+	if err == nil || err.Error() != string(PasswordlessErrorAccountDeleted) {
+		t.Fatalf("err = %v, want %s", err, PasswordlessErrorAccountDeleted)
+	}
+
+	var users int64
+	db.Unscoped().Model(&models.User{}).Count(&users)
+	if users != 1 {
+		t.Errorf("%d users, want only the deleted one", users)
+	}
+}
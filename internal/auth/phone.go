@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/sms"
+	"sentinel-auth-backend/internal/validators"
+
+	"gorm.io/gorm"
+)
+
+type PhoneError string
+
+const (
+	PhoneErrorInvalidPhone     PhoneError = "invalid phone number"
+	PhoneErrorProviderDisabled PhoneError = "phone sign in not enabled for client"
+)
+
+const phoneProviderOptionId = "phone"
+
+// whether texts can be sent, the phone provider can't be enabled otherwise
+var smsGatewayConfigured = false
+
+func SetSmsGatewayConfigured(configured bool) {
+	smsGatewayConfigured = configured
+}
+
+// IsPhoneProviderEnabled tells whether any client signs users in by phone
+func IsPhoneProviderEnabled(db *gorm.DB) (bool, error) {
+	var count int64
+	err := db.Model(&models.ClientProvider{}).
+		Where("provider_option_id = ? AND enabled", phoneProviderOptionId).
+		Count(&count).Error
+	return count > 0, err
+}
+
+type PhoneCodeInput struct {
+	ClientId string
+	Phone    string
+	Locale   string
+	// the authorization request the code completes
+	Authorization AuthorizationParams
+}
+
+// normalizeClientPhoneNumber turns phone into E.164. numbers without an
+// international prefix are read as local to the client provider's
+// default_country_code setting
+func normalizeClientPhoneNumber(clientProvider *models.ClientProvider, phone string) (string, bool) {
+	defaultCountryCode, _ := clientProvider.Data["default_country_code"].(string)
+	return validators.NormalizePhoneNumber(phone, defaultCountryCode)
+}
+
+// SendPhoneCode texts a six digit sign in code. it both signs in and signs
+// up, numbers without an account get one once the code is entered
+func SendPhoneCode(db *gorm.DB, sender sms.SMSSender, input PhoneCodeInput) error {
+	clientProvider, ok := getEnabledClientProvider(db, input.ClientId, phoneProviderOptionId)
+	if !ok {
+		return errors.New(string(PhoneErrorProviderDisabled))
+	}
+
+	phone, ok := normalizeClientPhoneNumber(clientProvider, input.Phone)
+	if !ok {
+		return errors.New(string(PhoneErrorInvalidPhone))
+	}
+	if err := checkCodeChallenge(input.Authorization.CodeChallenge, input.Authorization.CodeChallengeMethod); err != nil {
+		return err
+	}
+
+	code, err := issueOneTimeCode(db, models.OneTimeCodeChannelPhone, input.ClientId, phone, input.Authorization)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(sms.SignInCode(phone, input.Locale, clientProvider.Client.Name, code, oneTimeCodeDurationSeconds/60))
+}
+
+// VerifyPhoneCode checks a code against the latest one texted to the number
+// and continues the authorization request it was sent for. created tells
+// whether a new account was made for the number
+func VerifyPhoneCode(db *gorm.DB, clientId string, phone string, code string) (result *AuthorizationResult, created bool, err error) {
+	clientProvider, ok := getEnabledClientProvider(db, clientId, phoneProviderOptionId)
+	if !ok {
+		return nil, false, errors.New(string(PhoneErrorProviderDisabled))
+	}
+
+	phone, ok = normalizeClientPhoneNumber(clientProvider, phone)
+	if !ok {
+		return nil, false, errors.New(string(OneTimeCodeErrorInvalidCode))
+	}
+
+	record, err := redeemOneTimeCode(db, models.OneTimeCodeChannelPhone, clientId, phone, code)
+	if err != nil {
+		return nil, false, err
+	}
+
+	identity, created, err := findOrCreatePhoneIdentity(db, clientProvider, phone)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err = CompleteAuthorization(db, identity, oneTimeCodeAuthorizationParams(record))
+	return result, created, err
+}
+
+// findOrCreatePhoneIdentity returns the identity for a number the user just
+// proved they own, making a new user for numbers seen the first time. numbers
+// of deleted accounts can't be signed in with until the account is restored
+// or purged
+func findOrCreatePhoneIdentity(db *gorm.DB, clientProvider *models.ClientProvider, phone string) (*models.Identity, bool, error) {
+	var identity *models.Identity
+	created := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findIdentity(tx, clientProvider.ClientId, phoneProviderOptionId, phone)
+		if err == nil {
+			identity = existing
+			if !identity.PhoneVerified {
+				identity.PhoneVerified = true
+				return tx.Model(identity).Update("phone_verified", true).Error
+			}
+			return nil
+		}
+
+		var deleted int64
+		err = tx.Unscoped().Model(&models.Identity{}).
+			Joins("JOIN users ON users.id = identities.user_id").
+			Where("identities.client_id = ? AND identities.provider_option_id = ? AND identities.provider_sub = ?", clientProvider.ClientId, phoneProviderOptionId, phone).
+			Where("users.deleted_at IS NOT NULL").
+			Count(&deleted).Error
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			return errors.New(string(PasswordlessErrorAccountDeleted))
+		}
+
+		user := models.User{ClientId: clientProvider.ClientId}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		identity = &models.Identity{
+			ClientId:         clientProvider.ClientId,
+			UserId:           user.ID,
+			ProviderSub:      phone,
+			ProviderOptionId: phoneProviderOptionId,
+			ClientProviderId: clientProvider.ID,
+			Data:             models.JsonDictionary{},
+			Phone:            &phone,
+			PhoneVerified:    true,
+		}
+		if err := tx.Create(identity).Error; err != nil {
+			return err
+		}
+		identity.User = user
+		identity.Client = clientProvider.Client
+		created = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return identity, created, nil
+}
+
+// findUserPhone returns the phone number a user signs in with, preferring a
+// verified one
+func findUserPhone(db *gorm.DB, userId string) (string, bool, bool) {
+	var identity models.Identity
+	result := db.Where("user_id = ? AND phone IS NOT NULL", userId).
+		Order("phone_verified DESC, created_at ASC").Limit(1).Find(&identity)
+	if result.Error != nil || result.RowsAffected == 0 || identity.Phone == nil {
+		return "", false, false
+	}
+	return *identity.Phone, identity.PhoneVerified, true
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"regexp"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/sms"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// phoneTestDb enables phone sign in for client "app", reading local numbers
+// as british
+func phoneTestDb(t *testing.T) *gorm.DB {
+	db := testDb(t)
+	for _, statement := range []string{
+		"INSERT INTO provider_options (id, name) VALUES ('phone', 'Phone')",
+		`INSERT INTO client_providers (id, client_id, provider_option_id, data, enabled)
+			VALUES ('app-phone', 'app', 'phone', CAST('{"default_country_code":"44"}' AS blob), true)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+var testPhoneCodeInput = PhoneCodeInput{
+	ClientId:      "app",
+	Phone:         "020 7946 0958",
+	Authorization: AuthorizationParams{CodeChallenge: strings.Repeat("a", 43), CodeChallengeMethod: "S256"},
+}
+
+var sentCodePattern = regexp.MustCompile(`\b[0-9]{6}\b`)
+
+// sendTestPhoneCode texts a code to the test number and returns it
+func sendTestPhoneCode(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+
+	sender := sms.NewFakeSender(nil)
+	if err := SendPhoneCode(db, sender, testPhoneCodeInput); err != nil {
+		t.Fatal(err)
+	}
+	message, ok := sender.Last("+442079460958")
+	if !ok {
+		t.Fatal("no text sent to the normalized number")
+	}
+	code := sentCodePattern.FindString(message.Body)
+	if code == "" {
+		t.Fatalf("no code in %q", message.Body)
+	}
+	return code
+}
+
+func TestSendPhoneCodeThrottlesResends(t *testing.T) {
+	db := phoneTestDb(t)
+	sendTestPhoneCode(t, db)
+
+	sender := sms.NewFakeSender(nil)
+	err := SendPhoneCode(db, sender, testPhoneCodeInput)
+	if err == nil || err.Error() != string(OneTimeCodeErrorThrottled) {
+		t.Errorf("err = %v, want %s", err, OneTimeCodeErrorThrottled)
+	}
+	if len(sender.Messages()) != 0 {
+		t.Error("throttled code was still texted")
+	}
+
+	// the same number typed differently is the same recipient
+	input := testPhoneCodeInput
+	input.Phone = "+44 20 7946 0958"
+	err = SendPhoneCode(db, sender, input)
+	if err == nil || err.Error() != string(OneTimeCodeErrorThrottled) {
+		t.Errorf("err = %v, want %s", err, OneTimeCodeErrorThrottled)
+	}
+}
+
+func TestSendPhoneCodeRefusesInvalidNumbers(t *testing.T) {
+	db := phoneTestDb(t)
+
+	input := testPhoneCodeInput
+	input.Phone = "not a number"
+	err := SendPhoneCode(db, sms.NewFakeSender(nil), input)
+	if err == nil || err.Error() != string(PhoneErrorInvalidPhone) {
+		t.Errorf("err = %v, want %s", err, PhoneErrorInvalidPhone)
+	}
+}
+
+func TestVerifyPhoneCodeLimitsAttempts(t *testing.T) {
+	db := phoneTestDb(t)
+	code := sendTestPhoneCode(t, db)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 1; i < oneTimeCodeMaxAttempts; i++ {
+		_, _, err := VerifyPhoneCode(db, "app", testPhoneCodeInput.Phone, wrong)
+		if err == nil || err.Error() != string(OneTimeCodeErrorInvalidCode) {
+			t.Fatalf("attempt %d: err = %v, want %s", i, err, OneTimeCodeErrorInvalidCode)
+		}
+	}
+	_, _, err := VerifyPhoneCode(db, "app", testPhoneCodeInput.Phone, wrong)
+	if err == nil || err.Error() != string(OneTimeCodeErrorTooManyAttempts) {
+		t.Fatalf("last attempt: err = %v, want %s", err, OneTimeCodeErrorTooManyAttempts)
+	}
+
+	// not even the right code works anymore
+	_, _, err = VerifyPhoneCode(db, "app", testPhoneCodeInput.Phone, code)
+	if err == nil || err.Error() != string(OneTimeCodeErrorTooManyAttempts) {
+		t.Errorf("right code after the limit: err = %v, want %s", err, OneTimeCodeErrorTooManyAttempts)
+	}
+}
+
+func TestPhoneCodesWorkOnce(t *testing.T) {
+	db := phoneTestDb(t)
+	code := sendTestPhoneCode(t, db)
+
+	record, err := redeemOneTimeCode(db, models.OneTimeCodeChannelPhone, "app", "+442079460958", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.CodeChallenge != testPhoneCodeInput.Authorization.CodeChallenge {
+		t.Error("code lost the challenge of the authorization request")
+	}
+
+	_, err = redeemOneTimeCode(db, models.OneTimeCodeChannelPhone, "app", "+442079460958", code)
+	if err == nil || err.Error() != string(OneTimeCodeErrorInvalidCode) {
+		t.Errorf("second use: err = %v, want %s", err, OneTimeCodeErrorInvalidCode)
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReauthenticationError string
+
+const (
+	ReauthenticationErrorRequired ReauthenticationError = "sign in again to continue"
+)
+
+// deleting the account, changing the password or setting up a second factor
+// needs a sign in this recent
+const reauthenticationMaxAge = 10 * time.Minute
+
+// CheckRecentAuthentication fails unless the user proved who they are in the
+// session lately, like before deleting their account
+func CheckRecentAuthentication(db *gorm.DB, sessionId string) error {
+	var session models.Session
+	result := db.Limit(1).Find(&session, "id = ? AND revoked_at IS NULL", sessionId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || time.Since(session.AuthTime) > reauthenticationMaxAge {
+		return errors.New(string(ReauthenticationErrorRequired))
+	}
+	return nil
+}
+
+// CheckRecentOrAal2Authentication is CheckRecentAuthentication that also lets
+// sessions through which used a second factor, like before changing the
+// password or setting up another factor so a stolen token can't add its own
+func CheckRecentOrAal2Authentication(db *gorm.DB, sessionId string) error {
+	if requireAal2(db, sessionId) == nil {
+		return nil
+	}
+	return CheckRecentAuthentication(db, sessionId)
+}
+
+// ReauthenticationFactorPassword answers the reauthenticate step with the
+// user's password, next to the second factor types
+const ReauthenticationFactorPassword = "password"
+
+// reauthenticationFactors lists what a user can prove who they are with
+// again: their password when they have one and their second factors.
+// recovery codes are only meant for a lost device, not for this
+func reauthenticationFactors(db *gorm.DB, userId string) ([]string, error) {
+	factors := []string{}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userId).Error; err != nil {
+		return nil, err
+	}
+	identity, err := findUserEmailIdentity(db, &user)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if _, ok := identity.Data["password_hash"].(string); ok {
+			factors = append(factors, ReauthenticationFactorPassword)
+		}
+	}
+
+	mfaFactors, err := userMfaFactorTypes(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, factor := range mfaFactors {
+		if factor != models.MfaFactorTypeRecoveryCode {
+			factors = append(factors, factor)
+		}
+	}
+
+	return factors, nil
+}
+
+func verifyReauthenticationPassword(db *gorm.DB, userId string, password string) error {
+	var user models.User
+	if err := db.First(&user, "id = ?", userId).Error; err != nil {
+		return err
+	}
+	identity, err := findUserEmailIdentity(db, &user)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return errors.New(string(MfaErrorUnsupportedType))
+	}
+	hash, ok := identity.Data["password_hash"].(string)
+	if !ok {
+		return errors.New(string(MfaErrorUnsupportedType))
+	}
+
+	if matches, _ := CompareHashAndPassword(hash, password); !matches {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+	return nil
+}
+
+// Reauthentication answers the reauthenticate step with the password, a
+// code from the authenticator app or a passkey assertion
+type Reauthentication struct {
+	FlowToken  string
+	FactorType string
+	Password   string
+	Code       string
+	Passkey    *PasskeyAssertion
+}
+
+// Reauthenticate completes the reauthenticate step a step up ends in when
+// the session is older than max_age. the session's auth_time becomes now,
+// and it reaches aal2 when a second factor was used
+func Reauthenticate(db *gorm.DB, input Reauthentication) (*AuthorizationResult, error) {
+	flow, err := GetAuthenticationFlow(db, input.FlowToken, models.AuthenticationFlowStepReauthenticate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := countMfaAttempt(db, flow); err != nil {
+		return nil, err
+	}
+
+	switch input.FactorType {
+	case ReauthenticationFactorPassword:
+		err = verifyReauthenticationPassword(db, flow.UserId, input.Password)
+	case models.MfaFactorTypeTotp:
+		err = verifyTotpFactor(db, flow.UserId, input.Code)
+	case models.MfaFactorTypePasskey:
+		err = verifyPasskeyFactor(db, flow, input.Passkey)
+	default:
+		return nil, errors.New(string(MfaErrorUnsupportedType))
+	}
+	if err != nil {
+		if err.Error() == string(MfaErrorInvalidCode) {
+			return nil, wrongMfaCode(db, flow)
+		}
+		return nil, err
+	}
+
+	flow.AuthTime = time.Now()
+	if input.FactorType == ReauthenticationFactorPassword {
+		flow.Amr = appendAmr(flow.Amr, "pwd")
+	} else {
+		flow.Aal = 2
+		flow.Amr = appendAmr(flow.Amr, factorAmr(input.FactorType), "mfa")
+	}
+	return continueAuthenticationFlow(db, flow)
+}
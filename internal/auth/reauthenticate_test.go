@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"sentinel-auth-backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCheckRecentAuthentication(t *testing.T) {
+	db := testDb(t)
+	now := time.Now()
+	revokedAt := now
+	for _, session := range []models.Session{
+		{ID: "recent", UserId: "user", ClientId: "app", IdentityId: "identity", AuthTime: now.Add(-time.Minute), Aal: 1},
+		{ID: "old", UserId: "user", ClientId: "app", IdentityId: "identity", AuthTime: now.Add(-time.Hour), Aal: 1},
+		{ID: "old-aal2", UserId: "user", ClientId: "app", IdentityId: "identity", AuthTime: now.Add(-time.Hour), Aal: 2},
+		{ID: "revoked", UserId: "user", ClientId: "app", IdentityId: "identity", AuthTime: now, Aal: 2, RevokedAt: &revokedAt},
+	} {
+		if err := db.Omit("User", "Client", "Identity").Create(&session).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		sessionId   string
+		recent      bool
+		recentOrMfa bool
+	}{
+		{"recent", true, true},
+		{"old", false, false},
+		{"old-aal2", false, true},
+		{"revoked", false, false},
+		{"missing", false, false},
+		{"", false, false},
+	}
+
+	for _, test := range tests {
+		err := CheckRecentAuthentication(db, test.sessionId)
+		if (err == nil) != test.recent || (err != nil && err.Error() != string(ReauthenticationErrorRequired)) {
+			t.Errorf("CheckRecentAuthentication(%q) = %v", test.sessionId, err)
+		}
+		err = CheckRecentOrAal2Authentication(db, test.sessionId)
+		if (err == nil) != test.recentOrMfa || (err != nil && err.Error() != string(ReauthenticationErrorRequired)) {
+			t.Errorf("CheckRecentOrAal2Authentication(%q) = %v", test.sessionId, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// codes per set, and characters per code (about 50 bits each)
+const (
+	mfaRecoveryCodeCount  = 10
+	mfaRecoveryCodeLength = 10
+)
+
+// codes are shown split in two halves, xxxxx-xxxxx
+func formatRecoveryCode(code string) string {
+	return code[:mfaRecoveryCodeLength/2] + "-" + code[mfaRecoveryCodeLength/2:]
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// replaceRecoveryCodes drops the user's recovery codes and makes a new set.
+// the plain codes are only ever returned here
+func replaceRecoveryCodes(db *gorm.DB, userId string) ([]string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	records := make([]models.MfaRecoveryCode, 0, mfaRecoveryCodeCount)
+	for range mfaRecoveryCodeCount {
+		code := crypto.GenerateReadableCode(mfaRecoveryCodeLength)
+		salt := crypto.GenerateSecureSecret()
+		codes = append(codes, formatRecoveryCode(code))
+		records = append(records, models.MfaRecoveryCode{
+			UserId:   userId,
+			Salt:     salt,
+			CodeHash: crypto.HashCode(salt, code),
+		})
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.MfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// issueInitialRecoveryCodes hands out recovery codes when a user sets up a
+// second factor and has none left. nil means the user still has codes
+func issueInitialRecoveryCodes(db *gorm.DB, userId string) ([]string, error) {
+	remaining, err := CountRecoveryCodes(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, nil
+	}
+	return replaceRecoveryCodes(db, userId)
+}
+
+// CountRecoveryCodes is how many unused recovery codes the user has
+func CountRecoveryCodes(db *gorm.DB, userId string) (int64, error) {
+	var remaining int64
+	err := db.Model(&models.MfaRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Count(&remaining).Error
+	return remaining, err
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, for when they
+// ran low or the old ones leaked. like other second factor changes it needs
+// a session signed in with a second factor
+func RegenerateRecoveryCodes(db *gorm.DB, userId string, sessionId string) ([]string, error) {
+	if err := requireAal2(db, sessionId); err != nil {
+		return nil, err
+	}
+
+	enrolled, err := hasEnrolledFactors(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		return nil, errors.New(string(MfaErrorNotEnrolled))
+	}
+
+	return replaceRecoveryCodes(db, userId)
+}
+
+// hasEnrolledFactors tells whether the user set up any second factor,
+// regardless of whether their client currently accepts it
+func hasEnrolledFactors(db *gorm.DB, userId string) (bool, error) {
+	var factors int64
+	err := db.Model(&models.MfaFactor{}).Where("user_id = ? AND confirmed_at IS NOT NULL", userId).Count(&factors).Error
+	if err != nil || factors > 0 {
+		return factors > 0, err
+	}
+
+	var passkeys int64
+	err = db.Model(&models.PasskeyCredential{}).Where("user_id = ?", userId).Count(&passkeys).Error
+	return passkeys > 0, err
+}
+
+// dropUnneededRecoveryCodes removes recovery codes once the user has no
+// second factor left for them to stand in for
+func dropUnneededRecoveryCodes(db *gorm.DB, userId string) error {
+	enrolled, err := hasEnrolledFactors(db, userId)
+	if err != nil || enrolled {
+		return err
+	}
+	return db.Where("user_id = ?", userId).Delete(&models.MfaRecoveryCode{}).Error
+}
+
+// verifyRecoveryCode accepts one of the user's unused recovery codes and
+// uses it up. the user is emailed about it, a code they didn't use
+// themselves means the codes leaked
+func verifyRecoveryCode(db *gorm.DB, mailer mail.Mailer, userId string, code string, locale string) error {
+	code = normalizeRecoveryCode(code)
+
+	var records []models.MfaRecoveryCode
+	if err := db.Where("user_id = ? AND used_at IS NULL", userId).Find(&records).Error; err != nil {
+		return err
+	}
+
+	var match *models.MfaRecoveryCode
+	for i := range records {
+		hash := crypto.HashCode(records[i].Salt, code)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(records[i].CodeHash)) == 1 {
+			match = &records[i]
+		}
+	}
+	if match == nil {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	// claim in one statement so a parallel request can't use the code too
+	now := time.Now()
+	result := db.Model(&models.MfaRecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", match.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	// the sign in goes ahead either way, a missing alert shouldn't block it
+	if err := sendRecoveryCodeUsedEmail(db, mailer, userId, now, locale); err != nil {
+		log.Println("failed to send recovery code used email:", err)
+	}
+	return nil
+}
+
+func sendRecoveryCodeUsedEmail(db *gorm.DB, mailer mail.Mailer, userId string, usedAt time.Time, locale string) error {
+	var user models.User
+	if err := db.First(&user, "id = ?", userId).Error; err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	var client models.Client
+	if err := db.First(&client, "id = ?", user.ClientId).Error; err != nil {
+		return err
+	}
+
+	remaining, err := CountRecoveryCodes(db, userId)
+	if err != nil {
+		return err
+	}
+
+	message, err := mail.Render(&client, mail.TemplateRecoveryCodeUsed, locale, user.Email, mail.Data{
+		"UsedAt":    usedAt.UTC().Format("2006-01-02 15:04 MST"),
+		"Remaining": remaining,
+	})
+	if err != nil {
+		return err
+	}
+	return mailer.Send(message)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sentinel-auth-backend/internal/crypto"
 	"sentinel-auth-backend/internal/models"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -22,9 +23,12 @@ type Tokens struct {
 	Id        string
 	Refresh   string
 	ExpiresIn int
+	Scopes    []string
 }
 
-func RedeemAuthCode(db *gorm.DB, clientId string, code string, codeVerifier string, client *models.Client) (*Tokens, error) {
+// RedeemAuthCode exchanges a code for tokens. resource optionally names the
+// api (RFC 8707) the access token is for, out of those requested with the code
+func RedeemAuthCode(db *gorm.DB, clientId string, code string, codeVerifier string, resource string, client *models.Client) (*Tokens, error) {
 	var authCodeRecord models.RedeemAuthCode
 
 	result := db.Preload("Identity").Preload("User").Preload("Client").First(&authCodeRecord, "code = ? AND client_id = ?", code, clientId)
@@ -43,28 +47,43 @@ func RedeemAuthCode(db *gorm.DB, clientId string, code string, codeVerifier stri
 		return nil, errors.New(string(RedeemAuthCodeErrorCodeChallengeFailed))
 	}
 
-	shortTokenDurationSeconds := 60 * 60 * 1
-	accessToken, err := crypto.CreateAccessToken(
-		client.ID,
-		client.Secret,
-		"",
-		authCodeRecord.Identity.ProviderOptionId,
-		crypto.UserData{},
-		crypto.Identities{},
-		[]string{"profile"},
-		now.Unix(),
-		shortTokenDurationSeconds,
-	)
+	if err := checkSessionActive(db, authCodeRecord.SessionId); err != nil {
+		return nil, errors.New(string(RedeemAuthCodeErrorInvalidCode))
+	}
+
+	scopes := []string(authCodeRecord.Scopes)
+	// access tokens get the session claims, id tokens also the profile ones
+	tokenUser := crypto.NewUserData(authCodeRecord.UserId, crypto.ClaimsDict{}).WithSession(authCodeRecord.SessionId)
+	tokenUser = sessionAuthentication(db, authCodeRecord.SessionId, tokenUser)
+
+	userData := tokenUser
+	if slices.Contains(scopes, "email") {
+		userData = userData.WithEmail(authCodeRecord.User.Email, authCodeRecord.Identity.EmailVerified)
+	}
+	if slices.Contains(scopes, "phone") {
+		if phone, verified, ok := findUserPhone(db, authCodeRecord.UserId); ok {
+			userData = userData.WithPhone(phone, verified)
+		}
+	}
+
+	apiResource, err := selectResource(db, authCodeRecord.Resources, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := issueAccessToken(db, client, authCodeRecord.Identity.ProviderOptionId, tokenUser, scopes, apiResource, now.Unix())
 	if err != nil {
 		return nil, err
 	}
 
+	shortTokenDurationSeconds := 60 * 60 * 1
+
 	idToken, err := crypto.CreateIdToken(
 		client.ID,
 		client.Secret,
 		"",
 		authCodeRecord.Identity.ProviderOptionId,
-		crypto.UserData{},
+		userData,
 		crypto.Identities{},
 		now.Unix(),
 		shortTokenDurationSeconds,
@@ -73,17 +92,22 @@ func RedeemAuthCode(db *gorm.DB, clientId string, code string, codeVerifier stri
 		return nil, err
 	}
 
-	// 100 year duration
-	refresh, err := crypto.CreateRefreshToken(db, "", now.Unix(), 60*60*24*365*100, &authCodeRecord.Identity, authCodeRecord.CodeChallenge, authCodeRecord.CodeChallengeMethod)
-	if err != nil {
-		return nil, err
+	// only clients that registered the refresh_token grant get one, 100 year
+	// duration
+	refresh := ""
+	if ClientAllowsGrantType(client, GrantTypeRefreshToken) {
+		refresh, err = crypto.CreateRefreshToken(db, "", now.Unix(), 60*60*24*365*100, &authCodeRecord.Identity, authCodeRecord.SessionId, authCodeRecord.CodeChallenge, authCodeRecord.CodeChallengeMethod, scopes, authCodeRecord.Resources)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	tokens := Tokens{
-		Access:    accessToken,
+		Access:    accessToken.Token,
 		Id:        idToken,
 		Refresh:   refresh,
-		ExpiresIn: shortTokenDurationSeconds,
+		ExpiresIn: accessToken.ExpiresIn,
+		Scopes:    accessToken.Scopes,
 	}
 
 	authCodeRecord.Redeemed = true
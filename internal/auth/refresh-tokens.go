@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sentinel-auth-backend/internal/crypto"
 	"sentinel-auth-backend/internal/models"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,6 +20,7 @@ type RefreshedTokens struct {
 	Access    string
 	Id        string
 	ExpiresIn int
+	Scopes    []string
 }
 
 func getRefreshTokenByToken(db *gorm.DB, token string) (*models.RefreshToken, error) {
@@ -32,7 +34,10 @@ func getRefreshTokenByToken(db *gorm.DB, token string) (*models.RefreshToken, er
 	return &rf, nil
 }
 
-func RefreshTokensWithRefreshToken(db *gorm.DB, clientId string, token string, codeVerifier string) (*RefreshedTokens, error) {
+// RefreshTokensWithRefreshToken issues new access and id tokens. scope may
+// narrow the access token down to a subset of the scopes originally granted
+// and resource picks which of the originally requested apis it is for
+func RefreshTokensWithRefreshToken(db *gorm.DB, clientId string, token string, codeVerifier string, scope string, resource string) (*RefreshedTokens, error) {
 	rf, err := getRefreshTokenByToken(db, token)
 	if err != nil || rf.ClientId != clientId {
 		return nil, errors.New(string(RefreshTokensWithRefreshTokenErrorInvalidToken))
@@ -44,32 +49,51 @@ func RefreshTokensWithRefreshToken(db *gorm.DB, clientId string, token string, c
 		return nil, errors.New(string(RefreshTokensWithRefreshTokenErrorInvalidToken))
 	}
 
+	if err := checkSessionActive(db, rf.SessionId); err != nil {
+		return nil, errors.New(string(RefreshTokensWithRefreshTokenErrorInvalidToken))
+	}
+
 	if !passesCodeChallenge(rf.CodeChallenge, rf.CodeChallengeMethod, codeVerifier) {
 		return nil, errors.New(string(RedeemAuthCodeErrorCodeChallengeFailed))
 	}
 
-	shortTokenDurationSeconds := 60 * 60 * 1
-	accessToken, err := crypto.CreateAccessToken(
-		rf.Client.ID,
-		rf.Client.Secret,
-		"",
-		rf.Identity.ProviderOptionId,
-		crypto.UserData{},
-		crypto.Identities{},
-		[]string{"profile"},
-		now.Unix(),
-		shortTokenDurationSeconds,
-	)
+	scopes, err := DownscopeScopes(rf.Scopes, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// access tokens get the session claims, id tokens also the profile ones
+	tokenUser := crypto.NewUserData(rf.UserId, crypto.ClaimsDict{}).WithSession(rf.SessionId)
+	tokenUser = sessionAuthentication(db, rf.SessionId, tokenUser)
+
+	userData := tokenUser
+	if slices.Contains(scopes, "email") {
+		userData = userData.WithEmail(rf.User.Email, rf.Identity.EmailVerified)
+	}
+	if slices.Contains(scopes, "phone") {
+		if phone, verified, ok := findUserPhone(db, rf.UserId); ok {
+			userData = userData.WithPhone(phone, verified)
+		}
+	}
+
+	apiResource, err := selectResource(db, rf.Resources, resource)
 	if err != nil {
 		return nil, err
 	}
 
+	accessToken, err := issueAccessToken(db, &rf.Client, rf.Identity.ProviderOptionId, tokenUser, scopes, apiResource, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	shortTokenDurationSeconds := 60 * 60 * 1
+
 	idToken, err := crypto.CreateIdToken(
 		rf.Client.ID,
 		rf.Client.Secret,
 		"",
 		rf.Identity.ProviderOptionId,
-		crypto.UserData{},
+		userData,
 		crypto.Identities{},
 		now.Unix(),
 		shortTokenDurationSeconds,
@@ -79,9 +103,10 @@ func RefreshTokensWithRefreshToken(db *gorm.DB, clientId string, token string, c
 	}
 
 	tokens := RefreshedTokens{
-		Access:    accessToken,
+		Access:    accessToken.Token,
 		Id:        idToken,
-		ExpiresIn: shortTokenDurationSeconds,
+		ExpiresIn: accessToken.ExpiresIn,
+		Scopes:    accessToken.Scopes,
 	}
 
 	return &tokens, nil
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type RegisterClientError string
+
+const (
+	RegisterClientErrorInvalidRedirectUri RegisterClientError = "invalid redirect uri"
+	RegisterClientErrorInvalidMetadata    RegisterClientError = "invalid client metadata"
+	RegisterClientErrorInvalidToken       RegisterClientError = "invalid registration token"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+var supportedGrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken}
+
+// ClientAllowsGrantType tells whether the client registered grantType.
+// clients without any, like the ones admins create, may use all of them
+func ClientAllowsGrantType(client *models.Client, grantType string) bool {
+	return len(client.GrantTypes) == 0 || slices.Contains(client.GrantTypes, grantType)
+}
+
+var supportedTokenEndpointAuthMethods = []string{
+	models.TokenEndpointAuthMethodNone,
+	models.TokenEndpointAuthMethodPrivateKeyJwt,
+}
+
+type ClientMetadata struct {
+	Name                    string
+	RedirectUris            []string
+	GrantTypes              []string
+	TokenEndpointAuthMethod string
+	LogoUri                 *string
+	JwksUri                 *string
+	Jwks                    map[string]interface{}
+	Scopes                  []string
+	// whether password sign in waits for the email to be verified
+	RequireEmailVerification bool
+}
+
+// IsValidInitialAccessToken checks token against the comma separated list of
+// initial access tokens from the config
+func IsValidInitialAccessToken(allowedTokens string, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	valid := false
+	for _, allowed := range strings.Split(allowedTokens, ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed != "" && subtle.ConstantTimeCompare([]byte(allowed), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func isValidRedirectUri(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return parsed.Host != ""
+	case "http":
+		// plain http is only ok for local development
+		return isLoopbackHost(parsed.Hostname())
+	default:
+		// private use schemes for native apps, e.g. com.example.app:/callback
+		return strings.Contains(parsed.Scheme, ".")
+	}
+}
+
+func isValidHttpsUri(uri string) bool {
+	parsed, err := url.Parse(uri)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+// normalizeClientMetadata fills in defaults and validates the metadata
+// following RFC 7591 section 2
+func normalizeClientMetadata(db *gorm.DB, metadata *ClientMetadata) error {
+	if len(metadata.RedirectUris) == 0 {
+		return errors.New(string(RegisterClientErrorInvalidRedirectUri))
+	}
+	for _, uri := range metadata.RedirectUris {
+		if !isValidRedirectUri(uri) {
+			return errors.New(string(RegisterClientErrorInvalidRedirectUri))
+		}
+	}
+
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = supportedGrantTypes
+	}
+	for _, grantType := range metadata.GrantTypes {
+		if !slices.Contains(supportedGrantTypes, grantType) {
+			return errors.New(string(RegisterClientErrorInvalidMetadata))
+		}
+	}
+	if !slices.Contains(metadata.GrantTypes, GrantTypeAuthorizationCode) {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+
+	if metadata.TokenEndpointAuthMethod == "" {
+		metadata.TokenEndpointAuthMethod = models.TokenEndpointAuthMethodNone
+	}
+	if !slices.Contains(supportedTokenEndpointAuthMethods, metadata.TokenEndpointAuthMethod) {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+
+	hasJwksUri := metadata.JwksUri != nil && *metadata.JwksUri != ""
+	hasJwks := len(metadata.Jwks) > 0
+	if hasJwksUri && hasJwks {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+	if hasJwksUri && !isValidHttpsUri(*metadata.JwksUri) {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+	if hasJwks {
+		data, err := json.Marshal(metadata.Jwks)
+		if err != nil {
+			return errors.New(string(RegisterClientErrorInvalidMetadata))
+		}
+		if _, err := crypto.ParseJwks(data); err != nil {
+			return errors.New(string(RegisterClientErrorInvalidMetadata))
+		}
+	}
+	if metadata.TokenEndpointAuthMethod == models.TokenEndpointAuthMethodPrivateKeyJwt && !hasJwksUri && !hasJwks {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+
+	if metadata.LogoUri != nil && *metadata.LogoUri != "" && !isValidHttpsUri(*metadata.LogoUri) {
+		return errors.New(string(RegisterClientErrorInvalidMetadata))
+	}
+
+	for _, scope := range metadata.Scopes {
+		if !validators.IsValidScopeName(scope) {
+			return errors.New(string(RegisterClientErrorInvalidMetadata))
+		}
+	}
+
+	if len(metadata.Scopes) > 0 {
+		registered, err := registeredScopeIds(db, metadata.Scopes)
+		if err != nil {
+			return err
+		}
+		if len(registered) != len(metadata.Scopes) {
+			return errors.New(string(RegisterClientErrorInvalidMetadata))
+		}
+	}
+
+	metadata.Name = strings.TrimSpace(metadata.Name)
+	if metadata.Name == "" {
+		metadata.Name = "Unnamed Client"
+	}
+
+	return nil
+}
+
+func applyClientMetadata(client *models.Client, metadata *ClientMetadata) {
+	client.Name = metadata.Name
+	client.RedirectUris = pq.StringArray(metadata.RedirectUris)
+	client.GrantTypes = pq.StringArray(metadata.GrantTypes)
+	client.TokenEndpointAuthMethod = metadata.TokenEndpointAuthMethod
+	client.LogoUrl = metadata.LogoUri
+	client.JwksUri = metadata.JwksUri
+	client.Jwks = metadata.Jwks
+	client.AllowedScopes = pq.StringArray(metadata.Scopes)
+	client.RequireEmailVerification = metadata.RequireEmailVerification
+}
+
+// RegisterClient creates a client from registration metadata and returns the
+// registration access token for managing it. the token is only stored hashed
+func RegisterClient(db *gorm.DB, metadata ClientMetadata) (*models.Client, string, error) {
+	if err := normalizeClientMetadata(db, &metadata); err != nil {
+		return nil, "", err
+	}
+
+	registrationAccessToken := crypto.GenerateSecureSecret()
+	tokenHash := crypto.HashSecret(registrationAccessToken)
+
+	client := models.Client{
+		Secret:                      crypto.GenerateSecureSecret(),
+		RegistrationAccessTokenHash: &tokenHash,
+	}
+	applyClientMetadata(&client, &metadata)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&client).Error; err != nil {
+			return err
+		}
+
+		// new clients get email sign in, same as the root client
+		clientProvider := models.ClientProvider{
+			ClientId:         client.ID,
+			ProviderOptionId: "email",
+			Enabled:          true,
+			Data:             map[string]interface{}{},
+		}
+		return tx.Create(&clientProvider).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &client, registrationAccessToken, nil
+}
+
+// GetRegisteredClient returns the client if registrationAccessToken is the
+// token handed out when it was registered
+func GetRegisteredClient(db *gorm.DB, clientId string, registrationAccessToken string) (*models.Client, error) {
+	if registrationAccessToken == "" {
+		return nil, errors.New(string(RegisterClientErrorInvalidToken))
+	}
+
+	var client models.Client
+	result := db.First(&client, "id = ?", clientId)
+	if result.Error != nil || client.RegistrationAccessTokenHash == nil {
+		return nil, errors.New(string(RegisterClientErrorInvalidToken))
+	}
+
+	tokenHash := crypto.HashSecret(registrationAccessToken)
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(*client.RegistrationAccessTokenHash)) != 1 {
+		return nil, errors.New(string(RegisterClientErrorInvalidToken))
+	}
+
+	return &client, nil
+}
+
+func UpdateRegisteredClient(db *gorm.DB, clientId string, registrationAccessToken string, metadata ClientMetadata) (*models.Client, error) {
+	client, err := GetRegisteredClient(db, clientId, registrationAccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := normalizeClientMetadata(db, &metadata); err != nil {
+		return nil, err
+	}
+
+	applyClientMetadata(client, &metadata)
+
+	// Select makes sure cleared fields (like a removed jwks_uri) are written
+	result := db.Model(client).Select("Name", "RedirectUris", "GrantTypes", "TokenEndpointAuthMethod", "LogoUrl", "JwksUri", "Jwks", "AllowedScopes", "RequireEmailVerification").Updates(client)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return client, nil
+}
+
+func DeleteRegisteredClient(db *gorm.DB, clientId string, registrationAccessToken string) error {
+	client, err := GetRegisteredClient(db, clientId, registrationAccessToken)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := tx.NowFunc()
+		if err := tx.Model(&models.RefreshToken{}).Where("client_id = ?", client.ID).Update("revoked", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RedeemAuthCode{}).Where("client_id = ? AND expires_at > ?", client.ID, now).Update("revoked", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", client.ID).Delete(&models.ClientProvider{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(client).Error
+	})
+}
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type ApiResourceError string
+
+const (
+	ApiResourceErrorInvalidTarget      ApiResourceError = "invalid target"
+	ApiResourceErrorInvalidIdentifier  ApiResourceError = "invalid resource identifier"
+	ApiResourceErrorInvalidAlgorithm   ApiResourceError = "unsupported signing algorithm"
+	ApiResourceErrorInvalidLifetime    ApiResourceError = "invalid access token lifetime"
+	ApiResourceErrorUnknownScope       ApiResourceError = "resource scope does not exist"
+	ApiResourceErrorIdentifierTaken    ApiResourceError = "resource identifier already registered"
+	ApiResourceErrorResourceNotFound   ApiResourceError = "resource does not exist"
+	ApiResourceErrorResourceNotGranted ApiResourceError = "resource was not requested during authorization"
+)
+
+// audience of access tokens issued without a resource, meant for sentinel's
+// own apis
+const defaultAccessTokenAudience = "sentinel"
+
+const defaultAccessTokenLifetime = 60 * 60 * 1
+
+var supportedResourceSigningAlgorithms = []string{
+	models.SigningAlgorithmRS256,
+	models.SigningAlgorithmHS256,
+}
+
+type ApiResourceInput struct {
+	Identifier          string
+	Name                string
+	Scopes              []string
+	AccessTokenLifetime int
+	SigningAlgorithm    string
+}
+
+// isValidResourceIdentifier follows RFC 8707 section 2: an absolute uri
+// without a fragment
+func isValidResourceIdentifier(identifier string) bool {
+	parsed, err := url.Parse(identifier)
+	return err == nil && parsed.IsAbs() && parsed.Fragment == ""
+}
+
+func normalizeApiResourceInput(db *gorm.DB, input *ApiResourceInput) error {
+	input.Identifier = strings.TrimSpace(input.Identifier)
+	if !isValidResourceIdentifier(input.Identifier) {
+		return errors.New(string(ApiResourceErrorInvalidIdentifier))
+	}
+
+	if input.SigningAlgorithm == "" {
+		input.SigningAlgorithm = models.SigningAlgorithmRS256
+	}
+	if !slices.Contains(supportedResourceSigningAlgorithms, input.SigningAlgorithm) {
+		return errors.New(string(ApiResourceErrorInvalidAlgorithm))
+	}
+
+	if input.AccessTokenLifetime == 0 {
+		input.AccessTokenLifetime = defaultAccessTokenLifetime
+	}
+	// between a minute and a day
+	if input.AccessTokenLifetime < 60 || input.AccessTokenLifetime > 60*60*24 {
+		return errors.New(string(ApiResourceErrorInvalidLifetime))
+	}
+
+	input.Scopes = ParseScope(strings.Join(input.Scopes, " "))
+	if len(input.Scopes) > 0 {
+		registered, err := registeredScopeIds(db, input.Scopes)
+		if err != nil {
+			return err
+		}
+		if len(registered) != len(input.Scopes) {
+			return errors.New(string(ApiResourceErrorUnknownScope))
+		}
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		input.Name = input.Identifier
+	}
+
+	return nil
+}
+
+func ListApiResources(db *gorm.DB) ([]models.ApiResource, error) {
+	var resources []models.ApiResource
+	result := db.Order("identifier ASC").Find(&resources)
+	return resources, result.Error
+}
+
+func CreateApiResource(db *gorm.DB, input ApiResourceInput) (*models.ApiResource, error) {
+	if err := normalizeApiResourceInput(db, &input); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	db.Model(&models.ApiResource{}).Where("identifier = ?", input.Identifier).Count(&count)
+	if count > 0 {
+		return nil, errors.New(string(ApiResourceErrorIdentifierTaken))
+	}
+
+	resource := models.ApiResource{
+		Identifier:          input.Identifier,
+		Name:                input.Name,
+		Scopes:              pq.StringArray(input.Scopes),
+		AccessTokenLifetime: input.AccessTokenLifetime,
+		SigningAlgorithm:    input.SigningAlgorithm,
+		SigningSecret:       crypto.GenerateSecureSecret(),
+	}
+
+	if err := db.Create(&resource).Error; err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// UpdateApiResource replaces everything but the identifier, which tokens
+// already in circulation carry as their audience
+func UpdateApiResource(db *gorm.DB, id string, input ApiResourceInput) (*models.ApiResource, error) {
+	var resource models.ApiResource
+	result := db.Limit(1).Find(&resource, "id = ?", id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New(string(ApiResourceErrorResourceNotFound))
+	}
+
+	input.Identifier = resource.Identifier
+	if err := normalizeApiResourceInput(db, &input); err != nil {
+		return nil, err
+	}
+
+	resource.Name = input.Name
+	resource.Scopes = pq.StringArray(input.Scopes)
+	resource.AccessTokenLifetime = input.AccessTokenLifetime
+	resource.SigningAlgorithm = input.SigningAlgorithm
+	if resource.SigningSecret == "" {
+		resource.SigningSecret = crypto.GenerateSecureSecret()
+	}
+
+	if err := db.Save(&resource).Error; err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+func DeleteApiResource(db *gorm.DB, id string) error {
+	result := db.Delete(&models.ApiResource{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(ApiResourceErrorResourceNotFound))
+	}
+	return nil
+}
+
+// ResolveRequestedResources checks the resource parameters of an
+// authorization request. every one of them must be a registered api
+func ResolveRequestedResources(db *gorm.DB, resources []string) ([]string, error) {
+	requested := []string{}
+	for _, resource := range resources {
+		if !slices.Contains(requested, resource) {
+			requested = append(requested, resource)
+		}
+	}
+
+	if len(requested) == 0 {
+		return requested, nil
+	}
+
+	var count int64
+	result := db.Model(&models.ApiResource{}).Where("identifier IN ?", requested).Count(&count)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if int(count) != len(requested) {
+		return nil, errors.New(string(ApiResourceErrorInvalidTarget))
+	}
+
+	return requested, nil
+}
+
+// selectResource picks the api the access token is for out of the resources
+// granted during authorization. with a single granted resource it is used
+// even when the token request doesn't name it
+func selectResource(db *gorm.DB, granted []string, requested string) (*models.ApiResource, error) {
+	if requested == "" {
+		// with several the client has to say which one, a token for
+		// sentinel instead would be a surprise
+		if len(granted) > 1 {
+			return nil, errors.New(string(ApiResourceErrorInvalidTarget))
+		}
+		if len(granted) == 0 {
+			return nil, nil
+		}
+		requested = granted[0]
+	}
+
+	if !slices.Contains(granted, requested) {
+		return nil, errors.New(string(ApiResourceErrorResourceNotGranted))
+	}
+
+	var resource models.ApiResource
+	result := db.Limit(1).Find(&resource, "identifier = ?", requested)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New(string(ApiResourceErrorInvalidTarget))
+	}
+
+	return &resource, nil
+}
+
+type issuedAccessToken struct {
+	Token     string
+	ExpiresIn int
+	Scopes    []string
+}
+
+// issueAccessToken creates the access token for a code or refresh token
+// exchange. without a resource the token is for sentinel itself, signed with
+// the client's secret. with one, the audience is only that api, the scopes are
+// cut down to the ones it understands and its own lifetime and algorithm
+// apply. HS256 tokens for an api are signed with the api's own secret, so
+// clients can't make them up
+func issueAccessToken(db *gorm.DB, client *models.Client, signInProvider string, userData crypto.UserData, scopes []string, resource *models.ApiResource, issuedAt int64) (*issuedAccessToken, error) {
+	signer := crypto.HmacSigner(client.ID, client.Secret)
+	audience := defaultAccessTokenAudience
+	lifetime := defaultAccessTokenLifetime
+	tokenScopes := scopes
+
+	if resource != nil {
+		audience = resource.Identifier
+		lifetime = resource.AccessTokenLifetime
+
+		tokenScopes = []string{}
+		for _, scope := range scopes {
+			if slices.Contains(resource.Scopes, scope) {
+				tokenScopes = append(tokenScopes, scope)
+			}
+		}
+
+		switch resource.SigningAlgorithm {
+		case models.SigningAlgorithmRS256:
+			var err error
+			signer, err = crypto.RsaSigner(db)
+			if err != nil {
+				return nil, err
+			}
+		case models.SigningAlgorithmHS256:
+			// apis registered before they had secrets get one now
+			if resource.SigningSecret == "" {
+				resource.SigningSecret = crypto.GenerateSecureSecret()
+				if err := db.Model(resource).Update("signing_secret", resource.SigningSecret).Error; err != nil {
+					return nil, err
+				}
+			}
+			signer = crypto.HmacSigner(resource.ID, resource.SigningSecret)
+		}
+	}
+
+	token, err := crypto.CreateAccessToken(
+		signer,
+		"",
+		client.ID,
+		signInProvider,
+		userData,
+		crypto.Identities{},
+		tokenScopes,
+		audience,
+		issuedAt,
+		lifetime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issuedAccessToken{
+		Token:     token,
+		ExpiresIn: lifetime,
+		Scopes:    tokenScopes,
+	}, nil
+}
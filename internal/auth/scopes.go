@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"sentinel-auth-backend/internal/validators"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type ScopeError string
+
+const (
+	ScopeErrorInvalidScope     ScopeError = "invalid scope"
+	ScopeErrorScopeExists      ScopeError = "scope already exists"
+	ScopeErrorUnknownScope     ScopeError = "scope does not exist"
+	ScopeErrorStandardScope    ScopeError = "standard scopes can not be changed"
+	ScopeErrorClientNotFound   ScopeError = "client does not exist"
+	ScopeErrorScopeNotGranted  ScopeError = "scope was not granted"
+	ScopeErrorMissingScopeName ScopeError = "missing scope name"
+)
+
+// StandardScopes are the OpenID Connect scopes every deployment has
+var StandardScopes = []models.Scope{
+	{ID: "openid", Description: "Sign you in with your account", IsStandard: true},
+	{ID: "profile", Description: "View your basic profile info", IsStandard: true},
+	{ID: "email", Description: "View your email address", IsStandard: true},
+	{ID: "phone", Description: "View your phone number", IsStandard: true},
+	{ID: "address", Description: "View your address", IsStandard: true},
+	{ID: "offline_access", Description: "Stay signed in when you are not using the app", IsStandard: true},
+}
+
+// scopes granted when a client doesn't ask for any
+var defaultScopes = []string{"openid", "profile"}
+
+// ParseScope splits a space delimited scope parameter into its scopes,
+// dropping duplicates but keeping the order they were requested in
+func ParseScope(scope string) []string {
+	scopes := []string{}
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func standardScopeIds() []string {
+	ids := []string{}
+	for _, scope := range StandardScopes {
+		ids = append(ids, scope.ID)
+	}
+	return ids
+}
+
+// clientAllowedScopes returns the scopes client may request. clients without
+// an explicit list may only use the standard scopes
+func clientAllowedScopes(client *models.Client) []string {
+	if len(client.AllowedScopes) == 0 {
+		return standardScopeIds()
+	}
+	return client.AllowedScopes
+}
+
+func registeredScopeIds(db *gorm.DB, scopes []string) ([]string, error) {
+	var registered []string
+	result := db.Model(&models.Scope{}).Where("id IN ?", scopes).Pluck("id", &registered)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return registered, nil
+}
+
+// ResolveRequestedScopes turns the scope parameter of an authorization request
+// into the scopes that will be attached to the code. every scope must be in
+// the registry and allowed for the client, otherwise the request fails
+func ResolveRequestedScopes(db *gorm.DB, clientId string, requested string) ([]string, error) {
+	var client models.Client
+	result := db.First(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, errors.New(string(ScopeErrorClientNotFound))
+	}
+
+	allowed := clientAllowedScopes(&client)
+
+	scopes := ParseScope(requested)
+	if len(scopes) == 0 {
+		for _, scope := range defaultScopes {
+			if slices.Contains(allowed, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		return scopes, nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(allowed, scope) {
+			return nil, errors.New(string(ScopeErrorInvalidScope))
+		}
+	}
+
+	registered, err := registeredScopeIds(db, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if len(registered) != len(scopes) {
+		return nil, errors.New(string(ScopeErrorInvalidScope))
+	}
+
+	return scopes, nil
+}
+
+// DownscopeScopes narrows granted down to the requested scopes. asking for a
+// scope that was never granted is an error, asking for nothing keeps all
+func DownscopeScopes(granted []string, requested string) ([]string, error) {
+	scopes := ParseScope(requested)
+	if len(scopes) == 0 {
+		return granted, nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return nil, errors.New(string(ScopeErrorScopeNotGranted))
+		}
+	}
+
+	return scopes, nil
+}
+
+func ListScopes(db *gorm.DB) ([]models.Scope, error) {
+	var scopes []models.Scope
+	result := db.Order("is_standard DESC, id ASC").Find(&scopes)
+	return scopes, result.Error
+}
+
+func CreateScope(db *gorm.DB, name string, description string) (*models.Scope, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New(string(ScopeErrorMissingScopeName))
+	}
+	if !validators.IsValidScopeName(name) {
+		return nil, errors.New(string(ScopeErrorInvalidScope))
+	}
+
+	var existing models.Scope
+	result := db.Unscoped().Limit(1).Find(&existing, "id = ?", name)
+	if result.RowsAffected > 0 {
+		if existing.DeletedAt.Valid && !existing.IsStandard {
+			// bring a previously deleted scope back
+			existing.Description = description
+			existing.DeletedAt = gorm.DeletedAt{}
+			if err := db.Unscoped().Save(&existing).Error; err != nil {
+				return nil, err
+			}
+			return &existing, nil
+		}
+		return nil, errors.New(string(ScopeErrorScopeExists))
+	}
+
+	scope := models.Scope{
+		ID:          name,
+		Description: description,
+		IsStandard:  false,
+	}
+	if err := db.Create(&scope).Error; err != nil {
+		return nil, err
+	}
+
+	return &scope, nil
+}
+
+func DeleteScope(db *gorm.DB, name string) error {
+	var scope models.Scope
+	result := db.Limit(1).Find(&scope, "id = ?", name)
+	if result.RowsAffected == 0 {
+		return errors.New(string(ScopeErrorUnknownScope))
+	}
+	if scope.IsStandard {
+		return errors.New(string(ScopeErrorStandardScope))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// drop it from every client that was allowed to request it
+		err := tx.Model(&models.Client{}).
+			Where("? = ANY(allowed_scopes)", name).
+			Update("allowed_scopes", gorm.Expr("array_remove(allowed_scopes, ?)", name)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&scope).Error
+	})
+}
+
+// SetClientAllowedScopes replaces the list of scopes the client may request
+func SetClientAllowedScopes(db *gorm.DB, clientId string, scopes []string) (*models.Client, error) {
+	var client models.Client
+	result := db.First(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, errors.New(string(ScopeErrorClientNotFound))
+	}
+
+	scopes = ParseScope(strings.Join(scopes, " "))
+	registered, err := registeredScopeIds(db, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if len(registered) != len(scopes) {
+		return nil, errors.New(string(ScopeErrorUnknownScope))
+	}
+
+	client.AllowedScopes = pq.StringArray(scopes)
+	if err := db.Model(&client).Update("allowed_scopes", client.AllowedScopes).Error; err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
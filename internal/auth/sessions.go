@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type SessionError string
+
+const (
+	SessionErrorRevoked SessionError = "session was revoked"
+)
+
+func startSession(db *gorm.DB, identity *models.Identity, authTime time.Time, aal int, amr []string) (*models.Session, error) {
+	session := models.Session{
+		UserId:     identity.UserId,
+		ClientId:   identity.ClientId,
+		IdentityId: identity.ID,
+		AuthTime:   authTime,
+		Aal:        aal,
+		Amr:        pq.StringArray(amr),
+	}
+
+	if err := db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// stepUpSession records that the user authenticated again in an existing
+// session, so tokens issued from it carry the new auth_time, acr and amr
+func stepUpSession(db *gorm.DB, sessionId string, authTime time.Time, aal int, amr []string) (*models.Session, error) {
+	result := db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionId).
+		Updates(map[string]interface{}{
+			"auth_time": authTime,
+			"aal":       aal,
+			"amr":       pq.StringArray(amr),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(SessionErrorRevoked))
+	}
+
+	var session models.Session
+	if err := db.First(&session, "id = ?", sessionId).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// sessionAuthentication adds when and how the user signed in to the session
+// to the claims of tokens issued from it
+func sessionAuthentication(db *gorm.DB, sessionId string, userData crypto.UserData) crypto.UserData {
+	if sessionId == "" {
+		return userData
+	}
+
+	var session models.Session
+	result := db.Limit(1).Find(&session, "id = ?", sessionId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return userData
+	}
+	return userData.WithAuthentication(session.AuthTime.Unix(), acrForAal(session.Aal), session.Amr)
+}
+
+// checkSessionActive fails for revoked sessions. tokens issued before
+// sessions existed have no session id and are let through
+func checkSessionActive(db *gorm.DB, sessionId string) error {
+	if sessionId == "" {
+		return nil
+	}
+
+	var session models.Session
+	result := db.Limit(1).Find(&session, "id = ?", sessionId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || session.RevokedAt != nil {
+		return errors.New(string(SessionErrorRevoked))
+	}
+
+	return nil
+}
+
+// sessionAal returns the assurance level of an active session, 0 when the
+// session is unknown or revoked
+func sessionAal(db *gorm.DB, sessionId string) int {
+	var session models.Session
+	result := db.Limit(1).Find(&session, "id = ? AND revoked_at IS NULL", sessionId)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0
+	}
+	return session.Aal
+}
+
+// IsSessionActive is checkSessionActive for callers outside of auth
+func IsSessionActive(db *gorm.DB, sessionId string) bool {
+	return checkSessionActive(db, sessionId) == nil
+}
+
+// revokeSessions signs the user out of every session but keepSessionId.
+// refresh tokens are revoked, codes that were not redeemed yet can no longer
+// be and sign ins that are still in progress are dropped
+func revokeSessions(db *gorm.DB, userId string, keepSessionId string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := tx.NowFunc()
+
+		sessions := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userId)
+		refreshTokens := tx.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked = ?", userId, false)
+		codes := tx.Model(&models.RedeemAuthCode{}).Where("user_id = ? AND redeemed = ? AND expires_at > ?", userId, false, now)
+		if keepSessionId != "" {
+			sessions = sessions.Where("id <> ?", keepSessionId)
+			refreshTokens = refreshTokens.Where("session_id <> ?", keepSessionId)
+			codes = codes.Where("session_id <> ?", keepSessionId)
+		}
+
+		if err := sessions.Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if err := refreshTokens.Update("revoked", true).Error; err != nil {
+			return err
+		}
+		if err := codes.Update("revoked", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userId).Delete(&models.AuthenticationFlow{}).Error
+	})
+}
+
+// RevokeUserSessions signs a user out everywhere
+func RevokeUserSessions(db *gorm.DB, userId string) error {
+	return revokeSessions(db, userId, "")
+}
+
+// RevokeOtherUserSessions signs a user out everywhere except the session the
+// request came from
+func RevokeOtherUserSessions(db *gorm.DB, userId string, currentSessionId string) error {
+	return revokeSessions(db, userId, currentSessionId)
+}
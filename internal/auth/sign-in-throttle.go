@@ -0,0 +1,357 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"math"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SignInThrottleError string
+
+const (
+	SignInThrottleErrorInvalidToken   SignInThrottleError = "invalid or expired unlock token"
+	SignInThrottleErrorUserNotFound   SignInThrottleError = "user not found"
+	SignInThrottleErrorClientNotFound SignInThrottleError = "client not found"
+	SignInThrottleErrorInvalidPolicy  SignInThrottleError = "invalid lockout policy"
+)
+
+// failures of an email that are let through right away, after that every
+// attempt has to wait twice as long as the one before, up to a minute
+const (
+	signInBackoffFreeFailures = 3
+	signInBackoffMax          = time.Minute
+)
+
+// longest a lockout can get however often it happens
+const signInLockoutMax = 24 * time.Hour
+
+// SignInThrottledError is returned while an email or ip has to wait before
+// trying again
+type SignInThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *SignInThrottledError) Error() string {
+	return string(SignInWithEmailErrorThrottled)
+}
+
+func signInBackoff(failures int) time.Duration {
+	if failures < signInBackoffFreeFailures {
+		return 0
+	}
+	delay := time.Second << (failures - signInBackoffFreeFailures)
+	if delay <= 0 || delay > signInBackoffMax {
+		return signInBackoffMax
+	}
+	return delay
+}
+
+func signInLockoutDuration(client *models.Client, lockouts int) time.Duration {
+	duration := time.Duration(client.LockoutDurationSeconds) * time.Second * time.Duration(math.Pow(2, float64(min(lockouts, 16))))
+	if duration <= 0 || duration > signInLockoutMax {
+		return signInLockoutMax
+	}
+	return duration
+}
+
+// failures older than the lockout duration are forgotten
+func signInFailureWindowStart(client *models.Client, now time.Time) time.Time {
+	return now.Add(-time.Duration(client.LockoutDurationSeconds) * time.Second)
+}
+
+func findSignInThrottle(db *gorm.DB, clientId string, kind string, subject string) (*models.SignInThrottle, error) {
+	var throttle models.SignInThrottle
+	result := db.Limit(1).Find(&throttle, "client_id = ? AND kind = ? AND subject = ?", clientId, kind, subject)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &throttle, nil
+}
+
+// checkSignInThrottle fails when kind/subject is locked, or, for emails, when
+// the backoff after the last failure hasn't passed yet
+func checkSignInThrottle(db *gorm.DB, client *models.Client, kind string, subject string, now time.Time) error {
+	if subject == "" {
+		return nil
+	}
+
+	throttle, err := findSignInThrottle(db, client.ID, kind, subject)
+	if err != nil || throttle == nil {
+		return err
+	}
+
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return &SignInThrottledError{RetryAfter: throttle.LockedUntil.Sub(now)}
+	}
+
+	if kind == models.SignInThrottleKindIdentifier && throttle.LastFailureAt.After(signInFailureWindowStart(client, now)) {
+		wait := throttle.LastFailureAt.Add(signInBackoff(throttle.Failures)).Sub(now)
+		if wait > 0 {
+			return &SignInThrottledError{RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+type signInLockout struct {
+	Until       time.Time
+	Lockouts    int
+	UnlockToken string
+}
+
+// recordSignInFailure counts a failed sign in for kind/subject and locks it
+// once the client's threshold is reached. the lockout is returned when this
+// failure caused it
+func recordSignInFailure(db *gorm.DB, client *models.Client, kind string, subject string, threshold int, now time.Time) (*signInLockout, error) {
+	if subject == "" {
+		return nil, nil
+	}
+
+	// one statement, so parallel failures all get counted
+	throttle := models.SignInThrottle{
+		ClientId:      client.ID,
+		Kind:          kind,
+		Subject:       subject,
+		Failures:      1,
+		LastFailureAt: now,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}, {Name: "kind"}, {Name: "subject"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN sign_in_throttles.last_failure_at < ? THEN 1 ELSE sign_in_throttles.failures + 1 END", signInFailureWindowStart(client, now)),
+			"last_failure_at": now,
+			"updated_at":      now,
+		}),
+	}).Create(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := findSignInThrottle(db, client.ID, kind, subject)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	if threshold <= 0 || current.Failures < threshold {
+		return nil, nil
+	}
+
+	lockout := signInLockout{
+		Until:    now.Add(signInLockoutDuration(client, current.Lockouts)),
+		Lockouts: current.Lockouts + 1,
+	}
+	updates := map[string]interface{}{
+		"failures":     0,
+		"locked_until": lockout.Until,
+		"lockouts":     lockout.Lockouts,
+	}
+	if kind == models.SignInThrottleKindIdentifier {
+		lockout.UnlockToken = crypto.GenerateSecureSecret()
+		updates["unlock_token_hash"] = crypto.HashSecret(lockout.UnlockToken)
+	}
+
+	// only the request that crossed the threshold locks
+	result := db.Model(&models.SignInThrottle{}).
+		Where("id = ? AND failures >= ?", current.ID, threshold).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &lockout, nil
+}
+
+// clearSignInFailures forgets the failures of kind/subject after a sign in
+// succeeded. the lockouts are kept, otherwise the owner signing in between
+// would hand whoever guesses their password the shortest lockout again
+func clearSignInFailures(db *gorm.DB, clientId string, kind string, subject string) error {
+	return db.Model(&models.SignInThrottle{}).
+		Where("client_id = ? AND kind = ? AND subject = ? AND failures > 0", clientId, kind, subject).
+		Update("failures", 0).Error
+}
+
+// clearSignInThrottle forgets the failures and lockouts of kind/subject
+func clearSignInThrottle(db *gorm.DB, clientId string, kind string, subject string) error {
+	return db.Where("client_id = ? AND kind = ? AND subject = ?", clientId, kind, subject).
+		Delete(&models.SignInThrottle{}).Error
+}
+
+// signInFailed records a failed password sign in for the email and the ip.
+// lockouts are published as events, and the owner of a registered email gets
+// a link to unlock it. nothing about this shows in the response, so it
+// doesn't tell whether the email is registered
+func signInFailed(db *gorm.DB, mailer mail.Mailer, client *models.Client, identity *models.Identity, input SignInWithEmailInput, email string, now time.Time) {
+	lockout, err := recordSignInFailure(db, client, models.SignInThrottleKindIdentifier, email, client.LockoutThreshold, now)
+	if err != nil {
+		log.Println("failed to record failed sign in:", err)
+	}
+	if lockout != nil {
+		event := events.Event{
+			Type:     events.AccountLocked,
+			ClientId: client.ID,
+			Data: map[string]interface{}{
+				"identifier":   email,
+				"locked_until": lockout.Until,
+				"lockouts":     lockout.Lockouts,
+			},
+		}
+		if identity != nil {
+			event.UserId = identity.UserId
+			if err := sendUnlockEmail(mailer, client, email, lockout, input); err != nil {
+				log.Println("failed to send account locked email:", err)
+			}
+		}
+		events.Publish(event)
+	}
+
+	lockout, err = recordSignInFailure(db, client, models.SignInThrottleKindIp, input.Ip, client.IpLockoutThreshold, now)
+	if err != nil {
+		log.Println("failed to record failed sign in:", err)
+	}
+	if lockout != nil {
+		events.Publish(events.Event{
+			Type:     events.IpLocked,
+			ClientId: client.ID,
+			Data: map[string]interface{}{
+				"ip":           input.Ip,
+				"locked_until": lockout.Until,
+				"lockouts":     lockout.Lockouts,
+			},
+		})
+	}
+}
+
+func sendUnlockEmail(mailer mail.Mailer, client *models.Client, email string, lockout *signInLockout, input SignInWithEmailInput) error {
+	link, ok := emailLink(client, input.RedirectUri, lockout.UnlockToken)
+	if !ok {
+		return errors.New("no redirect uri for the unlock link")
+	}
+
+	message, err := mail.Render(client, mail.TemplateAccountLocked, input.Locale, email, mail.Data{
+		"Link":          link,
+		"LockedMinutes": int(math.Ceil(time.Until(lockout.Until).Minutes())),
+	})
+	if err != nil {
+		return err
+	}
+	return mailer.Send(message)
+}
+
+// UnlockSignIn lifts a lockout with the token from the lockout email
+func UnlockSignIn(db *gorm.DB, token string) error {
+	if token == "" {
+		return errors.New(string(SignInThrottleErrorInvalidToken))
+	}
+
+	var throttle models.SignInThrottle
+	result := db.Limit(1).Find(&throttle, "unlock_token_hash = ? AND locked_until > ?", crypto.HashSecret(token), time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(SignInThrottleErrorInvalidToken))
+	}
+
+	// the token hash is part of the condition so it only works once
+	result = db.Where("id = ? AND unlock_token_hash = ?", throttle.ID, crypto.HashSecret(token)).Delete(&models.SignInThrottle{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(SignInThrottleErrorInvalidToken))
+	}
+
+	event := events.Event{
+		Type:     events.AccountUnlocked,
+		ClientId: throttle.ClientId,
+		Data:     map[string]interface{}{"identifier": throttle.Subject, "by": "email"},
+	}
+	if identity, err := findIdentity(db, throttle.ClientId, "email", throttle.Subject); err == nil {
+		event.UserId = identity.UserId
+	}
+	events.Publish(event)
+
+	return nil
+}
+
+// UnlockUser lifts the lockouts of every email the user signs in with
+func UnlockUser(db *gorm.DB, userId string) error {
+	var user models.User
+	result := db.Limit(1).Find(&user, "id = ?", userId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(SignInThrottleErrorUserNotFound))
+	}
+
+	var identities []models.Identity
+	if err := db.Where("user_id = ? AND provider_option_id = ?", userId, "email").Find(&identities).Error; err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		if err := clearSignInThrottle(db, identity.ClientId, models.SignInThrottleKindIdentifier, identity.ProviderSub); err != nil {
+			return err
+		}
+		events.Publish(events.Event{
+			Type:     events.AccountUnlocked,
+			ClientId: identity.ClientId,
+			UserId:   userId,
+			Data:     map[string]interface{}{"identifier": identity.ProviderSub, "by": "admin"},
+		})
+	}
+
+	return nil
+}
+
+type LockoutPolicy struct {
+	Threshold       int
+	IpThreshold     int
+	DurationSeconds int
+}
+
+// SetClientLockoutPolicy changes when failed password sign ins lock an email
+// or ip of the client, and for how long
+func SetClientLockoutPolicy(db *gorm.DB, clientId string, policy LockoutPolicy) (*models.Client, error) {
+	if policy.Threshold < 0 || policy.IpThreshold < 0 || policy.DurationSeconds < 1 {
+		return nil, errors.New(string(SignInThrottleErrorInvalidPolicy))
+	}
+
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(SignInThrottleErrorClientNotFound))
+	}
+
+	// a map, so zero thresholds are written too
+	err := db.Model(&client).Updates(map[string]interface{}{
+		"lockout_threshold":        policy.Threshold,
+		"ip_lockout_threshold":     policy.IpThreshold,
+		"lockout_duration_seconds": policy.DurationSeconds,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	client.LockoutThreshold = policy.Threshold
+	client.IpLockoutThreshold = policy.IpThreshold
+	client.LockoutDurationSeconds = policy.DurationSeconds
+	return &client, nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var testThrottleClient = &models.Client{ID: "app", LockoutThreshold: 3, IpLockoutThreshold: 100, LockoutDurationSeconds: 60}
+
+func TestSignInBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{signInBackoffFreeFailures - 1, 0},
+		{signInBackoffFreeFailures, time.Second},
+		{signInBackoffFreeFailures + 1, 2 * time.Second},
+		{signInBackoffFreeFailures + 2, 4 * time.Second},
+		{signInBackoffFreeFailures + 6, signInBackoffMax},
+		{1000, signInBackoffMax},
+	}
+
+	for _, test := range tests {
+		if got := signInBackoff(test.failures); got != test.want {
+			t.Errorf("signInBackoff(%d) = %s, want %s", test.failures, got, test.want)
+		}
+	}
+}
+
+func TestSignInLockoutDuration(t *testing.T) {
+	tests := []struct {
+		lockouts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{100, signInLockoutMax},
+	}
+
+	for _, test := range tests {
+		if got := signInLockoutDuration(testThrottleClient, test.lockouts); got != test.want {
+			t.Errorf("signInLockoutDuration(%d) = %s, want %s", test.lockouts, got, test.want)
+		}
+	}
+}
+
+// retryAfter is how long checkSignInThrottle makes the email wait, 0 when it
+// doesn't
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	if err == nil {
+		return 0
+	}
+	var throttled *SignInThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatal(err)
+	}
+	return throttled.RetryAfter
+}
+
+func TestCheckSignInThrottleBacksOff(t *testing.T) {
+	db := testDb(t)
+	client := *testThrottleClient
+	client.LockoutThreshold = 0
+	now := time.Now()
+
+	for i := 0; i < signInBackoffFreeFailures; i++ {
+		if wait := retryAfter(t, checkSignInThrottle(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", now)); wait != 0 {
+			t.Fatalf("failure %d had to wait %s", i, wait)
+		}
+		if _, err := recordSignInFailure(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", client.LockoutThreshold, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if wait := retryAfter(t, checkSignInThrottle(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", now)); wait != time.Second {
+		t.Errorf("waits %s after the free failures, want 1s", wait)
+	}
+	now = now.Add(time.Second)
+	if wait := retryAfter(t, checkSignInThrottle(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", now)); wait != 0 {
+		t.Errorf("still waits %s once the backoff passed", wait)
+	}
+
+	recordSignInFailure(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", client.LockoutThreshold, now)
+	if wait := retryAfter(t, checkSignInThrottle(db, &client, models.SignInThrottleKindIdentifier, "user@example.com", now)); wait != 2*time.Second {
+		t.Errorf("waits %s after another failure, want the backoff doubled to 2s", wait)
+	}
+
+	// ips are only locked, never delayed
+	for i := 0; i < 10; i++ {
+		recordSignInFailure(db, &client, models.SignInThrottleKindIp, "192.0.2.1", client.IpLockoutThreshold, now)
+	}
+	if wait := retryAfter(t, checkSignInThrottle(db, &client, models.SignInThrottleKindIp, "192.0.2.1", now)); wait != 0 {
+		t.Errorf("ip waits %s below its threshold", wait)
+	}
+}
+
+// lockOut fails to sign in until the email is locked
+func lockOut(t *testing.T, db *gorm.DB, now time.Time) *signInLockout {
+	t.Helper()
+
+	for i := 1; i <= testThrottleClient.LockoutThreshold; i++ {
+		lockout, err := recordSignInFailure(db, testThrottleClient, models.SignInThrottleKindIdentifier, "user@example.com", testThrottleClient.LockoutThreshold, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < testThrottleClient.LockoutThreshold && lockout != nil {
+			t.Fatalf("locked after %d failures", i)
+		}
+		if i == testThrottleClient.LockoutThreshold {
+			if lockout == nil {
+				t.Fatal("not locked at the threshold")
+			}
+			return lockout
+		}
+	}
+	return nil
+}
+
+func TestRecordSignInFailureLocksAndDoubles(t *testing.T) {
+	db := testDb(t)
+	now := time.Now()
+
+	lockout := lockOut(t, db, now)
+	if lockout.Lockouts != 1 || !lockout.Until.Equal(now.Add(time.Minute)) || lockout.UnlockToken == "" {
+		t.Errorf("first lockout %+v, want one minute with an unlock token", lockout)
+	}
+	if wait := retryAfter(t, checkSignInThrottle(db, testThrottleClient, models.SignInThrottleKindIdentifier, "user@example.com", now.Add(10*time.Second))); wait != 50*time.Second {
+		t.Errorf("waits %s during the lockout, want the rest of it", wait)
+	}
+
+	// the owner signing in in between doesn't reset the doubling
+	if err := clearSignInFailures(db, "app", models.SignInThrottleKindIdentifier, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	throttle, _ := findSignInThrottle(db, "app", models.SignInThrottleKindIdentifier, "user@example.com")
+	if throttle.Failures != 0 || throttle.Lockouts != 1 {
+		t.Errorf("after a sign in %d failures and %d lockouts, want 0 and 1", throttle.Failures, throttle.Lockouts)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if wait := retryAfter(t, checkSignInThrottle(db, testThrottleClient, models.SignInThrottleKindIdentifier, "user@example.com", now)); wait != 0 {
+		t.Errorf("still waits %s after the lockout", wait)
+	}
+	lockout = lockOut(t, db, now)
+	if lockout.Lockouts != 2 || !lockout.Until.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("second lockout %+v, want it twice as long", lockout)
+	}
+}
+
+func TestUnlockSignIn(t *testing.T) {
+	db := testDb(t)
+	lockout := lockOut(t, db, time.Now())
+
+	if err := UnlockSignIn(db, "wrong token"); err == nil || err.Error() != string(SignInThrottleErrorInvalidToken) {
+		t.Errorf("wrong token: err = %v, want %s", err, SignInThrottleErrorInvalidToken)
+	}
+	if err := UnlockSignIn(db, lockout.UnlockToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSignInThrottle(db, testThrottleClient, models.SignInThrottleKindIdentifier, "user@example.com", time.Now()); err != nil {
+		t.Errorf("still throttled after unlocking: %v", err)
+	}
+	if err := UnlockSignIn(db, lockout.UnlockToken); err == nil || err.Error() != string(SignInThrottleErrorInvalidToken) {
+		t.Errorf("second use: err = %v, want %s", err, SignInThrottleErrorInvalidToken)
+	}
+}
@@ -2,8 +2,13 @@ package auth
 
 import (
 	"errors"
+	"log"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/mail"
 	"sentinel-auth-backend/internal/models"
 	"strings"
+	"sync"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,6 +19,9 @@ const (
 	SignInWithEmailErrorUnknownUser         SignInWithEmailError = "failed to find user"
 	SignInWithEmailErrorPasswordCheckFailed SignInWithEmailError = "failed to verify password"
 	SignInWithEmailErrorBadIdentityData     SignInWithEmailError = "identity data is malformed"
+	SignInWithEmailErrorEmailNotVerified    SignInWithEmailError = "email is not verified"
+	SignInWithEmailErrorThrottled           SignInWithEmailError = "too many failed sign ins"
+	SignInWithEmailErrorProviderDisabled    SignInWithEmailError = "email provider not enabled for client"
 )
 
 func findIdentity(db *gorm.DB, clientId string, providerOptionId string, providerSub string) (*models.Identity, error) {
@@ -29,24 +37,110 @@ func findIdentity(db *gorm.DB, clientId string, providerOptionId string, provide
 	return &identity, nil
 }
 
-func SignInWithEmail(db *gorm.DB, clientId string, email string, password string) (*models.Identity, error) {
-	email = strings.ToLower(strings.Trim(email, " "))
-	identity, err := findIdentity(db, clientId, "email", email)
+// SignInWithEmailInput is a password sign in attempt
+type SignInWithEmailInput struct {
+	ClientId string
+	Email    string
+	Password string
+	// address the attempt came from, failures are also counted per ip
+	Ip string
+	// where the unlock link of a lockout email goes, and its language
+	RedirectUri string
+	Locale      string
+}
 
-	if err != nil {
+// dummyPasswordHash is compared against for unknown emails, so they take as
+// long to reject as wrong passwords
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword(crypto.GenerateSecureSecret())
+	return hash
+})
+
+// SignInWithEmail checks an email and password. failed attempts are counted
+// per email and per ip, with a growing delay between attempts and a lockout
+// once the client's threshold is reached. unknown emails go through the same
+// counting so the responses don't tell which emails are registered. clients
+// with a legacy authenticator have unknown emails checked by their old system
+// first
+func SignInWithEmail(db *gorm.DB, mailer mail.Mailer, input SignInWithEmailInput) (*models.Identity, error) {
+	email := strings.ToLower(strings.Trim(input.Email, " "))
+
+	var client models.Client
+	result := db.Limit(1).Find(&client, "id = ?", input.ClientId)
+	if result.Error != nil || result.RowsAffected == 0 {
 		return nil, errors.New(string(SignInWithEmailErrorUnknownUser))
 	}
+	if _, ok := getEnabledClientProvider(db, input.ClientId, "email"); !ok {
+		return nil, errors.New(string(SignInWithEmailErrorProviderDisabled))
+	}
+
+	now := time.Now()
+	if err := checkSignInThrottle(db, &client, models.SignInThrottleKindIp, input.Ip, now); err != nil {
+		return nil, err
+	}
+	if err := checkSignInThrottle(db, &client, models.SignInThrottleKindIdentifier, email, now); err != nil {
+		return nil, err
+	}
+
+	identity, err := findIdentity(db, input.ClientId, "email", email)
+	if err != nil {
+		// users the client's old auth system accepts move over right here,
+		// the password is then checked against their new hash below
+		identity, err = migrateLegacyUser(db, &client, email, input.Password)
+		if err != nil {
+			switch err {
+			case errNoLegacyAuthenticator:
+				CompareHashAndPassword(dummyPasswordHash(), input.Password)
+			case errLegacyUserRejected:
+			default:
+				log.Println("legacy sign in failed:", err)
+			}
+			signInFailed(db, mailer, &client, nil, input, email, now)
+			return nil, errors.New(string(SignInWithEmailErrorUnknownUser))
+		}
+	}
 
 	hash := identity.Data["password_hash"]
 
 	switch v := hash.(type) {
 	case string:
-		matches, _ := CompareHashAndPassword(v, password)
+		matches, _ := CompareHashAndPassword(v, input.Password)
 		if !matches {
+			signInFailed(db, mailer, &client, identity, input, email, now)
 			return nil, errors.New(string(SignInWithEmailErrorPasswordCheckFailed))
 		}
+		if err := clearSignInFailures(db, client.ID, models.SignInThrottleKindIdentifier, email); err != nil {
+			return nil, err
+		}
+		if PasswordNeedsRehash(v) {
+			rehashPassword(db, identity, v, input.Password)
+		}
+		// only checked after the password so it doesn't reveal registered emails
+		if identity.Client.RequireEmailVerification && !identity.EmailVerified {
+			return nil, errors.New(string(SignInWithEmailErrorEmailNotVerified))
+		}
 		return identity, nil
 	default:
 		return nil, errors.New(string(SignInWithEmailErrorBadIdentityData))
 	}
 }
+
+// rehashPassword upgrades the identity's hash to the current algorithm and
+// parameters while the plain password is at hand. the sign in goes ahead
+// when this fails, it's tried again the next time
+func rehashPassword(db *gorm.DB, identity *models.Identity, oldHash string, password string) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		log.Println("failed to rehash password:", err)
+		return
+	}
+
+	identity.Data["password_hash"] = hash
+	// only if the password didn't change in the meantime
+	err = db.Model(&models.Identity{}).
+		Where("id = ? AND data->>'password_hash' = ?", identity.ID, oldHash).
+		Update("data", identity.Data).Error
+	if err != nil {
+		log.Println("failed to rehash password:", err)
+	}
+}
@@ -0,0 +1,13 @@
+package auth
+
+import "testing"
+
+func TestSignInWithEmailRefusesDisabledProvider(t *testing.T) {
+	db := testDb(t)
+	db.Exec("UPDATE client_providers SET enabled = false WHERE id = 'app-email'")
+
+	_, err := SignInWithEmail(db, nil, SignInWithEmailInput{ClientId: "app", Email: "user@example.com", Password: "password"})
+	if err == nil || err.Error() != string(SignInWithEmailErrorProviderDisabled) {
+		t.Errorf("err = %v, want %s", err, SignInWithEmailErrorProviderDisabled)
+	}
+}
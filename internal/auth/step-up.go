@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StepUpError string
+
+const (
+	StepUpErrorLoginRequired StepUpError = "session is too old, sign in again"
+	StepUpErrorUnmet         StepUpError = "requested authentication level can not be reached"
+)
+
+// acr values, one per authenticator assurance level
+const (
+	AcrAal1 = "aal1"
+	AcrAal2 = "aal2"
+)
+
+func acrForAal(aal int) string {
+	if aal >= 2 {
+		return AcrAal2
+	}
+	return AcrAal1
+}
+
+// requestedAal reads acr_values, which are in order of preference. wanted is
+// the level to aim for, minimum the lowest one the client still accepts.
+// unknown values are ignored
+func requestedAal(acrValues []string) (wanted int, minimum int) {
+	for _, value := range acrValues {
+		aal := 0
+		switch value {
+		case AcrAal1:
+			aal = 1
+		case AcrAal2:
+			aal = 2
+		default:
+			continue
+		}
+		if wanted == 0 {
+			wanted = aal
+		}
+		if minimum == 0 || aal < minimum {
+			minimum = aal
+		}
+	}
+	return wanted, minimum
+}
+
+// providerAmr is the amr (RFC 8176) of signing in with a provider
+func providerAmr(providerOptionId string) []string {
+	switch providerOptionId {
+	case "email":
+		return []string{"pwd"}
+	case magicLinkProviderOptionId, emailOtpProviderOptionId:
+		return []string{"otp"}
+	case phoneProviderOptionId:
+		return []string{"sms"}
+	case passkeyProviderOptionId:
+		return []string{"hwk", "user"}
+	}
+	return nil
+}
+
+// factorAmr is the amr of answering the second factor step with factorType
+func factorAmr(factorType string) string {
+	if factorType == models.MfaFactorTypePasskey {
+		return "hwk"
+	}
+	return "otp"
+}
+
+// StepUpInput is an authorization request made with an existing session
+type StepUpInput struct {
+	// acr_values go in Authorization.AcrValues
+	Authorization AuthorizationParams
+	// seconds since the user last authenticated after which they have to
+	// sign in again
+	MaxAge *int
+}
+
+// StepUp issues a new code for the session the request came from. when the
+// session is weaker than acr_values asks for the user is sent to the second
+// factor step first, and when it is older than max_age to the reauthenticate
+// step. users without a password or second factor to reauthenticate with
+// have to sign in again
+func StepUp(db *gorm.DB, userId string, sessionId string, input StepUpInput) (*AuthorizationResult, error) {
+	if err := checkCodeChallenge(input.Authorization.CodeChallenge, input.Authorization.CodeChallengeMethod); err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	result := db.Preload("Identity").Limit(1).Find(&session, "id = ? AND user_id = ? AND revoked_at IS NULL", sessionId, userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if sessionId == "" || result.RowsAffected == 0 {
+		return nil, errors.New(string(SessionErrorRevoked))
+	}
+
+	// sessions below aal2 of users with a second factor always go through
+	// the mfa step, so only users without one can fall short. checked first
+	// so nobody reauthenticates for a level they can't reach
+	wanted, minimum := requestedAal(input.Authorization.AcrValues)
+	if wanted > session.Aal {
+		enrolled, err := hasMfa(db, userId)
+		if err != nil {
+			return nil, err
+		}
+		if !enrolled && minimum > session.Aal {
+			return nil, errors.New(string(StepUpErrorUnmet))
+		}
+	}
+
+	params := input.Authorization
+	params.AuthTime = session.AuthTime
+	params.Aal = session.Aal
+	params.Amr = session.Amr
+	params.SessionId = session.ID
+
+	if input.MaxAge != nil && time.Since(session.AuthTime) > time.Duration(*input.MaxAge)*time.Second {
+		factors, err := reauthenticationFactors(db, userId)
+		if err != nil {
+			return nil, err
+		}
+		if len(factors) == 0 {
+			return nil, errors.New(string(StepUpErrorLoginRequired))
+		}
+
+		pending, err := createAuthenticationFlow(db, &session.Identity, params, models.AuthenticationFlowStepReauthenticate)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthorizationResult{Pending: pending, State: params.State}, nil
+	}
+
+	return CompleteAuthorization(db, &session.Identity, params)
+}
+
+func appendAmr(amr []string, methods ...string) []string {
+	for _, method := range methods {
+		if !slices.Contains(amr, method) {
+			amr = append(amr, method)
+		}
+	}
+	return amr
+}
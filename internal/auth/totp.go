@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"errors"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TotpEnrollment struct {
+	FactorId string
+	Secret   string
+	// otpauth uri for authenticator apps, usually shown as a qr code
+	Uri string
+}
+
+// totpAccountName is what authenticator apps list the code under
+func totpAccountName(db *gorm.DB, user *models.User) string {
+	if user.Email != "" {
+		return user.Email
+	}
+	if phone, _, ok := findUserPhone(db, user.ID); ok {
+		return phone
+	}
+	return user.ID
+}
+
+// EnrollTotp starts setting up an authenticator app. the factor is only used
+// for sign ins once ConfirmTotp saw a code from the app. starting again
+// replaces a setup that was never confirmed. the session has to be at aal2
+// or signed in within the last minutes
+func EnrollTotp(db *gorm.DB, userId string, sessionId string) (*TotpEnrollment, error) {
+	if err := CheckRecentOrAal2Authentication(db, sessionId); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userId).Error; err != nil {
+		return nil, err
+	}
+
+	var client models.Client
+	if err := db.First(&client, "id = ?", user.ClientId).Error; err != nil {
+		return nil, err
+	}
+
+	var confirmed int64
+	err := db.Model(&models.MfaFactor{}).
+		Where("user_id = ? AND type = ? AND confirmed_at IS NOT NULL", userId, models.MfaFactorTypeTotp).
+		Count(&confirmed).Error
+	if err != nil {
+		return nil, err
+	}
+	if confirmed > 0 {
+		return nil, errors.New(string(MfaErrorAlreadyEnrolled))
+	}
+
+	factor := models.MfaFactor{
+		UserId: userId,
+		Type:   models.MfaFactorTypeTotp,
+		Secret: crypto.GenerateTotpSecret(),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND type = ? AND confirmed_at IS NULL", userId, models.MfaFactorTypeTotp).
+			Delete(&models.MfaFactor{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&factor).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TotpEnrollment{
+		FactorId: factor.ID,
+		Secret:   factor.Secret,
+		Uri:      crypto.TotpUri(factor.Secret, client.Name, totpAccountName(db, &user)),
+	}, nil
+}
+
+// ConfirmTotp finishes an authenticator app setup with the first code the
+// app shows. from then on sign ins ask for a code. users setting up their
+// first second factor get their recovery codes here
+func ConfirmTotp(db *gorm.DB, userId string, code string) ([]string, error) {
+	var factor models.MfaFactor
+	result := db.Where("user_id = ? AND type = ? AND confirmed_at IS NULL", userId, models.MfaFactorTypeTotp).
+		Order("created_at DESC").Limit(1).Find(&factor)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(MfaErrorNoPendingEnrollment))
+	}
+
+	now := time.Now()
+	step, ok := crypto.ValidateTotp(factor.Secret, code, now, factor.LastUsedStep)
+	if !ok {
+		return nil, errors.New(string(MfaErrorInvalidCode))
+	}
+
+	var recoveryCodes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&factor).Updates(map[string]interface{}{
+			"confirmed_at":   now,
+			"last_used_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = issueInitialRecoveryCodes(tx, userId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// verifyTotpFactor checks a code from the user's authenticator app. each
+// code is accepted once
+func verifyTotpFactor(db *gorm.DB, userId string, code string) error {
+	var factor models.MfaFactor
+	result := db.Where("user_id = ? AND type = ? AND confirmed_at IS NOT NULL", userId, models.MfaFactorTypeTotp).
+		Limit(1).Find(&factor)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(MfaErrorUnsupportedType))
+	}
+
+	step, ok := crypto.ValidateTotp(factor.Secret, code, time.Now(), factor.LastUsedStep)
+	if !ok {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+
+	// store the step in one statement so a parallel request can't use the code too
+	result = db.Model(&models.MfaFactor{}).
+		Where("id = ? AND last_used_step < ?", factor.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(MfaErrorInvalidCode))
+	}
+	touchMfaFactor(db, &factor)
+
+	return nil
+}
+
+// RemoveTotp turns the authenticator app off. only sessions that were signed
+// in with a second factor may do that
+func RemoveTotp(db *gorm.DB, userId string, sessionId string) error {
+	if err := requireAal2(db, sessionId); err != nil {
+		return err
+	}
+
+	result := db.Where("user_id = ? AND type = ?", userId, models.MfaFactorTypeTotp).Delete(&models.MfaFactor{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(string(MfaErrorNotEnrolled))
+	}
+	return dropUnneededRecoveryCodes(db, userId)
+}
@@ -0,0 +1,451 @@
+package auth
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"sentinel-auth-backend/internal/events"
+	"sentinel-auth-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserExportError string
+
+const (
+	UserExportErrorUserNotFound UserExportError = "user not found"
+	UserExportErrorJobNotFound  UserExportError = "export not found"
+	UserExportErrorNotReady     UserExportError = "export not ready"
+	UserExportErrorExpired      UserExportError = "export expired"
+)
+
+const (
+	// how long a finished archive can be downloaded
+	userExportLifetime = 7 * 24 * time.Hour
+	// running jobs not done after this are taken to have died with their
+	// instance and are started again
+	userExportStaleAfter = 10 * time.Minute
+)
+
+// bumped whenever the layout of the archive changes
+const userExportFormatVersion = 1
+
+// RequestUserExport starts building an archive of everything stored about the
+// user. while one is still being built that one is returned instead. admins
+// can export deleted users until they are purged
+func RequestUserExport(db *gorm.DB, userId string, requestedBy string) (*models.ExportJob, error) {
+	query := db
+	if requestedBy == models.ExportRequestedByAdmin {
+		query = db.Unscoped()
+	}
+
+	var user models.User
+	result := query.Limit(1).Find(&user, "id = ?", userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(UserExportErrorUserNotFound))
+	}
+
+	var job models.ExportJob
+	result = db.Omit("archive").Limit(1).Find(&job, "user_id = ? AND status IN ?", userId,
+		[]string{models.ExportJobStatusPending, models.ExportJobStatusRunning})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &job, nil
+	}
+
+	job = models.ExportJob{
+		UserId:      userId,
+		RequestedBy: requestedBy,
+		Status:      models.ExportJobStatusPending,
+	}
+	if err := db.Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go runUserExport(db, job.ID)
+
+	return &job, nil
+}
+
+// GetUserExport returns one of the user's exports, without its archive
+func GetUserExport(db *gorm.DB, userId string, jobId string) (*models.ExportJob, error) {
+	var job models.ExportJob
+	result := db.Omit("archive").Limit(1).Find(&job, "id = ? AND user_id = ?", jobId, userId)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(string(UserExportErrorJobNotFound))
+	}
+	return &job, nil
+}
+
+// DownloadUserExport returns the archive of a finished export
+func DownloadUserExport(db *gorm.DB, userId string, jobId string) (*models.ExportJob, error) {
+	job, err := GetUserExport(db, userId, jobId)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.ExportJobStatusDone {
+		return nil, errors.New(string(UserExportErrorNotReady))
+	}
+	if job.ExpiresAt == nil || job.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New(string(UserExportErrorExpired))
+	}
+
+	var archives [][]byte
+	if err := db.Model(&models.ExportJob{}).Where("id = ?", job.ID).Pluck("archive", &archives).Error; err != nil {
+		return nil, err
+	}
+	if len(archives) == 0 {
+		return nil, errors.New(string(UserExportErrorJobNotFound))
+	}
+	job.Archive = archives[0]
+	return job, nil
+}
+
+// RunUserExports starts jobs that were left pending or died with their
+// instance, and drops archives past their expiry
+func RunUserExports(db *gorm.DB) {
+	now := time.Now()
+
+	var jobIds []string
+	err := db.Model(&models.ExportJob{}).
+		Where("status = ? OR (status = ? AND started_at < ?)", models.ExportJobStatusPending, models.ExportJobStatusRunning, now.Add(-userExportStaleAfter)).
+		Pluck("id", &jobIds).Error
+	if err != nil {
+		log.Println("failed to find export jobs:", err)
+	}
+	for _, jobId := range jobIds {
+		runUserExport(db, jobId)
+	}
+
+	if err := db.Where("expires_at < ?", now).Delete(&models.ExportJob{}).Error; err != nil {
+		log.Println("failed to delete expired exports:", err)
+	}
+}
+
+// StartUserExportWorker calls RunUserExports every interval
+func StartUserExportWorker(db *gorm.DB, interval time.Duration) {
+	go func() {
+		for {
+			RunUserExports(db)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+func runUserExport(db *gorm.DB, jobId string) {
+	now := time.Now()
+
+	// only one instance gets to build each job
+	result := db.Model(&models.ExportJob{}).
+		Where("id = ? AND (status = ? OR (status = ? AND started_at < ?))", jobId,
+			models.ExportJobStatusPending, models.ExportJobStatusRunning, now.Add(-userExportStaleAfter)).
+		Updates(map[string]interface{}{"status": models.ExportJobStatusRunning, "started_at": now})
+	if result.Error != nil {
+		log.Println("failed to start export:", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	var job models.ExportJob
+	if err := db.Omit("archive").First(&job, "id = ?", jobId).Error; err != nil {
+		log.Println("failed to load export:", err)
+		return
+	}
+
+	archive, err := buildUserArchive(db, job.UserId, now)
+	if err != nil {
+		log.Println("failed to build export:", err)
+		db.Model(&job).Updates(map[string]interface{}{"status": models.ExportJobStatusFailed, "completed_at": time.Now()})
+		return
+	}
+
+	completedAt := time.Now()
+	err = db.Model(&job).Updates(map[string]interface{}{
+		"status":       models.ExportJobStatusDone,
+		"archive":      archive,
+		"size":         len(archive),
+		"completed_at": completedAt,
+		"expires_at":   completedAt.Add(userExportLifetime),
+	}).Error
+	if err != nil {
+		log.Println("failed to save export:", err)
+		return
+	}
+
+	var user models.User
+	db.Unscoped().Limit(1).Find(&user, "id = ?", job.UserId)
+	events.Publish(events.Event{
+		Type:     events.UserExported,
+		ClientId: user.ClientId,
+		UserId:   job.UserId,
+		Data:     map[string]interface{}{"export_id": job.ID, "requested_by": job.RequestedBy},
+	})
+}
+
+type userExportInfo struct {
+	FormatVersion int       `json:"format_version"`
+	UserId        string    `json:"user_id"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+type userExportUser struct {
+	Id        string     `json:"id"`
+	ClientId  string     `json:"client_id"`
+	Email     string     `json:"email,omitempty"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type userExportIdentity struct {
+	Id            string                 `json:"id"`
+	ClientId      string                 `json:"client_id"`
+	Provider      string                 `json:"provider"`
+	Subject       string                 `json:"subject"`
+	EmailVerified bool                   `json:"email_verified"`
+	Phone         *string                `json:"phone,omitempty"`
+	PhoneVerified bool                   `json:"phone_verified"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+type userExportSession struct {
+	Id        string     `json:"id"`
+	ClientId  string     `json:"client_id"`
+	AuthTime  time.Time  `json:"auth_time"`
+	Aal       int        `json:"aal"`
+	Amr       []string   `json:"amr"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type userExportConsent struct {
+	ClientId   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scopes     []string  `json:"scopes"`
+	GrantedAt  time.Time `json:"granted_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type userExportMfaFactor struct {
+	Id          string     `json:"id"`
+	Type        string     `json:"type"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type userExportPasskey struct {
+	Id             string     `json:"id"`
+	ClientId       string     `json:"client_id"`
+	Name           string     `json:"name"`
+	BackupEligible bool       `json:"backup_eligible"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type userExportEvent struct {
+	Type      string                 `json:"type"`
+	ClientId  string                 `json:"client_id,omitempty"`
+	Data      map[string]interface{} `json:"data"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// identity data that is exported, the profile claims providers hand out.
+// everything else stays on the server, so a password hash or token stored
+// under a new name can't end up in an archive
+var exportedIdentityAttributes = map[string]bool{
+	"name":               true,
+	"given_name":         true,
+	"family_name":        true,
+	"middle_name":        true,
+	"nickname":           true,
+	"preferred_username": true,
+	"profile":            true,
+	"picture":            true,
+	"website":            true,
+	"gender":             true,
+	"birthdate":          true,
+	"zoneinfo":           true,
+	"locale":             true,
+}
+
+func exportIdentityAttributes(data models.JsonDictionary) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for key, value := range data {
+		if exportedIdentityAttributes[key] {
+			attributes[key] = value
+		}
+	}
+	return attributes
+}
+
+// buildUserArchive zips one json file per kind of data stored about the user
+func buildUserArchive(db *gorm.DB, userId string, now time.Time) ([]byte, error) {
+	var user models.User
+	// deleted users can still be exported until they are purged
+	if err := db.Unscoped().First(&user, "id = ?", userId).Error; err != nil {
+		return nil, err
+	}
+	exportUser := userExportUser{
+		Id:        user.ID,
+		ClientId:  user.ClientId,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		exportUser.DeletedAt = &user.DeletedAt.Time
+	}
+
+	var identities []models.Identity
+	if err := db.Unscoped().Where("user_id = ?", userId).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	exportIdentities := []userExportIdentity{}
+	for _, identity := range identities {
+		exportIdentities = append(exportIdentities, userExportIdentity{
+			Id:            identity.ID,
+			ClientId:      identity.ClientId,
+			Provider:      identity.ProviderOptionId,
+			Subject:       identity.ProviderSub,
+			EmailVerified: identity.EmailVerified,
+			Phone:         identity.Phone,
+			PhoneVerified: identity.PhoneVerified,
+			Attributes:    exportIdentityAttributes(identity.Data),
+			CreatedAt:     identity.CreatedAt,
+			UpdatedAt:     identity.UpdatedAt,
+		})
+	}
+
+	var sessions []models.Session
+	if err := db.Where("user_id = ?", userId).Order("created_at").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	exportSessions := []userExportSession{}
+	for _, session := range sessions {
+		exportSessions = append(exportSessions, userExportSession{
+			Id:        session.ID,
+			ClientId:  session.ClientId,
+			AuthTime:  session.AuthTime,
+			Aal:       session.Aal,
+			Amr:       append([]string{}, session.Amr...),
+			RevokedAt: session.RevokedAt,
+			CreatedAt: session.CreatedAt,
+		})
+	}
+
+	var grants []models.ConsentGrant
+	if err := db.Preload("Client").Where("user_id = ?", userId).Order("created_at").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	exportConsents := []userExportConsent{}
+	for _, grant := range grants {
+		exportConsents = append(exportConsents, userExportConsent{
+			ClientId:   grant.ClientId,
+			ClientName: grant.Client.Name,
+			Scopes:     append([]string{}, grant.Scopes...),
+			GrantedAt:  grant.CreatedAt,
+			UpdatedAt:  grant.UpdatedAt,
+		})
+	}
+
+	var factors []models.MfaFactor
+	if err := db.Where("user_id = ?", userId).Order("created_at").Find(&factors).Error; err != nil {
+		return nil, err
+	}
+	exportFactors := []userExportMfaFactor{}
+	for _, factor := range factors {
+		exportFactors = append(exportFactors, userExportMfaFactor{
+			Id:          factor.ID,
+			Type:        factor.Type,
+			ConfirmedAt: factor.ConfirmedAt,
+			LastUsedAt:  factor.LastUsedAt,
+			CreatedAt:   factor.CreatedAt,
+		})
+	}
+
+	var passkeys []models.PasskeyCredential
+	if err := db.Where("user_id = ?", userId).Order("created_at").Find(&passkeys).Error; err != nil {
+		return nil, err
+	}
+	exportPasskeys := []userExportPasskey{}
+	for _, passkey := range passkeys {
+		exportPasskeys = append(exportPasskeys, userExportPasskey{
+			Id:             passkey.ID,
+			ClientId:       passkey.ClientId,
+			Name:           passkey.Name,
+			BackupEligible: passkey.BackupEligible,
+			LastUsedAt:     passkey.LastUsedAt,
+			CreatedAt:      passkey.CreatedAt,
+		})
+	}
+
+	var auditEvents []models.AuditEvent
+	if err := db.Where("user_id = ?", userId).Order("created_at").Find(&auditEvents).Error; err != nil {
+		return nil, err
+	}
+	exportEvents := []userExportEvent{}
+	for _, event := range auditEvents {
+		data := map[string]interface{}{}
+		for key, value := range event.Data {
+			data[key] = value
+		}
+		exportEvents = append(exportEvents, userExportEvent{
+			Type:      event.Type,
+			ClientId:  event.ClientId,
+			Data:      data,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"export.json", userExportInfo{FormatVersion: userExportFormatVersion, UserId: userId, GeneratedAt: now}},
+		{"user.json", exportUser},
+		{"identities.json", exportIdentities},
+		{"sessions.json", exportSessions},
+		{"consents.json", exportConsents},
+		{"mfa_factors.json", exportFactors},
+		{"passkeys.json", exportPasskeys},
+		{"events.json", exportEvents},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fileWriter.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
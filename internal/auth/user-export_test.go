@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"reflect"
+	"sentinel-auth-backend/internal/models"
+	"testing"
+)
+
+func TestExportIdentityAttributes(t *testing.T) {
+	data := models.JsonDictionary{
+		"name":          "Ada Lovelace",
+		"picture":       "https://example.com/ada.png",
+		"locale":        "en-GB",
+		"password_hash": "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+		"refresh_tkn":   "opaque",
+		"mfa_seed":      "GEZDGNBVGY3TQOJQ",
+		"Name":          "not the claim",
+	}
+
+	got := exportIdentityAttributes(data)
+	want := map[string]interface{}{
+		"name":    "Ada Lovelace",
+		"picture": "https://example.com/ada.png",
+		"locale":  "en-GB",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("exported %v, want %v", got, want)
+	}
+
+	if got := exportIdentityAttributes(nil); got == nil || len(got) != 0 {
+		t.Errorf("identity without data exported %v, want an empty object", got)
+	}
+}
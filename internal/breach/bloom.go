@@ -0,0 +1,138 @@
+package breach
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+// written at the start of filter files
+const bloomMagic = "SNTLBLM1"
+
+// BloomFilter holds the sha1 of every breached password in much less space
+// than the hashes themselves. it never misses a breached password but claims
+// a small share of other passwords were breached too, which only means they
+// are refused or warned about by mistake
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint32
+}
+
+// NewBloomFilter sizes a filter for entries passwords, wrongly matching about
+// falsePositiveRate of others
+func NewBloomFilter(entries uint64, falsePositiveRate float64) *BloomFilter {
+	if entries < 1 {
+		entries = 1
+	}
+	size := uint64(math.Ceil(-float64(entries) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint32(math.Max(1, math.Round(float64(size)/float64(entries)*math.Ln2)))
+	return &BloomFilter{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+}
+
+// the bits of a hash, derived from its first 16 bytes by double hashing.
+// sha1 output is uniform enough to be used directly
+func (f *BloomFilter) positions(sum []byte, visit func(uint64) bool) {
+	first := binary.BigEndian.Uint64(sum[0:8])
+	second := binary.BigEndian.Uint64(sum[8:16]) | 1
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		if !visit((first + i*second) % f.size) {
+			return
+		}
+	}
+}
+
+// AddHash adds a password by its hex sha1, as listed in breach corpora
+func (f *BloomFilter) AddHash(hash string) error {
+	sum, err := hex.DecodeString(hash)
+	if err != nil || len(sum) != 20 {
+		return errors.New("breach: invalid sha1 " + hash)
+	}
+	f.positions(sum, func(bit uint64) bool {
+		f.bits[bit/64] |= 1 << (bit % 64)
+		return true
+	})
+	return nil
+}
+
+func (f *BloomFilter) containsHash(hash string) bool {
+	sum, err := hex.DecodeString(hash)
+	if err != nil || len(sum) != 20 {
+		return false
+	}
+	found := true
+	f.positions(sum, func(bit uint64) bool {
+		found = f.bits[bit/64]&(1<<(bit%64)) != 0
+		return found
+	})
+	return found
+}
+
+// Count is 1 for passwords in the filter, it can't tell how often they were
+// seen
+func (f *BloomFilter) Count(password string) (int, error) {
+	if f.containsHash(Hash(password)) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	buffered := bufio.NewWriter(w)
+	header := make([]byte, 0, len(bloomMagic)+12)
+	header = append(header, bloomMagic...)
+	header = binary.BigEndian.AppendUint64(header, f.size)
+	header = binary.BigEndian.AppendUint32(header, f.hashes)
+	if _, err := buffered.Write(header); err != nil {
+		return 0, err
+	}
+	word := make([]byte, 8)
+	for _, bits := range f.bits {
+		binary.BigEndian.PutUint64(word, bits)
+		if _, err := buffered.Write(word); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(header) + len(f.bits)*8), buffered.Flush()
+}
+
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	buffered := bufio.NewReader(r)
+	header := make([]byte, len(bloomMagic)+12)
+	if _, err := io.ReadFull(buffered, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(bloomMagic)]) != bloomMagic {
+		return nil, errors.New("breach: not a bloom filter file")
+	}
+
+	size := binary.BigEndian.Uint64(header[len(bloomMagic):])
+	hashes := binary.BigEndian.Uint32(header[len(bloomMagic)+8:])
+	if size == 0 || hashes == 0 {
+		return nil, errors.New("breach: corrupt bloom filter file")
+	}
+
+	filter := &BloomFilter{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+	// word by word, filters of the full corpora take gigabytes
+	word := make([]byte, 8)
+	for i := range filter.bits {
+		if _, err := io.ReadFull(buffered, word); err != nil {
+			return nil, err
+		}
+		filter.bits[i] = binary.BigEndian.Uint64(word)
+	}
+	return filter, nil
+}
+
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBloomFilter(file)
+}
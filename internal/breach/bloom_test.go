@@ -0,0 +1,76 @@
+package breach
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilterContainsAddedPasswords(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.001)
+	for i := 0; i < 1000; i++ {
+		if err := filter.AddHash(Hash(fmt.Sprintf("breached-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a bloom filter never misses what was added
+	for i := 0; i < 1000; i++ {
+		if count, _ := filter.Count(fmt.Sprintf("breached-%d", i)); count != 1 {
+			t.Fatalf("breached-%d missed", i)
+		}
+	}
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	const entries, rate = 10000, 0.01
+	filter := NewBloomFilter(entries, rate)
+	for i := 0; i < entries; i++ {
+		filter.AddHash(Hash(fmt.Sprintf("breached-%d", i)))
+	}
+
+	falsePositives := 0
+	const others = 100000
+	for i := 0; i < others; i++ {
+		if count, _ := filter.Count(fmt.Sprintf("other-%d", i)); count > 0 {
+			falsePositives++
+		}
+	}
+	// the sizing aims at rate, allow for chance
+	if measured := float64(falsePositives) / others; measured > rate*1.5 {
+		t.Errorf("%.4f of other passwords matched, sized for %.4f", measured, rate)
+	}
+}
+
+func TestBloomFilterRejectsInvalidHashes(t *testing.T) {
+	filter := NewBloomFilter(10, 0.01)
+	for _, hash := range []string{"", "not hex", "5BAA6", Hash("password") + "00"} {
+		if err := filter.AddHash(hash); err == nil {
+			t.Errorf("AddHash(%q) accepted", hash)
+		}
+	}
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	filter := NewBloomFilter(100, 0.01)
+	filter.AddHash(Hash("password"))
+
+	var file bytes.Buffer
+	if _, err := filter.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBloomFilter(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := read.Count("password"); count != 1 {
+		t.Error("read filter lost the password")
+	}
+	if read.size != filter.size || read.hashes != filter.hashes {
+		t.Errorf("read %d bits and %d hashes, wrote %d and %d", read.size, read.hashes, filter.size, filter.hashes)
+	}
+
+	if _, err := ReadBloomFilter(bytes.NewReader([]byte("NOTBLOOM and some more bytes"))); err == nil {
+		t.Error("read a file that isn't a filter")
+	}
+}
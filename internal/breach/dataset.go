@@ -0,0 +1,112 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Dataset tells whether a password showed up in known breaches. lookups
+// never leave the machine
+type Dataset interface {
+	// Count is how often password was seen in breaches, 0 when never
+	Count(password string) (int, error)
+}
+
+// Hash is the uppercase hex sha1 passwords are listed under
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// RangeDirectory reads a copy of the Have I Been Pwned range api: one file
+// per five character hash prefix, named after the prefix with or without a
+// .txt extension, listing the remaining 35 characters and a count per line
+// like 0018A45C4D1DEF81644B54AB7F969B88D65:10
+type RangeDirectory struct {
+	dir string
+}
+
+func OpenRangeDirectory(dir string) (*RangeDirectory, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New("breach: " + dir + " is not a directory")
+	}
+	return &RangeDirectory{dir: dir}, nil
+}
+
+func (d *RangeDirectory) Count(password string) (int, error) {
+	hash := Hash(password)
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(d.dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(d.dir, prefix+".txt"))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, count, ok := ParseRangeLine(scanner.Text())
+		if ok && strings.EqualFold(lineSuffix, suffix) {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// ParseRangeLine splits a HASH:COUNT line. padding lines with a count of 0,
+// which the range api adds to hide response sizes, are reported as not ok
+func ParseRangeLine(line string) (string, int, bool) {
+	hash, countText, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found {
+		return "", 0, false
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 1 {
+		return "", 0, false
+	}
+	return hash, count, true
+}
+
+// Open loads the dataset at path, a range directory or a bloom filter file
+// written by cmd/breach-filter
+func Open(path string) (Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return OpenRangeDirectory(path)
+	}
+	return LoadBloomFilter(path)
+}
+
+// the dataset passwords are screened against, none until one is loaded
+var defaultDataset Dataset
+
+func SetDataset(dataset Dataset) {
+	defaultDataset = dataset
+}
+
+// Count looks password up in the loaded dataset. it is 0 when none is loaded
+func Count(password string) (int, error) {
+	if defaultDataset == nil {
+		return 0, nil
+	}
+	return defaultDataset.Count(password)
+}
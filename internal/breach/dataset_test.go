@@ -0,0 +1,98 @@
+package breach
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	if hash := Hash("password"); hash != "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8" {
+		t.Errorf("Hash(password) = %s", hash)
+	}
+}
+
+func TestParseRangeLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		hash  string
+		count int
+		ok    bool
+	}{
+		{"1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824", "1E4C9B93F3F0682250B6CF8331B7EE68FD8", 9545824, true},
+		{" 1E4C9B93F3F0682250B6CF8331B7EE68FD8:3\r", "1E4C9B93F3F0682250B6CF8331B7EE68FD8", 3, true},
+		// padding the range api adds
+		{"0000000000000000000000000000000000A:0", "", 0, false},
+		{"1E4C9B93F3F0682250B6CF8331B7EE68FD8", "", 0, false},
+		{"1E4C9B93F3F0682250B6CF8331B7EE68FD8:many", "", 0, false},
+	}
+
+	for _, test := range tests {
+		hash, count, ok := ParseRangeLine(test.line)
+		if hash != test.hash || count != test.count || ok != test.ok {
+			t.Errorf("ParseRangeLine(%q) = %q, %d, %v", test.line, hash, count, ok)
+		}
+	}
+}
+
+// testdata/ranges holds the range files of "password", with a .txt
+// extension and padding, and of "123456", without and in lowercase
+func TestRangeDirectory(t *testing.T) {
+	dataset, err := Open(filepath.Join("testdata", "ranges"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 9545824},
+		{"123456", 37359195},
+		{"not breached", 0},
+		{"kX9$mQ2!vL7#pR4w", 0},
+	}
+	for _, test := range tests {
+		count, err := dataset.Count(test.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != test.want {
+			t.Errorf("Count(%q) = %d, want %d", test.password, count, test.want)
+		}
+	}
+}
+
+func TestOpenBloomFilterFile(t *testing.T) {
+	filter := NewBloomFilter(100, 0.01)
+	filter.AddHash(Hash("password"))
+
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filter.WriteTo(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	dataset, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := dataset.Count("password"); count != 1 {
+		t.Error("loaded filter missed the password")
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("opened a dataset that doesn't exist")
+	}
+}
+
+func TestCountWithoutDataset(t *testing.T) {
+	SetDataset(nil)
+	if count, err := Count("password"); count != 0 || err != nil {
+		t.Errorf("Count without a dataset = %d, %v", count, err)
+	}
+}
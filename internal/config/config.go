@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 )
 
 type Config struct {
@@ -13,6 +15,34 @@ type Config struct {
 	DB_NAME        string
 	DB_PORT        string
 	ROOT_CLIENT_ID string
+	BASE_URL       string
+
+	CLIENT_REGISTRATION_TOKENS string
+
+	MAIL_FROM          string
+	MAIL_SMTP_HOST     string
+	MAIL_SMTP_PORT     string
+	MAIL_SMTP_USERNAME string
+	MAIL_SMTP_PASSWORD string
+	MAIL_FILE          string
+
+	SMS_SENDER         string
+	SMS_FROM           string
+	TWILIO_ACCOUNT_SID string
+	TWILIO_AUTH_TOKEN  string
+
+	RATE_LIMIT_REDIS_URL string
+	RATE_LIMIT_FILE      string
+	RATE_LIMIT_FAIL_MODE string
+	TRUSTED_PROXIES      string
+
+	BREACHED_PASSWORDS string
+
+	PASSWORD_HASH_MEMORY_KIB  string
+	PASSWORD_HASH_ITERATIONS  string
+	PASSWORD_HASH_PARALLELISM string
+
+	ACCOUNT_DELETION_GRACE_DAYS string
 }
 
 func getNonemptyEnvOrError(variable string) (string, error) {
@@ -61,6 +91,72 @@ func InitConfig() (Config, error) {
 		return Config{}, err
 	}
 
+	// public url the api is reachable at. client assertions are checked
+	// against it and links in emails point at it
+	BASE_URL, err := getNonemptyEnvOrError("BASE_URL")
+	if err != nil {
+		return Config{}, err
+	}
+	BASE_URL = strings.TrimRight(BASE_URL, "/")
+	if parsed, err := url.Parse(BASE_URL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return Config{}, fmt.Errorf("Env variable BASE_URL must be an absolute http or https url")
+	}
+
+	// optional, comma separated initial access tokens allowed to register
+	// clients. dynamic registration is disabled when empty
+	CLIENT_REGISTRATION_TOKENS := os.Getenv("CLIENT_REGISTRATION_TOKENS")
+
+	// optional, emails go through smtp when MAIL_SMTP_HOST is set, otherwise
+	// they are appended to MAIL_FILE or printed to stdout
+	MAIL_FROM := os.Getenv("MAIL_FROM")
+	MAIL_SMTP_HOST := os.Getenv("MAIL_SMTP_HOST")
+	MAIL_SMTP_PORT := os.Getenv("MAIL_SMTP_PORT")
+	MAIL_SMTP_USERNAME := os.Getenv("MAIL_SMTP_USERNAME")
+	MAIL_SMTP_PASSWORD := os.Getenv("MAIL_SMTP_PASSWORD")
+	MAIL_FILE := os.Getenv("MAIL_FILE")
+
+	// optional, gateway texts are sent through: twilio, or stdout for local
+	// development. the phone provider can't be enabled without one
+	SMS_SENDER := os.Getenv("SMS_SENDER")
+	SMS_FROM := os.Getenv("SMS_FROM")
+	TWILIO_ACCOUNT_SID := os.Getenv("TWILIO_ACCOUNT_SID")
+	TWILIO_AUTH_TOKEN := os.Getenv("TWILIO_AUTH_TOKEN")
+
+	// optional, rate limit buckets are shared through redis when set and kept
+	// in memory otherwise. RATE_LIMIT_FILE overrides the limits of routes
+	RATE_LIMIT_REDIS_URL := os.Getenv("RATE_LIMIT_REDIS_URL")
+	RATE_LIMIT_FILE := os.Getenv("RATE_LIMIT_FILE")
+	// optional, open (default) lets requests through when the store can't be
+	// reached, closed refuses them
+	RATE_LIMIT_FAIL_MODE := os.Getenv("RATE_LIMIT_FAIL_MODE")
+
+	// optional, comma separated addresses or cidrs of proxies whose
+	// X-Forwarded-For header is believed. no proxy is trusted when empty
+	TRUSTED_PROXIES := os.Getenv("TRUSTED_PROXIES")
+
+	// optional, range directory or bloom filter of breached passwords. new
+	// passwords aren't screened when empty
+	BREACHED_PASSWORDS := os.Getenv("BREACHED_PASSWORDS")
+
+	// optional, argon2id parameters for new password hashes. existing hashes
+	// below them are upgraded when their users sign in
+	PASSWORD_HASH_MEMORY_KIB := os.Getenv("PASSWORD_HASH_MEMORY_KIB")
+	PASSWORD_HASH_ITERATIONS := os.Getenv("PASSWORD_HASH_ITERATIONS")
+	PASSWORD_HASH_PARALLELISM := os.Getenv("PASSWORD_HASH_PARALLELISM")
+
+	// optional, days deleted accounts can be restored before they are purged
+	ACCOUNT_DELETION_GRACE_DAYS := os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")
+
+	if MAIL_SMTP_HOST != "" && MAIL_FROM == "" {
+		return Config{}, fmt.Errorf("Env variable MAIL_FROM is required with MAIL_SMTP_HOST")
+	}
+	if RATE_LIMIT_FAIL_MODE != "" && RATE_LIMIT_FAIL_MODE != "open" && RATE_LIMIT_FAIL_MODE != "closed" {
+		return Config{}, fmt.Errorf("Env variable RATE_LIMIT_FAIL_MODE has to be open or closed")
+	}
+	if SMS_SENDER == "twilio" && (SMS_FROM == "" || TWILIO_ACCOUNT_SID == "" || TWILIO_AUTH_TOKEN == "") {
+		return Config{}, fmt.Errorf("Env variables SMS_FROM, TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN are required with SMS_SENDER=twilio")
+	}
+
 	config := Config{
 		API_ADDR,
 		DB_HOST,
@@ -69,6 +165,27 @@ func InitConfig() (Config, error) {
 		DB_NAME,
 		DB_PORT,
 		ROOT_CLIENT_ID,
+		BASE_URL,
+		CLIENT_REGISTRATION_TOKENS,
+		MAIL_FROM,
+		MAIL_SMTP_HOST,
+		MAIL_SMTP_PORT,
+		MAIL_SMTP_USERNAME,
+		MAIL_SMTP_PASSWORD,
+		MAIL_FILE,
+		SMS_SENDER,
+		SMS_FROM,
+		TWILIO_ACCOUNT_SID,
+		TWILIO_AUTH_TOKEN,
+		RATE_LIMIT_REDIS_URL,
+		RATE_LIMIT_FILE,
+		RATE_LIMIT_FAIL_MODE,
+		TRUSTED_PROXIES,
+		BREACHED_PASSWORDS,
+		PASSWORD_HASH_MEMORY_KIB,
+		PASSWORD_HASH_ITERATIONS,
+		PASSWORD_HASH_PARALLELISM,
+		ACCOUNT_DELETION_GRACE_DAYS,
 	}
 
 	return config, nil
@@ -0,0 +1,266 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// rsa
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// ec
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// PublicKey converts the jwk into a key usable by the jwt library
+func (k Jwk) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() == 0 || !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("malformed rsa key")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("malformed ec key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func keyTypeForAlgorithm(alg string) string {
+	if len(alg) < 2 {
+		return ""
+	}
+
+	switch alg[:2] {
+	case "RS", "PS":
+		return "RSA"
+	case "ES":
+		return "EC"
+	}
+	return ""
+}
+
+// FindKey returns the signing key matching kid. when the token carries no kid
+// we only accept a key set with a single candidate so there is no guessing
+func (s *Jwks) FindKey(kid string, alg string) (interface{}, error) {
+	var candidates []Jwk
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if key.Alg != "" && key.Alg != alg {
+			continue
+		}
+		if key.Kty != keyTypeForAlgorithm(alg) {
+			continue
+		}
+		if kid != "" && key.Kid != kid {
+			continue
+		}
+		candidates = append(candidates, key)
+	}
+
+	if len(candidates) != 1 {
+		return nil, errors.New("no unique matching key")
+	}
+
+	return candidates[0].PublicKey()
+}
+
+func ParseJwks(data []byte) (*Jwks, error) {
+	var jwks Jwks
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, errors.New("empty key set")
+	}
+	return &jwks, nil
+}
+
+type cachedJwks struct {
+	jwks      *Jwks
+	fetchedAt time.Time
+}
+
+const jwksCacheDuration = 10 * time.Minute
+
+// key sets are small, anything bigger isn't read
+const jwksMaxResponse = 64 << 10
+
+var errJwksAddressNotAllowed = errors.New("jwks uri resolves to an internal address")
+
+// cgnat and other ranges netip doesn't count as private
+var jwksBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPublicAddress is false for loopback, private, link local (like cloud
+// metadata endpoints) and other addresses that aren't on the internet
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range jwksBlockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// jwks uris come from whoever registers a client, so requests to them may
+// only reach public addresses. the check runs on the address actually dialed,
+// after dns resolution and for every redirect
+var jwksDialer = &net.Dialer{
+	Timeout: 3 * time.Second,
+	Control: func(network string, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil || !isPublicAddress(addrPort.Addr()) {
+			return errJwksAddressNotAllowed
+		}
+		return nil
+	},
+}
+
+var (
+	jwksCache      = map[string]cachedJwks{}
+	jwksCacheMutex sync.Mutex
+	jwksHttpClient = &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			// no proxy, it would be dialed instead of the jwks host
+			Proxy:                 nil,
+			DialContext:           jwksDialer.DialContext,
+			TLSHandshakeTimeout:   3 * time.Second,
+			ResponseHeaderTimeout: 3 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       time.Minute,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 || req.URL.Scheme != "https" {
+				return errors.New("jwks uri redirected too often or away from https")
+			}
+			return nil
+		},
+	}
+)
+
+// FetchJwks downloads a key set from uri, which must be https on a public
+// address. results are cached for a short while so that every client
+// assertion doesn't turn into an outgoing request
+func FetchJwks(uri string, skipCache bool) (*Jwks, error) {
+	if parsed, err := url.Parse(uri); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, errors.New("jwks uri must be an https url")
+	}
+
+	jwksCacheMutex.Lock()
+	cached, ok := jwksCache[uri]
+	jwksCacheMutex.Unlock()
+
+	if ok && !skipCache && time.Since(cached.fetchedAt) < jwksCacheDuration {
+		return cached.jwks, nil
+	}
+
+	resp, err := jwksHttpClient.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks uri returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxResponse+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > jwksMaxResponse {
+		return nil, errors.New("jwks response too large")
+	}
+
+	jwks, err := ParseJwks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	jwksCacheMutex.Lock()
+	jwksCache[uri] = cachedJwks{jwks: jwks, fetchedAt: time.Now()}
+	jwksCacheMutex.Unlock()
+
+	return jwks, nil
+}
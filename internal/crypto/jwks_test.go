@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		// loopback
+		{"127.0.0.1", false},
+		{"127.10.0.1", false},
+		{"::1", false},
+		// private
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		// link local, cloud metadata endpoints live here
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		// unspecified, multicast and other reserved ranges
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"255.255.255.255", false},
+		// internal addresses in other notations
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+
+	for _, test := range tests {
+		if got := isPublicAddress(netip.MustParseAddr(test.addr)); got != test.public {
+			t.Errorf("isPublicAddress(%s) = %v, want %v", test.addr, got, test.public)
+		}
+	}
+}
+
+func TestJwksDialerRefusesInternalAddresses(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:443", "[::1]:443", "169.254.169.254:80", "10.1.2.3:443"} {
+		if err := jwksDialer.Control("tcp", address, nil); !errors.Is(err, errJwksAddressNotAllowed) {
+			t.Errorf("dialing %s: err = %v, want it refused", address, err)
+		}
+	}
+	if err := jwksDialer.Control("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("dialing a public address: %v", err)
+	}
+}
+
+func TestFetchJwksRefusesInternalHosts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"","y":""}]}`))
+	}))
+	defer server.Close()
+
+	_, err := FetchJwks(server.URL, true)
+	if !errors.Is(err, errJwksAddressNotAllowed) {
+		t.Errorf("err = %v, want the loopback server refused", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestFetchJwksRequiresHttps(t *testing.T) {
+	for _, uri := range []string{"http://example.com/jwks", "file:///etc/passwd", "https://", "not a url"} {
+		if _, err := FetchJwks(uri, true); err == nil {
+			t.Errorf("FetchJwks(%q) accepted", uri)
+		}
+	}
+}
+
+// redirects to internal hosts are refused by the dialer like the first request
+func TestJwksRedirects(t *testing.T) {
+	redirect := func(to string, hops int) error {
+		target, _ := url.Parse(to)
+		via := make([]*http.Request, hops)
+		return jwksHttpClient.CheckRedirect(&http.Request{URL: target}, via)
+	}
+
+	if err := redirect("https://keys.example.com/jwks", 1); err != nil {
+		t.Errorf("https redirect refused: %v", err)
+	}
+	if err := redirect("http://keys.example.com/jwks", 1); err == nil {
+		t.Error("redirect away from https followed")
+	}
+	if err := redirect("https://keys.example.com/jwks", 3); err == nil || !strings.Contains(err.Error(), "too often") {
+		t.Errorf("fourth redirect: err = %v, want it refused", err)
+	}
+}
@@ -2,7 +2,10 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 )
 
 func GenerateSecureSecret() string {
@@ -10,3 +13,39 @@ func GenerateSecureSecret() string {
 	rand.Read(b)
 	return fmt.Sprintf("%x", b)
 }
+
+// HashSecret returns a stable digest of a high entropy secret (like the ones
+// from GenerateSecureSecret) that is safe to store and look up by
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode returns a random code of the given number of digits,
+// for codes users have to type in
+func GenerateNumericCode(digits int) string {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, _ := rand.Int(rand.Reader, max)
+	return fmt.Sprintf("%0*d", digits, n)
+}
+
+// HashCode digests a low entropy code together with a per code salt so
+// stored codes can't be matched against a precomputed table
+func HashCode(salt string, code string) string {
+	return HashSecret(salt + ":" + code)
+}
+
+// letters and digits that can't be mistaken for one another when read back
+const readableAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateReadableCode returns a random lowercase code of the given length,
+// for codes users write down and type in later
+func GenerateReadableCode(length int) string {
+	code := make([]byte, length)
+	max := big.NewInt(int64(len(readableAlphabet)))
+	for i := range code {
+		n, _ := rand.Int(rand.Reader, max)
+		code[i] = readableAlphabet[n.Int64()]
+	}
+	return string(code)
+}
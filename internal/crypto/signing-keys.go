@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sentinel-auth-backend/internal/models"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	parsedSigningKeys      = map[string]*rsa.PrivateKey{}
+	parsedSigningKeysMutex sync.Mutex
+)
+
+func parseSigningKey(key *models.SigningKey) (*rsa.PrivateKey, error) {
+	parsedSigningKeysMutex.Lock()
+	defer parsedSigningKeysMutex.Unlock()
+
+	if parsed, ok := parsedSigningKeys[key.ID]; ok {
+		return parsed, nil
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKeyPem))
+	if block == nil {
+		return nil, errors.New("malformed signing key")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an rsa key")
+	}
+
+	parsedSigningKeys[key.ID] = rsaKey
+	return rsaKey, nil
+}
+
+func createSigningKey(db *gorm.DB) (*models.SigningKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.SigningKey{
+		ID:            GenerateSecureSecret()[:16],
+		Algorithm:     models.SigningAlgorithmRS256,
+		PrivateKeyPem: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		Active:        true,
+	}
+
+	// another instance may have created one first, then that one is used
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+		return nil, err
+	}
+
+	var active models.SigningKey
+	if err := db.First(&active, "active = ? AND algorithm = ?", true, models.SigningAlgorithmRS256).Error; err != nil {
+		return nil, err
+	}
+	return &active, nil
+}
+
+// GetActiveSigningKey returns the newest active RS256 key, creating one the
+// first time asymmetric signing is needed
+func GetActiveSigningKey(db *gorm.DB) (string, *rsa.PrivateKey, error) {
+	var key models.SigningKey
+	result := db.Where("active = ? AND algorithm = ?", true, models.SigningAlgorithmRS256).Order("created_at DESC").Limit(1).Find(&key)
+	if result.Error != nil {
+		return "", nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		created, err := createSigningKey(db)
+		if err != nil {
+			return "", nil, err
+		}
+		key = *created
+	}
+
+	privateKey, err := parseSigningKey(&key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key.ID, privateKey, nil
+}
+
+func findSigningPublicKey(db *gorm.DB, kid string) (*rsa.PublicKey, error) {
+	var key models.SigningKey
+	result := db.Limit(1).Find(&key, "id = ?", kid)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New("unknown signing key")
+	}
+
+	privateKey, err := parseSigningKey(&key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &privateKey.PublicKey, nil
+}
+
+func JwkFromRsaPublicKey(kid string, publicKey *rsa.PublicKey) Jwk {
+	return Jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: models.SigningAlgorithmRS256,
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}
+}
+
+// PublicJwks returns the public halves of every signing key, including ones
+// that are no longer active so tokens they signed can still be checked
+func PublicJwks(db *gorm.DB) (*Jwks, error) {
+	var keys []models.SigningKey
+	result := db.Order("created_at DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	jwks := Jwks{Keys: []Jwk{}}
+	for _, key := range keys {
+		privateKey, err := parseSigningKey(&key)
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, JwkFromRsaPublicKey(key.ID, &privateKey.PublicKey))
+	}
+
+	return &jwks, nil
+}
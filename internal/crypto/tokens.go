@@ -13,8 +13,60 @@ import (
 type ClaimsDict = map[string]interface{}
 
 type UserData struct {
-	id         string
-	attributes ClaimsDict
+	id            string
+	attributes    ClaimsDict
+	email         string
+	emailVerified bool
+	phone         string
+	phoneVerified bool
+	sessionId     string
+	authTime      int64
+	acr           string
+	amr           []string
+}
+
+func NewUserData(id string, attributes ClaimsDict) UserData {
+	return UserData{
+		id:         id,
+		attributes: attributes,
+	}
+}
+
+// WithEmail adds the email claims to id tokens made from this user data
+func (u UserData) WithEmail(email string, verified bool) UserData {
+	u.email = email
+	u.emailVerified = verified
+	return u
+}
+
+// WithPhone adds the phone_number claims to id tokens made from this user data
+func (u UserData) WithPhone(phone string, verified bool) UserData {
+	u.phone = phone
+	u.phoneVerified = verified
+	return u
+}
+
+// WithSession adds the sid claim of the session the tokens belong to
+func (u UserData) WithSession(sessionId string) UserData {
+	u.sessionId = sessionId
+	return u
+}
+
+// WithAuthentication adds the auth_time, acr and amr claims, describing when
+// and how strongly the user signed in to the session
+func (u UserData) WithAuthentication(authTime int64, acr string, amr []string) UserData {
+	u.authTime = authTime
+	u.acr = acr
+	u.amr = amr
+	return u
+}
+
+// tokens default to their issue time as auth_time when the sign in is unknown
+func (u UserData) authTimeOr(issuedAt int64) int64 {
+	if u.authTime == 0 {
+		return issuedAt
+	}
+	return u.authTime
 }
 
 type Identities = map[string]ClaimsDict
@@ -22,12 +74,20 @@ type Identities = map[string]ClaimsDict
 type TokenClaims struct {
 	jwt.RegisteredClaims
 
-	Algorithm string                 `json:"alg,omitempty"`
-	KID       string                 `json:"kid,omitempty"`
-	AuthTime  int64                  `json:"auth_time,omitempty"`
-	Scopes    []string               `json:"scopes,omitempty"`
-	Sentinel  map[string]interface{} `json:"sentinel,omitempty"`
-	TokenType string                 `json:"typ,omitempty"`
+	Algorithm     string                 `json:"alg,omitempty"`
+	KID           string                 `json:"kid,omitempty"`
+	AuthTime      int64                  `json:"auth_time,omitempty"`
+	Acr           string                 `json:"acr,omitempty"`
+	Amr           []string               `json:"amr,omitempty"`
+	Scopes        []string               `json:"scopes,omitempty"`
+	Email         string                 `json:"email,omitempty"`
+	EmailVerified *bool                  `json:"email_verified,omitempty"`
+	PhoneNumber   string                 `json:"phone_number,omitempty"`
+	PhoneVerified *bool                  `json:"phone_number_verified,omitempty"`
+	ClientId      string                 `json:"client_id,omitempty"`
+	SessionId     string                 `json:"sid,omitempty"`
+	Sentinel      map[string]interface{} `json:"sentinel,omitempty"`
+	TokenType     string                 `json:"typ,omitempty"`
 }
 
 func CreateIdToken(
@@ -56,7 +116,10 @@ func CreateIdToken(
 		TokenType: "JWT",
 		Algorithm: "HS256",
 		KID:       secretKeyId,
-		AuthTime:  authTime,
+		AuthTime:  userData.authTimeOr(authTime),
+		Acr:       userData.acr,
+		Amr:       userData.amr,
+		SessionId: userData.sessionId,
 		Sentinel: map[string]interface{}{
 			"identities":       identities,
 			"attributes":       userData.attributes,
@@ -64,6 +127,16 @@ func CreateIdToken(
 		},
 	}
 
+	if userData.email != "" {
+		claims.Email = userData.email
+		claims.EmailVerified = &userData.emailVerified
+	}
+
+	if userData.phone != "" {
+		claims.PhoneNumber = userData.phone
+		claims.PhoneVerified = &userData.phoneVerified
+	}
+
 	var token = jwt.NewWithClaims(
 		jwt.SigningMethodHS256, claims,
 	)
@@ -73,14 +146,44 @@ func CreateIdToken(
 	return tokenStr, err
 }
 
+// TokenSigner is the key an access token gets signed with. either the
+// issuing client's secret (HS256) or one of sentinel's own keys (RS256)
+type TokenSigner struct {
+	KeyId  string
+	Method jwt.SigningMethod
+	Key    interface{}
+}
+
+func HmacSigner(secretKeyId string, secretKey string) TokenSigner {
+	return TokenSigner{
+		KeyId:  secretKeyId,
+		Method: jwt.SigningMethodHS256,
+		Key:    []byte(secretKey),
+	}
+}
+
+func RsaSigner(db *gorm.DB) (TokenSigner, error) {
+	keyId, privateKey, err := GetActiveSigningKey(db)
+	if err != nil {
+		return TokenSigner{}, err
+	}
+
+	return TokenSigner{
+		KeyId:  keyId,
+		Method: jwt.SigningMethodRS256,
+		Key:    privateKey,
+	}, nil
+}
+
 func CreateAccessToken(
-	secretKeyId string,
-	secretKey string,
+	signer TokenSigner,
 	issuer string,
+	clientId string,
 	signInProvider string,
 	userData UserData,
 	identities Identities,
 	scopes []string,
+	audience string,
 	authTime int64,
 	tokenDurationInSeconds int,
 ) (string, error) {
@@ -92,16 +195,20 @@ func CreateAccessToken(
 	claims := TokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    issuer,
-			Audience:  jwt.ClaimStrings{"sentinel"},
+			Audience:  jwt.ClaimStrings{audience},
 			IssuedAt:  jwt.NewNumericDate(time.Unix(authTime, 0)),
 			ExpiresAt: jwt.NewNumericDate(time.Unix(expiredAt, 0)),
 			Subject:   userData.id,
 		},
 		TokenType: "JWT",
-		Algorithm: "HS256",
-		KID:       secretKeyId,
-		AuthTime:  authTime,
+		Algorithm: signer.Method.Alg(),
+		KID:       signer.KeyId,
+		AuthTime:  userData.authTimeOr(authTime),
+		Acr:       userData.acr,
+		Amr:       userData.amr,
 		Scopes:    scopes,
+		ClientId:  clientId,
+		SessionId: userData.sessionId,
 		Sentinel: map[string]interface{}{
 			"identities":       identities,
 			"attributes":       userData.attributes,
@@ -109,9 +216,10 @@ func CreateAccessToken(
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(signer.Method, claims)
+	token.Header["kid"] = signer.KeyId
 
-	var tokenStr, err = token.SignedString([]byte(secretKey))
+	var tokenStr, err = token.SignedString(signer.Key)
 
 	return tokenStr, err
 }
@@ -122,8 +230,11 @@ func CreateRefreshToken(
 	authTime int64,
 	tokenDurationInSeconds int,
 	identity *models.Identity,
+	sessionId string,
 	codeChallenge string,
 	codeChallengeMethod string,
+	scopes []string,
+	resources []string,
 ) (string, error) {
 	expiresAtTimestamp := authTime + int64(tokenDurationInSeconds)
 	expiresAt := time.Unix(expiresAtTimestamp, 0)
@@ -138,11 +249,14 @@ func CreateRefreshToken(
 		ClientProviderId:    identity.ClientProviderId,
 		IdentityId:          identity.ID,
 		UserId:              identity.UserId,
+		SessionId:           sessionId,
 		Token:               token,
 		Revoked:             false,
 		ExpiresAt:           expiresAt,
 		CodeChallenge:       codeChallenge,
 		CodeChallengeMethod: codeChallengeMethod,
+		Scopes:              scopes,
+		Resources:           resources,
 	}
 
 	db.Create(&rf)
@@ -150,22 +264,73 @@ func CreateRefreshToken(
 	return token, nil
 }
 
-func VerifyToken(client *models.Client, jwtToken string) (*TokenClaims, error) {
+// VerifyToken checks a token issued for client. hmac tokens are signed with
+// the client's secret or, for an api, with the api's secret. rsa tokens are
+// signed with one of sentinel's signing keys
+func VerifyToken(db *gorm.DB, client *models.Client, jwtToken string) (*TokenClaims, error) {
 	var claims TokenClaims
+	signedByClient := false
 
 	_, err := jwt.ParseWithClaims(jwtToken, &claims, func(t *jwt.Token) (interface{}, error) {
-		fmt.Printf("Token algorithm: %v\n", t.Header["alg"])
-		fmt.Printf("Client secret: %v\n", client.Secret)
-
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("non-hmac signing method")
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			kid, _ := t.Header["kid"].(string)
+			if kid == "" || kid == client.ID {
+				signedByClient = true
+				return []byte(client.Secret), nil
+			}
+			var resource models.ApiResource
+			result := db.Limit(1).Find(&resource, "id = ? AND signing_algorithm = ?", kid, models.SigningAlgorithmHS256)
+			if result.Error != nil || result.RowsAffected == 0 || resource.SigningSecret == "" {
+				return nil, errors.New("unknown signing key")
+			}
+			return []byte(resource.SigningSecret), nil
+		case *jwt.SigningMethodRSA:
+			kid, _ := t.Header["kid"].(string)
+			return findSigningPublicKey(db, kid)
+		default:
+			return nil, errors.New("unsupported signing method")
 		}
-		return []byte(client.Secret), nil
-	})
+	}, jwt.WithValidMethods([]string{"HS256", "RS256"}))
 
 	if err != nil {
 		return nil, err
 	}
 
+	// every rsa or api token verifies against our keys, so make sure this one
+	// was actually issued to the client asking
+	if !signedByClient && claims.ClientId != client.ID {
+		return nil, errors.New("token was issued to another client")
+	}
+
+	// the client knows its secret, so tokens signed with it can't be
+	// trusted to be meant for an api
+	if signedByClient && len(claims.Audience) > 0 {
+		var apis int64
+		if err := db.Model(&models.ApiResource{}).Where("identifier IN ?", []string(claims.Audience)).Count(&apis).Error; err != nil {
+			return nil, err
+		}
+		if apis > 0 {
+			return nil, errors.New("token for an api was signed with a client secret")
+		}
+	}
+
 	return &claims, nil
 }
+
+// PeekTokenClientId reads the kid claim (the id of the client whose secret
+// signed the token) without verifying anything, so callers know which
+// client's secret to verify the token with
+func PeekTokenClientId(jwtToken string) (string, error) {
+	var claims TokenClaims
+
+	_, _, err := jwt.NewParser().ParseUnverified(jwtToken, &claims)
+	if err != nil {
+		return "", err
+	}
+	if claims.KID == "" {
+		return "", errors.New("token has no kid")
+	}
+
+	return claims.KID, nil
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// time based one time passwords (RFC 6238) with the defaults every
+// authenticator app understands: SHA1, 6 digits and 30 second steps
+const (
+	TotpDigits        = 6
+	TotpPeriodSeconds = 30
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret returns a new base32 encoded 160 bit secret
+func GenerateTotpSecret() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return totpEncoding.EncodeToString(b)
+}
+
+// TotpUri is the otpauth uri authenticator apps import, usually shown as a
+// qr code
+func TotpUri(secret string, issuer string, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TotpDigits))
+	query.Set("period", fmt.Sprint(TotpPeriodSeconds))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func totpStep(at time.Time) int64 {
+	return at.Unix() / TotpPeriodSeconds
+}
+
+func hotp(key []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", TotpDigits, value%1000000)
+}
+
+// TotpCode returns the code for the step at a point in time
+func TotpCode(secret string, at time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, totpStep(at)), nil
+}
+
+// ValidateTotp checks code against the current step and one step either
+// side for clock drift. steps up to lastUsedStep are refused so a code can't
+// be replayed. the matching step is returned to be stored as the new
+// lastUsedStep
+func ValidateTotp(secret string, code string, at time.Time, lastUsedStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	current := totpStep(at)
+
+	matched := int64(0)
+	for step := current - 1; step <= current+1; step++ {
+		// compare every step so timing doesn't tell which one matched
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 && step > lastUsedStep {
+			matched = step
+		}
+	}
+
+	return matched, matched != 0
+}
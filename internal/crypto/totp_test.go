@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+)
+
+// the SHA1 secret of RFC 6238 appendix B, "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 appendix B lists 8 digit codes, ours are their last 6 digits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTotpCodeMatchesRfc6238(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		code, err := TotpCode(rfc6238Secret, time.Unix(vector.unix, 0))
+		if err != nil {
+			t.Fatalf("TotpCode(%d): %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("TotpCode(%d) = %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestTotpCodeAcceptsLowercaseSecret(t *testing.T) {
+	code, err := TotpCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", time.Unix(59, 0))
+	if err != nil || code != "287082" {
+		t.Errorf("TotpCode = %q, %v, want 287082", code, err)
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	current := totpStep(at)
+
+	step, ok := ValidateTotp(rfc6238Secret, "050471", at, 0)
+	if !ok || step != current {
+		t.Fatalf("current code: got step %d, %v, want %d", step, ok, current)
+	}
+
+	// 1111111109 is one step earlier, allowed for clock drift
+	step, ok = ValidateTotp(rfc6238Secret, "081804", at, 0)
+	if !ok || step != current-1 {
+		t.Errorf("previous step: got step %d, %v, want %d", step, ok, current-1)
+	}
+
+	if _, ok := ValidateTotp(rfc6238Secret, "050 471", at, 0); !ok {
+		t.Error("code with a space was refused")
+	}
+	if _, ok := ValidateTotp(rfc6238Secret, "050472", at, 0); ok {
+		t.Error("wrong code was accepted")
+	}
+	if _, ok := ValidateTotp(rfc6238Secret, "050471", at.Add(2*TotpPeriodSeconds*time.Second), 0); ok {
+		t.Error("code two steps old was accepted")
+	}
+	if _, ok := ValidateTotp("not base32!", "050471", at, 0); ok {
+		t.Error("invalid secret was accepted")
+	}
+}
+
+func TestValidateTotpRefusesReplays(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+
+	step, ok := ValidateTotp(rfc6238Secret, "050471", at, 0)
+	if !ok {
+		t.Fatal("code was refused")
+	}
+	if _, ok := ValidateTotp(rfc6238Secret, "050471", at, step); ok {
+		t.Error("used code was accepted again")
+	}
+	// an older step than the last used one can't be used either
+	if _, ok := ValidateTotp(rfc6238Secret, "081804", at, step); ok {
+		t.Error("code older than the last used one was accepted")
+	}
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+	secret := GenerateTotpSecret()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret has %d bytes, want 20", len(key))
+	}
+	if GenerateTotpSecret() == secret {
+		t.Error("two secrets were the same")
+	}
+}
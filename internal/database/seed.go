@@ -2,15 +2,96 @@ package database
 
 import (
 	"log"
+	"sentinel-auth-backend/internal/auth"
 	"sentinel-auth-backend/internal/config"
 	"sentinel-auth-backend/internal/crypto"
 	"sentinel-auth-backend/internal/models"
 
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// SeedScopes makes sure the standard scopes exist, also on databases that
+// were seeded before the scope registry existed
+func SeedScopes(db *gorm.DB) {
+	for _, scope := range auth.StandardScopes {
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&scope).Error; err != nil {
+			log.Fatal("❌ Failed to create standard scope:", err)
+		}
+	}
+}
+
+const emailLogoUrl = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiIGNsYXNzPSJsdWNpZGUgbHVjaWRlLW1haWwtaWNvbiBsdWNpZGUtbWFpbCI+PHBhdGggZD0ibTIyIDctOC45OTEgNS43MjdhMiAyIDAgMCAxLTIuMDA5IDBMMiA3Ii8+PHJlY3QgeD0iMiIgeT0iNCIgd2lkdGg9IjIwIiBoZWlnaHQ9IjE2IiByeD0iMiIvPjwvc3ZnPg=="
+
+const magicLinkLogoUrl = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiPjxwYXRoIGQ9Ik0xMCAxM2E1IDUgMCAwIDAgNy41NC41NGwzLTNhNSA1IDAgMCAwLTcuMDctNy4wN2wtMS43MiAxLjcxIi8+PHBhdGggZD0iTTE0IDExYTUgNSAwIDAgMC03LjU0LS41NGwtMyAzYTUgNSAwIDAgMCA3LjA3IDcuMDdsMS43MS0xLjcxIi8+PC9zdmc+"
+
+const emailOtpLogoUrl = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiPjxyZWN0IHg9IjMiIHk9IjExIiB3aWR0aD0iMTgiIGhlaWdodD0iMTEiIHJ4PSIyIi8+PHBhdGggZD0iTTcgMTFWN2E1IDUgMCAwIDEgMTAgMHY0Ii8+PC9zdmc+"
+
+const phoneLogoUrl = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiPjxyZWN0IHg9IjUiIHk9IjIiIHdpZHRoPSIxNCIgaGVpZ2h0PSIyMCIgcng9IjIiLz48cGF0aCBkPSJNMTIgMThoLjAxIi8+PC9zdmc+"
+
+const passkeyLogoUrl = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiPjxjaXJjbGUgY3g9IjgiIGN5PSI4IiByPSI0Ii8+PHBhdGggZD0iTTIgMjF2LTJhNiA2IDAgMCAxIDktNS4yIi8+PGNpcmNsZSBjeD0iMTciIGN5PSIxNCIgcj0iMi41Ii8+PHBhdGggZD0iTTE3IDE2LjVWMjJsMi0xLjUiLz48L3N2Zz4="
+
+func providerOptions() []models.ProviderOption {
+	emailLogo := emailLogoUrl
+	magicLinkLogo := magicLinkLogoUrl
+	emailOtpLogo := emailOtpLogoUrl
+	phoneLogo := phoneLogoUrl
+	passkeyLogo := passkeyLogoUrl
+
+	return []models.ProviderOption{
+		{
+			ID:          "email",
+			Name:        "Email",
+			Description: "Authenticate users using email and password",
+			LogoUrl:     &emailLogo,
+			Mappings:    map[string]interface{}{},
+		},
+		{
+			ID:          "magic_link",
+			Name:        "Magic Link",
+			Description: "Authenticate users with a sign in link sent to their email",
+			LogoUrl:     &magicLinkLogo,
+			Mappings:    map[string]interface{}{},
+		},
+		{
+			ID:          "email_otp",
+			Name:        "Email Code",
+			Description: "Authenticate users with a one time code sent to their email",
+			LogoUrl:     &emailOtpLogo,
+			Mappings:    map[string]interface{}{},
+		},
+		{
+			ID:          "phone",
+			Name:        "Phone",
+			Description: "Authenticate users with a one time code sent to their phone",
+			LogoUrl:     &phoneLogo,
+			Mappings:    map[string]interface{}{},
+		},
+		{
+			ID:          "passkey",
+			Name:        "Passkey",
+			Description: "Authenticate users with a passkey stored on their device or password manager",
+			LogoUrl:     &passkeyLogo,
+			Mappings:    map[string]interface{}{},
+		},
+	}
+}
+
+// SeedProviderOptions makes sure every built in provider exists, also on
+// databases that were seeded before it was added
+func SeedProviderOptions(db *gorm.DB) {
+	for _, option := range providerOptions() {
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&option).Error; err != nil {
+			log.Fatal("❌ Failed to create provider option:", err)
+		}
+	}
+}
+
 func SeedDb(db *gorm.DB, appConfig config.Config) {
+	SeedScopes(db)
+	SeedProviderOptions(db)
+
 	// Check if database is already seeded by looking for a root client
 	var count int64
 	db.Model(&models.Client{}).Where("is_root_client = ?", true).Count(&count)
@@ -28,26 +109,13 @@ func SeedDb(db *gorm.DB, appConfig config.Config) {
 		RedirectUris:   pq.StringArray{"http://104.248.57.142:3000/callback"},
 		AllowedOrigins: pq.StringArray{"http://104.248.57.142:3000"},
 		IsRootClient:   true,
+		IsFirstParty:   true,
 	}
 
 	if err := db.Create(&rootClient).Error; err != nil {
 		log.Fatal("❌ Failed to create root client:", err)
 	}
 
-	emailLogoUrl := "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHdpZHRoPSIyNCIgaGVpZ2h0PSIyNCIgdmlld0JveD0iMCAwIDI0IDI0IiBmaWxsPSJub25lIiBzdHJva2U9ImN1cnJlbnRDb2xvciIgc3Ryb2tlLXdpZHRoPSIyIiBzdHJva2UtbGluZWNhcD0icm91bmQiIHN0cm9rZS1saW5lam9pbj0icm91bmQiIGNsYXNzPSJsdWNpZGUgbHVjaWRlLW1haWwtaWNvbiBsdWNpZGUtbWFpbCI+PHBhdGggZD0ibTIyIDctOC45OTEgNS43MjdhMiAyIDAgMCAxLTIuMDA5IDBMMiA3Ii8+PHJlY3QgeD0iMiIgeT0iNCIgd2lkdGg9IjIwIiBoZWlnaHQ9IjE2IiByeD0iMiIvPjwvc3ZnPg=="
-
-	emailProvider := models.ProviderOption{
-		ID:          "email",
-		Name:        "Email",
-		Description: "Authenticate users using email and password",
-		LogoUrl:     &emailLogoUrl,
-		Mappings:    map[string]interface{}{},
-	}
-
-	if err := db.Create(&emailProvider).Error; err != nil {
-		log.Fatal("❌ Failed to create email provider:", err)
-	}
-
 	clientProvider := models.ClientProvider{
 		ClientId:         rootClient.ID,
 		ProviderOptionId: "email",
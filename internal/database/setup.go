@@ -35,6 +35,28 @@ func SetupDb(appConfig config.Config) *gorm.DB {
 		&models.Identity{},
 		&models.RedeemAuthCode{},
 		&models.RefreshToken{},
+		&models.ClientAssertionJti{},
+		&models.Scope{},
+		&models.ApiResource{},
+		&models.SigningKey{},
+		&models.ConsentGrant{},
+		&models.AuthenticationFlow{},
+		&models.EmailVerificationToken{},
+		&models.PasswordResetToken{},
+		&models.Session{},
+		&models.MagicLink{},
+		&models.OneTimeCode{},
+		&models.MfaFactor{},
+		&models.PasskeyCredential{},
+		&models.PasskeyChallenge{},
+		&models.MfaRecoveryCode{},
+		&models.SignInThrottle{},
+		&models.PasswordPolicy{},
+		&models.PasswordHistory{},
+		&models.LegacyAuthenticator{},
+		&models.AuditEvent{},
+		&models.ExportJob{},
+		&models.AccountDeletion{},
 	}
 	err = db.AutoMigrate(dbModels...)
 	if err != nil {
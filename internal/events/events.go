@@ -0,0 +1,108 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	// an email was locked out of password sign in after too many failures
+	AccountLocked Type = "account.locked"
+	// a locked email was unlocked by its owner or an admin
+	AccountUnlocked Type = "account.unlocked"
+	// an ip was locked out of password sign in after too many failures
+	IpLocked Type = "ip.locked"
+	// a user unknown to sentinel was accepted by the client's legacy auth
+	// system and created
+	UserMigrated Type = "user.migrated"
+	// an archive of everything stored about a user is ready to download
+	UserExported Type = "user.exported"
+	// a user deleted their account or an admin did. it can be restored
+	// until purge_at
+	UserDeleted Type = "user.deleted"
+	// a deleted account was brought back during its grace period
+	UserRestored Type = "user.restored"
+	// a deleted account was erased for good, client apps should drop what
+	// they keep about the user
+	UserPurged Type = "user.purged"
+)
+
+// Event is something that happened to an account that other parts of the
+// system may want to react to, like alerting or auditing
+type Event struct {
+	Type     Type
+	ClientId string
+	// empty when the event is not about a known user
+	UserId string
+	Data   map[string]interface{}
+	At     time.Time
+}
+
+type Handler func(Event)
+
+// Bus hands published events to the handlers subscribed to their type.
+// handlers run in their own goroutine so they can't slow down or break the
+// request that published the event
+type Bus struct {
+	handlers map[Type][]Handler
+	all      []Handler
+	mutex    sync.RWMutex
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[Type][]Handler{}}
+}
+
+// Subscribe calls handler for every event of the given type
+func (b *Bus) Subscribe(eventType Type, handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// SubscribeAll calls handler for every event
+func (b *Bus) SubscribeAll(handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.all = append(b.all, handler)
+}
+
+func (b *Bus) Publish(event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	b.mutex.RLock()
+	handlers := append([]Handler{}, b.handlers[event.Type]...)
+	handlers = append(handlers, b.all...)
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		go func(handler Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("event handler for %s panicked: %v", event.Type, r)
+				}
+			}()
+			handler(event)
+		}(handler)
+	}
+}
+
+// the bus the rest of the app publishes to
+var defaultBus = NewBus()
+
+func Subscribe(eventType Type, handler Handler) {
+	defaultBus.Subscribe(eventType, handler)
+}
+
+func SubscribeAll(handler Handler) {
+	defaultBus.SubscribeAll(handler)
+}
+
+func Publish(event Event) {
+	defaultBus.Publish(event)
+}
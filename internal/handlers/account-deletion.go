@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func accountDeletionResponse(deletion *models.AccountDeletion) api.AccountDeletion {
+	return api.AccountDeletion{
+		UserId:    deletion.UserId,
+		DeletedBy: deletion.DeletedBy,
+		DeletedAt: deletion.DeletedAt,
+		PurgeAt:   deletion.PurgeAt,
+	}
+}
+
+// writeAccountDeletionError answers for the errors the user and admin
+// deletion endpoints share
+func writeAccountDeletionError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.AccountDeletionErrorUserNotFound):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "User does not exist",
+		})
+	case string(auth.AccountDeletionErrorNotDeleted):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "User is not deleted or can no longer be restored",
+		})
+	case string(auth.AccountDeletionErrorInvalidToken):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_token",
+			ErrorDescription: "Restore link is invalid or expired",
+		})
+	case string(auth.AccountDeletionErrorConflict):
+		ctx.JSON(http.StatusConflict, api.ErrorResponse{
+			Error:            "conflict",
+			ErrorDescription: "Another account signed up with one of the deleted account's identities",
+		})
+	case string(auth.ReauthenticationErrorRequired):
+		ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+			Error:            "login_required",
+			ErrorDescription: "Sign in again to delete your account",
+		})
+	case string(auth.AccountDeletionErrorInvalidRedirectUri):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Redirect uri is not registered for this client",
+		})
+	case string(auth.AccountDeletionErrorNoRestoreLink):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_client",
+			ErrorDescription: "Client has no redirect uri to send the restore link to",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
+
+// writeAccountDeletedError answers passwordless sign ins with the email of a
+// deleted account, which has to be restored first
+func writeAccountDeletedError(ctx *gin.Context, err error) bool {
+	if err.Error() != string(auth.PasswordlessErrorAccountDeleted) {
+		return false
+	}
+
+	ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+		Error:            "account_deleted",
+		ErrorDescription: "The account was deleted, restore it with the link from the deletion email",
+	})
+	return true
+}
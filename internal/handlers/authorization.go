@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeAuthorizationResult responds with the auth code, or with a flow token
+// when the user still has a step to complete. codeStatus is the status used
+// when a code was issued
+func writeAuthorizationResult(ctx *gin.Context, codeStatus int, result *auth.AuthorizationResult) {
+	if result.Pending != nil {
+		resp := api.AuthFlowResponse{
+			FlowToken: result.Pending.FlowToken,
+			NextStep:  api.AuthFlowResponseNextStep(result.Pending.NextStep),
+			ExpiresIn: result.Pending.ExpiresIn,
+			State:     result.State,
+		}
+		if len(result.Pending.MfaFactors) > 0 {
+			resp.MfaFactors = &result.Pending.MfaFactors
+		}
+		ctx.JSON(http.StatusAccepted, resp)
+		return
+	}
+
+	ctx.JSON(codeStatus, api.AuthCodeResponse{
+		Code:      result.Code.Code,
+		ExpiresIn: result.Code.ExpiresIn,
+		State:     result.State,
+	})
+}
+
+func writeAuthenticationFlowError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.AuthenticationFlowErrorInvalidFlow):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_flow",
+			ErrorDescription: "Sign in expired or is invalid, start again",
+		})
+	case string(auth.AuthenticationFlowErrorWrongStep):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_flow",
+			ErrorDescription: "Sign in is waiting for a different step",
+		})
+	case string(auth.AuthenticationFlowErrorAccessDenied):
+		ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+			Error:            "access_denied",
+			ErrorDescription: "User denied access",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
+
+// writeCodeChallengeError answers 400 when err is about the PKCE challenge
+func writeCodeChallengeError(ctx *gin.Context, err error) bool {
+	if err.Error() != string(auth.CodeChallengeErrorInvalid) {
+		return false
+	}
+
+	ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+		Error:            "invalid_request",
+		ErrorDescription: "code_challenge must be an S256 challenge of 43 characters",
+	})
+	return true
+}
+
+// writeUnmetAcrError answers 403 when acr_values asked for a level the user
+// has no second factor to reach
+func writeUnmetAcrError(ctx *gin.Context, err error) bool {
+	if err.Error() != string(auth.StepUpErrorUnmet) {
+		return false
+	}
+
+	ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+		Error:            "unmet_authentication_requirements",
+		ErrorDescription: "User has no second factor to reach the requested level with",
+	})
+	return true
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// urls a client assertion may name as its audience: the issuer and the
+// endpoint currently being called
+func endpointAudiences(ctx *gin.Context, appConfig *config.Config) []string {
+	base := baseUrl(appConfig)
+	return []string{base, base + ctx.Request.URL.Path}
+}
+
+// authenticateClient writes an error response and returns false when the
+// caller failed to prove it is allowed to act as client
+func authenticateClient(ctx *gin.Context, db *gorm.DB, appConfig *config.Config, client *models.Client, assertionType *string, assertion *string) bool {
+	err := auth.AuthenticateClient(db, client, derefString(assertionType), derefString(assertion), endpointAudiences(ctx, appConfig))
+	if err == nil {
+		return true
+	}
+
+	switch err.Error() {
+	case string(auth.AuthenticateClientErrorMissingAssertion),
+		string(auth.AuthenticateClientErrorInvalidAssertionType),
+		string(auth.AuthenticateClientErrorInvalidAssertion),
+		string(auth.AuthenticateClientErrorReplayedAssertion),
+		string(auth.AuthenticateClientErrorNoKeys):
+		ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+			Error:            "invalid_client",
+			ErrorDescription: "Client authentication failed",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerToken returns the token from an "Authorization: Bearer <token>" header
+func bearerToken(ctx *gin.Context) string {
+	header := ctx.GetHeader("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// baseUrl is the configured public url of the api. it is never taken from
+// the Host or X-Forwarded-* headers, which callers control
+func baseUrl(appConfig *config.Config) string {
+	return appConfig.BASE_URL
+}
+
+func clientMetadataFromRequest(req *api.ClientMetadata) auth.ClientMetadata {
+	metadata := auth.ClientMetadata{
+		Name:         derefString(req.ClientName),
+		RedirectUris: req.RedirectUris,
+		LogoUri:      req.LogoUri,
+		JwksUri:      req.JwksUri,
+	}
+
+	if req.GrantTypes != nil {
+		for _, grantType := range *req.GrantTypes {
+			metadata.GrantTypes = append(metadata.GrantTypes, string(grantType))
+		}
+	}
+	if req.TokenEndpointAuthMethod != nil {
+		metadata.TokenEndpointAuthMethod = string(*req.TokenEndpointAuthMethod)
+	}
+	if req.Jwks != nil {
+		metadata.Jwks = *req.Jwks
+	}
+	if req.Scope != nil {
+		metadata.Scopes = auth.ParseScope(*req.Scope)
+	}
+	if req.RequireEmailVerification != nil {
+		metadata.RequireEmailVerification = *req.RequireEmailVerification
+	}
+
+	return metadata
+}
+
+func clientInformationResponse(client *models.Client, registrationClientUri string, registrationAccessToken *string) api.ClientInformationResponse {
+	grantTypes := []api.ClientInformationResponseGrantTypes{}
+	for _, grantType := range client.GrantTypes {
+		grantTypes = append(grantTypes, api.ClientInformationResponseGrantTypes(grantType))
+	}
+	authMethod := api.ClientInformationResponseTokenEndpointAuthMethod(client.TokenEndpointAuthMethod)
+
+	resp := api.ClientInformationResponse{
+		ClientId:                 client.ID,
+		ClientIdIssuedAt:         client.CreatedAt.Unix(),
+		ClientName:               &client.Name,
+		GrantTypes:               &grantTypes,
+		JwksUri:                  client.JwksUri,
+		LogoUri:                  client.LogoUrl,
+		RedirectUris:             client.RedirectUris,
+		RegistrationAccessToken:  registrationAccessToken,
+		RegistrationClientUri:    registrationClientUri,
+		RequireEmailVerification: &client.RequireEmailVerification,
+		TokenEndpointAuthMethod:  &authMethod,
+	}
+	if len(client.AllowedScopes) > 0 {
+		scope := auth.FormatScope(client.AllowedScopes)
+		resp.Scope = &scope
+	}
+	if len(client.Jwks) > 0 {
+		jwks := map[string]interface{}(client.Jwks)
+		resp.Jwks = &jwks
+	}
+
+	return resp
+}
+
+// writeRegisterClientError maps errors from the auth registration functions
+// to the responses described in RFC 7591 and RFC 7592
+func writeRegisterClientError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.RegisterClientErrorInvalidRedirectUri):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_redirect_uri",
+			ErrorDescription: "One or more redirect uris are invalid",
+		})
+	case string(auth.RegisterClientErrorInvalidMetadata):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_client_metadata",
+			ErrorDescription: "Client metadata is invalid",
+		})
+	case string(auth.RegisterClientErrorInvalidToken):
+		ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+			Error:            "invalid_token",
+			ErrorDescription: "Invalid registration access token",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
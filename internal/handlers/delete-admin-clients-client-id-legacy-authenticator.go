@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteAdminClientsClientIdLegacyAuthenticatorHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		err := auth.DeleteClientLegacyAuthenticator(db, clientId)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.LegacyAuthenticatorErrorNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client has no legacy authenticator",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
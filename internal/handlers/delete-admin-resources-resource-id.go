@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteAdminResourcesResourceIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, resourceId string) {
+		if err := auth.DeleteApiResource(db, resourceId); err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
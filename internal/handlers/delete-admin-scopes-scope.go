@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteAdminScopesScopeHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, scope string) {
+		if err := auth.DeleteScope(db, scope); err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
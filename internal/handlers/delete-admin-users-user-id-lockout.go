@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteAdminUsersUserIdLockoutHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, userId string) {
+		err := auth.UnlockUser(db, userId)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.SignInThrottleErrorUserNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "User does not exist",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteAdminUsersUserIdHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context, string, api.DeleteAdminUsersUserIdParams) {
+	return func(ctx *gin.Context, userId string, params api.DeleteAdminUsersUserIdParams) {
+		purge := params.Purge != nil && *params.Purge
+
+		// users already waiting out their grace period can be purged
+		// right away too
+		deletion, err := auth.DeleteAccount(db, mailer, userId, auth.DeleteAccountInput{DeletedBy: models.AccountDeletedByAdmin})
+		if err != nil && !(purge && err.Error() == string(auth.AccountDeletionErrorUserNotFound)) {
+			writeAccountDeletionError(ctx, err)
+			return
+		}
+
+		if purge {
+			if err := auth.PurgeAccount(db, userId); err != nil {
+				if err.Error() == string(auth.AccountDeletionErrorNotDeleted) {
+					err = errors.New(string(auth.AccountDeletionErrorUserNotFound))
+				}
+				writeAccountDeletionError(ctx, err)
+				return
+			}
+			ctx.Status(http.StatusNoContent)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, accountDeletionResponse(deletion))
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteClientsClientIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		err := auth.DeleteRegisteredClient(db, clientId, bearerToken(ctx))
+		if err != nil {
+			writeRegisterClientError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
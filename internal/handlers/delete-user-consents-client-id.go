@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteUserConsentsClientIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		err := auth.RevokeConsent(db, ctx.GetString(middleware.UserIdKey), clientId)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.ConsentErrorNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "No consent was given to this client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteUserMfaTotpHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		err := auth.RemoveTotp(db, ctx.GetString(middleware.UserIdKey), ctx.GetString(middleware.SessionIdKey))
+		if err != nil {
+			writeMfaManagementError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+func writeMfaManagementError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.MfaErrorStepUpRequired):
+		ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+			Error:            "mfa_required",
+			ErrorDescription: "Sign in with a second factor to change it",
+		})
+	case string(auth.MfaErrorNotEnrolled):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "Second factor is not set up",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteUserPasskeysPasskeyIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, passkeyId string) {
+		err := auth.RemovePasskey(db, ctx.GetString(middleware.UserIdKey), ctx.GetString(middleware.SessionIdKey), passkeyId)
+		if err != nil {
+			writePasskeyError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
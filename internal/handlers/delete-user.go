@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/middleware"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeDeleteUserHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// the body is optional
+		var req api.DeleteAccountRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := auth.CheckRecentAuthentication(db, ctx.GetString(middleware.SessionIdKey)); err != nil {
+			writeAccountDeletionError(ctx, err)
+			return
+		}
+
+		deletion, err := auth.DeleteAccount(db, mailer, ctx.GetString(middleware.UserIdKey), auth.DeleteAccountInput{
+			DeletedBy:   models.AccountDeletedByUser,
+			RedirectUri: derefString(req.RedirectUri),
+			Locale:      derefString(req.Locale),
+		})
+		if err != nil {
+			writeAccountDeletionError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, accountDeletionResponse(deletion))
+	}
+}
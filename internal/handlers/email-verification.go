@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/mail"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emailVerifyUrl is where links in verification emails point to
+func emailVerifyUrl(appConfig *config.Config) string {
+	return baseUrl(appConfig) + "/v1/auth/providers/email/verify"
+}
+
+// requestLocale is the language emails sent for this request are written in
+func requestLocale(ctx *gin.Context) string {
+	return mail.MatchLocale(ctx.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAdminClientsClientIdPasswordPolicyHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		policy, err := auth.GetPasswordPolicy(db, clientId)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.PasswordPolicyErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, passwordPolicyResponse(policy))
+	}
+}
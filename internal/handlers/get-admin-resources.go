@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAdminResourcesHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		resources, err := auth.ListApiResources(db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.ApiResource{}
+		for _, resource := range resources {
+			resp = append(resp, apiResourceResponse(&resource))
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAdminUsersUserIdExportJobIdDownloadHandler(db *gorm.DB) func(*gin.Context, string, string) {
+	return func(ctx *gin.Context, userId string, jobId string) {
+		job, err := auth.DownloadUserExport(db, userId, jobId)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		writeUserExportArchive(ctx, job)
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAdminUsersUserIdExportJobIdHandler(db *gorm.DB) func(*gin.Context, string, string) {
+	return func(ctx *gin.Context, userId string, jobId string) {
+		job, err := auth.GetUserExport(db, userId, jobId)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, exportJobResponse(job))
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAdminUsersUserIdMfaHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, userId string) {
+		summary, err := auth.GetMfaSummary(db, userId)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorUserNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "User does not exist",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		factors := []api.MfaFactor{}
+		for _, factor := range summary.Factors {
+			factors = append(factors, api.MfaFactor{
+				Id:         factor.ID,
+				Type:       factor.Type,
+				CreatedAt:  factor.CreatedAt,
+				LastUsedAt: factor.LastUsedAt,
+			})
+		}
+
+		ctx.JSON(http.StatusOK, api.AdminUserMfa{
+			Factors:                factors,
+			Passkeys:               int(summary.Passkeys),
+			RecoveryCodesRemaining: int(summary.RecoveryCodesRemaining),
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetAuthConsentHandler(db *gorm.DB) func(*gin.Context, api.GetAuthConsentParams) {
+	return func(ctx *gin.Context, params api.GetAuthConsentParams) {
+		screen, err := auth.GetConsentScreen(db, params.FlowToken)
+		if err != nil {
+			writeAuthenticationFlowError(ctx, err)
+			return
+		}
+
+		scopes := []api.ConsentScope{}
+		for _, scope := range screen.RequestedScopes {
+			scopes = append(scopes, api.ConsentScope{
+				Id:          scope.ID,
+				Description: scope.Description,
+				Granted:     slices.Contains(screen.GrantedScopes, scope.ID),
+			})
+		}
+
+		ctx.JSON(http.StatusOK, api.ConsentScreenResponse{
+			ClientId:   screen.Client.ID,
+			ClientName: screen.Client.Name,
+			LogoUri:    screen.Client.LogoUrl,
+			Scopes:     scopes,
+		})
+	}
+}
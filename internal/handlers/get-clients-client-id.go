@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetClientsClientIdHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		client, err := auth.GetRegisteredClient(db, clientId, bearerToken(ctx))
+		if err != nil {
+			writeRegisterClientError(ctx, err)
+			return
+		}
+
+		registrationClientUri := baseUrl(appConfig) + ctx.Request.URL.Path
+		ctx.JSON(http.StatusOK, clientInformationResponse(client, registrationClientUri, nil))
+	}
+}
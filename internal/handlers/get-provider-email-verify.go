@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetProviderEmailVerifyHandler(db *gorm.DB) func(*gin.Context, api.GetAuthProvidersEmailVerifyParams) {
+	return func(ctx *gin.Context, params api.GetAuthProvidersEmailVerifyParams) {
+		identity, err := auth.VerifyEmail(db, params.Token)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.EmailVerificationErrorInvalidToken):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_token",
+					ErrorDescription: "Verification link is invalid, used or expired",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.EmailVerifiedResponse{
+			Email:         identity.ProviderSub,
+			EmailVerified: identity.EmailVerified,
+		})
+	}
+}
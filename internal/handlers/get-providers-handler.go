@@ -17,7 +17,7 @@ func MakeGetProvidersHandler(db *gorm.DB) func(*gin.Context, api.GetAuthProvider
 		}
 
 		var providers []models.ClientProvider
-		db.Where("client_id = ?", params.ClientId).Find(&providers)
+		db.Where("client_id = ? AND enabled = ?", params.ClientId, true).Find(&providers)
 
 		var enrichedProviders []api.StrippedClientProvider
 		for _, provider := range providers {
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetScopesHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		scopes, err := auth.ListScopes(db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.ScopeDescription{}
+		for _, scope := range scopes {
+			resp = append(resp, scopeDescription(&scope))
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
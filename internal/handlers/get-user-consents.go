@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserConsentsHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		grants, err := auth.ListConsents(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.ConsentGrant{}
+		for _, grant := range grants {
+			resp = append(resp, api.ConsentGrant{
+				ClientId:   grant.ClientId,
+				ClientName: grant.Client.Name,
+				LogoUri:    grant.Client.LogoUrl,
+				Scopes:     grant.Scopes,
+				GrantedAt:  grant.CreatedAt,
+				UpdatedAt:  grant.UpdatedAt,
+			})
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserCredentialsHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		credentials, err := auth.ListCredentials(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.Credential{}
+		for _, credential := range credentials {
+			resp = append(resp, api.Credential{
+				Id:            credential.IdentityId,
+				Provider:      credential.Provider,
+				Identifier:    credential.Identifier,
+				HasPassword:   credential.HasPassword,
+				EmailVerified: credential.EmailVerified,
+				CreatedAt:     credential.CreatedAt,
+			})
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
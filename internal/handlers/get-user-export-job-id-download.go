@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserExportJobIdDownloadHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, jobId string) {
+		job, err := auth.DownloadUserExport(db, ctx.GetString(middleware.UserIdKey), jobId)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		writeUserExportArchive(ctx, job)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserExportJobIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, jobId string) {
+		job, err := auth.GetUserExport(db, ctx.GetString(middleware.UserIdKey), jobId)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, exportJobResponse(job))
+	}
+}
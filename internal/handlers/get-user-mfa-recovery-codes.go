@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserMfaRecoveryCodesHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		remaining, err := auth.CountRecoveryCodes(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.RecoveryCodesStatus{Remaining: int(remaining)})
+	}
+}
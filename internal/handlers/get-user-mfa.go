@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserMfaHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		factors, err := auth.ListMfaFactors(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.MfaFactor{}
+		for _, factor := range factors {
+			resp = append(resp, api.MfaFactor{
+				Id:         factor.ID,
+				Type:       factor.Type,
+				CreatedAt:  factor.CreatedAt,
+				LastUsedAt: factor.LastUsedAt,
+			})
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
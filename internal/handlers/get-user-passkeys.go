@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetUserPasskeysHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		credentials, err := auth.ListPasskeys(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		resp := []api.Passkey{}
+		for _, credential := range credentials {
+			resp = append(resp, passkeyResponse(&credential))
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+func passkeyResponse(credential *models.PasskeyCredential) api.Passkey {
+	return api.Passkey{
+		Id:             credential.ID,
+		Name:           credential.Name,
+		BackupEligible: credential.BackupEligible,
+		CreatedAt:      credential.CreatedAt,
+		LastUsedAt:     credential.LastUsedAt,
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/crypto"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakeGetWellKnownJwksHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		jwks, err := crypto.PublicJwks(db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		// round trip through json so the keys come out exactly as a jwk
+		var resp api.JwksResponse
+		data, err := json.Marshal(jwks)
+		if err == nil {
+			err = json.Unmarshal(data, &resp)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/models"
+)
+
+// the secret is never shown again once set
+func legacyAuthenticatorResponse(config *models.LegacyAuthenticator) api.LegacyAuthenticator {
+	return api.LegacyAuthenticator{
+		Url:            config.Url,
+		HasSecret:      config.Secret != "",
+		TimeoutMs:      config.TimeoutMs,
+		Enabled:        config.Enabled,
+		MigratedUsers:  config.MigratedUsers,
+		LastMigratedAt: config.LastMigratedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func passwordPolicyResponse(policy *models.PasswordPolicy) api.PasswordPolicy {
+	bannedWords := []string{}
+	bannedWords = append(bannedWords, policy.BannedWords...)
+
+	return api.PasswordPolicy{
+		MinLength:        policy.MinLength,
+		MaxLength:        policy.MaxLength,
+		RequireUppercase: policy.RequireUppercase,
+		RequireLowercase: policy.RequireLowercase,
+		RequireDigit:     policy.RequireDigit,
+		RequireSymbol:    policy.RequireSymbol,
+		MinStrength:      policy.MinStrength,
+		BannedWords:      bannedWords,
+		HistorySize:      policy.HistorySize,
+		BreachMode:       policy.BreachMode,
+	}
+}
+
+// setPasswordWarnings tells the app which rules an accepted password only
+// passed because the client's policy warns about them, like breached ones
+func setPasswordWarnings(ctx *gin.Context, warnings []auth.PasswordRuleFailure) {
+	rules := []string{}
+	for _, warning := range warnings {
+		rules = append(rules, string(warning.Rule))
+	}
+	if len(rules) > 0 {
+		ctx.Header("Password-Warning", strings.Join(rules, ", "))
+	}
+}
+
+// writeWeakPasswordError answers with the rules a password broke, when err
+// is about that
+func writeWeakPasswordError(ctx *gin.Context, err error) bool {
+	var weak *auth.WeakPasswordError
+	if !errors.As(err, &weak) {
+		return false
+	}
+
+	failedRules := []api.PasswordRuleFailure{}
+	for _, failure := range weak.Failures {
+		failedRules = append(failedRules, api.PasswordRuleFailure{
+			Rule:        string(failure.Rule),
+			Description: failure.Description,
+		})
+	}
+
+	ctx.JSON(http.StatusBadRequest, api.WeakPasswordResponse{
+		Error:            "weak_password",
+		ErrorDescription: "Password does not meet the requirements",
+		FailedRules:      failedRules,
+	})
+	return true
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePatchUserPasskeysPasskeyIdHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, passkeyId string) {
+		// parse json request body and validate in proper schema
+		var req api.PasskeyRenameRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		credential, err := auth.RenamePasskey(db, ctx.GetString(middleware.UserIdKey), passkeyId, req.Name)
+		if err != nil {
+			writePasskeyError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, passkeyResponse(credential))
+	}
+}
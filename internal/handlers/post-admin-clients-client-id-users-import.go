@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// bigger imports go through the import-users command
+const (
+	importUsersMaxBytes = 32 << 20
+	importUsersMaxUsers = 10000
+)
+
+func importHashConfig(config *api.ImportHashConfig) auth.ForeignHashConfig {
+	if config == nil {
+		return auth.ForeignHashConfig{}
+	}
+	value := func(text *string) string {
+		if text == nil {
+			return ""
+		}
+		return *text
+	}
+	number := func(n *int) int {
+		if n == nil {
+			return 0
+		}
+		return *n
+	}
+	return auth.ForeignHashConfig{
+		Algorithm:             value(config.Algorithm),
+		FirebaseSignerKey:     value(config.FirebaseSignerKey),
+		FirebaseSaltSeparator: value(config.FirebaseSaltSeparator),
+		FirebaseRounds:        number(config.FirebaseRounds),
+		FirebaseMemCost:       number(config.FirebaseMemCost),
+		SaltPosition:          value(config.SaltPosition),
+		SaltEncoding:          value(config.SaltEncoding),
+	}
+}
+
+func MakePostAdminClientsClientIdUsersImportHandler(db *gorm.DB) func(*gin.Context, string, api.PostAdminClientsClientIdUsersImportParams) {
+	return func(ctx *gin.Context, clientId string, params api.PostAdminClientsClientIdUsersImportParams) {
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, importUsersMaxBytes))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Import must be at most " + strconv.Itoa(importUsersMaxBytes>>20) + " MiB",
+			})
+			return
+		}
+
+		var input auth.ImportUsersInput
+		contentType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+		switch contentType {
+		case "text/csv":
+			input.Users, err = auth.ParseImportUsersCsv(bytes.NewReader(body))
+			input.HashConfig = importHashConfig(&api.ImportHashConfig{
+				Algorithm:             params.HashAlgorithm,
+				FirebaseSignerKey:     params.FirebaseSignerKey,
+				FirebaseSaltSeparator: params.FirebaseSaltSeparator,
+				FirebaseRounds:        params.FirebaseRounds,
+				FirebaseMemCost:       params.FirebaseMemCost,
+				SaltPosition:          params.SaltPosition,
+				SaltEncoding:          params.SaltEncoding,
+			})
+		default:
+			var req api.ImportUsersRequest
+			if err = json.Unmarshal(body, &req); err != nil {
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Invalid request format: " + err.Error(),
+				})
+				return
+			}
+			// users are read on their own so firebase's field names work too
+			input.Users, err = auth.ParseImportUsersJson(bytes.NewReader(body))
+			input.HashConfig = importHashConfig(req.HashConfig)
+			if req.BatchSize != nil {
+				input.BatchSize = *req.BatchSize
+			}
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Import file could not be read",
+			})
+			return
+		}
+		if len(input.Users) > importUsersMaxUsers {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "At most " + strconv.Itoa(importUsersMaxUsers) + " users can be imported per request",
+			})
+			return
+		}
+
+		result, err := auth.ImportUsers(db, clientId, input)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.ImportUsersErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.ImportUsersErrorProviderNotEnabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Email sign in is not enabled for the client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		failures := make([]api.ImportUserFailure, len(result.Failures))
+		for i, failure := range result.Failures {
+			failures[i] = api.ImportUserFailure{
+				Index:  failure.Index,
+				Email:  failure.Email,
+				Reason: failure.Reason,
+			}
+			if failure.Detail != "" {
+				detail := failure.Detail
+				failures[i].Detail = &detail
+			}
+		}
+
+		ctx.JSON(http.StatusOK, api.ImportUsersResponse{
+			Imported: result.Imported,
+			Skipped:  result.Skipped,
+			Failures: failures,
+		})
+	}
+}
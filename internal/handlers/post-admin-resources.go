@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAdminResourcesHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ApiResourceRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		resource, err := auth.CreateApiResource(db, apiResourceInputFromRequest(&req))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, apiResourceResponse(resource))
+	}
+}
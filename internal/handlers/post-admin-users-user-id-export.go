@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAdminUsersUserIdExportHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, userId string) {
+		job, err := auth.RequestUserExport(db, userId, models.ExportRequestedByAdmin)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, exportJobResponse(job))
+	}
+}
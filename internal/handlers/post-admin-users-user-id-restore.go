@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAdminUsersUserIdRestoreHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, userId string) {
+		if err := auth.RestoreAccount(db, userId, models.AccountDeletedByAdmin); err != nil {
+			writeAccountDeletionError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAuthAccountRestoreHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.RestoreAccountRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := auth.RestoreAccountWithToken(db, req.Token); err != nil {
+			writeAccountDeletionError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAuthMfaPasskeyStartHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.MfaPasskeyStartRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		options, err := auth.StartPasskeyMfa(db, req.FlowToken)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorUnsupportedType):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "User has no passkeys",
+				})
+			case string(auth.PasskeyErrorProviderDisabled), string(auth.PasskeyErrorRelyingPartyMissing):
+				writePasskeyError(ctx, err)
+			default:
+				writeAuthenticationFlowError(ctx, err)
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.PasskeyOptionsResponse{PublicKey: options})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAuthMfaHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.MfaVerifyRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		factorType := models.MfaFactorTypeTotp
+		if req.FactorType != nil {
+			factorType = string(*req.FactorType)
+		}
+
+		input := auth.MfaVerification{
+			FlowToken:  req.FlowToken,
+			FactorType: factorType,
+			Code:       derefString(req.Code),
+			Locale:     requestLocale(ctx),
+		}
+		if req.Passkey != nil {
+			passkey := toPasskeyAssertion(*req.Passkey)
+			input.Passkey = &passkey
+		}
+
+		result, err := auth.VerifyMfa(db, mailer, input)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorInvalidCode):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_code",
+					ErrorDescription: "Code or passkey is wrong, or was already used",
+				})
+			case string(auth.MfaErrorTooManyAttempts):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "too_many_attempts",
+					ErrorDescription: "Too many wrong codes, sign in again",
+				})
+			case string(auth.MfaErrorUnsupportedType):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "User has no second factor of this type",
+				})
+			default:
+				writeAuthenticationFlowError(ctx, err)
+			}
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
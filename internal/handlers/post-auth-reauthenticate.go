@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostAuthReauthenticateHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ReauthenticateRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		input := auth.Reauthentication{
+			FlowToken:  req.FlowToken,
+			FactorType: string(req.FactorType),
+			Password:   derefString(req.Password),
+			Code:       derefString(req.Code),
+		}
+		if req.Passkey != nil {
+			passkey := toPasskeyAssertion(*req.Passkey)
+			input.Passkey = &passkey
+		}
+
+		result, err := auth.Reauthenticate(db, input)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorInvalidCode):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_grant",
+					ErrorDescription: "Password, code or passkey is wrong",
+				})
+			case string(auth.MfaErrorTooManyAttempts):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "too_many_attempts",
+					ErrorDescription: "Too many wrong attempts, sign in again",
+				})
+			case string(auth.MfaErrorUnsupportedType):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "User can't reauthenticate with this factor",
+				})
+			default:
+				writeAuthenticationFlowError(ctx, err)
+			}
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
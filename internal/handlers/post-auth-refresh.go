@@ -4,12 +4,13 @@ import (
 	"net/http"
 	"sentinel-auth-backend/internal/api"
 	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func MakePostAuthRefreshHandler(db *gorm.DB) func(*gin.Context) {
+func MakePostAuthRefreshHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// parse json request body and validate in proper schema
 		var req api.AuthRefreshRequest
@@ -21,7 +22,28 @@ func MakePostAuthRefreshHandler(db *gorm.DB) func(*gin.Context) {
 			return
 		}
 
-		tokens, err := auth.RefreshTokensWithRefreshToken(db, req.ClientId, req.RefreshToken, req.CodeVerifier)
+		client, err := getClientById(db, req.ClientId)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+				Error:            "invalid_credentials",
+				ErrorDescription: "Invalid credentials",
+			})
+			return
+		}
+
+		if !authenticateClient(ctx, db, appConfig, client, req.ClientAssertionType, req.ClientAssertion) {
+			return
+		}
+
+		if !auth.ClientAllowsGrantType(client, auth.GrantTypeRefreshToken) {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "unauthorized_client",
+				ErrorDescription: "Client is not registered for the refresh_token grant type",
+			})
+			return
+		}
+
+		tokens, err := auth.RefreshTokensWithRefreshToken(db, req.ClientId, req.RefreshToken, req.CodeVerifier, derefString(req.Scope), derefString(req.Resource))
 
 		// handle errors in creating user
 		if err != nil {
@@ -32,16 +54,31 @@ func MakePostAuthRefreshHandler(db *gorm.DB) func(*gin.Context) {
 					ErrorDescription: "Invalid credentials",
 				})
 				return
+			case string(auth.RedeemAuthCodeErrorCodeChallengeFailed):
+				// also tokens issued before S256 was required, they have to sign in again
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_grant",
+					ErrorDescription: "code_verifier doesn't match the code_challenge",
+				})
+				return
+			case string(auth.ScopeErrorScopeNotGranted):
+				writeScopeError(ctx, err)
+				return
+			case string(auth.ApiResourceErrorInvalidTarget), string(auth.ApiResourceErrorResourceNotGranted):
+				writeApiResourceError(ctx, err)
+				return
 			default:
 				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
 				return
 			}
 		}
 
+		scope := auth.FormatScope(tokens.Scopes)
 		ctx.JSON(http.StatusOK, api.AuthRefreshTokensResponse{
 			AccessToken: tokens.Access,
 			IdToken:     tokens.Id,
 			ExpiresIn:   tokens.ExpiresIn,
+			Scope:       &scope,
 		})
 	}
 }
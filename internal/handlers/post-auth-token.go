@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"sentinel-auth-backend/internal/api"
 	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
 	"sentinel-auth-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +22,7 @@ func getClientById(db *gorm.DB, clientId string) (*models.Client, error) {
 	return &client, nil
 }
 
-func MakePostAuthTokenHandler(db *gorm.DB) func(*gin.Context) {
+func MakePostAuthTokenHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// parse json request body and validate in proper schema
 		var req api.AuthTokenRequest
@@ -42,7 +43,19 @@ func MakePostAuthTokenHandler(db *gorm.DB) func(*gin.Context) {
 			return
 		}
 
-		tokens, err := auth.RedeemAuthCode(db, req.ClientId, req.Code, req.CodeVerifier, client)
+		if !authenticateClient(ctx, db, appConfig, client, req.ClientAssertionType, req.ClientAssertion) {
+			return
+		}
+
+		if !auth.ClientAllowsGrantType(client, auth.GrantTypeAuthorizationCode) {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "unauthorized_client",
+				ErrorDescription: "Client is not registered for the authorization_code grant type",
+			})
+			return
+		}
+
+		tokens, err := auth.RedeemAuthCode(db, req.ClientId, req.Code, req.CodeVerifier, derefString(req.Resource), client)
 
 		// handle errors in creating user
 		if err != nil {
@@ -59,17 +72,31 @@ func MakePostAuthTokenHandler(db *gorm.DB) func(*gin.Context) {
 					ErrorDescription: "Invalid credentials",
 				})
 				return
+			case string(auth.RedeemAuthCodeErrorCodeChallengeFailed):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_grant",
+					ErrorDescription: "code_verifier doesn't match the code_challenge",
+				})
+				return
+			case string(auth.ApiResourceErrorInvalidTarget), string(auth.ApiResourceErrorResourceNotGranted):
+				writeApiResourceError(ctx, err)
+				return
 			default:
 				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
 				return
 			}
 		}
 
-		ctx.JSON(http.StatusOK, api.AuthTokenTokensResponse{
-			AccessToken:  tokens.Access,
-			IdToken:      tokens.Id,
-			RefreshToken: tokens.Refresh,
-			ExpiresIn:    tokens.ExpiresIn,
-		})
+		scope := auth.FormatScope(tokens.Scopes)
+		resp := api.AuthTokenTokensResponse{
+			AccessToken: tokens.Access,
+			IdToken:     tokens.Id,
+			ExpiresIn:   tokens.ExpiresIn,
+			Scope:       &scope,
+		}
+		if tokens.Refresh != "" {
+			resp.RefreshToken = &tokens.Refresh
+		}
+		ctx.JSON(http.StatusOK, resp)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/config"
 	"sentinel-auth-backend/internal/crypto"
 
 	"github.com/gin-gonic/gin"
@@ -25,7 +26,7 @@ func tokenClaimsToMap(tokenClaims *crypto.TokenClaims) (map[string]interface{},
 	return claimsMap, nil
 }
 
-func MakePostAuthVerifyHandler(db *gorm.DB) func(*gin.Context) {
+func MakePostAuthVerifyHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// parse json request body and validate in proper schema
 		var req api.AuthVerifyRequest
@@ -46,7 +47,11 @@ func MakePostAuthVerifyHandler(db *gorm.DB) func(*gin.Context) {
 			return
 		}
 
-		claims, err := crypto.VerifyToken(client, req.Token)
+		if !authenticateClient(ctx, db, appConfig, client, req.ClientAssertionType, req.ClientAssertion) {
+			return
+		}
+
+		claims, err := crypto.VerifyToken(db, client, req.Token)
 
 		// handle errors in creating user
 		if err != nil {
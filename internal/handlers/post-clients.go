@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostClientsHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// registration is guarded by an initial access token (RFC 7591 section 3)
+		if !auth.IsValidInitialAccessToken(appConfig.CLIENT_REGISTRATION_TOKENS, bearerToken(ctx)) {
+			ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+				Error:            "invalid_token",
+				ErrorDescription: "Invalid initial access token",
+			})
+			return
+		}
+
+		// parse json request body and validate in proper schema
+		var req api.ClientMetadata
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_client_metadata",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		client, registrationAccessToken, err := auth.RegisterClient(db, clientMetadataFromRequest(&req))
+		if err != nil {
+			writeRegisterClientError(ctx, err)
+			return
+		}
+
+		registrationClientUri := baseUrl(appConfig) + ctx.Request.URL.Path + "/" + client.ID
+		ctx.JSON(http.StatusCreated, clientInformationResponse(client, registrationClientUri, &registrationAccessToken))
+	}
+}
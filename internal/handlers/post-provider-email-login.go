@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"sentinel-auth-backend/internal/api"
 	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func MakePostProviderEmailLoginHandler(db *gorm.DB) func(*gin.Context) {
+func MakePostProviderEmailLoginHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// parse json request body and validate in proper schema
 		var req api.EmailLoginRequest
@@ -21,11 +26,46 @@ func MakePostProviderEmailLoginHandler(db *gorm.DB) func(*gin.Context) {
 			return
 		}
 
-		email := string(req.Email)
-		identity, err := auth.SignInWithEmail(db, req.ClientId, email, req.Password)
+		// the code is only issued after the password was checked, a challenge
+		// it can't be redeemed with must not cost the user a sign in attempt
+		if err := auth.CheckCodeChallenge(req.CodeChallenge, string(req.CodeChallengeMethod)); err != nil {
+			writeCodeChallengeError(ctx, err)
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		identity, err := auth.SignInWithEmail(db, mailer, auth.SignInWithEmailInput{
+			ClientId:    req.ClientId,
+			Email:       string(req.Email),
+			Password:    req.Password,
+			Ip:          ctx.ClientIP(),
+			RedirectUri: derefString(req.RedirectUri),
+			Locale:      requestLocale(ctx),
+		})
 
 		// handle errors in creating user
 		if err != nil {
+			var throttled *auth.SignInThrottledError
+			if errors.As(err, &throttled) {
+				ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+				ctx.JSON(http.StatusTooManyRequests, api.ErrorResponse{
+					Error:            "temporarily_locked",
+					ErrorDescription: "Too many failed sign ins, try again later",
+				})
+				return
+			}
+
 			switch err.Error() {
 			case string(auth.SignInWithEmailErrorUnknownUser):
 				// we are not specific to prevent some attacks
@@ -41,6 +81,18 @@ func MakePostProviderEmailLoginHandler(db *gorm.DB) func(*gin.Context) {
 					ErrorDescription: "Invalid credentials",
 				})
 				return
+			case string(auth.SignInWithEmailErrorEmailNotVerified):
+				ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+					Error:            "email_not_verified",
+					ErrorDescription: "Verify your email before signing in",
+				})
+				return
+			case string(auth.SignInWithEmailErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Email sign in is not enabled for this client",
+				})
+				return
 			case string(auth.SignInWithEmailErrorBadIdentityData):
 				ctx.JSON(http.StatusInternalServerError, api.ErrorResponse{
 					Error:            "server_error",
@@ -53,17 +105,22 @@ func MakePostProviderEmailLoginHandler(db *gorm.DB) func(*gin.Context) {
 			}
 		}
 
-		codeResp, err := auth.GenerateAuthCode(db, identity, req.CodeChallenge, string(req.CodeChallengeMethod))
+		result, err := auth.CompleteAuthorization(db, identity, auth.AuthorizationParams{
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: string(req.CodeChallengeMethod),
+			Scopes:              scopes,
+			Resources:           resources,
+			State:               req.State,
+			AcrValues:           strings.Fields(derefString(req.AcrValues)),
+		})
 
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			if !writeUnmetAcrError(ctx, err) {
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
 			return
 		}
 
-		ctx.JSON(http.StatusOK, api.AuthCodeResponse{
-			Code:      codeResp.Code,
-			ExpiresIn: codeResp.ExpiresIn,
-			State:     req.State,
-		})
+		writeAuthorizationResult(ctx, http.StatusOK, result)
 	}
 }
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderEmailOtpStartHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.EmailOtpRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		err = auth.SendEmailOtp(db, mailer, auth.EmailOtpInput{
+			ClientId: req.ClientId,
+			Email:    string(req.Email),
+			Locale:   requestLocale(ctx),
+			Authorization: auth.AuthorizationParams{
+				CodeChallenge:       req.CodeChallenge,
+				CodeChallengeMethod: string(req.CodeChallengeMethod),
+				Scopes:              scopes,
+				Resources:           resources,
+				State:               req.State,
+				AcrValues:           strings.Fields(derefString(req.AcrValues)),
+			},
+		})
+
+		if err != nil {
+			if writeCodeChallengeError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.EmailOtpErrorInvalidEmail):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Invalid email",
+				})
+			case string(auth.EmailOtpErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Email codes are not enabled for this client",
+				})
+			case string(auth.OneTimeCodeErrorThrottled):
+				ctx.Header("Retry-After", "60")
+				ctx.JSON(http.StatusTooManyRequests, api.ErrorResponse{
+					Error:            "slow_down",
+					ErrorDescription: "A code was sent recently, try again later",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderEmailOtpVerifyHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.EmailOtpVerifyRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		result, err := auth.VerifyEmailOtp(db, req.ClientId, string(req.Email), req.Code)
+		if err != nil {
+			if writeUnmetAcrError(ctx, err) || writeAccountDeletedError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.OneTimeCodeErrorInvalidCode):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_code",
+					ErrorDescription: "Code is wrong or expired",
+				})
+			case string(auth.OneTimeCodeErrorTooManyAttempts):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "too_many_attempts",
+					ErrorDescription: "Too many wrong codes, request a new one",
+				})
+			case string(auth.EmailOtpErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Email codes are not enabled for this client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
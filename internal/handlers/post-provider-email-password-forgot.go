@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderEmailPasswordForgotHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ForgotPasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		err := auth.RequestPasswordReset(db, mailer, req.ClientId, string(req.Email), derefString(req.RedirectUri), requestLocale(ctx))
+		if err != nil {
+			switch err.Error() {
+			case string(auth.PasswordResetErrorInvalidClient):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.PasswordResetErrorInvalidRedirectUri):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Redirect uri is not registered for this client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
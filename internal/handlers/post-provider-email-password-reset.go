@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderEmailPasswordResetHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ResetPasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		warnings, err := auth.ResetPassword(db, req.Token, req.Password)
+		if err != nil {
+			if writeWeakPasswordError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.PasswordResetErrorInvalidToken):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_token",
+					ErrorDescription: "Reset link is invalid, used or expired",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		setPasswordWarnings(ctx, warnings)
+		ctx.Status(http.StatusNoContent)
+	}
+}
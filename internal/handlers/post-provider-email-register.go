@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"sentinel-auth-backend/internal/api"
 	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/mail"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func MakePostProviderEmailRegisterHandler(db *gorm.DB) func(*gin.Context) {
+func MakePostProviderEmailRegisterHandler(db *gorm.DB, appConfig *config.Config, mailer mail.Mailer) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// parse json request body and validate in proper schema
 		var req api.EmailRegistrationRequest
@@ -21,11 +24,34 @@ func MakePostProviderEmailRegisterHandler(db *gorm.DB) func(*gin.Context) {
 			return
 		}
 
+		// refused before the user is created, the account would exist while
+		// the sign in that created it fails
+		if err := auth.CheckCodeChallenge(req.CodeChallenge, string(req.CodeChallengeMethod)); err != nil {
+			writeCodeChallengeError(ctx, err)
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
 		email := string(req.Email)
-		_, identity, err := auth.CreateUserWithEmail(db, req.ClientId, email, req.Password, req.Metadata)
+		_, identity, warnings, err := auth.CreateUserWithEmail(db, req.ClientId, email, req.Password, req.Metadata)
 
 		// handle errors in creating user
 		if err != nil {
+			if writeWeakPasswordError(ctx, err) {
+				return
+			}
+
 			switch err.Error() {
 			case string(auth.CreateUserWithEmailErrorMissingRequiredFields):
 				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
@@ -39,12 +65,6 @@ func MakePostProviderEmailRegisterHandler(db *gorm.DB) func(*gin.Context) {
 					ErrorDescription: "Invalid email",
 				})
 				return
-			case string(auth.CreateUserWithEmailErrorWeakPassword):
-				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
-					Error:            "invalid_request",
-					ErrorDescription: "Weak email",
-				})
-				return
 			case string(auth.CreateUserWithEmailErrorEmailTaken):
 				ctx.JSON(http.StatusConflict, api.ErrorResponse{
 					Error:            "email_exists",
@@ -57,23 +77,48 @@ func MakePostProviderEmailRegisterHandler(db *gorm.DB) func(*gin.Context) {
 					ErrorDescription: "Client does not exist",
 				})
 				return
+			case string(auth.CreateUserWithEmailErrorInvalidClientProvider):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Email sign in is not enabled for this client",
+				})
+				return
 			default:
 				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
 				return
 			}
 		}
 
-		codeResp, err := auth.GenerateAuthCode(db, identity, req.CodeChallenge, string(req.CodeChallengeMethod))
+		setPasswordWarnings(ctx, warnings)
 
+		// the account is usable even if the email can't be sent, a new one can
+		// be requested through the resend endpoint
+		err = auth.SendEmailVerification(db, mailer, identity, emailVerifyUrl(appConfig), requestLocale(ctx))
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			log.Println("failed to send verification email:", err)
+		}
+
+		if identity.Client.RequireEmailVerification {
+			ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+				Error:            "email_not_verified",
+				ErrorDescription: "Account created, verify your email before signing in",
+			})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, api.AuthCodeResponse{
-			Code:      codeResp.Code,
-			ExpiresIn: codeResp.ExpiresIn,
-			State:     req.State,
+		result, err := auth.CompleteAuthorization(db, identity, auth.AuthorizationParams{
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: string(req.CodeChallengeMethod),
+			Scopes:              scopes,
+			Resources:           resources,
+			State:               req.State,
 		})
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
 	}
 }
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+	"sentinel-auth-backend/internal/mail"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderEmailVerifyResendHandler(db *gorm.DB, appConfig *config.Config, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ResendEmailVerificationRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		err := auth.ResendEmailVerification(db, mailer, req.ClientId, string(req.Email), emailVerifyUrl(appConfig), requestLocale(ctx))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
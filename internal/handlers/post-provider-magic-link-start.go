@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderMagicLinkStartHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.MagicLinkRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		err = auth.SendMagicLink(db, mailer, auth.MagicLinkInput{
+			ClientId:    req.ClientId,
+			Email:       string(req.Email),
+			RedirectUri: derefString(req.RedirectUri),
+			Locale:      requestLocale(ctx),
+			Authorization: auth.AuthorizationParams{
+				CodeChallenge:       req.CodeChallenge,
+				CodeChallengeMethod: string(req.CodeChallengeMethod),
+				Scopes:              scopes,
+				Resources:           resources,
+				State:               req.State,
+				AcrValues:           strings.Fields(derefString(req.AcrValues)),
+			},
+		})
+
+		if err != nil {
+			if writeCodeChallengeError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.MagicLinkErrorInvalidEmail):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Invalid email",
+				})
+			case string(auth.MagicLinkErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Magic links are not enabled for this client",
+				})
+			case string(auth.MagicLinkErrorInvalidRedirectUri):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Redirect uri is not registered for this client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderMagicLinkVerifyHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.MagicLinkVerifyRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		result, err := auth.RedeemMagicLink(db, req.Token)
+		if err != nil {
+			if writeUnmetAcrError(ctx, err) || writeAccountDeletedError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.MagicLinkErrorInvalidLink):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_token",
+					ErrorDescription: "Sign in link is invalid, used or expired",
+				})
+			case string(auth.MagicLinkErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Magic links are not enabled for this client",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
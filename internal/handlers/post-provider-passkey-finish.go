@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderPasskeyFinishHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.PasskeySignInFinishRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		result, err := auth.FinishPasskeySignIn(db, req.ClientId, toPasskeyAssertion(req.Credential))
+		if err != nil {
+			writePasskeyError(ctx, err)
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
+
+func toPasskeyAssertion(assertion api.PasskeyAssertion) auth.PasskeyAssertion {
+	return auth.PasskeyAssertion{
+		CredentialId:      assertion.Id,
+		ClientDataJSON:    assertion.ClientDataJson,
+		AuthenticatorData: assertion.AuthenticatorData,
+		Signature:         assertion.Signature,
+		UserHandle:        derefString(assertion.UserHandle),
+	}
+}
+
+// writePasskeyError maps errors from the auth passkey functions to responses
+func writePasskeyError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.PasskeyErrorProviderDisabled), string(auth.PasskeyErrorRelyingPartyMissing):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_client",
+			ErrorDescription: "Passkeys are not enabled for this client",
+		})
+	case string(auth.PasskeyErrorInvalidChallenge):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Passkey challenge is invalid, used or expired",
+		})
+	case string(auth.PasskeyErrorInvalidName):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Passkey name has to be between 1 and 64 characters",
+		})
+	case string(auth.PasskeyErrorVerificationFailed), string(auth.PasskeyErrorUnknownCredential), string(auth.PasskeyErrorCloned):
+		ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+			Error:            "invalid_grant",
+			ErrorDescription: "Passkey could not be verified",
+		})
+	case string(auth.PasskeyErrorAlreadyRegistered):
+		ctx.JSON(http.StatusConflict, api.ErrorResponse{
+			Error:            "already_exists",
+			ErrorDescription: "Passkey is already registered",
+		})
+	case string(auth.PasskeyErrorNotFound):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "Passkey not found",
+		})
+	case string(auth.MfaErrorStepUpRequired):
+		writeMfaManagementError(ctx, err)
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderPasskeyStartHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.PasskeySignInRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		options, err := auth.StartPasskeySignIn(db, req.ClientId, auth.AuthorizationParams{
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: string(req.CodeChallengeMethod),
+			Scopes:              scopes,
+			Resources:           resources,
+			State:               req.State,
+		})
+		if err != nil {
+			if writeCodeChallengeError(ctx, err) {
+				return
+			}
+			writePasskeyError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.PasskeyOptionsResponse{PublicKey: options})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/sms"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostProviderPhoneStartHandler(db *gorm.DB, sender sms.SMSSender) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.PhoneCodeRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, req.ClientId, derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		err = auth.SendPhoneCode(db, sender, auth.PhoneCodeInput{
+			ClientId: req.ClientId,
+			Phone:    req.Phone,
+			Locale:   requestLocale(ctx),
+			Authorization: auth.AuthorizationParams{
+				CodeChallenge:       req.CodeChallenge,
+				CodeChallengeMethod: string(req.CodeChallengeMethod),
+				Scopes:              scopes,
+				Resources:           resources,
+				State:               req.State,
+				AcrValues:           strings.Fields(derefString(req.AcrValues)),
+			},
+		})
+
+		if err != nil {
+			if writeCodeChallengeError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.PhoneErrorInvalidPhone):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Invalid phone number",
+				})
+			case string(auth.PhoneErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_client",
+					ErrorDescription: "Phone sign in is not enabled for this client",
+				})
+			case string(auth.OneTimeCodeErrorThrottled):
+				ctx.Header("Retry-After", "60")
+				ctx.JSON(http.StatusTooManyRequests, api.ErrorResponse{
+					Error:            "slow_down",
+					ErrorDescription: "A code was sent recently, try again later",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
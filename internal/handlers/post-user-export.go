@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserExportHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		job, err := auth.RequestUserExport(db, ctx.GetString(middleware.UserIdKey), models.ExportRequestedByUser)
+		if err != nil {
+			writeUserExportError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, exportJobResponse(job))
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserMfaRecoveryCodesHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		codes, err := auth.RegenerateRecoveryCodes(db, ctx.GetString(middleware.UserIdKey), ctx.GetString(middleware.SessionIdKey))
+		if err != nil {
+			writeMfaManagementError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserMfaTotpConfirmHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.TotpConfirmRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		recoveryCodes, err := auth.ConfirmTotp(db, ctx.GetString(middleware.UserIdKey), req.Code)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorInvalidCode):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_code",
+					ErrorDescription: "Code is wrong, check the time on the device",
+				})
+			case string(auth.MfaErrorNoPendingEnrollment):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "No authenticator app setup in progress",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		resp := api.MfaEnrollmentResponse{}
+		if recoveryCodes != nil {
+			resp.RecoveryCodes = &recoveryCodes
+		}
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
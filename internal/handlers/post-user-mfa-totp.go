@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserMfaTotpHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		enrollment, err := auth.EnrollTotp(db, ctx.GetString(middleware.UserIdKey), ctx.GetString(middleware.SessionIdKey))
+		if err != nil {
+			switch err.Error() {
+			case string(auth.MfaErrorAlreadyEnrolled):
+				ctx.JSON(http.StatusConflict, api.ErrorResponse{
+					Error:            "already_enrolled",
+					ErrorDescription: "An authenticator app is already set up, remove it first",
+				})
+			case string(auth.ReauthenticationErrorRequired):
+				ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+					Error:            "login_required",
+					ErrorDescription: "Sign in again to set up an authenticator app",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, api.TotpEnrollmentResponse{
+			FactorId:   enrollment.FactorId,
+			Secret:     enrollment.Secret,
+			OtpauthUri: enrollment.Uri,
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserPasskeysRegisterFinishHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.PasskeyRegistrationRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		credential, recoveryCodes, err := auth.FinishPasskeyRegistration(db, ctx.GetString(middleware.UserIdKey), req.ClientDataJson, req.AttestationObject, derefString(req.Name))
+		if err != nil {
+			switch err.Error() {
+			case string(auth.PasskeyErrorVerificationFailed):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Passkey could not be verified",
+				})
+			default:
+				writePasskeyError(ctx, err)
+			}
+			return
+		}
+
+		resp := api.PasskeyRegistrationResponse{Passkey: passkeyResponse(credential)}
+		if recoveryCodes != nil {
+			resp.RecoveryCodes = &recoveryCodes
+		}
+		ctx.JSON(http.StatusCreated, resp)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserPasskeysRegisterStartHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		options, err := auth.StartPasskeyRegistration(db, ctx.GetString(middleware.UserIdKey))
+		if err != nil {
+			writePasskeyError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.PasskeyOptionsResponse{PublicKey: options})
+	}
+}
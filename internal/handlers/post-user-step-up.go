@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/middleware"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePostUserStepUpHandler(db *gorm.DB) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.StepUpRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if req.MaxAge != nil && *req.MaxAge < 0 {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "max_age can not be negative",
+			})
+			return
+		}
+
+		scopes, err := auth.ResolveRequestedScopes(db, ctx.GetString(middleware.ClientIdKey), derefString(req.Scope))
+		if err != nil {
+			writeScopeError(ctx, err)
+			return
+		}
+
+		resources, err := auth.ResolveRequestedResources(db, derefStrings(req.Resource))
+		if err != nil {
+			writeApiResourceError(ctx, err)
+			return
+		}
+
+		result, err := auth.StepUp(db, ctx.GetString(middleware.UserIdKey), ctx.GetString(middleware.SessionIdKey), auth.StepUpInput{
+			Authorization: auth.AuthorizationParams{
+				CodeChallenge:       req.CodeChallenge,
+				CodeChallengeMethod: string(req.CodeChallengeMethod),
+				Scopes:              scopes,
+				Resources:           resources,
+				State:               req.State,
+				AcrValues:           strings.Fields(derefString(req.AcrValues)),
+			},
+			MaxAge: req.MaxAge,
+		})
+		if err != nil {
+			if writeCodeChallengeError(ctx, err) || writeUnmetAcrError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.StepUpErrorLoginRequired), string(auth.SessionErrorRevoked):
+				ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+					Error:            "login_required",
+					ErrorDescription: "Sign in again to continue",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		writeAuthorizationResult(ctx, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutAdminClientsClientIdEmailVerificationHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		// parse json request body and validate in proper schema
+		var req api.EmailVerificationPolicy
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		client, err := auth.SetClientRequireEmailVerification(db, clientId, req.RequireEmailVerification)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.EmailVerificationErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.EmailVerificationPolicy{
+			RequireEmailVerification: client.RequireEmailVerification,
+		})
+	}
+}
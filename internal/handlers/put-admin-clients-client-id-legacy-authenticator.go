@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutAdminClientsClientIdLegacyAuthenticatorHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		// parse json request body and validate in proper schema
+		var req api.LegacyAuthenticatorRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		input := auth.LegacyAuthenticatorInput{Url: req.Url, Enabled: true}
+		if req.Secret != nil {
+			input.Secret = *req.Secret
+		}
+		if req.TimeoutMs != nil {
+			input.TimeoutMs = *req.TimeoutMs
+		}
+		if req.Enabled != nil {
+			input.Enabled = *req.Enabled
+		}
+
+		config, err := auth.SetClientLegacyAuthenticator(db, clientId, input)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.LegacyAuthenticatorErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.LegacyAuthenticatorErrorInvalidUrl):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Url must be https, or http on localhost",
+				})
+			case string(auth.LegacyAuthenticatorErrorInvalidTimeout):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Timeout must be between 100 and 30000 milliseconds",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, legacyAuthenticatorResponse(config))
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutAdminClientsClientIdLockoutHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		// parse json request body and validate in proper schema
+		var req api.LockoutPolicy
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		client, err := auth.SetClientLockoutPolicy(db, clientId, auth.LockoutPolicy{
+			Threshold:       req.Threshold,
+			IpThreshold:     req.IpThreshold,
+			DurationSeconds: req.DurationSeconds,
+		})
+		if err != nil {
+			switch err.Error() {
+			case string(auth.SignInThrottleErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.SignInThrottleErrorInvalidPolicy):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Thresholds can't be negative and the duration must be at least a second",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, api.LockoutPolicy{
+			Threshold:       client.LockoutThreshold,
+			IpThreshold:     client.IpLockoutThreshold,
+			DurationSeconds: client.LockoutDurationSeconds,
+		})
+	}
+}
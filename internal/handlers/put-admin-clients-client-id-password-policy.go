@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutAdminClientsClientIdPasswordPolicyHandler(db *gorm.DB) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		// parse json request body and validate in proper schema
+		var req api.PasswordPolicy
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		policy, err := auth.SetPasswordPolicy(db, clientId, auth.PasswordPolicyInput{
+			MinLength:        req.MinLength,
+			MaxLength:        req.MaxLength,
+			RequireUppercase: req.RequireUppercase,
+			RequireLowercase: req.RequireLowercase,
+			RequireDigit:     req.RequireDigit,
+			RequireSymbol:    req.RequireSymbol,
+			MinStrength:      req.MinStrength,
+			BannedWords:      req.BannedWords,
+			HistorySize:      req.HistorySize,
+			BreachMode:       req.BreachMode,
+		})
+		if err != nil {
+			switch err.Error() {
+			case string(auth.PasswordPolicyErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.PasswordPolicyErrorInvalidPolicy):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Lengths must be between 1 and 256 with the minimum below the maximum, strength between 0 and 4, history size between 0 and 24 and breach mode block, warn or off",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		ctx.JSON(http.StatusOK, passwordPolicyResponse(policy))
+	}
+}
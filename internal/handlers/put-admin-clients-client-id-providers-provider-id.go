@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutAdminClientsClientIdProvidersProviderIdHandler(db *gorm.DB) func(*gin.Context, string, string) {
+	return func(ctx *gin.Context, clientId string, providerId string) {
+		// parse json request body and validate in proper schema
+		var req api.ClientProviderRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		clientProvider, err := auth.SetClientProvider(db, clientId, providerId, req.Enabled, req.Data)
+		if err != nil {
+			switch err.Error() {
+			case string(auth.ClientProviderErrorClientNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Client does not exist",
+				})
+			case string(auth.ClientProviderErrorProviderNotFound):
+				ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+					Error:            "not_found",
+					ErrorDescription: "Provider does not exist",
+				})
+			case string(auth.ClientProviderErrorNoSmsGateway):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Phone sign in needs an sms gateway, set SMS_SENDER first",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		providerOption := clientProvider.ProviderOption
+		ctx.JSON(http.StatusOK, api.StrippedClientProvider{
+			ClientId: &clientProvider.ClientId,
+			Data:     (*map[string]interface{})(&clientProvider.Data),
+			Id:       &clientProvider.ID,
+			ProviderOption: &struct {
+				Description *string `json:"description,omitempty"`
+				Id          *string `json:"id,omitempty"`
+				LogoUrl     *string `json:"logo_url,omitempty"`
+				Name        *string `json:"name,omitempty"`
+			}{
+				Description: &providerOption.Description,
+				Id:          &providerOption.ID,
+				LogoUrl:     providerOption.LogoUrl,
+				Name:        &providerOption.Name,
+			},
+		})
+	}
+}
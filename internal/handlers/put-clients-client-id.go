@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutClientsClientIdHandler(db *gorm.DB, appConfig *config.Config) func(*gin.Context, string) {
+	return func(ctx *gin.Context, clientId string) {
+		// parse json request body and validate in proper schema
+		var req api.ClientMetadata
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_client_metadata",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		client, err := auth.UpdateRegisteredClient(db, clientId, bearerToken(ctx), clientMetadataFromRequest(&req))
+		if err != nil {
+			writeRegisterClientError(ctx, err)
+			return
+		}
+
+		registrationClientUri := baseUrl(appConfig) + ctx.Request.URL.Path
+		ctx.JSON(http.StatusOK, clientInformationResponse(client, registrationClientUri, nil))
+	}
+}
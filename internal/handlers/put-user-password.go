@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MakePutUserPasswordHandler(db *gorm.DB, mailer mail.Mailer) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		// parse json request body and validate in proper schema
+		var req api.ChangePasswordRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+				Error:            "invalid_request",
+				ErrorDescription: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		// the current password doesn't prove much for accounts that have none
+		// yet, so both setting and changing it need a fresh sign in or a
+		// session that used a second factor
+		if err := auth.CheckRecentOrAal2Authentication(db, ctx.GetString(middleware.SessionIdKey)); err != nil {
+			if err.Error() == string(auth.ReauthenticationErrorRequired) {
+				ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+					Error:            "login_required",
+					ErrorDescription: "Sign in again to change your password",
+				})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			return
+		}
+
+		warnings, err := auth.ChangePassword(db, mailer, ctx.GetString(middleware.UserIdKey), auth.ChangePasswordInput{
+			CurrentPassword:      derefString(req.CurrentPassword),
+			NewPassword:          req.NewPassword,
+			SignOutOtherSessions: req.SignOutOtherSessions != nil && *req.SignOutOtherSessions,
+			CurrentSessionId:     ctx.GetString(middleware.SessionIdKey),
+			Locale:               requestLocale(ctx),
+		})
+
+		if err != nil {
+			if writeWeakPasswordError(ctx, err) {
+				return
+			}
+
+			switch err.Error() {
+			case string(auth.ChangePasswordErrorCurrentPasswordRequired):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Current password is required",
+				})
+			case string(auth.ChangePasswordErrorWrongPassword):
+				ctx.JSON(http.StatusForbidden, api.ErrorResponse{
+					Error:            "invalid_credentials",
+					ErrorDescription: "Current password is incorrect",
+				})
+			case string(auth.ChangePasswordErrorEmailTaken):
+				ctx.JSON(http.StatusConflict, api.ErrorResponse{
+					Error:            "email_exists",
+					ErrorDescription: "Another account already signs in with this email",
+				})
+			case string(auth.ChangePasswordErrorProviderDisabled):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Email sign in is not enabled for this client",
+				})
+			case string(auth.ChangePasswordErrorNoEmail):
+				ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+					Error:            "invalid_request",
+					ErrorDescription: "Account has no email a password could be used with",
+				})
+			case string(auth.ChangePasswordErrorUnknownUser):
+				ctx.JSON(http.StatusUnauthorized, api.ErrorResponse{
+					Error:            "invalid_token",
+					ErrorDescription: "User does not exist",
+				})
+			default:
+				ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+			}
+			return
+		}
+
+		setPasswordWarnings(ctx, warnings)
+		ctx.Status(http.StatusNoContent)
+	}
+}
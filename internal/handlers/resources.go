@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func apiResourceResponse(resource *models.ApiResource) api.ApiResource {
+	scopes := []string(resource.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	resp := api.ApiResource{
+		Id:                  resource.ID,
+		Identifier:          resource.Identifier,
+		Name:                resource.Name,
+		Scopes:              scopes,
+		AccessTokenLifetime: resource.AccessTokenLifetime,
+		SigningAlgorithm:    resource.SigningAlgorithm,
+	}
+	// the api needs it to check its HS256 tokens
+	if resource.SigningAlgorithm == models.SigningAlgorithmHS256 && resource.SigningSecret != "" {
+		resp.SigningSecret = &resource.SigningSecret
+	}
+	return resp
+}
+
+func apiResourceInputFromRequest(req *api.ApiResourceRequest) auth.ApiResourceInput {
+	input := auth.ApiResourceInput{
+		Identifier: req.Identifier,
+		Name:       derefString(req.Name),
+	}
+
+	if req.Scopes != nil {
+		input.Scopes = *req.Scopes
+	}
+	if req.AccessTokenLifetime != nil {
+		input.AccessTokenLifetime = *req.AccessTokenLifetime
+	}
+	if req.SigningAlgorithm != nil {
+		input.SigningAlgorithm = string(*req.SigningAlgorithm)
+	}
+
+	return input
+}
+
+func derefStrings(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
+
+// writeApiResourceError maps errors from the auth resource functions to
+// responses. invalid_target comes from RFC 8707
+func writeApiResourceError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.ApiResourceErrorInvalidTarget):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_target",
+			ErrorDescription: "Requested resource is not a registered api",
+		})
+	case string(auth.ApiResourceErrorResourceNotGranted):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_target",
+			ErrorDescription: "Requested resource was not requested during authorization",
+		})
+	case string(auth.ApiResourceErrorInvalidIdentifier):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Identifier must be an absolute uri without a fragment",
+		})
+	case string(auth.ApiResourceErrorInvalidAlgorithm):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Unsupported signing algorithm",
+		})
+	case string(auth.ApiResourceErrorInvalidLifetime):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Access token lifetime must be between 60 and 86400 seconds",
+		})
+	case string(auth.ApiResourceErrorUnknownScope):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_scope",
+			ErrorDescription: "One or more scopes are not in the registry",
+		})
+	case string(auth.ApiResourceErrorIdentifierTaken):
+		ctx.JSON(http.StatusConflict, api.ErrorResponse{
+			Error:            "resource_exists",
+			ErrorDescription: "Identifier already registered",
+		})
+	case string(auth.ApiResourceErrorResourceNotFound):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "Resource does not exist",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func scopeDescription(scope *models.Scope) api.ScopeDescription {
+	return api.ScopeDescription{
+		Id:          scope.ID,
+		Description: scope.Description,
+		Standard:    scope.IsStandard,
+	}
+}
+
+// writeScopeError maps errors from the auth scope functions to responses
+func writeScopeError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.ScopeErrorInvalidScope):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_scope",
+			ErrorDescription: "Requested scope is invalid, unknown, or not allowed for the client",
+		})
+	case string(auth.ScopeErrorScopeNotGranted):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_scope",
+			ErrorDescription: "Requested scope was not originally granted",
+		})
+	case string(auth.ScopeErrorMissingScopeName):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Missing scope name",
+		})
+	case string(auth.ScopeErrorUnknownScope):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "Scope does not exist",
+		})
+	case string(auth.ScopeErrorScopeExists):
+		ctx.JSON(http.StatusConflict, api.ErrorResponse{
+			Error:            "scope_exists",
+			ErrorDescription: "Scope already exists",
+		})
+	case string(auth.ScopeErrorStandardScope):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Standard scopes can not be changed",
+		})
+	case string(auth.ScopeErrorClientNotFound):
+		ctx.JSON(http.StatusBadRequest, api.ErrorResponse{
+			Error:            "invalid_client",
+			ErrorDescription: "Client does not exist",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
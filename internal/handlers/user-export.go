@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func exportJobResponse(job *models.ExportJob) api.ExportJob {
+	resp := api.ExportJob{
+		Id:          job.ID,
+		Status:      job.Status,
+		RequestedBy: job.RequestedBy,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+	if job.Status == models.ExportJobStatusDone {
+		size := int(job.Size)
+		resp.Size = &size
+	}
+	return resp
+}
+
+// writeUserExportError answers for the errors the user and admin export
+// endpoints share
+func writeUserExportError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case string(auth.UserExportErrorUserNotFound):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "User does not exist",
+		})
+	case string(auth.UserExportErrorJobNotFound):
+		ctx.JSON(http.StatusNotFound, api.ErrorResponse{
+			Error:            "not_found",
+			ErrorDescription: "Export does not exist",
+		})
+	case string(auth.UserExportErrorNotReady):
+		ctx.JSON(http.StatusConflict, api.ErrorResponse{
+			Error:            "not_ready",
+			ErrorDescription: "Export is not done yet",
+		})
+	case string(auth.UserExportErrorExpired):
+		ctx.JSON(http.StatusGone, api.ErrorResponse{
+			Error:            "expired",
+			ErrorDescription: "Export expired, start a new one",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, "Something went wrong :(")
+	}
+}
+
+func writeUserExportArchive(ctx *gin.Context, job *models.ExportJob) {
+	filename := fmt.Sprintf("sentinel-export-%s-%s.zip", job.UserId, job.CreatedAt.Format("2006-01-02"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "application/zip", job.Archive)
+}
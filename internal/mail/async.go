@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull = errors.New("mail queue is full")
+	ErrClosed    = errors.New("mailer is closed")
+)
+
+const (
+	asyncMailerQueueSize = 1000
+	asyncMailerWorkers   = 4
+	asyncMailerAttempts  = 5
+)
+
+// AsyncMailer queues emails and delivers them in the background so a slow
+// mail server can't hold up requests. failed sends are retried with
+// exponential backoff
+type AsyncMailer struct {
+	mailer  Mailer
+	queue   chan Message
+	backoff time.Duration
+	wg      sync.WaitGroup
+	// background jobs may still send while shutting down, the queue is only
+	// closed once no Send holds the read lock
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func NewAsyncMailer(mailer Mailer) *AsyncMailer {
+	m := &AsyncMailer{
+		mailer:  mailer,
+		queue:   make(chan Message, asyncMailerQueueSize),
+		backoff: time.Second,
+	}
+
+	for range asyncMailerWorkers {
+		m.wg.Add(1)
+		go m.work()
+	}
+
+	return m
+}
+
+func (m *AsyncMailer) work() {
+	defer m.wg.Done()
+	for message := range m.queue {
+		m.deliver(message)
+	}
+}
+
+func (m *AsyncMailer) deliver(message Message) {
+	delay := m.backoff
+	for attempt := 1; ; attempt++ {
+		err := m.mailer.Send(message)
+		if err == nil {
+			return
+		}
+
+		if attempt == asyncMailerAttempts {
+			log.Printf("giving up sending %q to %s: %v", message.Subject, message.To, err)
+			return
+		}
+
+		log.Printf("failed sending %q to %s (attempt %d): %v", message.Subject, message.To, attempt, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Send only queues the message, delivery errors are logged
+func (m *AsyncMailer) Send(message Message) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	select {
+	case m.queue <- message:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting emails and waits for the queued ones to be
+// delivered. emails sent afterwards fail with ErrClosed
+func (m *AsyncMailer) Close() {
+	m.mutex.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mutex.Unlock()
+
+	m.wg.Wait()
+}
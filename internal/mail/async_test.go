@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAsyncMailerDeliversQueuedMailOnClose(t *testing.T) {
+	var out bytes.Buffer
+	m := NewAsyncMailer(NewWriterMailer(&out))
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := m.Send(Message{To: to, Subject: "Hello", Text: "Hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Close()
+
+	if !strings.Contains(out.String(), "a@example.com") || !strings.Contains(out.String(), "b@example.com") {
+		t.Errorf("queued mail not delivered before Close returned:\n%s", out.String())
+	}
+}
+
+func TestAsyncMailerSendAfterClose(t *testing.T) {
+	m := NewAsyncMailer(NewWriterMailer(&bytes.Buffer{}))
+
+	// workers keep sending while the server shuts down
+	var senders sync.WaitGroup
+	for range 8 {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			for range 100 {
+				err := m.Send(Message{To: "user@example.com"})
+				if err != nil && !errors.Is(err, ErrClosed) && !errors.Is(err, ErrQueueFull) {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	m.Close()
+	senders.Wait()
+
+	if err := m.Send(Message{To: "user@example.com"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("err = %v, want %v", err, ErrClosed)
+	}
+	// closing twice is fine
+	m.Close()
+}
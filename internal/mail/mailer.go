@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sentinel-auth-backend/internal/config"
+	"sync"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	// optional html alternative to Text
+	Html string
+}
+
+// Mailer delivers emails to users
+type Mailer interface {
+	Send(message Message) error
+}
+
+// WriterMailer prints emails instead of sending them, for local development
+// and tests
+type WriterMailer struct {
+	out   io.Writer
+	mutex sync.Mutex
+}
+
+func NewWriterMailer(out io.Writer) *WriterMailer {
+	return &WriterMailer{out: out}
+}
+
+// NewFileMailer appends every email to the file at path
+func NewFileMailer(path string) (*WriterMailer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterMailer(file), nil
+}
+
+func (m *WriterMailer) Send(message Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, err := fmt.Fprintf(m.out, "To: %s\nSubject: %s\n\n%s\n\n", message.To, message.Subject, message.Text)
+	return err
+}
+
+// FromConfig picks the mailer configured through the environment. delivery
+// always happens in the background
+func FromConfig(appConfig config.Config) (*AsyncMailer, error) {
+	var mailer Mailer = NewWriterMailer(os.Stdout)
+
+	if appConfig.MAIL_SMTP_HOST != "" {
+		mailer = NewSmtpMailer(SmtpConfig{
+			Host:     appConfig.MAIL_SMTP_HOST,
+			Port:     appConfig.MAIL_SMTP_PORT,
+			Username: appConfig.MAIL_SMTP_USERNAME,
+			Password: appConfig.MAIL_SMTP_PASSWORD,
+			From:     appConfig.MAIL_FROM,
+		})
+	} else if appConfig.MAIL_FILE != "" {
+		fileMailer, err := NewFileMailer(appConfig.MAIL_FILE)
+		if err != nil {
+			return nil, err
+		}
+		mailer = fileMailer
+	}
+
+	return NewAsyncMailer(mailer), nil
+}
@@ -0,0 +1,267 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	htmltemplate "html/template"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+)
+
+type Template string
+
+const (
+	TemplateVerifyEmail      Template = "verify_email"
+	TemplateResetPassword    Template = "reset_password"
+	TemplatePasswordChanged  Template = "password_changed"
+	TemplateMagicLink        Template = "magic_link"
+	TemplateEmailOtp         Template = "email_otp"
+	TemplateRecoveryCodeUsed Template = "recovery_code_used"
+	TemplateAccountLocked    Template = "account_locked"
+	TemplateAccountDeleted   Template = "account_deleted"
+)
+
+const DefaultLocale = "en"
+
+var supportedLocales = []string{"en", "es", "fr"}
+
+type localizedTemplate struct {
+	Subject string
+	// label of the button links are shown as
+	Action string
+	// Body is plain text with paragraphs separated by blank lines. the html
+	// version wraps each paragraph in the branded layout
+	Body string
+}
+
+var templates = map[Template]map[string]localizedTemplate{
+	TemplateVerifyEmail: {
+		"en": {
+			Subject: "Verify your email for {{.ClientName}}",
+			Action:  "Verify email",
+			Body:    "Confirm this is your email address by opening the link below. It expires in {{.ExpiresInHours}} hours.\n\n{{.Link}}\n\nIf you didn't create an account with {{.ClientName}}, you can ignore this email.",
+		},
+		"es": {
+			Subject: "Verifica tu correo para {{.ClientName}}",
+			Action:  "Verificar correo",
+			Body:    "Confirma que esta es tu dirección de correo abriendo el siguiente enlace. Caduca en {{.ExpiresInHours}} horas.\n\n{{.Link}}\n\nSi no creaste una cuenta en {{.ClientName}}, puedes ignorar este correo.",
+		},
+		"fr": {
+			Subject: "Vérifiez votre adresse e-mail pour {{.ClientName}}",
+			Action:  "Vérifier l'adresse",
+			Body:    "Confirmez que cette adresse e-mail est la vôtre en ouvrant le lien ci-dessous. Il expire dans {{.ExpiresInHours}} heures.\n\n{{.Link}}\n\nSi vous n'avez pas créé de compte sur {{.ClientName}}, vous pouvez ignorer cet e-mail.",
+		},
+	},
+	TemplateResetPassword: {
+		"en": {
+			Subject: "Reset your {{.ClientName}} password",
+			Action:  "Choose a new password",
+			Body:    "Someone asked to reset the password of your {{.ClientName}} account. Open the link below to choose a new one. It expires in {{.ExpiresInMinutes}} minutes and can only be used once.\n\n{{.Link}}\n\nIf you didn't ask for this, you can ignore this email, your password stays the same.",
+		},
+		"es": {
+			Subject: "Restablece tu contraseña de {{.ClientName}}",
+			Action:  "Elegir una contraseña nueva",
+			Body:    "Alguien pidió restablecer la contraseña de tu cuenta de {{.ClientName}}. Abre el siguiente enlace para elegir una nueva. Caduca en {{.ExpiresInMinutes}} minutos y solo se puede usar una vez.\n\n{{.Link}}\n\nSi no lo pediste, puedes ignorar este correo, tu contraseña no cambiará.",
+		},
+		"fr": {
+			Subject: "Réinitialisez votre mot de passe {{.ClientName}}",
+			Action:  "Choisir un nouveau mot de passe",
+			Body:    "Quelqu'un a demandé la réinitialisation du mot de passe de votre compte {{.ClientName}}. Ouvrez le lien ci-dessous pour en choisir un nouveau. Il expire dans {{.ExpiresInMinutes}} minutes et ne peut être utilisé qu'une fois.\n\n{{.Link}}\n\nSi vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail, votre mot de passe reste inchangé.",
+		},
+	},
+	TemplatePasswordChanged: {
+		"en": {
+			Subject: "Your {{.ClientName}} password was changed",
+			Body:    "The password of your {{.ClientName}} account was changed on {{.ChangedAt}}.\n\nIf this wasn't you, reset your password right away using the forgot password link on the sign in page.",
+		},
+		"es": {
+			Subject: "Se cambió tu contraseña de {{.ClientName}}",
+			Body:    "La contraseña de tu cuenta de {{.ClientName}} se cambió el {{.ChangedAt}}.\n\nSi no fuiste tú, restablece tu contraseña de inmediato con el enlace de contraseña olvidada en la página de inicio de sesión.",
+		},
+		"fr": {
+			Subject: "Votre mot de passe {{.ClientName}} a été modifié",
+			Body:    "Le mot de passe de votre compte {{.ClientName}} a été modifié le {{.ChangedAt}}.\n\nSi vous n'êtes pas à l'origine de ce changement, réinitialisez votre mot de passe immédiatement avec le lien mot de passe oublié de la page de connexion.",
+		},
+	},
+	TemplateMagicLink: {
+		"en": {
+			Subject: "Sign in to {{.ClientName}}",
+			Action:  "Sign in",
+			Body:    "Open the link below to sign in to {{.ClientName}}. It expires in {{.ExpiresInMinutes}} minutes and can only be used once.\n\n{{.Link}}\n\nIf you didn't try to sign in, you can ignore this email.",
+		},
+		"es": {
+			Subject: "Inicia sesión en {{.ClientName}}",
+			Action:  "Iniciar sesión",
+			Body:    "Abre el siguiente enlace para iniciar sesión en {{.ClientName}}. Caduca en {{.ExpiresInMinutes}} minutos y solo se puede usar una vez.\n\n{{.Link}}\n\nSi no intentaste iniciar sesión, puedes ignorar este correo.",
+		},
+		"fr": {
+			Subject: "Connectez-vous à {{.ClientName}}",
+			Action:  "Se connecter",
+			Body:    "Ouvrez le lien ci-dessous pour vous connecter à {{.ClientName}}. Il expire dans {{.ExpiresInMinutes}} minutes et ne peut être utilisé qu'une fois.\n\n{{.Link}}\n\nSi vous n'avez pas essayé de vous connecter, ignorez cet e-mail.",
+		},
+	},
+	TemplateEmailOtp: {
+		"en": {
+			Subject: "{{.Code}} is your {{.ClientName}} sign in code",
+			Body:    "Enter this code to sign in to {{.ClientName}}. It expires in {{.ExpiresInMinutes}} minutes.\n\n{{.Code}}\n\nNever share this code with anyone. If you didn't try to sign in, you can ignore this email.",
+		},
+		"es": {
+			Subject: "{{.Code}} es tu código de acceso a {{.ClientName}}",
+			Body:    "Introduce este código para iniciar sesión en {{.ClientName}}. Caduca en {{.ExpiresInMinutes}} minutos.\n\n{{.Code}}\n\nNunca compartas este código. Si no intentaste iniciar sesión, puedes ignorar este correo.",
+		},
+		"fr": {
+			Subject: "{{.Code}} est votre code de connexion à {{.ClientName}}",
+			Body:    "Saisissez ce code pour vous connecter à {{.ClientName}}. Il expire dans {{.ExpiresInMinutes}} minutes.\n\n{{.Code}}\n\nNe partagez jamais ce code. Si vous n'avez pas essayé de vous connecter, ignorez cet e-mail.",
+		},
+	},
+	TemplateAccountLocked: {
+		"en": {
+			Subject: "Sign in to your {{.ClientName}} account was paused",
+			Action:  "Unlock my account",
+			Body:    "There were too many failed attempts to sign in to your {{.ClientName}} account with a password, so password sign in is paused for {{.LockedMinutes}} minutes.\n\nIf it was you, open the link below to unlock your account right away.\n\n{{.Link}}\n\nIf it wasn't you, someone may be guessing your password. Consider changing it to a strong one you don't use anywhere else.",
+		},
+		"es": {
+			Subject: "Se pausó el inicio de sesión en tu cuenta de {{.ClientName}}",
+			Action:  "Desbloquear mi cuenta",
+			Body:    "Hubo demasiados intentos fallidos de iniciar sesión en tu cuenta de {{.ClientName}} con contraseña, así que el inicio de sesión con contraseña está pausado durante {{.LockedMinutes}} minutos.\n\nSi fuiste tú, abre el siguiente enlace para desbloquear tu cuenta ahora.\n\n{{.Link}}\n\nSi no fuiste tú, puede que alguien esté intentando adivinar tu contraseña. Considera cambiarla por una segura que no uses en ningún otro sitio.",
+		},
+		"fr": {
+			Subject: "La connexion à votre compte {{.ClientName}} a été suspendue",
+			Action:  "Débloquer mon compte",
+			Body:    "Il y a eu trop de tentatives de connexion échouées à votre compte {{.ClientName}} avec un mot de passe, la connexion par mot de passe est donc suspendue pendant {{.LockedMinutes}} minutes.\n\nSi c'était vous, ouvrez le lien ci-dessous pour débloquer votre compte immédiatement.\n\n{{.Link}}\n\nSinon, quelqu'un essaie peut-être de deviner votre mot de passe. Pensez à le remplacer par un mot de passe robuste que vous n'utilisez nulle part ailleurs.",
+		},
+	},
+	TemplateAccountDeleted: {
+		"en": {
+			Subject: "Your {{.ClientName}} account was deleted",
+			Action:  "Restore my account",
+			Body:    "Your {{.ClientName}} account was deleted and you were signed out everywhere. It will be erased for good on {{.PurgeDate}}.\n\nIf you change your mind, open the link below before then to restore it.\n\n{{.Link}}\n\nIf you didn't delete your account, restore it and change your password right away.",
+		},
+		"es": {
+			Subject: "Se eliminó tu cuenta de {{.ClientName}}",
+			Action:  "Restaurar mi cuenta",
+			Body:    "Se eliminó tu cuenta de {{.ClientName}} y se cerraron todas tus sesiones. Se borrará definitivamente el {{.PurgeDate}}.\n\nSi cambias de opinión, abre el siguiente enlace antes de esa fecha para restaurarla.\n\n{{.Link}}\n\nSi no eliminaste tu cuenta, restáurala y cambia tu contraseña de inmediato.",
+		},
+		"fr": {
+			Subject: "Votre compte {{.ClientName}} a été supprimé",
+			Action:  "Restaurer mon compte",
+			Body:    "Votre compte {{.ClientName}} a été supprimé et toutes vos sessions ont été fermées. Il sera effacé définitivement le {{.PurgeDate}}.\n\nSi vous changez d'avis, ouvrez le lien ci-dessous avant cette date pour le restaurer.\n\n{{.Link}}\n\nSi vous n'avez pas supprimé votre compte, restaurez-le et changez votre mot de passe immédiatement.",
+		},
+	},
+	TemplateRecoveryCodeUsed: {
+		"en": {
+			Subject: "A recovery code was used for your {{.ClientName}} account",
+			Body:    "One of your {{.ClientName}} recovery codes was used in place of your second factor to sign in on {{.UsedAt}}. You have {{.Remaining}} recovery codes left.\n\nIf this wasn't you, change your password and create new recovery codes right away.",
+		},
+		"es": {
+			Subject: "Se usó un código de recuperación de tu cuenta de {{.ClientName}}",
+			Body:    "Se usó uno de tus códigos de recuperación de {{.ClientName}} en lugar de tu segundo factor para iniciar sesión el {{.UsedAt}}. Te quedan {{.Remaining}} códigos de recuperación.\n\nSi no fuiste tú, cambia tu contraseña y crea códigos de recuperación nuevos de inmediato.",
+		},
+		"fr": {
+			Subject: "Un code de récupération de votre compte {{.ClientName}} a été utilisé",
+			Body:    "Un de vos codes de récupération {{.ClientName}} a remplacé votre second facteur pour une connexion le {{.UsedAt}}. Il vous reste {{.Remaining}} codes de récupération.\n\nSi vous n'êtes pas à l'origine de cette connexion, changez votre mot de passe et créez de nouveaux codes de récupération immédiatement.",
+		},
+	},
+}
+
+// paragraphs that are just a url are rendered as a button
+func isLink(paragraph string) bool {
+	return (strings.HasPrefix(paragraph, "https://") || strings.HasPrefix(paragraph, "http://")) && !strings.ContainsAny(paragraph, " \n")
+}
+
+var layout = htmltemplate.Must(htmltemplate.New("layout").Funcs(htmltemplate.FuncMap{"isLink": isLink}).Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<body style="margin:0;padding:24px;background:#f4f4f5;font-family:Helvetica,Arial,sans-serif;color:#18181b">
+<div style="max-width:480px;margin:0 auto;background:#ffffff;border-radius:8px;padding:32px">
+{{if .LogoUrl}}<img src="{{.LogoUrl}}" alt="{{.ClientName}}" style="max-height:48px;margin-bottom:24px">{{else}}<h2 style="margin-top:0">{{.ClientName}}</h2>{{end}}
+{{range .Paragraphs}}{{if isLink .}}<p><a href="{{.}}" style="display:inline-block;padding:12px 20px;background:#18181b;color:#ffffff;border-radius:6px;text-decoration:none">{{$.Action}}</a></p>
+<p style="font-size:12px;color:#71717a;word-break:break-all">{{.}}</p>
+{{else}}<p style="line-height:1.5">{{.}}</p>
+{{end}}{{end}}</div>
+</body>
+</html>`))
+
+// Data is what templates can refer to. ClientName and LogoUrl are filled in
+// from the client the email is sent for
+type Data map[string]interface{}
+
+// MatchLocale picks the best supported locale from an Accept-Language header
+func MatchLocale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if slices.Contains(supportedLocales, language) {
+			return language
+		}
+	}
+	return DefaultLocale
+}
+
+func renderText(source string, data Data) (string, error) {
+	tmpl, err := texttemplate.New("").Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render builds the message for template in the given locale, branded for
+// client. unsupported locales fall back to english
+func Render(client *models.Client, template Template, locale string, to string, data Data) (Message, error) {
+	localized, ok := templates[template]
+	if !ok {
+		return Message{}, errors.New("unknown email template")
+	}
+
+	content, ok := localized[locale]
+	if !ok {
+		locale = DefaultLocale
+		content = localized[DefaultLocale]
+	}
+
+	values := Data{}
+	for key, value := range data {
+		values[key] = value
+	}
+	values["ClientName"] = client.Name
+	values["LogoUrl"] = ""
+	if client.LogoUrl != nil {
+		values["LogoUrl"] = *client.LogoUrl
+	}
+
+	subject, err := renderText(content.Subject, values)
+	if err != nil {
+		return Message{}, err
+	}
+
+	text, err := renderText(content.Body, values)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var html bytes.Buffer
+	err = layout.Execute(&html, map[string]interface{}{
+		"Locale":     locale,
+		"Action":     content.Action,
+		"ClientName": client.Name,
+		"LogoUrl":    values["LogoUrl"],
+		"Paragraphs": strings.Split(text, "\n\n"),
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: subject,
+		Text:    text,
+		Html:    html.String(),
+	}, nil
+}
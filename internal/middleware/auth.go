@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/auth"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// keys under which the authenticated caller is stored on the gin context
+const (
+	UserIdKey    = "sentinel_user_id"
+	ClientIdKey  = "sentinel_client_id"
+	SessionIdKey = "sentinel_session_id"
+	ClaimsKey    = "sentinel_claims"
+)
+
+// audience of access tokens meant for sentinel's own apis
+const SentinelAudience = "sentinel"
+
+func bearerToken(ctx *gin.Context) string {
+	header := ctx.GetHeader("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func abortUnauthorized(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, api.ErrorResponse{
+		Error:            "invalid_token",
+		ErrorDescription: "Missing or invalid access token",
+	})
+}
+
+// authenticate verifies the bearer access token against the secret of the
+// client that issued it
+func authenticate(ctx *gin.Context, db *gorm.DB) (*crypto.TokenClaims, *models.Client, bool) {
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, nil, false
+	}
+
+	clientId, err := crypto.PeekTokenClientId(token)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var client models.Client
+	result := db.First(&client, "id = ?", clientId)
+	if result.Error != nil {
+		return nil, nil, false
+	}
+
+	claims, err := crypto.VerifyToken(db, &client, token)
+	if err != nil || claims.Subject == "" || !slices.Contains(claims.Audience, SentinelAudience) {
+		return nil, nil, false
+	}
+
+	// tokens stop working as soon as their session is revoked
+	if !auth.IsSessionActive(db, claims.SessionId) {
+		return nil, nil, false
+	}
+
+	return claims, &client, true
+}
+
+// RequireUser only lets requests through that carry a valid access token for
+// sentinel issued to a first party app. third party apps a user consented to
+// get tokens too, but must not manage the account with them. the user,
+// client and claims are put on the context
+func RequireUser(db *gorm.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, client, ok := authenticate(ctx, db)
+		if !ok {
+			abortUnauthorized(ctx)
+			return
+		}
+
+		if !auth.IsFirstPartyClient(client) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, api.ErrorResponse{
+				Error:            "forbidden",
+				ErrorDescription: "Only first party apps can manage the account",
+			})
+			return
+		}
+
+		ctx.Set(UserIdKey, claims.Subject)
+		ctx.Set(ClientIdKey, client.ID)
+		ctx.Set(SessionIdKey, claims.SessionId)
+		ctx.Set(ClaimsKey, claims)
+		ctx.Next()
+	}
+}
+
+// RequireAdmin is RequireUser for users with the admin role signed in through
+// the root client
+func RequireAdmin(db *gorm.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, client, ok := authenticate(ctx, db)
+		if !ok {
+			abortUnauthorized(ctx)
+			return
+		}
+
+		var user models.User
+		result := db.First(&user, "id = ? AND client_id = ?", claims.Subject, client.ID)
+		if result.Error != nil || !client.IsRootClient || user.Role != models.UserRoleAdmin {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, api.ErrorResponse{
+				Error:            "forbidden",
+				ErrorDescription: "Admin access required",
+			})
+			return
+		}
+
+		ctx.Set(UserIdKey, claims.Subject)
+		ctx.Set(ClientIdKey, client.ID)
+		ctx.Set(SessionIdKey, claims.SessionId)
+		ctx.Set(ClaimsKey, claims)
+		ctx.Next()
+	}
+}
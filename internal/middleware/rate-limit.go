@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sentinel-auth-backend/internal/api"
+	"sentinel-auth-backend/internal/crypto"
+	"sentinel-auth-backend/internal/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodies bigger than this aren't looked into for client ids and identifiers
+const rateLimitMaxBody = 64 * 1024
+
+// the fields of a json body the limits are keyed by
+type rateLimitBody struct {
+	ClientId string `json:"client_id"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+}
+
+// rateLimitSubjects finds what the request is counted by for every key. the
+// body is read and put back for the handler
+func rateLimitSubjects(ctx *gin.Context) map[string]string {
+	subjects := map[string]string{
+		ratelimit.KeyIp:     ctx.ClientIP(),
+		ratelimit.KeyClient: ctx.Query("client_id"),
+	}
+
+	if ctx.Request.Body == nil || !strings.HasPrefix(ctx.ContentType(), "application/json") {
+		return subjects
+	}
+
+	data, err := io.ReadAll(io.LimitReader(ctx.Request.Body, rateLimitMaxBody+1))
+	ctx.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), ctx.Request.Body), ctx.Request.Body}
+	if err != nil || len(data) > rateLimitMaxBody {
+		return subjects
+	}
+
+	var body rateLimitBody
+	if json.Unmarshal(data, &body) != nil {
+		return subjects
+	}
+	if body.ClientId != "" {
+		subjects[ratelimit.KeyClient] = body.ClientId
+	}
+
+	// identifiers are only counted within their client
+	identifier := strings.ToLower(strings.TrimSpace(body.Email))
+	if identifier == "" {
+		// only digits, so spacing and dashes don't make new buckets
+		identifier = strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, body.Phone)
+	}
+	if identifier != "" {
+		subjects[ratelimit.KeyIdentifier] = subjects[ratelimit.KeyClient] + ":" + identifier
+	}
+
+	return subjects
+}
+
+func ceilSeconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
+
+// RateLimit applies the limits of the route a request matched. the tightest
+// limit is reported in RateLimit-* headers, and requests over any of them get
+// 429 with Retry-After. when the store fails requests are let through, so an
+// outage of the store doesn't take down sign ins, unless failClosed is set,
+// then they get 503
+func RateLimit(store ratelimit.Store, routes ratelimit.Routes, failClosed bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		rules := routes[route]
+		if len(rules) == 0 {
+			ctx.Next()
+			return
+		}
+
+		subjects := rateLimitSubjects(ctx)
+
+		var tightest *ratelimit.Result
+		var tightestRule ratelimit.Rule
+		policies := []string{}
+		for _, rule := range rules {
+			if subjects[rule.Key] != "" {
+				policies = append(policies, strconv.Itoa(rule.Requests)+";w="+ceilSeconds(rule.Period))
+			}
+		}
+
+		for _, rule := range rules {
+			subject := subjects[rule.Key]
+			if subject == "" {
+				continue
+			}
+
+			key := "ratelimit:" + route + ":" + rule.Key + ":" + crypto.HashSecret(subject)
+			result, err := store.Take(ctx.Request.Context(), key, rule.Limit)
+			if err != nil {
+				if failClosed {
+					log.Println("failed to check rate limit, refusing request:", err)
+					ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, api.ErrorResponse{
+						Error:            "temporarily_unavailable",
+						ErrorDescription: "Try again later",
+					})
+					return
+				}
+				log.Println("failed to check rate limit, letting request through:", err)
+				continue
+			}
+
+			if tightest == nil || !result.Allowed || result.Remaining < tightest.Remaining {
+				tightest = &result
+				tightestRule = rule
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+
+		if tightest == nil {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Policy", strings.Join(policies, ", "))
+		ctx.Header("RateLimit-Limit", strconv.Itoa(tightestRule.Requests))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+		ctx.Header("RateLimit-Reset", ceilSeconds(tightest.Reset))
+
+		if !tightest.Allowed {
+			ctx.Header("Retry-After", ceilSeconds(tightest.RetryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, api.ErrorResponse{
+				Error:            "rate_limited",
+				ErrorDescription: "Too many requests, try again later",
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sentinel-auth-backend/internal/ratelimit"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failingStore stands in for a store that can't be reached
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func rateLimitedRouter(store ratelimit.Store, routes ratelimit.Routes, failClosed bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(store, routes, failClosed))
+	router.POST("/login", func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, string(body))
+	})
+	router.GET("/open", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func postLogin(router *gin.Engine, remoteAddr string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitRefusesOverTheLimit(t *testing.T) {
+	router := rateLimitedRouter(ratelimit.NewMemoryStore(), ratelimit.Routes{
+		"POST /login": {{Key: ratelimit.KeyIp, Limit: ratelimit.Limit{Requests: 2, Period: time.Minute}}},
+	}, false)
+
+	for i := 1; i >= 0; i-- {
+		recorder := postLogin(router, "192.0.2.1:1234", "{}")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("request was refused with %d", recorder.Code)
+		}
+		if got := recorder.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(i) {
+			t.Errorf("RateLimit-Remaining = %q, want %d", got, i)
+		}
+		if got := recorder.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("RateLimit-Policy = %q", got)
+		}
+	}
+
+	recorder := postLogin(router, "192.0.2.1:1234", "{}")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request got %d, want 429", recorder.Code)
+	}
+	if got := recorder.Header().Get("Retry-After"); got == "" || got == "0" {
+		t.Errorf("Retry-After = %q", got)
+	}
+	if !strings.Contains(recorder.Body.String(), "rate_limited") {
+		t.Errorf("body = %s", recorder.Body.String())
+	}
+
+	if recorder := postLogin(router, "192.0.2.2:1234", "{}"); recorder.Code != http.StatusOK {
+		t.Errorf("another address was refused with %d", recorder.Code)
+	}
+}
+
+func TestRateLimitCountsIdentifiersWithinTheirClient(t *testing.T) {
+	router := rateLimitedRouter(ratelimit.NewMemoryStore(), ratelimit.Routes{
+		"POST /login": {{Key: ratelimit.KeyIdentifier, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}}},
+	}, false)
+
+	body := `{"client_id": "app", "email": "User@Example.com"}`
+	recorder := postLogin(router, "192.0.2.1:1234", body)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("first request got %d", recorder.Code)
+	}
+	if recorder.Body.String() != body {
+		t.Errorf("handler read %q, want the body it was sent", recorder.Body.String())
+	}
+
+	// other addresses and spelling still count against the same email
+	if recorder := postLogin(router, "192.0.2.2:1234", `{"client_id": "app", "email": " user@example.com"}`); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("same email got %d, want 429", recorder.Code)
+	}
+	if recorder := postLogin(router, "192.0.2.1:1234", `{"client_id": "other", "email": "user@example.com"}`); recorder.Code != http.StatusOK {
+		t.Errorf("same email of another client got %d", recorder.Code)
+	}
+
+	if recorder := postLogin(router, "192.0.2.1:1234", `{"client_id": "app", "phone": "+1 555-0100"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("first phone request got %d", recorder.Code)
+	}
+	if recorder := postLogin(router, "192.0.2.1:1234", `{"client_id": "app", "phone": "+15550100"}`); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("same phone number got %d, want 429", recorder.Code)
+	}
+}
+
+func TestRateLimitSkipsRoutesWithoutLimits(t *testing.T) {
+	router := rateLimitedRouter(failingStore{}, ratelimit.Routes{
+		"POST /login": {{Key: ratelimit.KeyIp, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}}},
+	}, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/open", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("route without limits got %d", recorder.Code)
+	}
+	if recorder.Header().Get("RateLimit-Limit") != "" {
+		t.Error("route without limits got rate limit headers")
+	}
+}
+
+func TestRateLimitStoreFailure(t *testing.T) {
+	routes := ratelimit.Routes{
+		"POST /login": {{Key: ratelimit.KeyIp, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}}},
+	}
+
+	open := rateLimitedRouter(failingStore{}, routes, false)
+	for i := 0; i < 3; i++ {
+		if recorder := postLogin(open, "192.0.2.1:1234", "{}"); recorder.Code != http.StatusOK {
+			t.Fatalf("failing open refused a request with %d", recorder.Code)
+		}
+	}
+
+	closed := rateLimitedRouter(failingStore{}, routes, true)
+	recorder := postLogin(closed, "192.0.2.1:1234", "{}")
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("failing closed answered %d, want 503", recorder.Code)
+	}
+}
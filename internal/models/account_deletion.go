@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// who deleted or restored an account
+const (
+	AccountDeletedByUser  = "user"
+	AccountDeletedByAdmin = "admin"
+)
+
+// AccountDeletion is a deleted user waiting out the grace period. until
+// PurgeAt the user can be restored, after that everything about them is
+// erased
+type AccountDeletion struct {
+	UserId   string `gorm:"type:uuid;primaryKey"`
+	ClientId string `gorm:"type:uuid;not null"`
+	// user or admin
+	DeletedBy string `gorm:"type:varchar;not null"`
+	// the DeletedAt the user and their identities got, restoring only brings
+	// back identities deleted at that moment
+	DeletedAt time.Time `gorm:"not null"`
+	PurgeAt   time.Time `gorm:"not null;index"`
+	// sha256 of the token in the restore link emailed to the user
+	RestoreTokenHash *string `gorm:"type:varchar;index"`
+	CreatedAt        time.Time
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmHS256 = "HS256"
+)
+
+// ApiResource is a resource server (RFC 8707) that access tokens can be
+// restricted to. the identifier becomes the token's audience
+type ApiResource struct {
+	ID         string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Identifier string `gorm:"uniqueIndex;not null"`
+	Name       string
+	// scopes from the registry that mean something to this api
+	Scopes pq.StringArray `gorm:"type:text[]"`
+	// in seconds
+	AccessTokenLifetime int    `gorm:"default:3600"`
+	SigningAlgorithm    string `gorm:"type:varchar;default:'RS256'"`
+	// key HS256 tokens for this api are signed with. only sentinel and the
+	// api know it, clients never do
+	SigningSecret string `gorm:"type:varchar" json:"-"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AuditEvent is an event of the events bus kept for later, like a user
+// looking up what happened to their account
+type AuditEvent struct {
+	ID   string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Type string `gorm:"type:varchar;not null"`
+	// empty for events not about a client or a known user
+	ClientId  string         `gorm:"type:varchar"`
+	UserId    string         `gorm:"type:varchar;index"`
+	Data      JsonDictionary `gorm:"type:jsonb"`
+	CreatedAt time.Time
+}
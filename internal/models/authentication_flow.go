@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	AuthenticationFlowStepConsent        = "consent"
+	AuthenticationFlowStepMfa            = "mfa"
+	AuthenticationFlowStepReauthenticate = "reauthenticate"
+)
+
+// AuthenticationFlow holds a sign in that succeeded but still needs another
+// step (like consent) before an auth code can be issued. the parameters of
+// the original authorization request are kept so the code matches them
+type AuthenticationFlow struct {
+	ID                  string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId            string `gorm:"type:uuid;not null"`
+	IdentityId          string `gorm:"type:uuid;not null"`
+	UserId              string `gorm:"type:uuid;not null;index"`
+	TokenHash           string `gorm:"uniqueIndex;not null"`
+	NextStep            string `gorm:"type:varchar;not null"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
+	State               *string
+	ExpiresAt           time.Time `gorm:"index"`
+	// when the user proved who they are, kept across steps for auth_time
+	AuthTime time.Time
+	// 2 once the user passed a second factor during this sign in
+	Aal int `gorm:"not null;default:1"`
+	// authentication methods used so far during this sign in
+	Amr pq.StringArray `gorm:"type:text[]"`
+	// set when the flow steps up an existing session instead of starting one
+	SessionId *string `gorm:"type:uuid"`
+	// wrong second factor codes entered for this flow
+	Attempts  int `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Client   Client   `gorm:"foreignKey:ClientId" json:"-"`
+	Identity Identity `gorm:"foreignKey:IdentityId" json:"-"`
+	User     User     `gorm:"foreignKey:UserId" json:"-"`
+}
@@ -7,6 +7,11 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	TokenEndpointAuthMethodNone          = "none"
+	TokenEndpointAuthMethodPrivateKeyJwt = "private_key_jwt"
+)
+
 type Client struct {
 	ID             string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	Name           string `gorm:"not null"`
@@ -18,4 +23,30 @@ type Client struct {
 	UpdatedAt      time.Time
 	DeletedAt      gorm.DeletedAt `gorm:"index"`
 	IsRootClient   bool           `gorm:"default:FALSE"`
+	// first party apps are trusted and never ask users for consent
+	IsFirstParty bool `gorm:"default:FALSE"`
+	// email users can't sign in until they followed the verification link
+	RequireEmailVerification bool `gorm:"default:FALSE"`
+
+	// failed password sign ins for one email before it is locked, 0 turns
+	// lockouts off
+	LockoutThreshold int `gorm:"not null;default:10"`
+	// failed password sign ins from one ip before it is locked
+	IpLockoutThreshold int `gorm:"not null;default:100"`
+	// how long the first lockout lasts, following ones double up to a day
+	LockoutDurationSeconds int `gorm:"not null;default:900"`
+
+	// how the client proves its identity to the token and verify endpoints
+	TokenEndpointAuthMethod string `gorm:"type:varchar;default:'none'"`
+	// public keys used to check private_key_jwt client assertions. either the
+	// key set itself or a uri it can be fetched from
+	Jwks    JsonDictionary `gorm:"type:jsonb"`
+	JwksUri *string
+
+	GrantTypes pq.StringArray `gorm:"type:text[]"`
+	// scopes from the registry the client may request
+	AllowedScopes pq.StringArray `gorm:"type:text[]"`
+	// set only for clients created through dynamic registration. sha256 of the
+	// token that lets the registrant read, update and delete the client
+	RegistrationAccessTokenHash *string
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// ClientAssertionJti remembers the jti of every client assertion we accepted
+// until the assertion expires so that it can't be replayed
+type ClientAssertionJti struct {
+	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId  string    `gorm:"type:uuid;not null;uniqueIndex:idx_client_assertion_jti"`
+	Jti       string    `gorm:"not null;uniqueIndex:idx_client_assertion_jti"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
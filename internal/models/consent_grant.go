@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ConsentGrant records which scopes a user agreed to share with a client
+type ConsentGrant struct {
+	ID        string         `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId    string         `gorm:"type:uuid;not null;uniqueIndex:idx_consent_grant_user_client"`
+	ClientId  string         `gorm:"type:uuid;not null;uniqueIndex:idx_consent_grant_user_client"`
+	Scopes    pq.StringArray `gorm:"type:text[]"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User   User   `gorm:"foreignKey:UserId" json:"-"`
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
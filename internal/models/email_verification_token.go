@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerificationToken is a single use token mailed to prove the owner of
+// an email identity can read that inbox. only its sha256 is stored
+type EmailVerificationToken struct {
+	ID         string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	IdentityId string `gorm:"type:uuid;not null;index"`
+	TokenHash  string `gorm:"type:varchar;not null;uniqueIndex"`
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+
+	Identity Identity `gorm:"foreignKey:IdentityId" json:"-"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	ExportJobStatusPending = "pending"
+	ExportJobStatusRunning = "running"
+	ExportJobStatusDone    = "done"
+	ExportJobStatusFailed  = "failed"
+)
+
+// who asked for an export
+const (
+	ExportRequestedByUser  = "user"
+	ExportRequestedByAdmin = "admin"
+)
+
+// ExportJob builds an archive of everything stored about a user in the
+// background. the archive can be downloaded until ExpiresAt
+type ExportJob struct {
+	ID          string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId      string `gorm:"type:uuid;not null;index"`
+	RequestedBy string `gorm:"type:varchar;not null"`
+	Status      string `gorm:"type:varchar;not null;default:'pending'"`
+	// zip of json files, kept in the database so any instance can serve it
+	Archive     []byte `json:"-"`
+	Size        int64  `gorm:"not null;default:0"`
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	User User `gorm:"foreignKey:UserId" json:"-"`
+}
@@ -14,6 +14,9 @@ type Identity struct {
 	ClientProviderId string         `gorm:"type:varchar"`
 	UserId           string         `gorm:"type:uuid"`
 	Data             JsonDictionary `gorm:"type:jsonb"`
+	EmailVerified    bool           `gorm:"default:FALSE"`
+	Phone            *string        `gorm:"type:varchar;index"`
+	PhoneVerified    bool           `gorm:"default:FALSE"`
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	DeletedAt        gorm.DeletedAt `gorm:"index"`
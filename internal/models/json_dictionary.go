@@ -11,6 +11,11 @@ import (
 type JsonDictionary map[string]interface{}
 
 func (j *JsonDictionary) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+
 	data, ok := value.([]byte)
 	if !ok {
 		return errors.New("failed to cast value to []bytes")
@@ -18,6 +23,11 @@ func (j *JsonDictionary) Scan(value interface{}) error {
 	return json.Unmarshal(data, &j)
 }
 
-func (j JsonDictionary) Value(value interface{}) (driver.Value, error) {
+// Value makes it a driver.Valuer, so drivers that don't encode maps
+// themselves store it as json too
+func (j JsonDictionary) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
 	return json.Marshal(j)
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// LegacyAuthenticator is the endpoint of a client's old auth system that
+// checks the password of emails sentinel doesn't know yet. users it accepts
+// are created on the spot, so they move over with their next sign in
+type LegacyAuthenticator struct {
+	ClientId string `gorm:"type:uuid;primaryKey"`
+	Url      string `gorm:"not null"`
+	// sent as a bearer token so the endpoint can tell the requests are ours
+	Secret    string
+	TimeoutMs int `gorm:"not null;default:5000"`
+	// no default, gorm would write it in place of false
+	Enabled bool `gorm:"not null"`
+	// users created through the endpoint so far, once it stops growing the
+	// old system can be turned off
+	MigratedUsers  int `gorm:"not null;default:0"`
+	LastMigratedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MagicLink is a pending passwordless sign in. the authorization request is
+// kept here until the emailed link is opened, so the code it results in is
+// bound to the code challenge of the device that asked for the link
+type MagicLink struct {
+	ID                  string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId            string `gorm:"type:uuid;not null"`
+	Email               string `gorm:"not null;index"`
+	TokenHash           string `gorm:"type:varchar;not null;uniqueIndex"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
+	AcrValues           pq.StringArray `gorm:"type:text[]"`
+	State               *string
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+	CreatedAt           time.Time
+
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
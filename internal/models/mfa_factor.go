@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	MfaFactorTypeTotp    = "totp"
+	MfaFactorTypePasskey = "passkey"
+	// not a factor of its own, answers the second factor step in place of one
+	MfaFactorTypeRecoveryCode = "recovery_code"
+)
+
+// MfaFactor is a second factor a user enrolled. factors only count once
+// ConfirmedAt is set, which happens after the user proved the setup works
+type MfaFactor struct {
+	ID     string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId string `gorm:"type:uuid;not null;index"`
+	Type   string `gorm:"type:varchar;not null"`
+	// base32 totp secret
+	Secret string `gorm:"type:varchar;not null" json:"-"`
+	// last totp step a code was accepted for, older ones are refused as replays
+	LastUsedStep int64 `gorm:"not null;default:0"`
+	ConfirmedAt  *time.Time
+	LastUsedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	User User `gorm:"foreignKey:UserId" json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// MfaRecoveryCode is a single use code that stands in for a second factor
+// when the user lost their device. only a salted hash is stored
+type MfaRecoveryCode struct {
+	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId    string `gorm:"type:uuid;not null;index"`
+	Salt      string `gorm:"type:varchar;not null" json:"-"`
+	CodeHash  string `gorm:"type:varchar;not null" json:"-"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserId" json:"-"`
+}
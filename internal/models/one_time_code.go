@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	OneTimeCodeChannelEmail = "email"
+	OneTimeCodeChannelPhone = "phone"
+)
+
+// OneTimeCode is a pending sign in with a short code sent by email or text
+// message. like MagicLink it keeps the authorization request the code
+// completes
+type OneTimeCode struct {
+	ID       string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId string `gorm:"type:uuid;not null;index:idx_one_time_code_recipient"`
+	Channel  string `gorm:"type:varchar;not null;index:idx_one_time_code_recipient"`
+	// email address or E.164 phone number the code was sent to
+	Recipient           string `gorm:"not null;index:idx_one_time_code_recipient"`
+	Salt                string `gorm:"type:varchar;not null"`
+	CodeHash            string `gorm:"type:varchar;not null"`
+	Attempts            int    `gorm:"not null;default:0"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
+	AcrValues           pq.StringArray `gorm:"type:text[]"`
+	State               *string
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+	CreatedAt           time.Time
+
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
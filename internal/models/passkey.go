@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PasskeyCredential is a webauthn credential a user registered. it signs the
+// user in on its own or serves as their second factor
+type PasskeyCredential struct {
+	ID       string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId   string `gorm:"type:uuid;not null;index"`
+	ClientId string `gorm:"type:uuid;not null"`
+	// base64url credential id the authenticator picked
+	CredentialId string `gorm:"type:varchar;not null;uniqueIndex"`
+	// PKIX encoded public key and its COSE algorithm
+	PublicKey []byte `gorm:"not null" json:"-"`
+	Algorithm int    `gorm:"not null"`
+	// signatures carry a counter that only goes up, going back means the
+	// credential was cloned
+	SignCount      int64 `gorm:"not null;default:0"`
+	Name           string
+	BackupEligible bool
+	LastUsedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	User   User   `gorm:"foreignKey:UserId" json:"-"`
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
+
+const (
+	PasskeyCeremonyRegistration = "registration"
+	PasskeyCeremonySignIn       = "sign_in"
+	PasskeyCeremonyMfa          = "mfa"
+)
+
+// PasskeyChallenge is an unfinished webauthn ceremony. sign ins keep the
+// authorization request they complete, second factor checks the flow they
+// belong to
+type PasskeyChallenge struct {
+	ID                  string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId            string  `gorm:"type:uuid;not null"`
+	UserId              *string `gorm:"type:uuid"`
+	FlowId              *string `gorm:"type:uuid"`
+	Ceremony            string  `gorm:"type:varchar;not null"`
+	ChallengeHash       string  `gorm:"type:varchar;not null;uniqueIndex"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
+	State               *string
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+	CreatedAt           time.Time
+}
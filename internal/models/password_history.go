@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordHistory is a password an identity had, kept to stop users from
+// going back to recent ones
+type PasswordHistory struct {
+	ID           string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	IdentityId   string `gorm:"type:uuid;not null;index"`
+	PasswordHash string `gorm:"type:varchar;not null" json:"-"`
+	CreatedAt    time.Time
+
+	Identity Identity `gorm:"foreignKey:IdentityId" json:"-"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// what happens to passwords found in breach corpora
+const (
+	PasswordBreachModeBlock = "block"
+	PasswordBreachModeWarn  = "warn"
+	PasswordBreachModeOff   = "off"
+)
+
+// PasswordPolicy is what passwords of a client's users must look like.
+// clients without one use the default policy
+type PasswordPolicy struct {
+	ClientId  string `gorm:"type:uuid;primaryKey"`
+	MinLength int    `gorm:"not null"`
+	MaxLength int    `gorm:"not null"`
+
+	RequireUppercase bool `gorm:"not null;default:FALSE"`
+	RequireLowercase bool `gorm:"not null;default:FALSE"`
+	RequireDigit     bool `gorm:"not null;default:FALSE"`
+	RequireSymbol    bool `gorm:"not null;default:FALSE"`
+
+	// lowest zxcvbn style score from 0 to 4 that is accepted
+	MinStrength int `gorm:"not null"`
+	// words passwords may not contain, on top of the user's email and the
+	// client's name
+	BannedWords pq.StringArray `gorm:"type:text[]"`
+	// how many of the user's latest passwords can't be used again, the
+	// current one included. 0 allows any
+	HistorySize int `gorm:"not null"`
+	// whether breached passwords are refused or only reported to the app
+	BreachMode string `gorm:"type:varchar;not null;default:'block'"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Client Client `gorm:"foreignKey:ClientId" json:"-"`
+}
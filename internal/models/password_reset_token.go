@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single use token mailed to let a user choose a new
+// password. only its sha256 is stored
+type PasswordResetToken struct {
+	ID         string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	IdentityId string `gorm:"type:uuid;not null;index"`
+	TokenHash  string `gorm:"type:varchar;not null;uniqueIndex"`
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+
+	Identity Identity `gorm:"foreignKey:IdentityId" json:"-"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -11,9 +12,12 @@ type RedeemAuthCode struct {
 	ClientId            string `gorm:"type:uuid;not null"`
 	IdentityId          string `gorm:"type:uuid;not null"`
 	UserId              string `gorm:"type:uuid;not null"`
+	SessionId           string `gorm:"type:varchar;index"`
 	Code                string
 	CodeChallenge       string
 	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
 	Redeemed            bool
 	Revoked             bool
 	ExpiresAt           time.Time
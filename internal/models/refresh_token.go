@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -14,10 +15,13 @@ type RefreshToken struct {
 	ClientProviderId    string `gorm:"type:varchar"`
 	IdentityId          string `gorm:"type:uuid"`
 	UserId              string `gorm:"type:uuid"`
+	SessionId           string `gorm:"type:varchar;index"`
 	Token               string `gorm:"not null"`
 	Revoked             bool   `gorm:"default:FALSE"`
 	CodeChallenge       string
 	CodeChallengeMethod string
+	Scopes              pq.StringArray `gorm:"type:text[]"`
+	Resources           pq.StringArray `gorm:"type:text[]"`
 	ExpiresAt           time.Time
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scope is an entry in the scope registry. standard scopes come from OpenID
+// Connect and are seeded, everything else is a custom api scope
+type Scope struct {
+	ID          string `gorm:"type:varchar;primaryKey"`
+	Description string
+	IsStandard  bool `gorm:"default:FALSE"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Session is one sign in of a user on a client. codes, refresh tokens and the
+// tokens issued from them carry its id, so revoking the session signs that
+// device out
+type Session struct {
+	ID         string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserId     string `gorm:"type:uuid;not null;index"`
+	ClientId   string `gorm:"type:uuid;not null"`
+	IdentityId string `gorm:"type:uuid;not null"`
+	// when the user last proved who they are
+	AuthTime time.Time
+	// authenticator assurance level, 2 once a second factor was used
+	Aal int `gorm:"not null;default:1"`
+	// authentication methods used (RFC 8176), like pwd and otp
+	Amr       pq.StringArray `gorm:"type:text[]"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User     User     `gorm:"foreignKey:UserId" json:"-"`
+	Client   Client   `gorm:"foreignKey:ClientId" json:"-"`
+	Identity Identity `gorm:"foreignKey:IdentityId" json:"-"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	SignInThrottleKindIdentifier = "identifier"
+	SignInThrottleKindIp         = "ip"
+)
+
+// SignInThrottle counts failed password sign ins for an email or an ip of a
+// client. emails are tracked whether or not they are registered, so the
+// throttling looks the same for both
+type SignInThrottle struct {
+	ID       string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId string `gorm:"type:uuid;not null;uniqueIndex:idx_sign_in_throttles_subject"`
+	Kind     string `gorm:"type:varchar;not null;uniqueIndex:idx_sign_in_throttles_subject"`
+	// normalized email or ip address
+	Subject       string `gorm:"type:varchar;not null;uniqueIndex:idx_sign_in_throttles_subject"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	// lockouts so far, each one lasts twice as long as the one before. kept
+	// through successful sign ins, only unlocking resets them
+	Lockouts int `gorm:"not null;default:0"`
+	// sha256 of the token in the unlock email sent with the last lockout
+	UnlockTokenHash *string `gorm:"type:varchar;index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
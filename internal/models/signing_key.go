@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a private key sentinel signs asymmetric tokens with. the
+// public half is published at /.well-known/jwks.json
+type SigningKey struct {
+	ID string `gorm:"type:varchar;primaryKey"`
+	// one active key per algorithm, so instances starting at the same time
+	// can't each create their own
+	Algorithm     string `gorm:"type:varchar;not null;uniqueIndex:idx_signing_keys_active,where:active"`
+	PrivateKeyPem string `gorm:"not null" json:"-"`
+	Active        bool   `gorm:"default:TRUE"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
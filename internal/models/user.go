@@ -6,10 +6,17 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type User struct {
-	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	ClientId  string `gorm:"not null"`
-	Email     string `gorm:"unique;not null"`
+	ID       string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ClientId string `gorm:"not null"`
+	// empty (null) for users that signed up with a phone number
+	Email     string `gorm:"unique;default:null"`
+	Role      string `gorm:"type:varchar;default:'user'"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"sentinel-auth-backend/internal/config"
+)
+
+// FromConfig picks the store and limits configured through the environment.
+// buckets live in memory unless a redis url is set
+func FromConfig(appConfig config.Config) (Store, Routes, error) {
+	routes := DefaultRoutes()
+	if appConfig.RATE_LIMIT_FILE != "" {
+		loaded, err := LoadRoutes(appConfig.RATE_LIMIT_FILE)
+		if err != nil {
+			return nil, nil, err
+		}
+		routes = loaded
+	}
+
+	if appConfig.RATE_LIMIT_REDIS_URL == "" {
+		return NewMemoryStore(), routes, nil
+	}
+
+	redisConfig, err := ParseRedisUrl(appConfig.RATE_LIMIT_REDIS_URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewRedisStore(redisConfig), routes, nil
+}
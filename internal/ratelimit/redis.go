@@ -0,0 +1,315 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// takes a token from the bucket in KEYS[1] in one step, so instances sharing
+// the server can't race each other. the server's clock is used so instances
+// with drifting clocks still agree. ARGV is the bucket size and the
+// microseconds it takes to refill a token
+const takeScript = `
+if redis.replicate_commands then redis.replicate_commands() end
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(bucket[1])
+local at = tonumber(bucket[2])
+if tokens == nil or at == nil then
+  tokens = capacity
+  at = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - at) / interval)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', string.format('%.6f', tokens), 'at', string.format('%.0f', now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) * interval / 1000) + 1000)
+return {allowed, string.format('%.6f', tokens)}
+`
+
+var takeScriptSha = func() string {
+	sum := sha1.Sum([]byte(takeScript))
+	return hex.EncodeToString(sum[:])
+}()
+
+const (
+	redisDialTimeout = 2 * time.Second
+	// limits must not hold up requests for long when the server is slow
+	redisCommandTimeout = 500 * time.Millisecond
+	redisMaxIdle        = 16
+)
+
+type RedisConfig struct {
+	// host:port of the server
+	Addr     string
+	Username string
+	Password string
+	Database int
+	Tls      bool
+}
+
+// ParseRedisUrl reads redis://[user:password@]host[:port][/database], or
+// rediss:// for tls
+func ParseRedisUrl(rawUrl string) (RedisConfig, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" {
+		return RedisConfig{}, fmt.Errorf("unsupported redis url scheme %q", parsed.Scheme)
+	}
+
+	config := RedisConfig{Addr: parsed.Host, Tls: parsed.Scheme == "rediss"}
+	if parsed.Port() == "" {
+		config.Addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		config.Username = parsed.User.Username()
+		config.Password, _ = parsed.User.Password()
+	}
+	if database := strings.Trim(parsed.Path, "/"); database != "" {
+		config.Database, err = strconv.Atoi(database)
+		if err != nil {
+			return RedisConfig{}, fmt.Errorf("invalid redis database %q", database)
+		}
+	}
+	return config, nil
+}
+
+// RedisStore keeps buckets in redis, or anything speaking its protocol and
+// running lua scripts, so all instances share the same limits
+type RedisStore struct {
+	config RedisConfig
+	idle   chan *redisConn
+}
+
+func NewRedisStore(config RedisConfig) *RedisStore {
+	return &RedisStore{config: config, idle: make(chan *redisConn, redisMaxIdle)}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.interval().Microseconds()
+	if interval < 1 {
+		interval = 1
+	}
+	capacity := strconv.Itoa(limit.Requests)
+	intervalArg := strconv.FormatInt(interval, 10)
+
+	reply, err := s.do(ctx, "EVALSHA", takeScriptSha, "1", key, capacity, intervalArg)
+	var redisErr redisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		reply, err = s.do(ctx, "EVAL", takeScript, "1", key, capacity, intervalArg)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	tokensText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+
+	return result(limit, tokens, allowed == 1), nil
+}
+
+// Close drops the idle connections
+func (s *RedisStore) Close() error {
+	for {
+		select {
+		case conn := <-s.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do runs one command on a pooled connection
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// the connection may be half way through a reply, don't reuse it
+		conn.Close()
+		return nil, err
+	}
+
+	select {
+	case s.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+func (s *RedisStore) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-s.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var netConn net.Conn
+	var err error
+	if s.config.Tls {
+		host, _, _ := net.SplitHostPort(s.config.Addr)
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}
+		netConn, err = tlsDialer.DialContext(ctx, "tcp", s.config.Addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", s.config.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+	if s.config.Password != "" {
+		args := []string{"AUTH", s.config.Password}
+		if s.config.Username != "" {
+			args = []string{"AUTH", s.config.Username, s.config.Password}
+		}
+		if _, err := conn.do(ctx, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if s.config.Database != 0 {
+		if _, err := conn.do(ctx, "SELECT", strconv.Itoa(s.config.Database)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply from the server. the connection stays usable
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn speaks RESP, the redis protocol
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	deadline := time.Now().Add(redisCommandTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, command.String()); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+func (c *redisConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", errors.New("redis: malformed reply")
+	}
+	return line[:len(line)-2], nil
+}
+
+// readReply returns strings, int64s, nil and slices of those, or a redisError
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, errors.New("redis: malformed reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, length)
+		for i := range values {
+			value, err := c.readReply()
+			var redisErr redisError
+			if errors.As(err, &redisErr) {
+				// keep reading so the rest of the array doesn't stay on the wire
+				values[i] = redisErr
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
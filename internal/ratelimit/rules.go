@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// what requests are counted together
+const (
+	// the address the request came from
+	KeyIp = "ip"
+	// the client_id of the request
+	KeyClient = "client"
+	// the email or phone number a request signs in with or sends a code to
+	KeyIdentifier = "identifier"
+)
+
+type Rule struct {
+	Key string
+	Limit
+}
+
+type ruleJson struct {
+	Key      string `json:"key"`
+	Requests int    `json:"requests"`
+	Period   string `json:"period"`
+}
+
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var raw ruleJson
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Key != KeyIp && raw.Key != KeyClient && raw.Key != KeyIdentifier {
+		return fmt.Errorf("unknown rate limit key %q", raw.Key)
+	}
+	period, err := time.ParseDuration(raw.Period)
+	if err != nil {
+		return fmt.Errorf("invalid rate limit period %q", raw.Period)
+	}
+	if raw.Requests < 1 || period <= 0 {
+		return fmt.Errorf("rate limit for %s needs at least one request and a positive period", raw.Key)
+	}
+
+	*r = Rule{Key: raw.Key, Limit: Limit{Requests: raw.Requests, Period: period}}
+	return nil
+}
+
+// Routes maps "METHOD /path" (with gin's :param placeholders) to the limits
+// of the route. a request has to be within every one of them
+type Routes map[string][]Rule
+
+func perMinute(key string, requests int) Rule {
+	return Rule{Key: key, Limit: Limit{Requests: requests, Period: time.Minute}}
+}
+
+func perHour(key string, requests int) Rule {
+	return Rule{Key: key, Limit: Limit{Requests: requests, Period: time.Hour}}
+}
+
+// DefaultRoutes limits the endpoints that guess passwords or codes, send
+// emails and texts or create accounts and clients
+func DefaultRoutes() Routes {
+	return Routes{
+		"POST /v1/auth/providers/email/register": {perHour(KeyIp, 20), perMinute(KeyClient, 300)},
+		"POST /v1/auth/providers/email/login":    {perMinute(KeyIp, 30), perMinute(KeyIdentifier, 10), perMinute(KeyClient, 600)},
+
+		"POST /v1/auth/providers/email/verify/resend":   {perHour(KeyIp, 20), perHour(KeyIdentifier, 10)},
+		"POST /v1/auth/providers/email/password/forgot": {perHour(KeyIp, 20), perHour(KeyIdentifier, 10)},
+		"POST /v1/auth/providers/email/password/reset":  {perMinute(KeyIp, 10)},
+		"POST /v1/auth/providers/email/unlock":          {perMinute(KeyIp, 10)},
+
+		"POST /v1/auth/providers/magic_link/start":  {perHour(KeyIp, 20), perHour(KeyIdentifier, 10)},
+		"POST /v1/auth/providers/magic_link/verify": {perMinute(KeyIp, 20)},
+		"POST /v1/auth/providers/email_otp/start":   {perHour(KeyIp, 20), perHour(KeyIdentifier, 10)},
+		"POST /v1/auth/providers/email_otp/verify":  {perMinute(KeyIp, 20), perMinute(KeyIdentifier, 10)},
+		"POST /v1/auth/providers/phone/start":       {perHour(KeyIp, 10), perHour(KeyIdentifier, 5)},
+		"POST /v1/auth/providers/phone/verify":      {perMinute(KeyIp, 20), perMinute(KeyIdentifier, 10)},
+
+		"POST /v1/auth/providers/passkey/start":  {perMinute(KeyIp, 60)},
+		"POST /v1/auth/providers/passkey/finish": {perMinute(KeyIp, 30)},
+		"POST /v1/auth/mfa":                      {perMinute(KeyIp, 20)},
+		"POST /v1/auth/mfa/passkey/start":        {perMinute(KeyIp, 30)},
+		"POST /v1/auth/reauthenticate":           {perMinute(KeyIp, 20)},
+
+		"POST /v1/user/step_up": {perMinute(KeyIp, 30)},
+		"PUT /v1/user/password": {perMinute(KeyIp, 10)},
+
+		"POST /v1/auth/token":   {perMinute(KeyIp, 60), perMinute(KeyClient, 1200)},
+		"POST /v1/auth/refresh": {perMinute(KeyIp, 120), perMinute(KeyClient, 2400)},
+
+		"POST /v1/clients": {perHour(KeyIp, 10)},
+	}
+}
+
+// LoadRoutes reads routes from a json file like
+// {"POST /v1/auth/token": [{"key": "ip", "requests": 60, "period": "1m"}]}.
+// routes in the file replace the default limits of that route, an empty list
+// turns them off
+func LoadRoutes(path string) (Routes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides Routes
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid rate limit file %s: %w", path, err)
+	}
+
+	routes := DefaultRoutes()
+	for route, rules := range overrides {
+		routes[route] = rules
+	}
+	return routes, nil
+}
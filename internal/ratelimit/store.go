@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit lets Requests requests through per Period. the bucket holds at most
+// Requests tokens and refills evenly over the period, so short bursts are
+// fine as long as the average stays below the limit
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// time it takes the bucket to refill one token
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+type Result struct {
+	Allowed bool
+	// whole tokens left after this request
+	Remaining int
+	// how long until the next request would be allowed, when this one wasn't
+	RetryAfter time.Duration
+	// how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the token buckets. Take removes a token from the bucket at key
+// when there is one
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result turns the tokens left in a bucket into what the caller is told
+func result(limit Limit, tokens float64, allowed bool) Result {
+	interval := limit.interval()
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Requests) - tokens) * float64(interval)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+	return result
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	// when the bucket is full again and can be forgotten
+	full time.Time
+}
+
+// MemoryStore keeps buckets in the process. limits aren't shared between
+// instances, use it when running a single one
+type MemoryStore struct {
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	interval := limit.interval()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), at: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+float64(now.Sub(b.at))/float64(interval))
+	b.at = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(limit.Requests) - b.tokens) * float64(interval)))
+
+	return result(limit, b.tokens, allowed), nil
+}
+
+// sweep drops full buckets once a minute, they behave the same as missing ones
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
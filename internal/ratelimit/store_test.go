@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTakesUntilEmpty(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d was refused", 3-i)
+		}
+		if result.Remaining != i {
+			t.Errorf("remaining = %d, want %d", result.Remaining, i)
+		}
+	}
+
+	result, _ := store.Take(context.Background(), "key", limit)
+	if result.Allowed {
+		t.Fatal("fourth request was allowed")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 20*time.Second {
+		t.Errorf("retry after = %v, want up to the 20s a token takes", result.RetryAfter)
+	}
+	if result.Reset <= 40*time.Second || result.Reset > time.Minute {
+		t.Errorf("reset = %v, want close to a minute", result.Reset)
+	}
+
+	other, _ := store.Take(context.Background(), "other", limit)
+	if !other.Allowed || other.Remaining != 2 {
+		t.Errorf("another key shared the bucket: %+v", other)
+	}
+}
+
+func TestMemoryStoreRefills(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: 100 * time.Millisecond}
+
+	store.Take(context.Background(), "key", limit)
+	store.Take(context.Background(), "key", limit)
+	if result, _ := store.Take(context.Background(), "key", limit); result.Allowed {
+		t.Fatal("empty bucket let a request through")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if result, _ := store.Take(context.Background(), "key", limit); !result.Allowed {
+		t.Error("bucket didn't refill a token")
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	store.Take(context.Background(), "short", Limit{Requests: 10, Period: time.Millisecond})
+	store.Take(context.Background(), "long", Limit{Requests: 10, Period: time.Hour})
+
+	time.Sleep(5 * time.Millisecond)
+	store.sweep(store.lastSweep.Add(time.Minute))
+
+	if _, ok := store.buckets["short"]; ok {
+		t.Error("full bucket was kept")
+	}
+	if _, ok := store.buckets["long"]; !ok {
+		t.Error("bucket that isn't full was dropped")
+	}
+}
+
+func TestResult(t *testing.T) {
+	limit := Limit{Requests: 10, Period: 10 * time.Second}
+
+	allowed := result(limit, 4.5, true)
+	if allowed.Remaining != 4 || allowed.RetryAfter != 0 || allowed.Reset != 5500*time.Millisecond {
+		t.Errorf("allowed result = %+v", allowed)
+	}
+
+	refused := result(limit, 0.25, false)
+	if refused.Remaining != 0 || refused.RetryAfter != 750*time.Millisecond {
+		t.Errorf("refused result = %+v", refused)
+	}
+}
@@ -8,5 +8,50 @@ import (
 
 // make sure all these handlers check if admin
 func RegisterAdminRoutes(g *gin.RouterGroup, wrapper *api.ServerInterfaceWrapper) {
+	// manage custom api scopes in the scope registry
+	g.POST("/scopes", wrapper.PostAdminScopes)
+	g.DELETE("/scopes/:scope", wrapper.DeleteAdminScopesScope)
 
+	// register the apis access tokens can be restricted to (RFC 8707)
+	g.GET("/resources", wrapper.GetAdminResources)
+	g.POST("/resources", wrapper.PostAdminResources)
+	g.PUT("/resources/:resource_id", wrapper.PutAdminResourcesResourceId)
+	g.DELETE("/resources/:resource_id", wrapper.DeleteAdminResourcesResourceId)
+
+	// replace the scopes a client is allowed to request
+	g.PUT("/clients/:client_id/scopes", wrapper.PutAdminClientsClientIdScopes)
+
+	// turn sign in providers on or off for a client
+	g.PUT("/clients/:client_id/providers/:provider_id", wrapper.PutAdminClientsClientIdProvidersProviderId)
+
+	// when failed password sign ins lock a client's emails and addresses
+	g.PUT("/clients/:client_id/lockout", wrapper.PutAdminClientsClientIdLockout)
+
+	// rules passwords of a client's users must follow
+	g.GET("/clients/:client_id/password_policy", wrapper.GetAdminClientsClientIdPasswordPolicy)
+	g.PUT("/clients/:client_id/password_policy", wrapper.PutAdminClientsClientIdPasswordPolicy)
+
+	// bring in users exported from another system
+	g.POST("/clients/:client_id/users/import", wrapper.PostAdminClientsClientIdUsersImport)
+
+	// move users over from a client's old auth system as they sign in
+	g.GET("/clients/:client_id/legacy_authenticator", wrapper.GetAdminClientsClientIdLegacyAuthenticator)
+	g.PUT("/clients/:client_id/legacy_authenticator", wrapper.PutAdminClientsClientIdLegacyAuthenticator)
+	g.DELETE("/clients/:client_id/legacy_authenticator", wrapper.DeleteAdminClientsClientIdLegacyAuthenticator)
+
+	// second factors a user set up and the recovery codes they have left
+	g.GET("/users/:user_id/mfa", wrapper.GetAdminUsersUserIdMfa)
+
+	// lift sign in lockouts on a user's emails
+	g.DELETE("/users/:user_id/lockout", wrapper.DeleteAdminUsersUserIdLockout)
+
+	// archives of everything stored about a user, built in the background
+	g.POST("/users/:user_id/export", wrapper.PostAdminUsersUserIdExport)
+	g.GET("/users/:user_id/export/:job_id", wrapper.GetAdminUsersUserIdExportJobId)
+	g.GET("/users/:user_id/export/:job_id/download", wrapper.GetAdminUsersUserIdExportJobIdDownload)
+
+	// delete a user, right away with ?purge=true, or bring them back
+	// during the grace period
+	g.DELETE("/users/:user_id", wrapper.DeleteAdminUsersUserId)
+	g.POST("/users/:user_id/restore", wrapper.PostAdminUsersUserIdRestore)
 }
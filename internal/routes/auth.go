@@ -15,6 +15,43 @@ func RegisterAuthRoutes(g *gin.RouterGroup, wrapper *api.ServerInterfaceWrapper)
 	g.POST("/providers/email/register", wrapper.PostAuthProvidersEmailRegister)
 	// sign in user and return a one time code that can be used to fetch tokens later
 	g.POST("/providers/email/login", wrapper.PostAuthProvidersEmailLogin)
+	// follow the link from the verification email, or ask for a new one
+	g.GET("/providers/email/verify", wrapper.GetAuthProvidersEmailVerify)
+	g.POST("/providers/email/verify/resend", wrapper.PostAuthProvidersEmailVerifyResend)
+	// email a single use reset link, then set the new password with its token
+	g.POST("/providers/email/password/forgot", wrapper.PostAuthProvidersEmailPasswordForgot)
+	g.POST("/providers/email/password/reset", wrapper.PostAuthProvidersEmailPasswordReset)
+	// lift a lockout with the link from the lockout email
+	g.POST("/providers/email/unlock", wrapper.PostAuthProvidersEmailUnlock)
+
+	// bring back a deleted account with the link from the account deleted
+	// email
+	g.POST("/account/restore", wrapper.PostAuthAccountRestore)
+
+	// screen data for and answer to a pending consent request. approving
+	// continues the sign in with a code
+	g.GET("/consent", wrapper.GetAuthConsent)
+	g.POST("/consent", wrapper.PostAuthConsent)
+
+	// second factor step of a sign in for users that enrolled one
+	g.POST("/mfa", wrapper.PostAuthMfa)
+	g.POST("/mfa/passkey/start", wrapper.PostAuthMfaPasskeyStart)
+
+	// passwordless sign in through a single use link sent by email
+	g.POST("/providers/magic_link/start", wrapper.PostAuthProvidersMagicLinkStart)
+	g.POST("/providers/magic_link/verify", wrapper.PostAuthProvidersMagicLinkVerify)
+
+	// passwordless sign in with a six digit code sent by email
+	g.POST("/providers/email_otp/start", wrapper.PostAuthProvidersEmailOtpStart)
+	g.POST("/providers/email_otp/verify", wrapper.PostAuthProvidersEmailOtpVerify)
+
+	// sign in or sign up with a code texted to a phone number
+	g.POST("/providers/phone/start", wrapper.PostAuthProvidersPhoneStart)
+	g.POST("/providers/phone/verify", wrapper.PostAuthProvidersPhoneVerify)
+
+	// passwordless sign in with a passkey the user registered under /user
+	g.POST("/providers/passkey/start", wrapper.PostAuthProvidersPasskeyStart)
+	g.POST("/providers/passkey/finish", wrapper.PostAuthProvidersPasskeyFinish)
 
 	// use code to fetch sentinel auth tokens (id, access, refresh). has code verification step
 	g.POST("/token", wrapper.PostAuthToken)
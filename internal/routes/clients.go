@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"sentinel-auth-backend/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dynamic client registration (RFC 7591) and management (RFC 7592)
+func RegisterClientRoutes(g *gin.RouterGroup, wrapper *api.ServerInterfaceWrapper) {
+	// register a client, requires an initial access token
+	g.POST("", wrapper.PostClients)
+
+	// read, replace and delete a registered client with its registration access token
+	g.GET("/:client_id", wrapper.GetClientsClientId)
+	g.PUT("/:client_id", wrapper.PutClientsClientId)
+	g.DELETE("/:client_id", wrapper.DeleteClientsClientId)
+}
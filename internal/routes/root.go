@@ -2,11 +2,14 @@ package routes
 
 import (
 	"sentinel-auth-backend/internal/api"
-	"sentinel-auth-backend/internal/handlers"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRootRoutes(g *gin.RouterGroup, wrapper *api.ServerInterfaceWrapper) {
-	g.GET("/.well-known/jwks.json", handlers.StubHandler)
+	// public keys for checking RS256 access tokens issued for api resources
+	g.GET("/.well-known/jwks.json", wrapper.GetWellKnownJwksJson)
+
+	// list the scope registry so apps can show what they are asking for
+	g.GET("/scopes", wrapper.GetScopes)
 }
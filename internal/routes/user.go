@@ -12,6 +12,43 @@ func RegisterUserRoutes(g *gin.RouterGroup, wrapper *api.ServerInterfaceWrapper)
 	// return all claims/attributes you would find on the id token
 	g.GET("/info", handlers.StubHandler)
 
+	// change the password, or add one to an account from another provider
+	g.PUT("/password", wrapper.PutUserPassword)
+	// every way the user can sign in
+	g.GET("/credentials", wrapper.GetUserCredentials)
+
+	// second factors: listing them and setting up an authenticator app
+	g.GET("/mfa", wrapper.GetUserMfa)
+	g.POST("/mfa/totp", wrapper.PostUserMfaTotp)
+	g.POST("/mfa/totp/confirm", wrapper.PostUserMfaTotpConfirm)
+	g.DELETE("/mfa/totp", wrapper.DeleteUserMfaTotp)
+	// single use codes for when the second factor is lost
+	g.GET("/mfa/recovery_codes", wrapper.GetUserMfaRecoveryCodes)
+	g.POST("/mfa/recovery_codes", wrapper.PostUserMfaRecoveryCodes)
+
+	// passkeys, used both to sign in and as a second factor
+	g.GET("/passkeys", wrapper.GetUserPasskeys)
+	g.POST("/passkeys/register/start", wrapper.PostUserPasskeysRegisterStart)
+	g.POST("/passkeys/register/finish", wrapper.PostUserPasskeysRegisterFinish)
+	g.PATCH("/passkeys/:passkey_id", wrapper.PatchUserPasskeysPasskeyId)
+	g.DELETE("/passkeys/:passkey_id", wrapper.DeleteUserPasskeysPasskeyId)
+
+	// new code for the current session, asking for a second factor or a
+	// fresh sign in first when acr_values or max_age require it
+	g.POST("/step_up", wrapper.PostUserStepUp)
+
+	// clients the user shared data with, and revoking that access
+	g.GET("/consents", wrapper.GetUserConsents)
+	g.DELETE("/consents/:client_id", wrapper.DeleteUserConsentsClientId)
+
+	// download everything stored about the user, built in the background
+	g.POST("/export", wrapper.PostUserExport)
+	g.GET("/export/:job_id", wrapper.GetUserExportJobId)
+	g.GET("/export/:job_id/download", wrapper.GetUserExportJobIdDownload)
+
+	// delete the account, it can be restored until the grace period ends
+	g.DELETE("", wrapper.DeleteUser)
+
 	// provided an id token, revoke it
 	g.POST("/revoke/id", handlers.StubHandler)
 
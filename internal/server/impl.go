@@ -4,6 +4,8 @@ import (
 	"sentinel-auth-backend/internal/api"
 	"sentinel-auth-backend/internal/config"
 	"sentinel-auth-backend/internal/handlers"
+	"sentinel-auth-backend/internal/mail"
+	"sentinel-auth-backend/internal/sms"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -12,12 +14,16 @@ import (
 type Server struct {
 	DB     *gorm.DB
 	Config *config.Config
+	Mailer mail.Mailer
+	SMS    sms.SMSSender
 }
 
-func Create(db *gorm.DB, config *config.Config) *Server {
+func Create(db *gorm.DB, config *config.Config, mailer mail.Mailer, smsSender sms.SMSSender) *Server {
 	return &Server{
 		DB:     db,
 		Config: config,
+		Mailer: mailer,
+		SMS:    smsSender,
 	}
 }
 
@@ -26,21 +32,293 @@ func (s *Server) GetAuthProviders(c *gin.Context, params api.GetAuthProvidersPar
 }
 
 func (s *Server) PostAuthProvidersEmailRegister(c *gin.Context) {
-	handlers.MakePostProviderEmailRegisterHandler(s.DB)(c)
+	handlers.MakePostProviderEmailRegisterHandler(s.DB, s.Config, s.Mailer)(c)
 }
 
 func (s *Server) PostAuthProvidersEmailLogin(c *gin.Context) {
-	handlers.MakePostProviderEmailLoginHandler(s.DB)(c)
+	handlers.MakePostProviderEmailLoginHandler(s.DB, s.Mailer)(c)
 }
 
 func (s *Server) PostAuthToken(c *gin.Context) {
-	handlers.MakePostAuthTokenHandler(s.DB)(c)
+	handlers.MakePostAuthTokenHandler(s.DB, s.Config)(c)
 }
 
 func (s *Server) PostAuthRefresh(c *gin.Context) {
-	handlers.MakePostAuthRefreshHandler(s.DB)(c)
+	handlers.MakePostAuthRefreshHandler(s.DB, s.Config)(c)
 }
 
 func (s *Server) PostAuthVerify(c *gin.Context) {
-	handlers.MakePostAuthVerifyHandler(s.DB)(c)
+	handlers.MakePostAuthVerifyHandler(s.DB, s.Config)(c)
+}
+
+func (s *Server) PostClients(c *gin.Context) {
+	handlers.MakePostClientsHandler(s.DB, s.Config)(c)
+}
+
+func (s *Server) GetClientsClientId(c *gin.Context, clientId string) {
+	handlers.MakeGetClientsClientIdHandler(s.DB, s.Config)(c, clientId)
+}
+
+func (s *Server) PutClientsClientId(c *gin.Context, clientId string) {
+	handlers.MakePutClientsClientIdHandler(s.DB, s.Config)(c, clientId)
+}
+
+func (s *Server) DeleteClientsClientId(c *gin.Context, clientId string) {
+	handlers.MakeDeleteClientsClientIdHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) GetScopes(c *gin.Context) {
+	handlers.MakeGetScopesHandler(s.DB)(c)
+}
+
+func (s *Server) PostAdminScopes(c *gin.Context) {
+	handlers.MakePostAdminScopesHandler(s.DB)(c)
+}
+
+func (s *Server) DeleteAdminScopesScope(c *gin.Context, scope string) {
+	handlers.MakeDeleteAdminScopesScopeHandler(s.DB)(c, scope)
+}
+
+func (s *Server) PutAdminClientsClientIdScopes(c *gin.Context, clientId string) {
+	handlers.MakePutAdminClientsClientIdScopesHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) GetWellKnownJwksJson(c *gin.Context) {
+	handlers.MakeGetWellKnownJwksHandler(s.DB)(c)
+}
+
+func (s *Server) GetAdminResources(c *gin.Context) {
+	handlers.MakeGetAdminResourcesHandler(s.DB)(c)
+}
+
+func (s *Server) PostAdminResources(c *gin.Context) {
+	handlers.MakePostAdminResourcesHandler(s.DB)(c)
+}
+
+func (s *Server) PutAdminResourcesResourceId(c *gin.Context, resourceId string) {
+	handlers.MakePutAdminResourcesResourceIdHandler(s.DB)(c, resourceId)
+}
+
+func (s *Server) DeleteAdminResourcesResourceId(c *gin.Context, resourceId string) {
+	handlers.MakeDeleteAdminResourcesResourceIdHandler(s.DB)(c, resourceId)
+}
+
+func (s *Server) GetAuthConsent(c *gin.Context, params api.GetAuthConsentParams) {
+	handlers.MakeGetAuthConsentHandler(s.DB)(c, params)
+}
+
+func (s *Server) PostAuthConsent(c *gin.Context) {
+	handlers.MakePostAuthConsentHandler(s.DB)(c)
+}
+
+func (s *Server) GetUserConsents(c *gin.Context) {
+	handlers.MakeGetUserConsentsHandler(s.DB)(c)
+}
+
+func (s *Server) DeleteUserConsentsClientId(c *gin.Context, clientId string) {
+	handlers.MakeDeleteUserConsentsClientIdHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) GetAuthProvidersEmailVerify(c *gin.Context, params api.GetAuthProvidersEmailVerifyParams) {
+	handlers.MakeGetProviderEmailVerifyHandler(s.DB)(c, params)
+}
+
+func (s *Server) PostAuthProvidersEmailVerifyResend(c *gin.Context) {
+	handlers.MakePostProviderEmailVerifyResendHandler(s.DB, s.Config, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthProvidersEmailPasswordForgot(c *gin.Context) {
+	handlers.MakePostProviderEmailPasswordForgotHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthProvidersEmailPasswordReset(c *gin.Context) {
+	handlers.MakePostProviderEmailPasswordResetHandler(s.DB)(c)
+}
+
+func (s *Server) PutUserPassword(c *gin.Context) {
+	handlers.MakePutUserPasswordHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) GetUserCredentials(c *gin.Context) {
+	handlers.MakeGetUserCredentialsHandler(s.DB)(c)
+}
+
+func (s *Server) PutAdminClientsClientIdProvidersProviderId(c *gin.Context, clientId string, providerId string) {
+	handlers.MakePutAdminClientsClientIdProvidersProviderIdHandler(s.DB)(c, clientId, providerId)
+}
+
+func (s *Server) PostAuthProvidersMagicLinkStart(c *gin.Context) {
+	handlers.MakePostProviderMagicLinkStartHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthProvidersMagicLinkVerify(c *gin.Context) {
+	handlers.MakePostProviderMagicLinkVerifyHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthProvidersEmailOtpStart(c *gin.Context) {
+	handlers.MakePostProviderEmailOtpStartHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthProvidersEmailOtpVerify(c *gin.Context) {
+	handlers.MakePostProviderEmailOtpVerifyHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthProvidersPhoneStart(c *gin.Context) {
+	handlers.MakePostProviderPhoneStartHandler(s.DB, s.SMS)(c)
+}
+
+func (s *Server) PostAuthProvidersPhoneVerify(c *gin.Context) {
+	handlers.MakePostProviderPhoneVerifyHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthMfa(c *gin.Context) {
+	handlers.MakePostAuthMfaHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthReauthenticate(c *gin.Context) {
+	handlers.MakePostAuthReauthenticateHandler(s.DB)(c)
+}
+
+func (s *Server) GetUserMfa(c *gin.Context) {
+	handlers.MakeGetUserMfaHandler(s.DB)(c)
+}
+
+func (s *Server) PostUserMfaTotp(c *gin.Context) {
+	handlers.MakePostUserMfaTotpHandler(s.DB)(c)
+}
+
+func (s *Server) PostUserMfaTotpConfirm(c *gin.Context) {
+	handlers.MakePostUserMfaTotpConfirmHandler(s.DB)(c)
+}
+
+func (s *Server) DeleteUserMfaTotp(c *gin.Context) {
+	handlers.MakeDeleteUserMfaTotpHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthProvidersPasskeyStart(c *gin.Context) {
+	handlers.MakePostProviderPasskeyStartHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthProvidersPasskeyFinish(c *gin.Context) {
+	handlers.MakePostProviderPasskeyFinishHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthMfaPasskeyStart(c *gin.Context) {
+	handlers.MakePostAuthMfaPasskeyStartHandler(s.DB)(c)
+}
+
+func (s *Server) GetUserPasskeys(c *gin.Context) {
+	handlers.MakeGetUserPasskeysHandler(s.DB)(c)
+}
+
+func (s *Server) PostUserPasskeysRegisterStart(c *gin.Context) {
+	handlers.MakePostUserPasskeysRegisterStartHandler(s.DB)(c)
+}
+
+func (s *Server) PostUserPasskeysRegisterFinish(c *gin.Context) {
+	handlers.MakePostUserPasskeysRegisterFinishHandler(s.DB)(c)
+}
+
+func (s *Server) PatchUserPasskeysPasskeyId(c *gin.Context, passkeyId string) {
+	handlers.MakePatchUserPasskeysPasskeyIdHandler(s.DB)(c, passkeyId)
+}
+
+func (s *Server) DeleteUserPasskeysPasskeyId(c *gin.Context, passkeyId string) {
+	handlers.MakeDeleteUserPasskeysPasskeyIdHandler(s.DB)(c, passkeyId)
+}
+
+func (s *Server) GetUserMfaRecoveryCodes(c *gin.Context) {
+	handlers.MakeGetUserMfaRecoveryCodesHandler(s.DB)(c)
+}
+
+func (s *Server) PostUserMfaRecoveryCodes(c *gin.Context) {
+	handlers.MakePostUserMfaRecoveryCodesHandler(s.DB)(c)
+}
+
+func (s *Server) GetAdminUsersUserIdMfa(c *gin.Context, userId string) {
+	handlers.MakeGetAdminUsersUserIdMfaHandler(s.DB)(c, userId)
+}
+
+func (s *Server) PostUserStepUp(c *gin.Context) {
+	handlers.MakePostUserStepUpHandler(s.DB)(c)
+}
+
+func (s *Server) PostAuthProvidersEmailUnlock(c *gin.Context) {
+	handlers.MakePostProviderEmailUnlockHandler(s.DB)(c)
+}
+
+func (s *Server) PutAdminClientsClientIdLockout(c *gin.Context, clientId string) {
+	handlers.MakePutAdminClientsClientIdLockoutHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) PutAdminClientsClientIdEmailVerification(c *gin.Context, clientId string) {
+	handlers.MakePutAdminClientsClientIdEmailVerificationHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) DeleteAdminUsersUserIdLockout(c *gin.Context, userId string) {
+	handlers.MakeDeleteAdminUsersUserIdLockoutHandler(s.DB)(c, userId)
+}
+
+func (s *Server) GetAdminClientsClientIdPasswordPolicy(c *gin.Context, clientId string) {
+	handlers.MakeGetAdminClientsClientIdPasswordPolicyHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) PutAdminClientsClientIdPasswordPolicy(c *gin.Context, clientId string) {
+	handlers.MakePutAdminClientsClientIdPasswordPolicyHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) PostAdminClientsClientIdUsersImport(c *gin.Context, clientId string, params api.PostAdminClientsClientIdUsersImportParams) {
+	handlers.MakePostAdminClientsClientIdUsersImportHandler(s.DB)(c, clientId, params)
+}
+
+func (s *Server) GetAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string) {
+	handlers.MakeGetAdminClientsClientIdLegacyAuthenticatorHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) PutAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string) {
+	handlers.MakePutAdminClientsClientIdLegacyAuthenticatorHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) DeleteAdminClientsClientIdLegacyAuthenticator(c *gin.Context, clientId string) {
+	handlers.MakeDeleteAdminClientsClientIdLegacyAuthenticatorHandler(s.DB)(c, clientId)
+}
+
+func (s *Server) PostUserExport(c *gin.Context) {
+	handlers.MakePostUserExportHandler(s.DB)(c)
+}
+
+func (s *Server) GetUserExportJobId(c *gin.Context, jobId string) {
+	handlers.MakeGetUserExportJobIdHandler(s.DB)(c, jobId)
+}
+
+func (s *Server) GetUserExportJobIdDownload(c *gin.Context, jobId string) {
+	handlers.MakeGetUserExportJobIdDownloadHandler(s.DB)(c, jobId)
+}
+
+func (s *Server) PostAdminUsersUserIdExport(c *gin.Context, userId string) {
+	handlers.MakePostAdminUsersUserIdExportHandler(s.DB)(c, userId)
+}
+
+func (s *Server) GetAdminUsersUserIdExportJobId(c *gin.Context, userId string, jobId string) {
+	handlers.MakeGetAdminUsersUserIdExportJobIdHandler(s.DB)(c, userId, jobId)
+}
+
+func (s *Server) GetAdminUsersUserIdExportJobIdDownload(c *gin.Context, userId string, jobId string) {
+	handlers.MakeGetAdminUsersUserIdExportJobIdDownloadHandler(s.DB)(c, userId, jobId)
+}
+
+func (s *Server) DeleteUser(c *gin.Context) {
+	handlers.MakeDeleteUserHandler(s.DB, s.Mailer)(c)
+}
+
+func (s *Server) PostAuthAccountRestore(c *gin.Context) {
+	handlers.MakePostAuthAccountRestoreHandler(s.DB)(c)
+}
+
+func (s *Server) DeleteAdminUsersUserId(c *gin.Context, userId string, params api.DeleteAdminUsersUserIdParams) {
+	handlers.MakeDeleteAdminUsersUserIdHandler(s.DB, s.Mailer)(c, userId, params)
+}
+
+func (s *Server) PostAdminUsersUserIdRestore(c *gin.Context, userId string) {
+	handlers.MakePostAdminUsersUserIdRestoreHandler(s.DB)(c, userId)
 }
@@ -0,0 +1,87 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sentinel-auth-backend/internal/config"
+	"sync"
+)
+
+type Message struct {
+	// E.164 phone number
+	To   string
+	Body string
+}
+
+// SMSSender delivers text messages to phones. gateways plug in by
+// implementing it
+type SMSSender interface {
+	Send(message Message) error
+}
+
+// FakeSender keeps the messages it is given instead of sending them, and
+// prints them when out is set. for local development and tests
+type FakeSender struct {
+	out      io.Writer
+	messages []Message
+	mutex    sync.Mutex
+}
+
+func NewFakeSender(out io.Writer) *FakeSender {
+	return &FakeSender{out: out}
+}
+
+func (s *FakeSender) Send(message Message) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, message)
+	if s.out == nil {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(s.out, "SMS to: %s\n\n%s\n\n", message.To, message.Body)
+	return err
+}
+
+// Messages returns what was sent so far, oldest first
+func (s *FakeSender) Messages() []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Message{}, s.messages...)
+}
+
+// Last returns the latest message sent to a number
+func (s *FakeSender) Last(to string) (Message, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To == to {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// FromConfig picks the gateway configured through SMS_SENDER. without one
+// there is no sender and the phone provider can't be used. printing texts to
+// stdout has to be asked for explicitly, it is only meant for development
+func FromConfig(appConfig config.Config) (SMSSender, error) {
+	switch appConfig.SMS_SENDER {
+	case "":
+		return nil, nil
+	case "twilio":
+		return NewTwilioSender(TwilioConfig{
+			AccountSid: appConfig.TWILIO_ACCOUNT_SID,
+			AuthToken:  appConfig.TWILIO_AUTH_TOKEN,
+			From:       appConfig.SMS_FROM,
+		}), nil
+	case "stdout":
+		return NewFakeSender(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS_SENDER %q", appConfig.SMS_SENDER)
+	}
+}
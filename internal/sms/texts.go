@@ -0,0 +1,24 @@
+package sms
+
+import "fmt"
+
+// sign in code texts by locale, kept short to fit in a single message
+var signInCodeTexts = map[string]string{
+	"en": "%s is your %s sign in code. It expires in %d minutes. Never share it with anyone.",
+	"es": "%s es tu código de acceso a %s. Caduca en %d minutos. No lo compartas con nadie.",
+	"fr": "%s est votre code de connexion à %s. Il expire dans %d minutes. Ne le partagez jamais.",
+}
+
+// SignInCode writes the text carrying a sign in code, in english when locale
+// isn't supported
+func SignInCode(to string, locale string, clientName string, code string, expiresInMinutes int) Message {
+	text, ok := signInCodeTexts[locale]
+	if !ok {
+		text = signInCodeTexts["en"]
+	}
+
+	return Message{
+		To:   to,
+		Body: fmt.Sprintf(text, code, clientName, expiresInMinutes),
+	}
+}
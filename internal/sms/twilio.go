@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioApiUrl = "https://api.twilio.com/2010-04-01"
+
+type TwilioConfig struct {
+	AccountSid string
+	AuthToken  string
+	// number or alphanumeric sender id the texts come from
+	From string
+}
+
+// TwilioSender sends texts through twilio's messages api
+type TwilioSender struct {
+	config TwilioConfig
+	apiUrl string
+	client *http.Client
+}
+
+func NewTwilioSender(config TwilioConfig) *TwilioSender {
+	return &TwilioSender{
+		config: config,
+		apiUrl: twilioApiUrl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TwilioSender) Send(message Message) error {
+	form := url.Values{}
+	form.Set("To", message.To)
+	form.Set("From", s.config.From)
+	form.Set("Body", message.Body)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", s.apiUrl, url.PathEscape(s.config.AccountSid))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.config.AccountSid, s.config.AuthToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("twilio answered %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package validators
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// most used passwords, most common first
+//
+//go:embed common-passwords.txt
+var commonPasswordsText string
+
+var commonPasswordRanks = func() map[string]int {
+	ranks := map[string]int{}
+	for i, word := range strings.Fields(commonPasswordsText) {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}()
+
+// rows of a qwerty keyboard, for finding runs of neighbouring keys
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+var leetSubstitutions = strings.NewReplacer(
+	"@", "a", "4", "a", "8", "b", "(", "c", "3", "e", "6", "g", "1", "i", "!", "i",
+	"|", "l", "0", "o", "$", "s", "5", "s", "7", "t", "+", "t", "2", "z",
+)
+
+// password is worth this many guesses per character no pattern explains
+const bruteforceGuessesPerRune = 10
+
+// PasswordStrength estimates how hard a password is to guess, from 0 (too
+// guessable) to 4 (very unguessable), the same scale as zxcvbn. the password
+// is split into the cheapest series of patterns an attacker would try: common
+// passwords, userInputs like the user's name or email, keyboard runs,
+// sequences, repeats and years, with leftover characters guessed one by one
+func PasswordStrength(password string, userInputs []string) int {
+	guesses := PasswordGuesses(password, userInputs)
+	switch {
+	case guesses < 1e3:
+		return 0
+	case guesses < 1e6:
+		return 1
+	case guesses < 1e8:
+		return 2
+	case guesses < 1e10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// PasswordGuesses estimates how many guesses it takes to find password
+func PasswordGuesses(password string, userInputs []string) float64 {
+	inputRanks := map[string]int{}
+	for i, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if utf8.RuneCountInString(input) >= 3 {
+			if _, ok := inputRanks[input]; !ok {
+				inputRanks[input] = i + 1
+			}
+		}
+	}
+
+	return minimumGuesses([]rune(password), inputRanks)
+}
+
+// minimumGuesses splits runes into the patterns that are cheapest to guess
+func minimumGuesses(runes []rune, inputRanks map[string]int) float64 {
+	// best[i] is the fewest guesses for the first i runes
+	best := make([]float64, len(runes)+1)
+	best[0] = 1
+	for end := 1; end <= len(runes); end++ {
+		best[end] = best[end-1] * bruteforceGuessesPerRune
+		for start := 0; start < end; start++ {
+			if guesses := patternGuesses(runes[start:end], inputRanks); guesses > 0 {
+				best[end] = math.Min(best[end], best[start]*guesses)
+			}
+		}
+	}
+	return best[len(runes)]
+}
+
+// patternGuesses is the guesses for token when it matches a known pattern,
+// 0 when it doesn't
+func patternGuesses(token []rune, inputRanks map[string]int) float64 {
+	if len(token) < 3 {
+		return 0
+	}
+
+	guesses := math.Inf(1)
+	if dictionary := dictionaryGuesses(string(token), inputRanks); dictionary > 0 {
+		guesses = dictionary
+	}
+	if sequence := sequenceGuesses(token); sequence > 0 {
+		guesses = math.Min(guesses, sequence)
+	}
+	if repeat := repeatGuesses(token, inputRanks); repeat > 0 {
+		guesses = math.Min(guesses, repeat)
+	}
+	if keyboard := keyboardGuesses(token); keyboard > 0 {
+		guesses = math.Min(guesses, keyboard)
+	}
+	if year := yearGuesses(string(token)); year > 0 {
+		guesses = math.Min(guesses, year)
+	}
+
+	if math.IsInf(guesses, 1) {
+		return 0
+	}
+	return guesses
+}
+
+// words from the lists, also capitalized, reversed or with l33t spelling
+func dictionaryGuesses(token string, inputRanks map[string]int) float64 {
+	lower := strings.ToLower(token)
+
+	variations := 1.0
+	if lower != token {
+		// capitalized first letter or all caps are the usual variations
+		variations = 2
+		first, size := utf8.DecodeRuneInString(token)
+		capitalized := string(unicode.ToUpper(first)) + lower[size:]
+		if strings.ToUpper(token) != token && capitalized != token {
+			variations = 4
+		}
+	}
+
+	candidates := []struct {
+		word   string
+		factor float64
+	}{
+		{lower, 1},
+		{reverse(lower), 2},
+	}
+	if unleet := leetSubstitutions.Replace(lower); unleet != lower {
+		candidates = append(candidates, struct {
+			word   string
+			factor float64
+		}{unleet, 2})
+	}
+
+	guesses := 0.0
+	for _, candidate := range candidates {
+		rank := 0
+		if r, ok := inputRanks[candidate.word]; ok {
+			rank = r
+		} else if r, ok := commonPasswordRanks[candidate.word]; ok {
+			rank = r
+		}
+		if rank == 0 {
+			continue
+		}
+		candidateGuesses := float64(rank) * candidate.factor * variations
+		if guesses == 0 || candidateGuesses < guesses {
+			guesses = candidateGuesses
+		}
+	}
+	return guesses
+}
+
+func reverse(text string) string {
+	runes := []rune(text)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// runs like abc, 9876 or aceg
+func sequenceGuesses(token []rune) float64 {
+	delta := token[1] - token[0]
+	if delta == 0 || delta > 2 || delta < -2 {
+		return 0
+	}
+	for i := 2; i < len(token); i++ {
+		if token[i]-token[i-1] != delta {
+			return 0
+		}
+	}
+
+	start := unicode.ToLower(token[0])
+	base := 26.0
+	switch {
+	case strings.ContainsRune("az019", start):
+		base = 4
+	case unicode.IsDigit(start):
+		base = 10
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return base * float64(len(token))
+}
+
+// the same character or block over and over, like aaaa or abcabc
+func repeatGuesses(token []rune, inputRanks map[string]int) float64 {
+	for size := 1; size <= len(token)/2; size++ {
+		if len(token)%size != 0 {
+			continue
+		}
+		block := token[:size]
+		repeated := true
+		for i := size; i < len(token); i++ {
+			if token[i] != block[i%size] {
+				repeated = false
+				break
+			}
+		}
+		if !repeated {
+			continue
+		}
+
+		return minimumGuesses(block, inputRanks) * float64(len(token)/size)
+	}
+	return 0
+}
+
+func keyPosition(key rune) (int, int, bool) {
+	for row, keys := range keyboardRows {
+		if column := strings.IndexRune(keys, key); column >= 0 {
+			return row, column, true
+		}
+	}
+	return 0, 0, false
+}
+
+// runs of neighbouring keys like qwerty, asdf or 1qaz. every change of
+// direction makes the run a little harder to guess
+func keyboardGuesses(token []rune) float64 {
+	if len(token) < 4 {
+		return 0
+	}
+
+	turns := 0
+	lastRow, lastColumn := 0, 0
+	lastDirection := [2]int{}
+	for i, key := range token {
+		row, column, ok := keyPosition(unicode.ToLower(key))
+		if !ok {
+			return 0
+		}
+		if i > 0 {
+			direction := [2]int{row - lastRow, column - lastColumn}
+			if direction[0] < -1 || direction[0] > 1 || direction[1] < -1 || direction[1] > 1 || direction == [2]int{} {
+				return 0
+			}
+			if i > 1 && direction != lastDirection {
+				turns++
+			}
+			lastDirection = direction
+		}
+		lastRow, lastColumn = row, column
+	}
+
+	// about 40 keys to start from
+	return 40 * float64(len(token)) * math.Pow(4, float64(turns+1))
+}
+
+// years people like to put in passwords
+func yearGuesses(token string) float64 {
+	if len(token) != 4 {
+		return 0
+	}
+	for _, r := range token {
+		if !unicode.IsDigit(r) {
+			return 0
+		}
+	}
+	if token < "1900" || token > "2049" {
+		return 0
+	}
+	return 150
+}
+
+// ContainsWord finds the first of words, three characters or longer, that
+// password contains regardless of case or l33t spelling
+func ContainsWord(password string, words []string) (string, bool) {
+	lower := strings.ToLower(password)
+	unleet := leetSubstitutions.Replace(lower)
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if utf8.RuneCountInString(word) < 3 {
+			continue
+		}
+		if strings.Contains(lower, word) || strings.Contains(unleet, word) {
+			return word, true
+		}
+	}
+	return "", false
+}
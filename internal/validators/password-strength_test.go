@@ -0,0 +1,63 @@
+package validators
+
+import "testing"
+
+func TestPasswordStrength(t *testing.T) {
+	userInputs := []string{"john.smith@example.com", "johnsmith", "Sentinel"}
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 0},
+		{"P@ssw0rd", 0},
+		{"drowssap", 0},
+		{"123456", 0},
+		{"qwertyuiop", 0},
+		{"abcdefgh", 0},
+		{"aaaaaaaaaa", 0},
+		{"1987", 0},
+		{"iloveyou1990", 1},
+		// guessable only because they are made of the user's own details
+		{"johnsmith2024", 0},
+		{"sentinel123", 0},
+		{"Tr0ub4dour&3", 4},
+		{"kX9$mQ2!vL7#pR4w", 4},
+	}
+
+	for _, test := range tests {
+		if got := PasswordStrength(test.password, userInputs); got != test.want {
+			t.Errorf("PasswordStrength(%q) = %d, want %d", test.password, got, test.want)
+		}
+	}
+}
+
+func TestPasswordGuessesGrowWithLength(t *testing.T) {
+	short := PasswordGuesses("x7#Kq9", nil)
+	long := PasswordGuesses("x7#Kq9!mZ2@v", nil)
+	if long <= short {
+		t.Errorf("%g guesses for 12 random characters, %g for 6", long, short)
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		password string
+		words    []string
+		want     string
+		found    bool
+	}{
+		{"mySentinelPass", []string{"sentinel"}, "sentinel", true},
+		{"s3nt1n3l!", []string{"Sentinel"}, "sentinel", true},
+		{"john.smith@example.com!", []string{"john.smith@example.com"}, "john.smith@example.com", true},
+		// words shorter than three characters are ignored
+		{"abcdef", []string{"ab", " "}, "", false},
+		{"unrelated", []string{"sentinel"}, "", false},
+	}
+
+	for _, test := range tests {
+		got, found := ContainsWord(test.password, test.words)
+		if got != test.want || found != test.found {
+			t.Errorf("ContainsWord(%q, %v) = %q, %v, want %q, %v", test.password, test.words, got, found, test.want, test.found)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package validators
 
 import (
 	"regexp"
-	"unicode/utf8"
+	"strings"
 )
 
 func IsValidEmail(email string) bool {
@@ -10,7 +10,49 @@ func IsValidEmail(email string) bool {
 	return re.MatchString(email)
 }
 
-func IsPasswordStrong(password string) bool {
-	runeLength := utf8.RuneCountInString(password)
-	return runeLength >= 6
+// IsValidScopeName checks the scope-token grammar from RFC 6749 section 3.3
+func IsValidScopeName(scope string) bool {
+	re := regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+	return re.MatchString(scope)
+}
+
+// NormalizePhoneNumber turns a phone number as users type it into E.164.
+// numbers without an international prefix get defaultCountryCode (like "44"
+// or "+44") with the national trunk zero removed, and are rejected when
+// there is none
+func NormalizePhoneNumber(phone string, defaultCountryCode string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	number := digits.String()
+
+	if !international && strings.HasPrefix(number, "00") {
+		number = number[2:]
+		international = true
+	}
+
+	if !international {
+		countryCode := strings.TrimPrefix(strings.TrimSpace(defaultCountryCode), "+")
+		if countryCode == "" || !regexp.MustCompile(`^[1-9][0-9]{0,2}$`).MatchString(countryCode) {
+			return "", false
+		}
+		number = countryCode + strings.TrimPrefix(number, "0")
+	}
+
+	// country codes never start with zero and E.164 allows at most 15 digits
+	if !regexp.MustCompile(`^[1-9][0-9]{6,14}$`).MatchString(number) {
+		return "", false
+	}
+
+	return "+" + number, true
 }
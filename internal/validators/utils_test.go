@@ -0,0 +1,36 @@
+package validators
+
+import "testing"
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		phone              string
+		defaultCountryCode string
+		want               string
+		ok                 bool
+	}{
+		{"+44 20 7946 0958", "", "+442079460958", true},
+		{"+1 (555) 010-0199", "44", "+15550100199", true},
+		{"0044 20 7946 0958", "", "+442079460958", true},
+		// the national trunk zero goes when the country code is added
+		{"020 7946 0958", "44", "+442079460958", true},
+		{"020 7946 0958", "+44", "+442079460958", true},
+		{" 555.010.0199 ", "1", "+15550100199", true},
+		// local numbers need a default country code
+		{"020 7946 0958", "", "", false},
+		{"020 7946 0958", "0", "", false},
+		{"020 7946 0958", "4444", "", false},
+		{"+44 20 7946 0958 ext 1", "", "", false},
+		{"+0 123 456 789", "", "", false},
+		{"+12345", "", "", false},
+		{"+1234567890123456", "", "", false},
+		{"", "44", "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := NormalizePhoneNumber(test.phone, test.defaultCountryCode)
+		if got != test.want || ok != test.ok {
+			t.Errorf("NormalizePhoneNumber(%q, %q) = %q, %v, want %q, %v", test.phone, test.defaultCountryCode, got, ok, test.want, test.ok)
+		}
+	}
+}
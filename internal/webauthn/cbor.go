@@ -0,0 +1,168 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// just enough of a CBOR (RFC 8949) decoder for attestation objects and COSE
+// keys. integers decode to int64, byte strings to []byte, text to string,
+// arrays to []interface{} and maps to map[interface{}]interface{}
+
+const maxCborDepth = 16
+
+var errCbor = errors.New("malformed cbor")
+
+// decodeCbor reads one item from data and returns it with the bytes after it
+func decodeCbor(data []byte) (interface{}, []byte, error) {
+	return decodeCborItem(data, 0)
+}
+
+func cborArgument(data []byte) (byte, uint64, []byte, error) {
+	if len(data) < 1 {
+		return 0, 0, nil, errCbor
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, 0, nil, errCbor
+		}
+		return major, uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, 0, nil, errCbor
+		}
+		return major, uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, 0, nil, errCbor
+		}
+		return major, uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, 0, nil, errCbor
+		}
+		return major, binary.BigEndian.Uint64(data), data[8:], nil
+	}
+
+	// indefinite lengths are never used by authenticators
+	return 0, 0, nil, errCbor
+}
+
+func decodeCborItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCborDepth {
+		return nil, nil, errCbor
+	}
+
+	if len(data) > 0 && data[0]>>5 == 7 {
+		return decodeCborSimple(data)
+	}
+
+	major, arg, rest, err := cborArgument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, nil, errCbor
+		}
+		return int64(arg), rest, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, nil, errCbor
+		}
+		return -1 - int64(arg), rest, nil
+	case 2, 3:
+		if arg > uint64(len(rest)) {
+			return nil, nil, errCbor
+		}
+		value := rest[:arg]
+		if major == 3 {
+			return string(value), rest[arg:], nil
+		}
+		return append([]byte{}, value...), rest[arg:], nil
+	case 4:
+		if arg > uint64(len(rest)) {
+			return nil, nil, errCbor
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item interface{}
+			item, rest, err = decodeCborItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, rest, nil
+	case 5:
+		if arg > uint64(len(rest)) {
+			return nil, nil, errCbor
+		}
+		items := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value interface{}
+			key, rest, err = decodeCborItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errCbor
+			}
+			value, rest, err = decodeCborItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items[key] = value
+		}
+		return items, rest, nil
+	case 6:
+		// tags carry no meaning for us, use the tagged item
+		return decodeCborItem(rest, depth+1)
+	}
+
+	return nil, nil, errCbor
+}
+
+func decodeCborSimple(data []byte) (interface{}, []byte, error) {
+	info := data[0] & 0x1f
+	rest := data[1:]
+
+	switch info {
+	case 20:
+		return false, rest, nil
+	case 21:
+		return true, rest, nil
+	case 22, 23:
+		return nil, rest, nil
+	case 25:
+		if len(rest) < 2 {
+			return nil, nil, errCbor
+		}
+		// half precision floats aren't needed, skip the value
+		return nil, rest[2:], nil
+	case 26:
+		if len(rest) < 4 {
+			return nil, nil, errCbor
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(rest))), rest[4:], nil
+	case 27:
+		if len(rest) < 8 {
+			return nil, nil, errCbor
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(rest)), rest[8:], nil
+	}
+
+	return nil, nil, errCbor
+}
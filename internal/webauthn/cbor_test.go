@@ -0,0 +1,128 @@
+package webauthn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// examples from RFC 8949 appendix A
+func TestDecodeCborRfc8949Examples(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want interface{}
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"1903e8", int64(1000)},
+		{"1a000f4240", int64(1000000)},
+		{"1b000000e8d4a51000", int64(1000000000000)},
+		{"20", int64(-1)},
+		{"3863", int64(-100)},
+		{"3903e7", int64(-1000)},
+		{"40", []byte{}},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"60", ""},
+		{"6449455446", "IETF"},
+		{"62c3bc", "ü"},
+		{"80", []interface{}{}},
+		{"83010203", []interface{}{int64(1), int64(2), int64(3)}},
+		{"8301820203820405", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"a0", map[interface{}]interface{}{}},
+		{"a201020304", map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{"a26161016162820203", map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"fb3ff199999999999a", 1.1},
+		{"fa47c35000", float64(100000)},
+		{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.hex)
+		got, rest, err := decodeCbor(data)
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if len(rest) != 0 {
+			t.Errorf("%s: %d bytes left over", test.hex, len(rest))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.hex, got, test.want)
+		}
+	}
+}
+
+func TestDecodeCborReturnsTheRest(t *testing.T) {
+	_, rest, err := decodeCbor([]byte{0x01, 0xaa, 0xbb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rest, []byte{0xaa, 0xbb}) {
+		t.Errorf("rest = %x, want aabb", rest)
+	}
+}
+
+func TestDecodeCborRejectsMalformed(t *testing.T) {
+	tests := map[string]string{
+		"empty":                     "",
+		"truncated argument":        "19",
+		"truncated 8 byte argument": "1b00000000",
+		"uint over int64":           "1bffffffffffffffff",
+		"negative under int64":      "3bffffffffffffffff",
+		"byte string too long":      "4501020304",
+		"text too long":             "6449455",
+		"array with missing items":  "830102",
+		"array count over length":   "9bffffffffffffffff",
+		"map with missing value":    "a20102",
+		"map with byte string key":  "a1410102",
+		"map with array key":        "a1800102",
+		"indefinite array":          "9f01ff",
+		"indefinite byte string":    "5f4101ff",
+		"reserved argument":         "1c",
+		"truncated float":           "fb3ff1",
+		"unassigned simple value":   "f0",
+		"tag without item":          "c0",
+	}
+
+	for name, value := range tests {
+		data, _ := hex.DecodeString(value)
+		if _, _, err := decodeCbor(data); err == nil {
+			t.Errorf("%s (%s) was decoded", name, value)
+		}
+	}
+}
+
+func TestDecodeCborRejectsDeepNesting(t *testing.T) {
+	allowed := append(bytes.Repeat([]byte{0x81}, maxCborDepth), 0x01)
+	if _, _, err := decodeCbor(allowed); err != nil {
+		t.Errorf("%d levels were refused: %v", maxCborDepth, err)
+	}
+
+	tooDeep := append(bytes.Repeat([]byte{0x81}, maxCborDepth+1), 0x01)
+	if _, _, err := decodeCbor(tooDeep); err == nil {
+		t.Errorf("%d levels were decoded", maxCborDepth+1)
+	}
+}
+
+func TestDecodeCborRejectsEveryTruncation(t *testing.T) {
+	data := encodeCbor(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": bytes.Repeat([]byte{0x42}, 300),
+		int64(-3):  []interface{}{int64(1), "two", []byte{3}},
+	})
+	if _, _, err := decodeCbor(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for length := 0; length < len(data); length++ {
+		if _, _, err := decodeCbor(data[:length]); err == nil {
+			t.Errorf("truncated to %d of %d bytes was decoded", length, len(data))
+		}
+	}
+}
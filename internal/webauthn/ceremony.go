@@ -0,0 +1,274 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+type VerificationError string
+
+const (
+	VerificationErrorMalformed        VerificationError = "malformed webauthn response"
+	VerificationErrorWrongType        VerificationError = "webauthn response is for another ceremony"
+	VerificationErrorWrongChallenge   VerificationError = "webauthn challenge does not match"
+	VerificationErrorWrongOrigin      VerificationError = "webauthn origin is not allowed"
+	VerificationErrorWrongRpId        VerificationError = "webauthn relying party id does not match"
+	VerificationErrorUserNotPresent   VerificationError = "user presence was not confirmed"
+	VerificationErrorUserNotVerified  VerificationError = "user verification was required"
+	VerificationErrorUnsupportedKey   VerificationError = "unsupported passkey algorithm"
+	VerificationErrorInvalidSignature VerificationError = "invalid webauthn signature"
+)
+
+// authenticator data flags
+const (
+	flagUserPresent       = 0x01
+	flagUserVerified      = 0x04
+	flagBackupEligible    = 0x08
+	flagAttestedData      = 0x40
+	flagExtensionIncluded = 0x80
+)
+
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// Expectation is what a response has to match to be accepted
+type Expectation struct {
+	// base64url challenge handed out with the options
+	Challenge string
+	RpId      string
+	Origins   []string
+	// passwordless sign ins need the authenticator to have checked the user
+	RequireUserVerification bool
+}
+
+// NewChallenge returns a random base64url challenge for ceremony options
+func NewChallenge() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return EncodeBase64Url(b)
+}
+
+func EncodeBase64Url(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBase64Url accepts base64url with or without padding, like browsers
+// and libraries send it
+func DecodeBase64Url(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func parseClientData(clientDataJSON []byte) (*clientData, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return nil, errors.New(string(VerificationErrorMalformed))
+	}
+	return &data, nil
+}
+
+// ClientDataChallenge reads the challenge a response answers, to find the
+// ceremony it belongs to before verifying it
+func ClientDataChallenge(clientDataJSON []byte) (string, error) {
+	data, err := parseClientData(clientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return data.Challenge, nil
+}
+
+func checkClientData(clientDataJSON []byte, ceremony string, expect Expectation) error {
+	data, err := parseClientData(clientDataJSON)
+	if err != nil {
+		return err
+	}
+	if data.Type != ceremony {
+		return errors.New(string(VerificationErrorWrongType))
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(expect.Challenge)) != 1 {
+		return errors.New(string(VerificationErrorWrongChallenge))
+	}
+	if !slices.Contains(expect.Origins, data.Origin) {
+		return errors.New(string(VerificationErrorWrongOrigin))
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	rpIdHash  []byte
+	flags     byte
+	signCount uint32
+	// only present during registration
+	credentialId []byte
+	publicKey    []byte
+	algorithm    int
+}
+
+func parseAuthenticatorData(data []byte, expectCredential bool) (*authenticatorData, error) {
+	malformed := errors.New(string(VerificationErrorMalformed))
+	if len(data) < 37 {
+		return nil, malformed
+	}
+
+	parsed := authenticatorData{
+		rpIdHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if !expectCredential {
+		return &parsed, nil
+	}
+
+	rest := data[37:]
+	if parsed.flags&flagAttestedData == 0 || len(rest) < 18 {
+		return nil, malformed
+	}
+	// skip the aaguid, attestation isn't checked
+	rest = rest[16:]
+	idLength := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, malformed
+	}
+	parsed.credentialId = rest[:idLength]
+	rest = rest[idLength:]
+
+	publicKey, algorithm, after, err := parseCoseKey(rest)
+	if err != nil {
+		return nil, errors.New(string(VerificationErrorUnsupportedKey))
+	}
+	if len(after) > 0 && parsed.flags&flagExtensionIncluded == 0 {
+		return nil, malformed
+	}
+
+	pkix, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, errors.New(string(VerificationErrorUnsupportedKey))
+	}
+	parsed.publicKey = pkix
+	parsed.algorithm = algorithm
+
+	return &parsed, nil
+}
+
+func checkAuthenticatorData(data *authenticatorData, expect Expectation) error {
+	rpIdHash := sha256.Sum256([]byte(expect.RpId))
+	if !bytes.Equal(data.rpIdHash, rpIdHash[:]) {
+		return errors.New(string(VerificationErrorWrongRpId))
+	}
+	if data.flags&flagUserPresent == 0 {
+		return errors.New(string(VerificationErrorUserNotPresent))
+	}
+	if expect.RequireUserVerification && data.flags&flagUserVerified == 0 {
+		return errors.New(string(VerificationErrorUserNotVerified))
+	}
+	return nil
+}
+
+// Credential is a newly registered passkey
+type Credential struct {
+	Id []byte
+	// PKIX encoded
+	PublicKey      []byte
+	Algorithm      int
+	SignCount      uint32
+	UserVerified   bool
+	BackupEligible bool
+}
+
+// VerifyRegistration checks the response of navigator.credentials.create.
+// attestation statements aren't verified, options ask for none
+func VerifyRegistration(clientDataJSON []byte, attestationObject []byte, expect Expectation) (*Credential, error) {
+	if err := checkClientData(clientDataJSON, ceremonyCreate, expect); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCbor(attestationObject)
+	if err != nil {
+		return nil, errors.New(string(VerificationErrorMalformed))
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New(string(VerificationErrorMalformed))
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New(string(VerificationErrorMalformed))
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAuthenticatorData(authData, expect); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		Id:             authData.credentialId,
+		PublicKey:      authData.publicKey,
+		Algorithm:      authData.algorithm,
+		SignCount:      authData.signCount,
+		UserVerified:   authData.flags&flagUserVerified != 0,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// IsSignCountValid tells whether an assertion's signature counter may follow
+// the one stored for the passkey. it has to go up, a counter that didn't
+// means the passkey was cloned. authenticators without a counter always
+// send zero
+func IsSignCountValid(stored int64, received uint32) bool {
+	if stored == 0 && received == 0 {
+		return true
+	}
+	return int64(received) > stored
+}
+
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+// VerifyAssertion checks the response of navigator.credentials.get against
+// a stored passkey
+func VerifyAssertion(clientDataJSON []byte, rawAuthData []byte, signature []byte, publicKey []byte, algorithm int, expect Expectation) (*Assertion, error) {
+	if err := checkClientData(clientDataJSON, ceremonyGet, expect); err != nil {
+		return nil, err
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAuthenticatorData(authData, expect); err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if !verifySignature(publicKey, algorithm, signed, signature) {
+		return nil, errors.New(string(VerificationErrorInvalidSignature))
+	}
+
+	return &Assertion{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+	}, nil
+}
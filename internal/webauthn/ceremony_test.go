@@ -0,0 +1,293 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+)
+
+var testCredentialId = []byte("credential-id-0123456789")
+
+func registrationResponse(t *testing.T, rpId string, flags byte, coseKey []byte) ([]byte, []byte) {
+	t.Helper()
+	authData := testAuthData(rpId, flags, 0, testCredentialId, coseKey)
+	return testClientData(ceremonyCreate, testChallenge, testOrigin), testAttestationObject(authData)
+}
+
+func expectVerificationError(t *testing.T, name string, err error, want VerificationError) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s: was accepted, want %q", name, want)
+		return
+	}
+	if err.Error() != string(want) {
+		t.Errorf("%s: got %q, want %q", name, err, want)
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientDataJSON, attestationObject := registrationResponse(t, testRpId, flagUserPresent|flagUserVerified|flagBackupEligible|flagAttestedData, ec2CoseKey(&key.PublicKey))
+
+	credential, err := VerifyRegistration(clientDataJSON, attestationObject, testExpectation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(credential.Id) != string(testCredentialId) {
+		t.Errorf("credential id = %q", credential.Id)
+	}
+	if credential.Algorithm != AlgorithmES256 {
+		t.Errorf("algorithm = %d", credential.Algorithm)
+	}
+	if !credential.UserVerified || !credential.BackupEligible {
+		t.Errorf("flags not read: verified %v, backup eligible %v", credential.UserVerified, credential.BackupEligible)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(credential.PublicKey)
+	if err != nil || !key.PublicKey.Equal(publicKey) {
+		t.Errorf("stored public key doesn't match: %v", err)
+	}
+}
+
+func TestVerifyRegistrationChecksClientData(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, attestationObject := registrationResponse(t, testRpId, flagUserPresent|flagUserVerified|flagAttestedData, ec2CoseKey(&key.PublicKey))
+
+	tests := []struct {
+		name           string
+		clientDataJSON []byte
+		want           VerificationError
+	}{
+		{"assertion client data", testClientData(ceremonyGet, testChallenge, testOrigin), VerificationErrorWrongType},
+		{"other challenge", testClientData(ceremonyCreate, NewChallenge(), testOrigin), VerificationErrorWrongChallenge},
+		{"other origin", testClientData(ceremonyCreate, testChallenge, "https://evil.example"), VerificationErrorWrongOrigin},
+		{"subdomain origin", testClientData(ceremonyCreate, testChallenge, "https://login.example.com"), VerificationErrorWrongOrigin},
+		{"not json", []byte("{"), VerificationErrorMalformed},
+	}
+
+	for _, test := range tests {
+		_, err := VerifyRegistration(test.clientDataJSON, attestationObject, testExpectation)
+		expectVerificationError(t, test.name, err, test.want)
+	}
+}
+
+func TestVerifyRegistrationChecksAuthenticatorData(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	coseKey := ec2CoseKey(&key.PublicKey)
+
+	tests := []struct {
+		name  string
+		rpId  string
+		flags byte
+		want  VerificationError
+	}{
+		{"other rp id", "evil.example", flagUserPresent | flagUserVerified | flagAttestedData, VerificationErrorWrongRpId},
+		{"parent domain rp id", "com", flagUserPresent | flagUserVerified | flagAttestedData, VerificationErrorWrongRpId},
+		{"user not present", testRpId, flagUserVerified | flagAttestedData, VerificationErrorUserNotPresent},
+		{"user not verified", testRpId, flagUserPresent | flagAttestedData, VerificationErrorUserNotVerified},
+		{"no attested credential", testRpId, flagUserPresent | flagUserVerified, VerificationErrorMalformed},
+	}
+
+	for _, test := range tests {
+		clientDataJSON, attestationObject := registrationResponse(t, test.rpId, test.flags, coseKey)
+		_, err := VerifyRegistration(clientDataJSON, attestationObject, testExpectation)
+		expectVerificationError(t, test.name, err, test.want)
+	}
+}
+
+func TestVerifyRegistrationWithoutUserVerification(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientDataJSON, attestationObject := registrationResponse(t, testRpId, flagUserPresent|flagAttestedData, ec2CoseKey(&key.PublicKey))
+
+	expect := testExpectation
+	expect.RequireUserVerification = false
+	credential, err := VerifyRegistration(clientDataJSON, attestationObject, expect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.UserVerified {
+		t.Error("credential marked user verified without the flag")
+	}
+}
+
+func TestVerifyRegistrationRejectsUnsupportedAlgorithms(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	point, _ := ecKey.PublicKey.ECDH()
+	es384 := encodeCbor(map[interface{}]interface{}{
+		int64(coseKty): coseKtyEc2, int64(coseAlg): -35, int64(coseParam1): 2,
+		int64(coseParam2): point.Bytes()[1:49], int64(coseParam3): point.Bytes()[49:],
+	})
+
+	clientDataJSON, attestationObject := registrationResponse(t, testRpId, flagUserPresent|flagUserVerified|flagAttestedData, es384)
+	_, err := VerifyRegistration(clientDataJSON, attestationObject, testExpectation)
+	expectVerificationError(t, "ES384", err, VerificationErrorUnsupportedKey)
+}
+
+func TestVerifyRegistrationRejectsMalformedAttestation(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	coseKey := ec2CoseKey(&key.PublicKey)
+	clientDataJSON, attestationObject := registrationResponse(t, testRpId, flagUserPresent|flagUserVerified|flagAttestedData, coseKey)
+
+	for length := 0; length < len(attestationObject); length++ {
+		if _, err := VerifyRegistration(clientDataJSON, attestationObject[:length], testExpectation); err == nil {
+			t.Errorf("attestation object truncated to %d of %d bytes was accepted", length, len(attestationObject))
+		}
+	}
+
+	// authenticator data cut off inside the attested credential
+	authData := testAuthData(testRpId, flagUserPresent|flagUserVerified|flagAttestedData, 0, testCredentialId, coseKey)
+	for _, length := range []int{36, 37, 52, 54, 54 + len(testCredentialId), len(authData) - 1} {
+		_, err := VerifyRegistration(clientDataJSON, testAttestationObject(authData[:length]), testExpectation)
+		if err == nil {
+			t.Errorf("authenticator data truncated to %d of %d bytes was accepted", length, len(authData))
+		}
+	}
+
+	// bytes after the key are only allowed for extensions
+	trailing := append(append([]byte{}, authData...), 0xa0)
+	_, err := VerifyRegistration(clientDataJSON, testAttestationObject(trailing), testExpectation)
+	expectVerificationError(t, "trailing bytes", err, VerificationErrorMalformed)
+
+	withExtensions := testAuthData(testRpId, flagUserPresent|flagUserVerified|flagAttestedData|flagExtensionIncluded, 0, testCredentialId, coseKey)
+	withExtensions = append(withExtensions, 0xa0)
+	if _, err := VerifyRegistration(clientDataJSON, testAttestationObject(withExtensions), testExpectation); err != nil {
+		t.Errorf("extensions were refused: %v", err)
+	}
+
+	notAMap := encodeCbor([]interface{}{authData})
+	_, err = VerifyRegistration(clientDataJSON, notAMap, testExpectation)
+	expectVerificationError(t, "attestation list", err, VerificationErrorMalformed)
+
+	noAuthData := encodeCbor(map[interface{}]interface{}{"fmt": "none"})
+	_, err = VerifyRegistration(clientDataJSON, noAuthData, testExpectation)
+	expectVerificationError(t, "no authData", err, VerificationErrorMalformed)
+
+	emptyId := testAuthData(testRpId, flagUserPresent|flagUserVerified|flagAttestedData, 0, []byte{}, coseKey)
+	_, err = VerifyRegistration(clientDataJSON, testAttestationObject(emptyId), testExpectation)
+	expectVerificationError(t, "empty credential id", err, VerificationErrorMalformed)
+}
+
+type testPasskey struct {
+	publicKey []byte
+	algorithm int
+	sign      func(data []byte) []byte
+}
+
+func newEs256Passkey() testPasskey {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return testPasskey{publicKey, AlgorithmES256, func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		signature, _ := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		return signature
+	}}
+}
+
+func newEdDsaPasskey() testPasskey {
+	publicKeyRaw, key, _ := ed25519.GenerateKey(rand.Reader)
+	publicKey, _ := x509.MarshalPKIXPublicKey(publicKeyRaw)
+	return testPasskey{publicKey, AlgorithmEdDSA, func(data []byte) []byte {
+		return ed25519.Sign(key, data)
+	}}
+}
+
+func (passkey testPasskey) assert(rpId string, flags byte, signCount uint32) ([]byte, []byte, []byte) {
+	clientDataJSON := testClientData(ceremonyGet, testChallenge, testOrigin)
+	authData := testAuthData(rpId, flags, signCount, nil, nil)
+	return clientDataJSON, authData, passkey.sign(assertionSignedData(authData, clientDataJSON))
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	for name, passkey := range map[string]testPasskey{"ES256": newEs256Passkey(), "EdDSA": newEdDsaPasskey()} {
+		clientDataJSON, authData, signature := passkey.assert(testRpId, flagUserPresent|flagUserVerified, 7)
+
+		assertion, err := VerifyAssertion(clientDataJSON, authData, signature, passkey.publicKey, passkey.algorithm, testExpectation)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if assertion.SignCount != 7 || !assertion.UserVerified {
+			t.Errorf("%s: got %+v", name, assertion)
+		}
+	}
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	passkey := newEs256Passkey()
+	otherPasskey := newEs256Passkey()
+
+	clientDataJSON, authData, signature := passkey.assert(testRpId, flagUserPresent|flagUserVerified, 1)
+	_, wrongRpAuthData, wrongRpSignature := passkey.assert("evil.example", flagUserPresent|flagUserVerified, 1)
+	_, notPresentAuthData, notPresentSignature := passkey.assert(testRpId, flagUserVerified, 1)
+	_, notVerifiedAuthData, notVerifiedSignature := passkey.assert(testRpId, flagUserPresent, 1)
+
+	flipped := append([]byte{}, authData...)
+	flipped[32] |= flagBackupEligible
+
+	tests := []struct {
+		name           string
+		clientDataJSON []byte
+		authData       []byte
+		signature      []byte
+		publicKey      []byte
+		algorithm      int
+		want           VerificationError
+	}{
+		{"registration client data", testClientData(ceremonyCreate, testChallenge, testOrigin), authData, signature, passkey.publicKey, AlgorithmES256, VerificationErrorWrongType},
+		{"other challenge", testClientData(ceremonyGet, NewChallenge(), testOrigin), authData, signature, passkey.publicKey, AlgorithmES256, VerificationErrorWrongChallenge},
+		{"other origin", testClientData(ceremonyGet, testChallenge, "http://example.com"), authData, signature, passkey.publicKey, AlgorithmES256, VerificationErrorWrongOrigin},
+		{"other rp id", clientDataJSON, wrongRpAuthData, wrongRpSignature, passkey.publicKey, AlgorithmES256, VerificationErrorWrongRpId},
+		{"user not present", clientDataJSON, notPresentAuthData, notPresentSignature, passkey.publicKey, AlgorithmES256, VerificationErrorUserNotPresent},
+		{"user not verified", clientDataJSON, notVerifiedAuthData, notVerifiedSignature, passkey.publicKey, AlgorithmES256, VerificationErrorUserNotVerified},
+		{"short authenticator data", clientDataJSON, authData[:36], signature, passkey.publicKey, AlgorithmES256, VerificationErrorMalformed},
+		{"changed flags", clientDataJSON, flipped, signature, passkey.publicKey, AlgorithmES256, VerificationErrorInvalidSignature},
+		{"other passkey", clientDataJSON, authData, signature, otherPasskey.publicKey, AlgorithmES256, VerificationErrorInvalidSignature},
+		{"stored with another algorithm", clientDataJSON, authData, signature, passkey.publicKey, AlgorithmEdDSA, VerificationErrorInvalidSignature},
+		{"unsupported algorithm", clientDataJSON, authData, signature, passkey.publicKey, -35, VerificationErrorInvalidSignature},
+		{"empty signature", clientDataJSON, authData, []byte{}, passkey.publicKey, AlgorithmES256, VerificationErrorInvalidSignature},
+	}
+
+	for _, test := range tests {
+		_, err := VerifyAssertion(test.clientDataJSON, test.authData, test.signature, test.publicKey, test.algorithm, testExpectation)
+		expectVerificationError(t, test.name, err, test.want)
+	}
+}
+
+func TestVerifyAssertionSignatureCoversClientData(t *testing.T) {
+	passkey := newEs256Passkey()
+	_, authData, signature := passkey.assert(testRpId, flagUserPresent|flagUserVerified, 1)
+
+	// same challenge and origin, but not the client data that was signed
+	otherClientData := []byte(`{"type":"webauthn.get","challenge":"` + testChallenge + `","origin":"` + testOrigin + `","crossOrigin":false}`)
+	_, err := VerifyAssertion(otherClientData, authData, signature, passkey.publicKey, passkey.algorithm, testExpectation)
+	expectVerificationError(t, "other client data", err, VerificationErrorInvalidSignature)
+}
+
+func TestIsSignCountValid(t *testing.T) {
+	tests := []struct {
+		stored   int64
+		received uint32
+		want     bool
+	}{
+		// authenticators without a counter
+		{0, 0, true},
+		{0, 1, true},
+		{5, 6, true},
+		{5, 500, true},
+		// went backwards or stood still, the passkey was cloned
+		{5, 5, false},
+		{5, 4, false},
+		{5, 0, false},
+		{4294967295, 0, false},
+	}
+
+	for _, test := range tests {
+		if got := IsSignCountValid(test.stored, test.received); got != test.want {
+			t.Errorf("IsSignCountValid(%d, %d) = %v, want %v", test.stored, test.received, got, test.want)
+		}
+	}
+}
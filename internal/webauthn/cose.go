@@ -0,0 +1,145 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithms (RFC 9053) passkeys are created with
+const (
+	AlgorithmES256 = -7
+	AlgorithmEdDSA = -8
+	AlgorithmRS256 = -257
+)
+
+// SupportedAlgorithms in order of preference, for creation options
+var SupportedAlgorithms = []int{AlgorithmES256, AlgorithmEdDSA, AlgorithmRS256}
+
+// COSE key parameters
+const (
+	coseKty = 1
+	coseAlg = 3
+	// crv for EC2 and OKP, n for RSA
+	coseParam1 = -1
+	// x for EC2 and OKP, e for RSA
+	coseParam2 = -2
+	// y for EC2
+	coseParam3 = -3
+
+	coseKtyOkp = 1
+	coseKtyEc2 = 2
+	coseKtyRsa = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+var errUnsupportedKey = errors.New("unsupported public key")
+
+func coseInt(key map[interface{}]interface{}, label int64) (int64, bool) {
+	value, ok := key[label].(int64)
+	return value, ok
+}
+
+func coseBytes(key map[interface{}]interface{}, label int64) ([]byte, bool) {
+	value, ok := key[label].([]byte)
+	return value, ok
+}
+
+// parseCoseKey turns a COSE_Key into a public key and its algorithm
+func parseCoseKey(data []byte) (crypto.PublicKey, int, []byte, error) {
+	decoded, rest, err := decodeCbor(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, nil, errUnsupportedKey
+	}
+
+	kty, _ := coseInt(key, coseKty)
+	alg, _ := coseInt(key, coseAlg)
+
+	switch {
+	case kty == coseKtyEc2 && alg == AlgorithmES256:
+		crv, _ := coseInt(key, coseParam1)
+		x, okX := coseBytes(key, coseParam2)
+		y, okY := coseBytes(key, coseParam3)
+		if crv != coseCrvP256 || !okX || !okY || len(x) != 32 || len(y) != 32 {
+			return nil, 0, nil, errUnsupportedKey
+		}
+		// ecdh checks the point is on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, nil, errUnsupportedKey
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, AlgorithmES256, rest, nil
+
+	case kty == coseKtyOkp && alg == AlgorithmEdDSA:
+		crv, _ := coseInt(key, coseParam1)
+		x, ok := coseBytes(key, coseParam2)
+		if crv != coseCrvEd25519 || !ok || len(x) != ed25519.PublicKeySize {
+			return nil, 0, nil, errUnsupportedKey
+		}
+		return ed25519.PublicKey(x), AlgorithmEdDSA, rest, nil
+
+	case kty == coseKtyRsa && alg == AlgorithmRS256:
+		n, okN := coseBytes(key, coseParam1)
+		e, okE := coseBytes(key, coseParam2)
+		if !okN || !okE || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, nil, errUnsupportedKey
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, AlgorithmRS256, rest, nil
+	}
+
+	return nil, 0, nil, errUnsupportedKey
+}
+
+// verifySignature checks an assertion signature made with a stored PKIX
+// public key
+func verifySignature(publicKey []byte, algorithm int, data []byte, signature []byte) bool {
+	parsed, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return false
+	}
+
+	switch algorithm {
+	case AlgorithmES256:
+		key, ok := parsed.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case AlgorithmEdDSA:
+		key, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return false
+		}
+		return ed25519.Verify(key, data, signature)
+	case AlgorithmRS256:
+		key, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+)
+
+func TestParseCoseKey(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name      string
+		coseKey   []byte
+		publicKey crypto.PublicKey
+		algorithm int
+	}{
+		{"ES256", ec2CoseKey(&ecKey.PublicKey), &ecKey.PublicKey, AlgorithmES256},
+		{"EdDSA", okpCoseKey(edKey), edKey, AlgorithmEdDSA},
+		{"RS256", rsaCoseKey(&rsaKey.PublicKey), &rsaKey.PublicKey, AlgorithmRS256},
+	}
+
+	for _, test := range tests {
+		publicKey, algorithm, rest, err := parseCoseKey(append(test.coseKey, 0xa0))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if algorithm != test.algorithm {
+			t.Errorf("%s: algorithm %d, want %d", test.name, algorithm, test.algorithm)
+		}
+		if !bytes.Equal(rest, []byte{0xa0}) {
+			t.Errorf("%s: rest = %x, want a0", test.name, rest)
+		}
+		if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(test.publicKey) {
+			t.Errorf("%s: parsed a different key", test.name)
+		}
+	}
+}
+
+func TestParseCoseKeyRejectsUnsupported(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	point, _ := ecKey.PublicKey.ECDH()
+	x, y := point.Bytes()[1:33], point.Bytes()[33:]
+	smallRsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	offCurveY := append([]byte{}, y...)
+	offCurveY[31] ^= 0x01
+
+	tests := map[string]interface{}{
+		"not a map": []interface{}{int64(1)},
+		"ES384": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): -35, int64(coseParam1): 2,
+			int64(coseParam2): x, int64(coseParam3): y,
+		},
+		"PS256": map[interface{}]interface{}{
+			int64(coseKty): coseKtyRsa, int64(coseAlg): -37,
+			int64(coseParam1): smallRsaKey.N.Bytes(), int64(coseParam2): []byte{1, 0, 1},
+		},
+		"ES256 with an okp key type": map[interface{}]interface{}{
+			int64(coseKty): coseKtyOkp, int64(coseAlg): AlgorithmES256, int64(coseParam1): coseCrvP256,
+			int64(coseParam2): x, int64(coseParam3): y,
+		},
+		"ES256 on another curve": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): AlgorithmES256, int64(coseParam1): 2,
+			int64(coseParam2): x, int64(coseParam3): y,
+		},
+		"ES256 point off the curve": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): AlgorithmES256, int64(coseParam1): coseCrvP256,
+			int64(coseParam2): x, int64(coseParam3): offCurveY,
+		},
+		"ES256 short coordinate": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): AlgorithmES256, int64(coseParam1): coseCrvP256,
+			int64(coseParam2): x[1:], int64(coseParam3): y,
+		},
+		"ES256 without y": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): AlgorithmES256, int64(coseParam1): coseCrvP256,
+			int64(coseParam2): x,
+		},
+		"EdDSA on X25519": map[interface{}]interface{}{
+			int64(coseKty): coseKtyOkp, int64(coseAlg): AlgorithmEdDSA, int64(coseParam1): 4,
+			int64(coseParam2): make([]byte, 32),
+		},
+		"EdDSA short key": map[interface{}]interface{}{
+			int64(coseKty): coseKtyOkp, int64(coseAlg): AlgorithmEdDSA, int64(coseParam1): coseCrvEd25519,
+			int64(coseParam2): make([]byte, 31),
+		},
+		"RS256 under 2048 bits": map[interface{}]interface{}{
+			int64(coseKty): coseKtyRsa, int64(coseAlg): AlgorithmRS256,
+			int64(coseParam1): smallRsaKey.N.Bytes(), int64(coseParam2): []byte{1, 0, 1},
+		},
+		"RS256 without exponent": map[interface{}]interface{}{
+			int64(coseKty): coseKtyRsa, int64(coseAlg): AlgorithmRS256,
+			int64(coseParam1): make([]byte, 256),
+		},
+		"algorithm as text": map[interface{}]interface{}{
+			int64(coseKty): coseKtyEc2, int64(coseAlg): "ES256", int64(coseParam1): coseCrvP256,
+			int64(coseParam2): x, int64(coseParam3): y,
+		},
+	}
+
+	for name, key := range tests {
+		if _, _, _, err := parseCoseKey(encodeCbor(key)); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte("authenticator data and client data hash")
+	digest := sha256.Sum256(data)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecPublic, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	ecSignature, _ := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+
+	edPublicKey, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPublic, _ := x509.MarshalPKIXPublicKey(edPublicKey)
+	edSignature := ed25519.Sign(edKey, data)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPublic, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaSignature, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+
+	tests := []struct {
+		name      string
+		publicKey []byte
+		algorithm int
+		signature []byte
+	}{
+		{"ES256", ecPublic, AlgorithmES256, ecSignature},
+		{"EdDSA", edPublic, AlgorithmEdDSA, edSignature},
+		{"RS256", rsaPublic, AlgorithmRS256, rsaSignature},
+	}
+
+	for _, test := range tests {
+		if !verifySignature(test.publicKey, test.algorithm, data, test.signature) {
+			t.Errorf("%s: valid signature was refused", test.name)
+		}
+
+		tampered := append([]byte{}, data...)
+		tampered[0] ^= 0x01
+		if verifySignature(test.publicKey, test.algorithm, tampered, test.signature) {
+			t.Errorf("%s: signature over other data was accepted", test.name)
+		}
+
+		for _, other := range tests {
+			if other.algorithm != test.algorithm && verifySignature(test.publicKey, other.algorithm, data, test.signature) {
+				t.Errorf("%s: accepted as %s", test.name, other.name)
+			}
+		}
+	}
+
+	if verifySignature(ecPublic, -35, data, ecSignature) {
+		t.Error("unsupported algorithm was accepted")
+	}
+	if verifySignature([]byte("not a key"), AlgorithmES256, data, ecSignature) {
+		t.Error("invalid public key was accepted")
+	}
+}
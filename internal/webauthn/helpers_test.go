@@ -0,0 +1,168 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// the tests build authenticator responses themselves, with keys generated
+// for each run, the same way an authenticator lays them out
+
+const (
+	testRpId      = "example.com"
+	testOrigin    = "https://example.com"
+	testChallenge = "dGVzdC1jaGFsbGVuZ2UtdGVzdC1jaGFsbGVuZ2UtMzI"
+)
+
+var testExpectation = Expectation{
+	Challenge:               testChallenge,
+	RpId:                    testRpId,
+	Origins:                 []string{testOrigin},
+	RequireUserVerification: true,
+}
+
+// cborHead writes the initial byte and argument of an item
+func cborHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= 0xff:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= 0xffff:
+		buf.WriteByte(major<<5 | 25)
+		binary.Write(buf, binary.BigEndian, uint16(arg))
+	case arg <= 0xffffffff:
+		buf.WriteByte(major<<5 | 26)
+		binary.Write(buf, binary.BigEndian, uint32(arg))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		binary.Write(buf, binary.BigEndian, arg)
+	}
+}
+
+// encodeCbor writes ints, strings, byte strings, lists and maps with int or
+// string keys. map keys are sorted so the output is stable
+func encodeCbor(value interface{}) []byte {
+	var buf bytes.Buffer
+	writeCbor(&buf, value)
+	return buf.Bytes()
+}
+
+func writeCbor(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case int:
+		writeCbor(buf, int64(v))
+	case int64:
+		if v >= 0 {
+			cborHead(buf, 0, uint64(v))
+		} else {
+			cborHead(buf, 1, uint64(-1-v))
+		}
+	case []byte:
+		cborHead(buf, 2, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		cborHead(buf, 3, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		cborHead(buf, 4, uint64(len(v)))
+		for _, item := range v {
+			writeCbor(buf, item)
+		}
+	case map[interface{}]interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		cborHead(buf, 5, uint64(len(v)))
+		for _, key := range keys {
+			writeCbor(buf, key)
+			writeCbor(buf, v[key])
+		}
+	default:
+		panic(fmt.Sprintf("can't encode %T", value))
+	}
+}
+
+func ec2CoseKey(key *ecdsa.PublicKey) []byte {
+	point, _ := key.ECDH()
+	raw := point.Bytes()
+	return encodeCbor(map[interface{}]interface{}{
+		int64(coseKty):    coseKtyEc2,
+		int64(coseAlg):    AlgorithmES256,
+		int64(coseParam1): coseCrvP256,
+		int64(coseParam2): raw[1:33],
+		int64(coseParam3): raw[33:],
+	})
+}
+
+func okpCoseKey(key ed25519.PublicKey) []byte {
+	return encodeCbor(map[interface{}]interface{}{
+		int64(coseKty):    coseKtyOkp,
+		int64(coseAlg):    AlgorithmEdDSA,
+		int64(coseParam1): coseCrvEd25519,
+		int64(coseParam2): []byte(key),
+	})
+}
+
+func rsaCoseKey(key *rsa.PublicKey) []byte {
+	e := binary.BigEndian.AppendUint32(nil, uint32(key.E))
+	return encodeCbor(map[interface{}]interface{}{
+		int64(coseKty):    coseKtyRsa,
+		int64(coseAlg):    AlgorithmRS256,
+		int64(coseParam1): key.N.Bytes(),
+		int64(coseParam2): bytes.TrimLeft(e, "\x00"),
+	})
+}
+
+func testClientData(ceremony string, challenge string, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    origin,
+	})
+	return data
+}
+
+// testAuthData lays out authenticator data. credentialId and coseKey are
+// only written when coseKey is set
+func testAuthData(rpId string, flags byte, signCount uint32, credentialId []byte, coseKey []byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+
+	var buf bytes.Buffer
+	buf.Write(rpIdHash[:])
+	buf.WriteByte(flags)
+	binary.Write(&buf, binary.BigEndian, signCount)
+	if coseKey != nil {
+		buf.Write(make([]byte, 16))
+		binary.Write(&buf, binary.BigEndian, uint16(len(credentialId)))
+		buf.Write(credentialId)
+		buf.Write(coseKey)
+	}
+	return buf.Bytes()
+}
+
+func testAttestationObject(authData []byte) []byte {
+	return encodeCbor(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+}
+
+// assertionSignedData is what authenticators sign for an assertion
+func assertionSignedData(authData []byte, clientDataJSON []byte) []byte {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	return append(append([]byte{}, authData...), clientDataHash[:]...)
+}